@@ -21,9 +21,12 @@ require (
 	github.com/pkg/errors v0.9.1
 	github.com/spf13/afero v1.2.2
 	github.com/spf13/cobra v1.0.0
+	github.com/spf13/pflag v1.0.5
 	github.com/tektoncd/pipeline v0.15.2
 	github.com/tektoncd/triggers v0.5.0
+	golang.org/x/crypto v0.0.0-20200510223506-06a226fb4e37
 	gopkg.in/AlecAivazis/survey.v1 v1.8.0
+	gopkg.in/yaml.v3 v3.0.0-20200313102051-9f266ea9e77c
 	k8s.io/api v0.18.2
 	k8s.io/apimachinery v0.18.2
 	k8s.io/client-go v12.0.0+incompatible
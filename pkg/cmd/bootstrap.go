@@ -4,19 +4,32 @@ import (
 	"fmt"
 	"net/url"
 	"os"
+	"sort"
 	"strings"
+	"time"
 
 	"github.com/jenkins-x/go-scm/scm/factory"
+	"github.com/mkmik/multierror"
 	"github.com/openshift/odo/pkg/log"
 	"github.com/rhd-gitops-example/gitops-cli/pkg/cmd/genericclioptions"
 	"github.com/rhd-gitops-example/gitops-cli/pkg/cmd/ui"
 	"github.com/rhd-gitops-example/gitops-cli/pkg/cmd/utility"
 	"github.com/rhd-gitops-example/gitops-cli/pkg/pipelines"
+	"github.com/rhd-gitops-example/gitops-cli/pkg/pipelines/config"
+	"github.com/rhd-gitops-example/gitops-cli/pkg/pipelines/eventlisteners"
+	"github.com/rhd-gitops-example/gitops-cli/pkg/pipelines/git"
+	"github.com/rhd-gitops-example/gitops-cli/pkg/pipelines/imagerepo"
 	"github.com/rhd-gitops-example/gitops-cli/pkg/pipelines/ioutils"
+	"github.com/rhd-gitops-example/gitops-cli/pkg/pipelines/repotemplate"
+	"github.com/rhd-gitops-example/gitops-cli/pkg/pipelines/scm"
+	"github.com/rhd-gitops-example/gitops-cli/pkg/pipelines/secrets"
+	"github.com/rhd-gitops-example/gitops-cli/pkg/pipelines/tasks"
 	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
 
 	"k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/validation"
 	ktemplates "k8s.io/kubectl/pkg/util/templates"
 )
 
@@ -59,8 +72,32 @@ var (
 // BootstrapParameters encapsulates the parameters for the odo pipelines init command.
 type BootstrapParameters struct {
 	*pipelines.BootstrapOptions
+	credentials          []string
+	accessTokens         []string      // Tokens for the git-host-access-token, the first that authenticates is used.
+	yes                  bool          // If true, bootstrap proceeds without confirmation.
+	nonInteractive       bool          // If true, the options are never prompted for, and bootstrap isn't confirmed.
+	strictHostKeys       bool          // If false, the keys of unknown SSH hosts are accepted.
+	strictNamespaces     bool          // If true, generated namespaces that collide with system namespaces are an error, rather than a warning.
+	waitForSealedSecrets time.Duration // How long to wait for the sealed secrets controller to be ready.
+	pipelineResources    map[string]string
+	withQuota            bool              // If true, the environments are generated with a ResourceQuota and LimitRange.
+	quota                map[string]string // The quantities of the quota, keyed by cpu, memory, default-cpu and default-memory.
+	createNamespace      bool              // If true, the CI/CD namespace is created if it doesn't exist.
+	validateHook         string            // Executable that's run with the names that would be created, to reject them.
+	client               *utility.Client
 }
 
+// vars to allow replacement in tests.
+var (
+	confirmSummary    = ui.ConfirmSummary
+	selectAccessToken = ui.SelectAccessToken
+	bootstrap         = pipelines.Bootstrap
+	stdinIsTerminal   = func() bool {
+		fi, err := os.Stdin.Stat()
+		return err == nil && fi.Mode()&os.ModeCharDevice != 0
+	}
+)
+
 type status interface {
 	WarningStatus(status string)
 	Start(status string, debug bool)
@@ -70,7 +107,9 @@ type status interface {
 // NewBootstrapParameters bootsraps a Bootstrap Parameters instance.
 func NewBootstrapParameters() *BootstrapParameters {
 	return &BootstrapParameters{
-		BootstrapOptions: &pipelines.BootstrapOptions{},
+		BootstrapOptions: &pipelines.BootstrapOptions{
+			Warnf: genericclioptions.Warningf,
+		},
 	}
 }
 
@@ -82,6 +121,29 @@ func (io *BootstrapParameters) Complete(name string, cmd *cobra.Command, args []
 	if err != nil {
 		return err
 	}
+	io.MaxNameLength = utility.MaxNameLength
+
+	io.Credentials, err = git.ParseCredentials(io.credentials)
+	if err != nil {
+		return err
+	}
+	stdinSecrets := map[string]*string{
+		"gitops-webhook-secret":  &io.GitOpsWebhookSecret,
+		"service-webhook-secret": &io.ServiceWebhookSecret,
+	}
+	for i := range io.accessTokens {
+		name := "git-host-access-token"
+		if len(io.accessTokens) > 1 {
+			name = fmt.Sprintf("%s #%d", name, i+1)
+		}
+		stdinSecrets[name] = &io.accessTokens[i]
+	}
+	err = utility.ReadStdinSecrets(stdinSecrets, io.Credentials)
+	if err != nil {
+		return err
+	}
+	io.AcceptNewHostKeys = !io.strictHostKeys
+	io.client = client
 
 	if io.PrivateRepoDriver != "" {
 		host, err := hostFromURL(io.GitOpsRepoURL)
@@ -93,13 +155,12 @@ func (io *BootstrapParameters) Complete(name string, cmd *cobra.Command, args []
 	}
 
 	// ask for sealed secrets only when default is absent
-	flagset := cmd.Flags()
-	if flagset.NFlag() == 0 {
+	if !modeFlagsChanged(cmd) && !io.nonInteractive {
 		err := checkBootstrapDependencies(io, client, log.NewStatus(os.Stdout))
 		if err != nil {
 			return err
 		}
-		err = initiateInteractiveMode(io)
+		err = initiateInteractiveMode(io, client)
 		if err != nil {
 			return err
 		}
@@ -109,16 +170,38 @@ func (io *BootstrapParameters) Complete(name string, cmd *cobra.Command, args []
 			return err
 		}
 	}
+	if err := io.selectAccessToken(); err != nil {
+		return err
+	}
+	if io.waitForSealedSecrets > 0 {
+		return secrets.WaitForClusterPublicKey(io.SealedSecretsService, io.waitForSealedSecrets, client.CheckIfSealedSecretsExists)
+	}
+	return nil
+}
+
+// selectAccessToken sets the GitHostAccessToken from the provided tokens, if
+// more than one was provided, the first that authenticates for the service
+// repository is used.
+func (io *BootstrapParameters) selectAccessToken() error {
+	switch len(io.accessTokens) {
+	case 0:
+		return nil
+	case 1:
+		io.GitHostAccessToken = io.accessTokens[0]
+		return nil
+	}
+	token, err := selectAccessToken(io.accessTokens, io.ServiceRepoURL)
+	if err != nil {
+		return fmt.Errorf("none of the provided --git-host-access-token values could be used: %w", err)
+	}
+	io.GitHostAccessToken = token
 	return nil
 }
 
 // nonInteractiveMode gets triggered if a flag is passed, checks for mandatory flags.
 func nonInteractiveMode(io *BootstrapParameters, client *utility.Client) error {
-	mandatoryFlags := map[string]string{io.ServiceRepoURL: "service-repo-url", io.GitOpsRepoURL: "gitops-repo-url", io.ImageRepo: "image-repo"}
-	for key, value := range mandatoryFlags {
-		if key == "" {
-			return fmt.Errorf("The mandatory flag %q has not been set", value)
-		}
+	if err := validateFlags(io); err != nil {
+		return err
 	}
 	err := checkBootstrapDependencies(io, client, log.NewStatus(os.Stdout))
 	if err != nil {
@@ -128,11 +211,18 @@ func nonInteractiveMode(io *BootstrapParameters, client *utility.Client) error {
 }
 
 // initiateInteractiveMode starts the interactive mode impplementation if no flags are passed.
-func initiateInteractiveMode(io *BootstrapParameters) error {
+func initiateInteractiveMode(io *BootstrapParameters, client *utility.Client) error {
 	// ask for sealed secrets only when default is absent
 	if io.SealedSecretsService == (types.NamespacedName{}) {
-		io.SealedSecretsService.Name = ui.EnterSealedSecretService(&io.SealedSecretsService)
-
+		services, err := client.FindSealedSecretsServices()
+		if err != nil {
+			return clusterErr(err.Error())
+		}
+		if len(services) > 1 {
+			io.SealedSecretsService = ui.SelectSealedSecretService(services)
+		} else {
+			io.SealedSecretsService.Name = ui.EnterSealedSecretService(&io.SealedSecretsService)
+		}
 	}
 	io.GitOpsRepoURL = utility.AddGitSuffixIfNecessary(ui.EnterGitRepo())
 	if !isKnownDriver(io.GitOpsRepoURL) {
@@ -154,6 +244,11 @@ func initiateInteractiveMode(io *BootstrapParameters) error {
 	}
 	io.GitOpsWebhookSecret = ui.EnterGitWebhookSecret()
 	io.ServiceRepoURL = ui.EnterServiceRepoURL()
+	defaultName, err := pipelines.DefaultServiceName(io.ServiceRepoURL, utility.MaxNameLength)
+	if err != nil {
+		return fmt.Errorf("failed to parse the service repository url: %w", err)
+	}
+	io.ServiceName = ui.EnterServiceName(defaultName)
 	if ui.IsPrivateRepo() {
 		io.GitHostAccessToken = ui.EnterGitHostAccessToken(io.ServiceRepoURL)
 	}
@@ -166,11 +261,40 @@ func initiateInteractiveMode(io *BootstrapParameters) error {
 		}
 	}
 	io.Prefix = ui.EnterPrefix()
-	io.OutputPath = ui.EnterOutputPath()
+	io.OutputPath = ui.EnterOutputPath(io.ManifestFile)
 	io.Overwrite = true
 	return nil
 }
 
+// validateFlags checks the mandatory flags and the values of the flags that
+// would otherwise be validated by the prompts, all the failures are reported
+// together so that they can be fixed in one go.
+func validateFlags(io *BootstrapParameters) error {
+	errs := []error{}
+	mandatoryFlags := []struct {
+		flag  string
+		value string
+	}{
+		{"gitops-repo-url", io.GitOpsRepoURL},
+		{"service-repo-url", io.ServiceRepoURL},
+		{"image-repo", io.ImageRepo},
+	}
+	for _, f := range mandatoryFlags {
+		if f.value == "" {
+			errs = append(errs, fmt.Errorf("The mandatory flag %q has not been set", f.flag))
+		}
+	}
+	errs = append(errs, ui.ValidateFlags(
+		ui.PrefixFlag("prefix", io.Prefix),
+		ui.SecretFlag("gitops-webhook-secret", io.GitOpsWebhookSecret),
+		ui.SecretFlag("service-webhook-secret", io.ServiceWebhookSecret),
+	)...)
+	if len(errs) == 0 {
+		return nil
+	}
+	return multierror.Join(errs)
+}
+
 func checkBootstrapDependencies(io *BootstrapParameters, client *utility.Client, spinner status) error {
 	var errs []error
 	log.Progressf("\nChecking dependencies\n")
@@ -185,14 +309,16 @@ func checkBootstrapDependencies(io *BootstrapParameters, client *utility.Client,
 		return clusterErr(err.Error())
 	}
 
-	spinner.Start("Checking if ArgoCD Operator is installed with the default configuration", false)
-	err = client.CheckIfArgoCDExists(argoCDNS)
-	setSpinnerStatus(spinner, "Please install ArgoCD operator from OperatorHub, with an ArgoCD resource called 'argocd'", err)
-	if err != nil {
-		if !errors.IsNotFound(err) {
-			return clusterErr(err.Error())
+	if io.GitOpsEngine != pipelines.FluxEngine {
+		spinner.Start("Checking if ArgoCD Operator is installed with the default configuration", false)
+		err = client.CheckIfArgoCDExists(argoCDNS)
+		setSpinnerStatus(spinner, "Please install ArgoCD operator from OperatorHub, with an ArgoCD resource called 'argocd'", err)
+		if err != nil {
+			if !errors.IsNotFound(err) {
+				return clusterErr(err.Error())
+			}
+			errs = append(errs, err)
 		}
-		errs = append(errs, err)
 	}
 
 	spinner.Start("Checking if OpenShift Pipelines Operator is installed with the default configuration", false)
@@ -222,6 +348,25 @@ func setSpinnerStatus(spinner status, warningMsg string, err error) {
 	spinner.End(true)
 }
 
+// modeFlagsChanged returns true if any of the bootstrap command's own flags
+// was given, other than those that can be used with the prompts.
+//
+// The global flags are persistent flags of the root command, and so they never
+// change the mode.
+func modeFlagsChanged(cmd *cobra.Command) bool {
+	changed := false
+	cmd.LocalFlags().VisitAll(func(f *pflag.Flag) {
+		switch f.Name {
+		case "yes", "wait-for-sealed-secrets", "manifest-file":
+			return
+		}
+		if f.Changed {
+			changed = true
+		}
+	})
+	return changed
+}
+
 // Validate validates the parameters of the BootstrapParameters.
 func (io *BootstrapParameters) Validate() error {
 	gr, err := url.Parse(io.GitOpsRepoURL)
@@ -240,16 +385,218 @@ func (io *BootstrapParameters) Validate() error {
 		}
 	}
 
+	if io.TaskCatalogRef != "" {
+		if err := ui.ValidateName(io.TaskCatalogRef); err != nil {
+			return fmt.Errorf("invalid --task-catalog-ref: %w", err)
+		}
+	}
+
+	if io.TemplateWins && io.FromTemplate == "" {
+		return fmt.Errorf("--template-wins can only be used with --from-template")
+	}
+
+	if io.ForceMakefile && !io.WithMakefile {
+		return fmt.Errorf("--force can only be used with --with-makefile")
+	}
+
+	if io.AllowLFSPointers && io.FromTemplate == "" {
+		return fmt.Errorf("--allow-lfs-pointers can only be used with --from-template")
+	}
+
+	if (len(io.TemplateVars) > 0 || io.AllowMissingVars) && io.FromTemplate == "" {
+		return fmt.Errorf("--template-var and --allow-missing-vars can only be used with --from-template")
+	}
+	for k := range io.TemplateVars {
+		if err := repotemplate.ValidateVarName(k); err != nil {
+			return fmt.Errorf("invalid --template-var: %w", err)
+		}
+	}
+
+	if io.SSHKnownHostsFile != "" {
+		if io.FromTemplate == "" {
+			return fmt.Errorf("--ssh-known-hosts can only be used with --from-template")
+		}
+		if _, err := os.Stat(io.SSHKnownHostsFile); err != nil {
+			return fmt.Errorf("failed to read the SSH known hosts file: %w", err)
+		}
+	}
+
+	if io.ManifestFile != "" {
+		if err := ui.ValidateManifestFile(io.ManifestFile, "output path"); err != nil {
+			return err
+		}
+	}
+
+	switch io.GitOpsEngine {
+	case "", pipelines.ArgoCDEngine:
+	case pipelines.FluxEngine:
+		if io.UseApplicationSet {
+			return fmt.Errorf("--use-applicationset can only be used with the %s GitOps engine", pipelines.ArgoCDEngine)
+		}
+	default:
+		return fmt.Errorf("invalid GitOps engine: %q, must be %s or %s", io.GitOpsEngine, pipelines.ArgoCDEngine, pipelines.FluxEngine)
+	}
+
+	if io.WithNamespaceDefaults && !config.IsPodSecurityLevel(io.PodSecurity) {
+		return fmt.Errorf("invalid Pod Security level: %q, must be one of %s", io.PodSecurity, strings.Join(config.PodSecurityLevels, ", "))
+	}
+
+	if len(io.quota) > 0 && !io.withQuota {
+		return fmt.Errorf("--quota can only be used with --with-quota")
+	}
+	if io.withQuota {
+		q, err := config.ParseQuota(io.quota)
+		if err != nil {
+			return fmt.Errorf("invalid --quota: %w", err)
+		}
+		io.Quota = q
+	}
+
+	if io.ArgoCDAPIVersion != "" && !config.IsArgoCDAPIVersion(io.ArgoCDAPIVersion) {
+		return fmt.Errorf("invalid Argo CD API version: %q, must be one of %s", io.ArgoCDAPIVersion, strings.Join(config.ArgoCDAPIVersions, ", "))
+	}
+
+	for env, policy := range io.SyncPolicies {
+		if _, err := config.ParseSyncPolicy(policy); err != nil {
+			return fmt.Errorf("invalid sync policy for environment %s: %w", env, err)
+		}
+	}
+	if len(io.SyncPolicies) > 0 && (io.UseApplicationSet || io.GitOpsEngine == pipelines.FluxEngine) {
+		return fmt.Errorf("--sync-policy can only be used with Argo CD Applications")
+	}
+
+	if io.ImageRegistry != "" {
+		if err := imagerepo.ValidateRegistry(io.ImageRegistry); err != nil {
+			return err
+		}
+	}
+
+	if io.PipelineTimeout < 0 {
+		return fmt.Errorf("invalid --pipeline-timeout %s, the duration must be positive", io.PipelineTimeout)
+	}
+	if len(io.pipelineResources) > 0 {
+		reqs, err := tasks.ParseResources(io.pipelineResources)
+		if err != nil {
+			return fmt.Errorf("invalid --pipeline-resources: %w", err)
+		}
+		io.PipelineResources = reqs
+	}
+	for _, branch := range io.BranchFilter {
+		if !config.IsBranchName(branch) {
+			return fmt.Errorf("invalid --branch-filter %q, branch names can't be empty or contain quotes, backslashes or whitespace", branch)
+		}
+	}
+	for name := range io.ImagePullSecrets {
+		if errs := validation.IsDNS1123Subdomain(name); len(errs) > 0 {
+			return fmt.Errorf("invalid --image-pull-secret name %q: %s", name, errs[0])
+		}
+	}
+	if io.GitOpsWebhookSecretName != "" {
+		if errs := validation.IsDNS1035Label(io.GitOpsWebhookSecretName); len(errs) > 0 {
+			return fmt.Errorf("invalid --gitops-webhook-secret-name %q: %s", io.GitOpsWebhookSecretName, errs[0])
+		}
+	}
+	if len(io.GitLabCIVariables) > 0 {
+		if err := io.validateGitLabCIVariables(); err != nil {
+			return err
+		}
+	}
+
 	io.Prefix = utility.MaybeCompletePrefix(io.Prefix)
+	for _, envName := range []string{"cicd", "dev", "stage"} {
+		if err := ui.ValidateNamespacePattern(io.NamespacePattern, io.Prefix, envName); err != nil {
+			return err
+		}
+		if err := ui.CheckSystemNamespace(io.NamespacePattern, io.Prefix, envName, io.strictNamespaces, genericclioptions.Warningf); err != nil {
+			return err
+		}
+	}
 	io.GitOpsRepoURL = utility.AddGitSuffixIfNecessary(io.GitOpsRepoURL)
 	io.ServiceRepoURL = utility.AddGitSuffixIfNecessary(io.ServiceRepoURL)
+	if io.ServiceName != "" {
+		if err := ui.ValidateName(io.ServiceName); err != nil {
+			return fmt.Errorf("invalid --service-name: %w", err)
+		}
+	}
 
+	return io.runValidateHook()
+}
+
+// runValidateHook runs the validate hook with the names that the bootstrap
+// would create.
+func (io *BootstrapParameters) runValidateHook() error {
+	if io.validateHook == "" {
+		return nil
+	}
+	inputs := map[string]string{
+		"prefix":           io.Prefix,
+		"gitops-repo-url":  io.GitOpsRepoURL,
+		"service-repo-url": io.ServiceRepoURL,
+		"image-repo":       io.ImageRepo,
+		"service-name":     io.ServiceName,
+	}
+	if io.ServiceName == "" {
+		if name, err := pipelines.DefaultServiceName(io.ServiceRepoURL, utility.MaxNameLength); err == nil {
+			inputs["service-name"] = name
+		}
+	}
+	for _, envName := range []string{"cicd", "dev", "stage"} {
+		ns, err := utility.NamespaceFromPattern(io.NamespacePattern, io.Prefix, envName)
+		if err != nil {
+			return err
+		}
+		inputs[envName+"-namespace"] = ns
+	}
+	return ui.RunValidateHook(io.validateHook, BootstrapRecommendedCommandName, inputs)
+}
+
+// validateGitLabCIVariables checks that the GitLab CI/CD variables can be
+// set, at least one of the repositories must be on GitLab, with an access
+// token to set them with.
+func (io *BootstrapParameters) validateGitLabCIVariables() error {
+	keys := []string{}
+	for k := range io.GitLabCIVariables {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		if err := git.ValidateCIVariable(k, io.GitLabCIVariables[k]); err != nil {
+			return fmt.Errorf("invalid --gitlab-ci-variables: %w", err)
+		}
+	}
+	onGitLab := false
+	for _, repoURL := range []string{io.GitOpsRepoURL, io.ServiceRepoURL} {
+		if driver, err := scm.GetDriverName(repoURL); err != nil || driver != "gitlab" {
+			continue
+		}
+		if io.Credentials.Token(repoURL, io.GitHostAccessToken) == "" {
+			return fmt.Errorf("--gitlab-ci-variables requires an access token for %s, provide --git-host-access-token or --credential", repoURL)
+		}
+		onGitLab = true
+	}
+	if !onGitLab {
+		return fmt.Errorf("--gitlab-ci-variables can only be used with GitLab repositories")
+	}
 	return nil
 }
 
 // Run runs the project Bootstrap command.
 func (io *BootstrapParameters) Run() error {
-	err := pipelines.Bootstrap(io.BootstrapOptions, ioutils.NewFilesystem())
+	if !io.confirmed() {
+		log.Info("Bootstrap cancelled, no changes were made")
+		return nil
+	}
+	if io.client != nil {
+		cicdNamespace, err := utility.NamespaceFromPattern(io.NamespacePattern, io.Prefix, "cicd")
+		if err != nil {
+			return err
+		}
+		err = io.client.CheckSecretNamespace(cicdNamespace, io.createNamespace, genericclioptions.Warningf)
+		if err != nil {
+			return err
+		}
+	}
+	err := bootstrap(io.BootstrapOptions, ioutils.NewFilesystem())
 	if err != nil {
 		return err
 	}
@@ -271,23 +618,116 @@ func NewCmdBootstrap(name, fullName string) *cobra.Command {
 		},
 	}
 	bootstrapCmd.Flags().StringVar(&o.GitOpsRepoURL, "gitops-repo-url", "", "Provide the URL for your GitOps repository e.g. https://github.com/organisation/repository.git")
-	bootstrapCmd.Flags().StringVar(&o.GitOpsWebhookSecret, "gitops-webhook-secret", "", "Provide a secret that we can use to authenticate incoming hooks from your Git hosting service for the GitOps repository. (if not provided, it will be auto-generated)")
+	bootstrapCmd.Flags().StringVar(&o.GitOpsWebhookSecret, "gitops-webhook-secret", "", "Provide a secret that we can use to authenticate incoming hooks from your Git hosting service for the GitOps repository, or - to read it from stdin. (if not provided, it will be auto-generated)")
 	bootstrapCmd.Flags().StringVar(&o.OutputPath, "output", ".", "Path to write GitOps resources")
+	bootstrapCmd.Flags().StringVar(&o.GitOpsPath, "gitops-path", "", "Directory of the output path within the GitOps repository e.g. clusters/prod, by default it's found from the Git checkout that the output path is in")
+	bootstrapCmd.Flags().StringVar(&o.OutputRoot, "output-root", "", "If provided, the output path must be within this directory")
+	bootstrapCmd.Flags().StringVar(&o.ManifestFile, "manifest-file", "pipelines.yaml", "Path of the manifest file to write within the output path e.g. gitops/pipelines.yaml")
 	bootstrapCmd.Flags().StringVarP(&o.Prefix, "prefix", "p", "", "Add a prefix to the environment names(Dev, stage,prod,cicd etc.) to distinguish and identify individual environments")
+	bootstrapCmd.Flags().StringVar(&o.NamespacePattern, "namespace-pattern", utility.DefaultNamespacePattern, "Template for the environment namespaces, with the {{.Prefix}} and {{.Env}} name e.g. {{.Env}}-{{.Prefix}}, the namespaces must be valid DNS-1123 labels")
 	bootstrapCmd.Flags().StringVar(&o.DockerConfigJSONFilename, "dockercfgjson", "~/.docker/config.json", "Filepath to config.json which authenticates the image push to the desired image registry ")
 	bootstrapCmd.Flags().StringVar(&o.InternalRegistryHostname, "image-repo-internal-registry-hostname", "image-registry.openshift-image-registry.svc:5000", "Host-name for internal image registry e.g. docker-registry.default.svc.cluster.local:5000, used if you are pushing your images to the internal image registry")
 	bootstrapCmd.Flags().StringVar(&o.ImageRepo, "image-repo", "", "Image repository of the form <registry>/<username>/<repository> or <project>/<app> which is used to push newly built images")
 	bootstrapCmd.Flags().StringVar(&o.SealedSecretsService.Namespace, "sealed-secrets-ns", sealedSecretsNS, "Namespace in which the Sealed Secrets operator is installed, automatically generated secrets are encrypted with this operator")
 	bootstrapCmd.Flags().StringVar(&o.SealedSecretsService.Name, "sealed-secrets-svc", sealedSecretsController, "Name of the Sealed Secrets Services that encrypts secrets")
-	bootstrapCmd.Flags().StringVar(&o.GitHostAccessToken, "git-host-access-token", "", "Used to authenticate repository clones, and commit-status notifications (if enabled)")
+	bootstrapCmd.Flags().StringArrayVar(&o.accessTokens, "git-host-access-token", nil, "Used to authenticate repository clones, and commit-status notifications (if enabled), or - to read it from stdin, can be repeated to try each token in order, the first that can access the service repository is used")
+	bootstrapCmd.Flags().StringArrayVar(&o.credentials, "credential", nil, "Access token for a specific Git host in the form host=token, used instead of the git-host-access-token for repositories on that host, can be repeated, a token of - is read from stdin")
+	bootstrapCmd.Flags().DurationVar(&o.waitForSealedSecrets, "wait-for-sealed-secrets", 0, "How long to wait for the Sealed Secrets controller to be ready before failing e.g. 2m, by default it's not waited for")
+	bootstrapCmd.Flags().BoolVarP(&o.yes, "yes", "y", false, "Proceed without confirming the summary of changes")
+	bootstrapCmd.Flags().BoolVar(&o.nonInteractive, "non-interactive", false, "Never prompt for options or confirmation, missing mandatory flags are an error")
 	bootstrapCmd.Flags().BoolVar(&o.Overwrite, "overwrite", false, "Overwrites previously existing GitOps configuration (if any)")
+	bootstrapCmd.Flags().BoolVar(&o.strictNamespaces, "strict", false, "Fail rather than warn if a generated namespace collides with a Kubernetes or OpenShift system namespace")
+	bootstrapCmd.Flags().BoolVar(&o.Fresh, "fresh", false, "Start the bootstrap again, rather than resuming a previous bootstrap to the output path that failed part way through, it can only be resumed with the same options")
+	bootstrapCmd.Flags().StringVar(&o.FromTemplate, "from-template", "", "Provide the URL for a template repository to use as the starting point for the GitOps repository")
+	bootstrapCmd.Flags().BoolVar(&o.TemplateWins, "template-wins", false, "Keep files from the template repository where they conflict with generated files")
+	bootstrapCmd.Flags().StringVar(&o.SSHKnownHostsFile, "ssh-known-hosts", "", "known_hosts file to verify the host key of an SSH template repository with, rather than the user's known_hosts")
+	bootstrapCmd.Flags().BoolVar(&o.strictHostKeys, "strict-host-key-checking", true, "Fail to clone an SSH template repository from a host that's not in the known_hosts, rather than accepting its key")
+	bootstrapCmd.Flags().StringToStringVar(&o.TemplateVars, "template-var", nil, "Variable to render the --from-template files with, in the form key=value e.g. TeamName=payments, can be repeated, files with a .tmpl suffix are always rendered as Go templates with the built-in Prefix, Org and Environments variables, with this flag all text files are rendered")
+	bootstrapCmd.Flags().BoolVar(&o.AllowMissingVars, "allow-missing-vars", false, "Copy --from-template files that can't be parsed or reference undefined variables unrendered, rather than failing")
+	bootstrapCmd.Flags().BoolVar(&o.AllowLFSPointers, "allow-lfs-pointers", false, "Copy Git LFS pointer files from the template repository, rather than failing, as LFS objects are not fetched")
+	bootstrapCmd.Flags().BoolVar(&o.UseApplicationSet, "use-applicationset", false, "Generate a single Argo CD ApplicationSet rather than an Application per environment and application")
+	bootstrapCmd.Flags().StringVar(&o.GitOpsEngine, "gitops-engine", pipelines.ArgoCDEngine, "GitOps engine to deploy the environments with, argocd generates Argo CD Applications and flux generates Flux Kustomizations")
+	bootstrapCmd.Flags().StringVar(&o.ArgoCDAPIVersion, "argocd-api-version", config.DefaultArgoCDAPIVersion, fmt.Sprintf("apiVersion of the generated Argo CD Applications and ApplicationSets, one of %s", strings.Join(config.ArgoCDAPIVersions, ", ")))
+	bootstrapCmd.Flags().StringToStringVar(&o.SyncPolicies, "sync-policy", nil, "Argo CD sync policy for each environment in the form env=policy e.g. dev=auto+prune+selfheal,stage=manual, the policy is manual, or auto optionally followed by +prune and +selfheal")
+	bootstrapCmd.Flags().BoolVar(&o.NoGitIgnore, "no-gitignore", false, "Do not write a .gitignore to the GitOps repository")
+	bootstrapCmd.Flags().BoolVar(&o.WithMakefile, "with-makefile", false, "Write a Makefile with validate, build, diff, dry-run and apply targets to the GitOps repository")
+	bootstrapCmd.Flags().BoolVar(&o.ForceMakefile, "force", false, "Replace an existing Makefile when --with-makefile is used")
+	bootstrapCmd.Flags().BoolVar(&o.withQuota, "with-quota", false, "Generate a ResourceQuota and LimitRange for each environment namespace")
+	bootstrapCmd.Flags().StringToStringVar(&o.quota, "quota", nil, "Quota for each environment namespace with --with-quota, in the form name=quantity e.g. cpu=4,memory=8Gi, the limits of containers that don't set them are default-cpu and default-memory, by default 500m and 512Mi")
+	bootstrapCmd.Flags().BoolVar(&o.WithNamespaceDefaults, "with-namespace-defaults", false, "Generate the environment namespaces with Pod Security labels, a default-deny NetworkPolicy and a NetworkPolicy allowing traffic from the CI/CD namespace")
+	bootstrapCmd.Flags().StringVar(&o.PodSecurity, "pod-security", config.PodSecurityBaseline, fmt.Sprintf("Pod Security Standard level for the environment namespaces with --with-namespace-defaults, one of %s", strings.Join(config.PodSecurityLevels, ", ")))
 	bootstrapCmd.Flags().StringVar(&o.ServiceRepoURL, "service-repo-url", "", "Provide the URL for your Service repository e.g. https://github.com/organisation/service.git")
-	bootstrapCmd.Flags().StringVar(&o.ServiceWebhookSecret, "service-webhook-secret", "", "Provide a secret that we can use to authenticate incoming hooks from your Git hosting service for the Service repository. (if not provided, it will be auto-generated)")
+	bootstrapCmd.Flags().StringVar(&o.ServiceName, "service-name", "", "Name of the service, by default it's derived from the service repository name, lowercased with invalid characters replaced by hyphens")
+	bootstrapCmd.Flags().StringVar(&o.ServiceWebhookSecret, "service-webhook-secret", "", "Provide a secret that we can use to authenticate incoming hooks from your Git hosting service for the Service repository, or - to read it from stdin. (if not provided, it will be auto-generated)")
 	bootstrapCmd.Flags().StringVar(&o.PrivateRepoDriver, "private-repo-driver", "", "If your Git repositories are on a custom domain, please indicate which driver to use github or gitlab")
 	bootstrapCmd.Flags().BoolVar(&o.CommitStatusTracker, "commit-status-tracker", true, "Enable or disable the commit-status-tracker which reports the success/failure of your pipelineruns to GitHub/GitLab")
+	bootstrapCmd.Flags().StringVar(&o.ImageRegistry, "image-registry", "", "Registry to pull the images used by the generated tasks and deployments from e.g. an internal mirror registry.example.com/mirror")
+	bootstrapCmd.Flags().StringVar(&o.TaskCatalogRef, "task-catalog-ref", "buildah", "Name of the ClusterTask used to build images in the generated CI pipeline")
+	bootstrapCmd.Flags().DurationVar(&o.PipelineTimeout, "pipeline-timeout", 0, "Timeout of the PipelineRuns started by the generated triggers e.g. 1h30m, by default the Tekton default is used")
+	bootstrapCmd.Flags().StringToStringVar(&o.pipelineResources, "pipeline-resources", nil, "Requests and limits for the steps of the generated Tasks e.g. requests.cpu=250m,limits.memory=1Gi, ClusterTasks such as the image build task are not changed")
+	bootstrapCmd.Flags().StringSliceVar(&o.BranchFilter, "branch-filter", nil, "Only start the generated pipelines for pushes to these branches e.g. main,release, by default pushes to any branch start them")
+	bootstrapCmd.Flags().StringToStringVar(&o.ImagePullSecrets, "image-pull-secret", nil, "Image pull secret for the pipeline service account in the form name=dockerconfigjson e.g. private-registry=~/.docker/config.json, the Docker config is sealed as the secret, can be repeated")
+	bootstrapCmd.Flags().StringToStringVar(&o.GitLabCIVariables, "gitlab-ci-variables", nil, "Protected and masked CI/CD variables to create or update on the GitLab repositories, for pipelines run by GitLab CI, in the form key=value e.g. CLUSTER_ENDPOINT=https://api.example.com:6443")
+	bootstrapCmd.Flags().StringVar(&o.validateHook, "validate-hook", "", "Executable to run with the names and inputs of the bootstrap as JSON on stdin, a non-zero exit status rejects them, with its stderr as the error")
+	bootstrapCmd.Flags().BoolVar(&o.createNamespace, "create-namespace", false, "Create the CI/CD namespace if it doesn't exist, the secrets are sealed for it and can't be unsealed until it's created")
+	bootstrapCmd.Flags().StringVar(&o.GitOpsWebhookSecretName, "gitops-webhook-secret-name", "", fmt.Sprintf("Name of the Secret that the GitOps repository webhook is authenticated with by the EventListener, defaults to %s", eventlisteners.GitOpsWebhookSecret))
 	return bootstrapCmd
 }
 
+// confirmed returns true if bootstrap should proceed, the user is asked to
+// confirm the summary of changes on a terminal, unless --yes or
+// --non-interactive was provided.
+func (io *BootstrapParameters) confirmed() bool {
+	if io.yes || io.nonInteractive || !stdinIsTerminal() {
+		return true
+	}
+	return confirmSummary(bootstrapSummary(io.BootstrapOptions))
+}
+
+// bootstrapSummary describes the repositories, secrets and files that
+// bootstrap will touch.
+func bootstrapSummary(o *pipelines.BootstrapOptions) []string {
+	// The pattern has been validated, so the namespace can be rendered.
+	devNamespace, _ := utility.NamespaceFromPattern(o.NamespacePattern, o.Prefix, "dev")
+	summary := []string{
+		fmt.Sprintf("Configure the GitOps repository %s", o.GitOpsRepoURL),
+		fmt.Sprintf("Configure the service repository %s in the %s environment", o.ServiceRepoURL, devNamespace),
+	}
+	if o.FromTemplate != "" {
+		summary = append(summary, fmt.Sprintf("Clone the template repository %s", o.FromTemplate))
+	}
+	sealed := []string{"GitOps webhook secret", "service webhook secret"}
+	if o.DockerConfigJSONFilename != "" {
+		sealed = append(sealed, fmt.Sprintf("Docker config from %s", o.DockerConfigJSONFilename))
+	}
+	pullSecrets := []string{}
+	for name := range o.ImagePullSecrets {
+		pullSecrets = append(pullSecrets, name)
+	}
+	sort.Strings(pullSecrets)
+	for _, name := range pullSecrets {
+		sealed = append(sealed, fmt.Sprintf("image pull secret %s from %s", name, o.ImagePullSecrets[name]))
+	}
+	if o.GitHostAccessToken != "" || len(o.Credentials) > 0 {
+		sealed = append(sealed, "Git host access token")
+	}
+	summary = append(summary, fmt.Sprintf("Seal secrets with %s/%s: %s",
+		o.SealedSecretsService.Namespace, o.SealedSecretsService.Name, strings.Join(sealed, ", ")))
+	if len(o.GitLabCIVariables) > 0 {
+		keys := []string{}
+		for k := range o.GitLabCIVariables {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		summary = append(summary, fmt.Sprintf("Set the CI/CD variables %s on the GitLab repositories", strings.Join(keys, ", ")))
+	}
+	write := fmt.Sprintf("Write the GitOps configuration to %s", o.OutputPath)
+	if o.Overwrite {
+		write += ", replacing any existing files"
+	}
+	return append(summary, write)
+}
+
 func nextSteps() {
 	log.Success("Bootstrapped OpenShift resources sucessfully.\n",
 		"Next Steps:\n",
@@ -4,21 +4,31 @@ import (
 	"bytes"
 	"fmt"
 	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
 	"regexp"
+	"strings"
 	"testing"
+	"time"
 
 	"k8s.io/apimachinery/pkg/runtime"
 
 	"github.com/google/go-cmp/cmp"
 	v1alpha1 "github.com/operator-framework/operator-lifecycle-manager/pkg/api/apis/operators/v1alpha1"
 	operatorsfake "github.com/operator-framework/operator-lifecycle-manager/pkg/api/client/clientset/versioned/fake"
+	"github.com/rhd-gitops-example/gitops-cli/pkg/cmd/genericclioptions"
 	"github.com/rhd-gitops-example/gitops-cli/pkg/cmd/utility"
 	"github.com/rhd-gitops-example/gitops-cli/pkg/pipelines"
+	"github.com/rhd-gitops-example/gitops-cli/pkg/pipelines/clientconfig"
+	"github.com/rhd-gitops-example/gitops-cli/pkg/pipelines/network"
+	"github.com/spf13/afero"
 	appv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/client-go/kubernetes/fake"
 )
 
@@ -43,7 +53,7 @@ func TestValidatePrefix(t *testing.T) {
 
 	for _, tt := range completeTests {
 		o := BootstrapParameters{
-			&pipelines.BootstrapOptions{Prefix: tt.prefix, GitOpsRepoURL: tt.gitRepo, ServiceRepoURL: tt.serviceRepo, ImageRepo: ""},
+			BootstrapOptions: &pipelines.BootstrapOptions{Prefix: tt.prefix, GitOpsRepoURL: tt.gitRepo, ServiceRepoURL: tt.serviceRepo, ImageRepo: ""},
 		}
 
 		err := o.Validate()
@@ -58,6 +68,67 @@ func TestValidatePrefix(t *testing.T) {
 	}
 }
 
+func TestValidateGitOpsEngine(t *testing.T) {
+	engineTests := []struct {
+		name           string
+		engine         string
+		applicationSet bool
+		errMsg         string
+	}{
+		{"argocd", "argocd", false, ""},
+		{"argocd with applicationset", "argocd", true, ""},
+		{"flux", "flux", false, ""},
+		{"flux with applicationset", "flux", true, "--use-applicationset can only be used with the argocd GitOps engine"},
+		{"unknown engine", "spinnaker", false, `invalid GitOps engine: "spinnaker", must be argocd or flux`},
+	}
+
+	for _, tt := range engineTests {
+		o := BootstrapParameters{
+			BootstrapOptions: &pipelines.BootstrapOptions{
+				GitOpsRepoURL:     "https://github.com/example/gitops.git",
+				GitOpsEngine:      tt.engine,
+				UseApplicationSet: tt.applicationSet,
+			},
+		}
+		err := o.Validate()
+
+		if !matchError(t, tt.errMsg, err) {
+			t.Errorf("Validate() %#v failed to match error: got %s, want %s", tt.name, err, tt.errMsg)
+		}
+	}
+}
+
+func TestValidateTaskCatalogRef(t *testing.T) {
+	refTests := []struct {
+		ref    string
+		errMsg string
+	}{
+		{"", ""},
+		{"buildah", ""},
+		{"kaniko-v1", ""},
+		{"Buildah_Task", "invalid --task-catalog-ref: Buildah_Task is not a valid name"},
+		{"buildah --privileged", `invalid --task-catalog-ref: buildah --privileged is not a valid name`},
+	}
+
+	for _, tt := range refTests {
+		o := BootstrapParameters{
+			BootstrapOptions: &pipelines.BootstrapOptions{
+				GitOpsRepoURL:  "https://github.com/example/gitops.git",
+				TaskCatalogRef: tt.ref,
+			},
+		}
+		err := o.Validate()
+
+		if err != nil && tt.errMsg == "" {
+			t.Errorf("Validate() %q got an unexpected error: %s", tt.ref, err)
+			continue
+		}
+		if !matchError(t, tt.errMsg, err) {
+			t.Errorf("Validate() %q failed to match error: got %s, want %s", tt.ref, err, tt.errMsg)
+		}
+	}
+}
+
 func TestAddSuffixWithBootstrap(t *testing.T) {
 	gitOpsURL := "https://github.com/org/gitops"
 	appURL := "https://github.com/org/app"
@@ -75,7 +146,7 @@ func TestAddSuffixWithBootstrap(t *testing.T) {
 	for _, test := range tt {
 		t.Run(test.name, func(rt *testing.T) {
 			o := BootstrapParameters{
-				&pipelines.BootstrapOptions{
+				BootstrapOptions: &pipelines.BootstrapOptions{
 					GitOpsRepoURL:  test.gitOpsURL,
 					ServiceRepoURL: test.appURL},
 			}
@@ -111,7 +182,7 @@ func TestValidateBootstrapParameter(t *testing.T) {
 
 	for _, tt := range optionTests {
 		o := BootstrapParameters{
-			&pipelines.BootstrapOptions{
+			BootstrapOptions: &pipelines.BootstrapOptions{
 				GitOpsRepoURL:     tt.gitRepo,
 				PrivateRepoDriver: tt.driver,
 				Prefix:            "test"},
@@ -129,6 +200,171 @@ func TestValidateBootstrapParameter(t *testing.T) {
 	}
 }
 
+func TestValidateImageRegistry(t *testing.T) {
+	optionTests := []struct {
+		name     string
+		registry string
+		errMsg   string
+	}{
+		{"no registry", "", ""},
+		{"valid registry", "registry.example.com/mirror", ""},
+		{"valid registry with port", "registry.example.com:5000", ""},
+		{"registry with scheme", "https://registry.example.com", "failed to parse image registry"},
+		{"registry without host", "mirror", "failed to parse image registry"},
+	}
+
+	for _, tt := range optionTests {
+		o := BootstrapParameters{
+			BootstrapOptions: &pipelines.BootstrapOptions{
+				GitOpsRepoURL: "test/repo",
+				ImageRegistry: tt.registry,
+				Prefix:        "test"},
+		}
+		err := o.Validate()
+
+		if err != nil && tt.errMsg == "" {
+			t.Errorf("Validate() %#v got an unexpected error: %s", tt.name, err)
+			continue
+		}
+
+		if !matchError(t, tt.errMsg, err) {
+			t.Errorf("Validate() %#v failed to match error: got %s, want %s", tt.name, err, tt.errMsg)
+		}
+	}
+}
+
+func TestValidatePipelineTimeoutAndResources(t *testing.T) {
+	optionTests := []struct {
+		name      string
+		timeout   time.Duration
+		resources map[string]string
+		errMsg    string
+	}{
+		{"defaults", 0, nil, ""},
+		{"timeout and resources", time.Hour, map[string]string{"limits.memory": "1Gi"}, ""},
+		{"negative timeout", -time.Minute, nil, "invalid --pipeline-timeout -1m0s, the duration must be positive"},
+		{"invalid resource", 0, map[string]string{"memory": "1Gi"}, `invalid --pipeline-resources: invalid resource "memory"`},
+		{"invalid quantity", 0, map[string]string{"limits.cpu": "fast"}, `invalid --pipeline-resources: invalid quantity "fast"`},
+	}
+
+	for _, tt := range optionTests {
+		o := BootstrapParameters{
+			BootstrapOptions: &pipelines.BootstrapOptions{
+				GitOpsRepoURL:   "test/repo",
+				PipelineTimeout: tt.timeout,
+				Prefix:          "test"},
+			pipelineResources: tt.resources,
+		}
+		err := o.Validate()
+
+		if err != nil && tt.errMsg == "" {
+			t.Errorf("Validate() %#v got an unexpected error: %s", tt.name, err)
+			continue
+		}
+
+		if !matchError(t, tt.errMsg, err) {
+			t.Errorf("Validate() %#v failed to match error: got %s, want %s", tt.name, err, tt.errMsg)
+		}
+	}
+}
+
+func TestValidateBranchFilterAndWebhookSecretName(t *testing.T) {
+	optionTests := []struct {
+		name       string
+		branches   []string
+		secretName string
+		errMsg     string
+	}{
+		{"defaults", nil, "", ""},
+		{"branches and secret name", []string{"main", "release/v1"}, "gitops-hooks", ""},
+		{"quoted branch", []string{"main'"}, "", `invalid --branch-filter "main'"`},
+		{"empty branch", []string{""}, "", `invalid --branch-filter ""`},
+		{"invalid secret name", nil, "GitOps_Hooks", `invalid --gitops-webhook-secret-name "GitOps_Hooks"`},
+	}
+
+	for _, tt := range optionTests {
+		o := BootstrapParameters{
+			BootstrapOptions: &pipelines.BootstrapOptions{
+				GitOpsRepoURL:           "test/repo",
+				BranchFilter:            tt.branches,
+				GitOpsWebhookSecretName: tt.secretName,
+				Prefix:                  "test"},
+		}
+		err := o.Validate()
+
+		if err != nil && tt.errMsg == "" {
+			t.Errorf("Validate() %#v got an unexpected error: %s", tt.name, err)
+			continue
+		}
+
+		if !matchError(t, tt.errMsg, err) {
+			t.Errorf("Validate() %#v failed to match error: got %s, want %s", tt.name, err, tt.errMsg)
+		}
+	}
+}
+
+func TestValidateGitLabCIVariables(t *testing.T) {
+	optionTests := []struct {
+		name      string
+		gitOpsURL string
+		token     string
+		variables map[string]string
+		errMsg    string
+	}{
+		{"gitlab repository", "https://gitlab.com/org/gitops.git", "token", map[string]string{"CLUSTER_ENDPOINT": "https://api.example.com:6443"}, ""},
+		{"invalid key", "https://gitlab.com/org/gitops.git", "token", map[string]string{"CLUSTER-ENDPOINT": "https://api.example.com:6443"}, `invalid --gitlab-ci-variables: invalid CI/CD variable key "CLUSTER-ENDPOINT"`},
+		{"unmaskable value", "https://gitlab.com/org/gitops.git", "token", map[string]string{"CLUSTER_NAME": "prod"}, "invalid --gitlab-ci-variables: invalid value for CI/CD variable CLUSTER_NAME"},
+		{"no access token", "https://gitlab.com/org/gitops.git", "", map[string]string{"CLUSTER_NAME": "production"}, "--gitlab-ci-variables requires an access token for https://gitlab.com/org/gitops.git"},
+		{"github repositories", "https://github.com/org/gitops.git", "token", map[string]string{"CLUSTER_NAME": "production"}, "--gitlab-ci-variables can only be used with GitLab repositories"},
+	}
+
+	for _, tt := range optionTests {
+		o := BootstrapParameters{
+			BootstrapOptions: &pipelines.BootstrapOptions{
+				GitOpsRepoURL:      tt.gitOpsURL,
+				ServiceRepoURL:     "https://github.com/org/service.git",
+				GitHostAccessToken: tt.token,
+				GitLabCIVariables:  tt.variables,
+				Prefix:             "test"},
+		}
+		err := o.Validate()
+
+		if err != nil && tt.errMsg == "" {
+			t.Errorf("Validate() %#v got an unexpected error: %s", tt.name, err)
+			continue
+		}
+
+		if !matchError(t, tt.errMsg, err) {
+			t.Errorf("Validate() %#v failed to match error: got %s, want %s", tt.name, err, tt.errMsg)
+		}
+	}
+}
+
+func TestSelectAccessToken(t *testing.T) {
+	defer func(f func([]string, string) (string, error)) {
+		selectAccessToken = f
+	}(selectAccessToken)
+	var tried []string
+	selectAccessToken = func(tokens []string, serviceRepo string) (string, error) {
+		tried = tokens
+		return tokens[1], nil
+	}
+
+	o := BootstrapParameters{
+		BootstrapOptions: &pipelines.BootstrapOptions{ServiceRepoURL: "https://github.com/my-org/http-api.git"},
+		accessTokens:     []string{"machine-token", "personal-token"},
+	}
+	if err := o.selectAccessToken(); err != nil {
+		t.Fatal(err)
+	}
+	if o.GitHostAccessToken != "personal-token" {
+		t.Fatalf("got access token %q, want personal-token", o.GitHostAccessToken)
+	}
+	if diff := cmp.Diff([]string{"machine-token", "personal-token"}, tried); diff != "" {
+		t.Fatalf("tried tokens failed:\n%s", diff)
+	}
+}
+
 func TestValidateMandatoryFlags(t *testing.T) {
 	optionTests := []struct {
 		name        string
@@ -144,7 +380,7 @@ func TestValidateMandatoryFlags(t *testing.T) {
 
 	for _, tt := range optionTests {
 		o := BootstrapParameters{
-			&pipelines.BootstrapOptions{
+			BootstrapOptions: &pipelines.BootstrapOptions{
 				GitOpsRepoURL:  tt.gitRepo,
 				ServiceRepoURL: tt.serviceRepo,
 				ImageRepo:      tt.imagerepo},
@@ -157,6 +393,35 @@ func TestValidateMandatoryFlags(t *testing.T) {
 	}
 }
 
+func TestValidateFlagsReportsAllFailures(t *testing.T) {
+	o := BootstrapParameters{
+		BootstrapOptions: &pipelines.BootstrapOptions{
+			GitOpsRepoURL:        "https://github.com/example/gitops.git",
+			Prefix:               "Test@",
+			GitOpsWebhookSecret:  "short",
+			ServiceWebhookSecret: "also-short",
+		},
+	}
+
+	err := nonInteractiveMode(&o, &utility.Client{})
+	if err == nil {
+		t.Fatal("nonInteractiveMode() did not fail with invalid flags")
+	}
+
+	wantErrs := []string{
+		`The mandatory flag "service-repo-url" has not been set`,
+		`The mandatory flag "image-repo" has not been set`,
+		`invalid value for --prefix: Test@-stage is not a valid name`,
+		`invalid value for --gitops-webhook-secret: The secret length should 16 or more`,
+		`invalid value for --service-webhook-secret: The secret length should 16 or more`,
+	}
+	for _, want := range wantErrs {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("nonInteractiveMode() error did not include %q, got:\n%s", want, err)
+		}
+	}
+}
+
 func TestCheckSpinner(t *testing.T) {
 	tests := []struct {
 		name      string
@@ -209,7 +474,7 @@ Checking if OpenShift Pipelines Operator is installed with the default configura
 
 	buff := &bytes.Buffer{}
 	fakeSpinner := &mockSpinner{writer: buff}
-	err := checkBootstrapDependencies(&BootstrapParameters{&pipelines.BootstrapOptions{}}, fakeClient, fakeSpinner)
+	err := checkBootstrapDependencies(&BootstrapParameters{BootstrapOptions: &pipelines.BootstrapOptions{}}, fakeClient, fakeSpinner)
 	wantErr := "Failed to satisfy the required dependencies"
 
 	assertError(t, err, wantErr)
@@ -226,7 +491,7 @@ Checking if OpenShift Pipelines Operator is installed with the default configura
 
 	buff := &bytes.Buffer{}
 	fakeSpinner := &mockSpinner{writer: buff}
-	wizardParams := &BootstrapParameters{&pipelines.BootstrapOptions{}}
+	wizardParams := &BootstrapParameters{BootstrapOptions: &pipelines.BootstrapOptions{}}
 	err := checkBootstrapDependencies(wizardParams, fakeClient, fakeSpinner)
 
 	assertError(t, err, "")
@@ -246,7 +511,7 @@ Checking if OpenShift Pipelines Operator is installed with the default configura
 
 	buff := &bytes.Buffer{}
 	fakeSpinner := &mockSpinner{writer: buff}
-	wizardParams := &BootstrapParameters{&pipelines.BootstrapOptions{}}
+	wizardParams := &BootstrapParameters{BootstrapOptions: &pipelines.BootstrapOptions{}}
 	err := checkBootstrapDependencies(wizardParams, fakeClient, fakeSpinner)
 	wantErr := "Failed to satisfy the required dependencies"
 
@@ -264,7 +529,7 @@ Checking if OpenShift Pipelines Operator is installed with the default configura
 
 	buff := &bytes.Buffer{}
 	fakeSpinner := &mockSpinner{writer: buff}
-	wizardParams := &BootstrapParameters{&pipelines.BootstrapOptions{}}
+	wizardParams := &BootstrapParameters{BootstrapOptions: &pipelines.BootstrapOptions{}}
 	err := checkBootstrapDependencies(wizardParams, fakeClient, fakeSpinner)
 	wantErr := "Failed to satisfy the required dependencies"
 
@@ -360,3 +625,175 @@ func argoCDCSV() *v1alpha1.ClusterServiceVersion {
 		},
 	}
 }
+
+func TestBootstrapWithYesSkipsConfirmation(t *testing.T) {
+	defer stubConfirmSummary(func(summary []string) bool {
+		t.Fatal("confirmation was requested with --yes")
+		return false
+	})()
+
+	o := BootstrapParameters{BootstrapOptions: &pipelines.BootstrapOptions{}, yes: true}
+
+	if !o.confirmed() {
+		t.Fatal("bootstrap was not confirmed with --yes")
+	}
+}
+
+func TestBootstrapConfirmation(t *testing.T) {
+	confirmTests := []struct {
+		name           string
+		yes            bool
+		nonInteractive bool
+		terminal       bool
+		wantConfirm    bool
+	}{
+		{"driven by flags on a terminal", false, false, true, true},
+		{"with --yes", true, false, true, false},
+		{"with --non-interactive", false, true, true, false},
+		{"stdin is not a terminal", false, false, false, false},
+	}
+
+	for _, tt := range confirmTests {
+		t.Run(tt.name, func(rt *testing.T) {
+			asked := false
+			defer stubConfirmSummary(func(summary []string) bool {
+				asked = true
+				return true
+			})()
+			defer stubStdinIsTerminal(tt.terminal)()
+			o := BootstrapParameters{
+				BootstrapOptions: &pipelines.BootstrapOptions{},
+				yes:              tt.yes,
+				nonInteractive:   tt.nonInteractive,
+			}
+
+			if !o.confirmed() {
+				rt.Fatal("bootstrap was not confirmed")
+			}
+			if asked != tt.wantConfirm {
+				rt.Fatalf("confirmation requested got %v, want %v", asked, tt.wantConfirm)
+			}
+		})
+	}
+}
+
+func TestModeFlagsChanged(t *testing.T) {
+	modeTests := []struct {
+		args []string
+		want bool
+	}{
+		{[]string{}, false},
+		{[]string{"--yes"}, false},
+		{[]string{"--wait-for-sealed-secrets", "2m"}, false},
+		{[]string{"--manifest-file", "gitops/pipelines.yaml"}, false},
+		{[]string{"--k8s-ca-file", "ca.pem"}, false},
+		{[]string{"--as", "testing"}, false},
+		{[]string{"--as-group", "testing"}, false},
+		{[]string{"--as-uid", "1000"}, false},
+		{[]string{"--context", "testing"}, false},
+		{[]string{"--profile", "testing"}, false},
+		{[]string{"--fail-on-warning"}, false},
+		{[]string{"--no-network"}, false},
+		{[]string{"--allowed-hosts", "github.com"}, false},
+		{[]string{"--max-name-length", "100"}, false},
+		{[]string{"--repo-root-detection"}, false},
+		{[]string{"--gitops-repo-url", "https://github.com/my-org/gitops.git"}, true},
+		{[]string{"--no-network", "--gitops-repo-url", "https://github.com/my-org/gitops.git"}, true},
+	}
+
+	for _, tt := range modeTests {
+		t.Run(strings.Join(tt.args, " "), func(rt *testing.T) {
+			defer restoreGlobalFlags()()
+			cmd, _, err := makeRootCmd().Find([]string{BootstrapRecommendedCommandName})
+			assertNoError(rt, err)
+			assertNoError(rt, cmd.ParseFlags(tt.args))
+
+			if got := modeFlagsChanged(cmd); got != tt.want {
+				rt.Fatalf("modeFlagsChanged() got %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestBootstrapDeclinedMakesNoChanges(t *testing.T) {
+	var confirmed []string
+	defer stubConfirmSummary(func(summary []string) bool {
+		confirmed = summary
+		return false
+	})()
+	defer stubStdinIsTerminal(true)()
+	origBootstrap := bootstrap
+	bootstrap = func(o *pipelines.BootstrapOptions, fs afero.Fs) error {
+		t.Fatal("bootstrap was run after it was declined")
+		return nil
+	}
+	defer func() {
+		bootstrap = origBootstrap
+	}()
+	tmpDir, err := ioutil.TempDir("", "bootstrap")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(tmpDir)
+	outputPath := filepath.Join(tmpDir, "gitops")
+
+	o := BootstrapParameters{
+		BootstrapOptions: &pipelines.BootstrapOptions{
+			GitOpsRepoURL:        "https://github.com/my-org/gitops.git",
+			ServiceRepoURL:       "https://github.com/my-org/http-api.git",
+			Prefix:               "tst-",
+			OutputPath:           outputPath,
+			SealedSecretsService: types.NamespacedName{Namespace: "cicd", Name: "sealed-secrets"},
+		},
+	}
+
+	if err := o.Run(); err != nil {
+		t.Fatal(err)
+	}
+
+	want := []string{
+		"Configure the GitOps repository https://github.com/my-org/gitops.git",
+		"Configure the service repository https://github.com/my-org/http-api.git in the tst-dev environment",
+		"Seal secrets with cicd/sealed-secrets: GitOps webhook secret, service webhook secret",
+		"Write the GitOps configuration to " + outputPath,
+	}
+	if diff := cmp.Diff(want, confirmed); diff != "" {
+		t.Fatalf("confirmation summary failed:\n%s", diff)
+	}
+	if _, err := os.Stat(outputPath); !os.IsNotExist(err) {
+		t.Fatalf("declined bootstrap wrote to %s", outputPath)
+	}
+}
+
+// restoreGlobalFlags returns a function that resets the values of the root
+// command's persistent flags to their values when it was called.
+func restoreGlobalFlags() func() {
+	caFile, impersonate, impersonateUID, kubeContext := clientconfig.CAFile, clientconfig.Impersonate, clientconfig.ImpersonateUID, clientconfig.Context
+	profile, failOnWarning := profileName, genericclioptions.FailOnWarning
+	disabled, allowedHosts := network.Disabled, network.AllowedHosts
+	repoRootDetection, maxNameLength := utility.RepoRootDetection, utility.MaxNameLength
+	return func() {
+		clientconfig.CAFile, clientconfig.Impersonate, clientconfig.ImpersonateUID, clientconfig.Context = caFile, impersonate, impersonateUID, kubeContext
+		profileName, genericclioptions.FailOnWarning = profile, failOnWarning
+		network.Disabled, network.AllowedHosts = disabled, allowedHosts
+		utility.RepoRootDetection, utility.MaxNameLength = repoRootDetection, maxNameLength
+	}
+}
+
+func stubStdinIsTerminal(terminal bool) func() {
+	origFunc := stdinIsTerminal
+	stdinIsTerminal = func() bool {
+		return terminal
+	}
+	return func() {
+		stdinIsTerminal = origFunc
+	}
+}
+
+func stubConfirmSummary(f func([]string) bool) func() {
+	origFunc := confirmSummary
+	confirmSummary = f
+	return func() {
+		confirmSummary = origFunc
+	}
+}
@@ -2,10 +2,14 @@ package cmd
 
 import (
 	"fmt"
+	"strings"
 
 	"github.com/openshift/odo/pkg/log"
 	"github.com/rhd-gitops-example/gitops-cli/pkg/cmd/genericclioptions"
+	"github.com/rhd-gitops-example/gitops-cli/pkg/cmd/ui"
 	"github.com/rhd-gitops-example/gitops-cli/pkg/pipelines"
+	"github.com/rhd-gitops-example/gitops-cli/pkg/pipelines/clientconfig"
+	"github.com/rhd-gitops-example/gitops-cli/pkg/pipelines/dryrun"
 	"github.com/rhd-gitops-example/gitops-cli/pkg/pipelines/ioutils"
 	"github.com/spf13/cobra"
 
@@ -21,6 +25,9 @@ var (
 	buildExample = ktemplates.Examples(`
 	# Build files from pipelines
 	%[1]s 
+
+	# Build the files into an archive
+	%[1]s --output-archive gitops.tgz
 	`)
 
 	buildLongDesc  = ktemplates.LongDesc(`Build GitOps pipelines files`)
@@ -30,7 +37,13 @@ var (
 // BuildParameters encapsulates the parameters for the odo pipelines build command.
 type BuildParameters struct {
 	pipelinesFolderPath string
+	manifestFile        string // path of the manifest within the pipelinesFolderPath
 	output              string // path to add Gitops resources
+	outputRoot          string // if set, output must be within this directory
+	useApplicationSet   bool
+	pkg                 string // how the applications are packaged, kustomize or helm
+	serverDryRun        bool   // if true, the built resources are checked by the cluster
+	outputArchive       string // if set, the resources are written to this .tgz rather than the output path
 }
 
 // NewBuildParameters bootstraps a BuildParameters instance.
@@ -45,14 +58,36 @@ func (io *BuildParameters) Complete(name string, cmd *cobra.Command, args []stri
 
 // Validate validates the parameters of the BuildParameters.
 func (io *BuildParameters) Validate() error {
-	return nil
+	if io.pkg != pipelines.PackageKustomize && io.pkg != pipelines.PackageHelm {
+		return fmt.Errorf("invalid package %q, must be one of %s", io.pkg, strings.Join(pipelines.Packages, ", "))
+	}
+	if io.pkg == pipelines.PackageHelm && io.useApplicationSet {
+		return fmt.Errorf("--package %s can't be used with --use-applicationset", io.pkg)
+	}
+	return ui.ValidateManifestFile(io.manifestFile, "pipelines folder")
 }
 
 // Run runs the project bootstrap command.
 func (io *BuildParameters) Run() error {
 	options := pipelines.BuildParameters{
 		PipelinesFolderPath: io.pipelinesFolderPath,
+		ManifestFile:        io.manifestFile,
 		OutputPath:          io.output,
+		OutputRoot:          io.outputRoot,
+		UseApplicationSet:   io.useApplicationSet,
+		Package:             io.pkg,
+		OutputArchive:       io.outputArchive,
+		Warnf:               genericclioptions.Warningf,
+	}
+	if io.serverDryRun {
+		cfg, err := clientconfig.GetRESTConfig()
+		if err != nil {
+			return err
+		}
+		options.DryRunApplier, err = dryrun.NewClusterApplier(cfg)
+		if err != nil {
+			return err
+		}
 	}
 	err := pipelines.BuildResources(&options, ioutils.NewFilesystem())
 	if err != nil {
@@ -76,6 +111,12 @@ func NewCmdBuild(name, fullName string) *cobra.Command {
 	}
 
 	buildCmd.Flags().StringVar(&o.output, "output", ".", "Folder path to add GitOps resources")
+	buildCmd.Flags().StringVar(&o.outputArchive, "output-archive", "", "Path of a gzip compressed tar archive e.g. gitops.tgz to write the GitOps resources to, rather than the output folder, for transferring them to air-gapped clusters")
+	buildCmd.Flags().StringVar(&o.outputRoot, "output-root", "", "If provided, the output path must be within this directory")
+	buildCmd.Flags().BoolVar(&o.useApplicationSet, "use-applicationset", false, "Generate a single Argo CD ApplicationSet rather than an Application per environment and application")
+	buildCmd.Flags().StringVar(&o.pkg, "package", pipelines.PackageKustomize, "How the applications are packaged for Argo CD, kustomize, or helm to generate a Helm chart for each application and Applications with a Helm source")
+	buildCmd.Flags().BoolVar(&o.serverDryRun, "server-dry-run", false, "Apply the built resources to the cluster with a server-side dry run, nothing is persisted, and fail if any are rejected e.g. by admission controllers")
 	buildCmd.Flags().StringVar(&o.pipelinesFolderPath, "pipelines-folder", ".", "Folder path to retrieve manifest, eg. /test where manifest exists at /test/pipelines.yaml")
+	buildCmd.Flags().StringVar(&o.manifestFile, "manifest-file", "pipelines.yaml", "Path of the manifest file within the --pipelines-folder e.g. gitops/pipelines.yaml")
 	return buildCmd
 }
@@ -0,0 +1,80 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/rhd-gitops-example/gitops-cli/pkg/cmd/genericclioptions"
+	"github.com/rhd-gitops-example/gitops-cli/pkg/pipelines"
+	"github.com/rhd-gitops-example/gitops-cli/pkg/pipelines/ioutils"
+	"github.com/spf13/cobra"
+
+	ktemplates "k8s.io/kubectl/pkg/util/templates"
+)
+
+const (
+	// DiffRecommendedCommandName the recommended command name
+	DiffRecommendedCommandName = "diff"
+)
+
+var (
+	diffExample = ktemplates.Examples(`
+	# Compare the manifest on the main branch with the one in a pull request
+	git show main:pipelines.yaml > /tmp/pipelines.yaml
+	%[1]s /tmp/pipelines.yaml pipelines.yaml
+	`)
+
+	diffLongDesc = ktemplates.LongDesc(`Report the semantic differences between two pipelines.yaml files.
+
+	Environments, applications and services are matched by name, so reordering
+	and reformatting the files are not differences. The command fails if there
+	are differences, so that it can be used as a CI check.`)
+	diffShortDesc = `Compare two manifests`
+)
+
+// DiffParameters encapsulates the parameters for the diff command.
+type DiffParameters struct {
+	genericclioptions.IOStreams
+	oldPath string
+	newPath string
+}
+
+// NewDiffParameters bootstraps a DiffParameters instance.
+func NewDiffParameters(streams genericclioptions.IOStreams) *DiffParameters {
+	return &DiffParameters{IOStreams: streams}
+}
+
+// Complete completes DiffParameters after they've been created.
+func (io *DiffParameters) Complete(name string, cmd *cobra.Command, args []string) error {
+	io.oldPath, io.newPath = args[0], args[1]
+	return nil
+}
+
+// Validate validates the parameters of the DiffParameters.
+func (io *DiffParameters) Validate() error {
+	return nil
+}
+
+// Run runs the diff command.
+func (io *DiffParameters) Run() error {
+	options := pipelines.DiffParameters{
+		OldPath: io.oldPath,
+		NewPath: io.newPath,
+	}
+	return pipelines.Diff(&options, ioutils.NewFilesystem(), io.Out)
+}
+
+// NewCmdDiff creates the diff command, which writes the differences to the
+// streams.
+func NewCmdDiff(name, fullName string, streams genericclioptions.IOStreams) *cobra.Command {
+	o := NewDiffParameters(streams)
+	return &cobra.Command{
+		Use:     name + " OLD NEW",
+		Short:   diffShortDesc,
+		Long:    diffLongDesc,
+		Example: fmt.Sprintf(diffExample, fullName),
+		Args:    cobra.ExactArgs(2),
+		Run: func(cmd *cobra.Command, args []string) {
+			genericclioptions.GenericRun(o, cmd, args)
+		},
+	}
+}
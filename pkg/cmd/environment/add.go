@@ -3,9 +3,10 @@ package environment
 import (
 	"fmt"
 
-	"github.com/openshift/odo/pkg/log"
 	"github.com/rhd-gitops-example/gitops-cli/pkg/cmd/genericclioptions"
+	"github.com/rhd-gitops-example/gitops-cli/pkg/cmd/ui"
 	"github.com/rhd-gitops-example/gitops-cli/pkg/pipelines"
+	"github.com/rhd-gitops-example/gitops-cli/pkg/pipelines/config"
 	"github.com/rhd-gitops-example/gitops-cli/pkg/pipelines/ioutils"
 	"github.com/spf13/cobra"
 
@@ -29,14 +30,21 @@ var (
 
 // AddEnvParameters encapsulates the parameters for the odo pipelines init command.
 type AddEnvParameters struct {
-	envName         string
-	pipelinesFolder string
-	cluster         string
+	genericclioptions.IOStreams
+	envName            string
+	pipelinesFolder    string
+	manifestFile       string
+	cluster            string
+	syncPolicy         string
+	noDeploy           bool
+	skipNameValidation bool
+	strict             bool   // If true, an environment that collides with a system namespace is an error, rather than a warning.
+	validateHook       string // Executable that's run with the environment name, to reject it.
 }
 
 // NewAddEnvParameters bootstraps a AddEnvParameters instance.
-func NewAddEnvParameters() *AddEnvParameters {
-	return &AddEnvParameters{}
+func NewAddEnvParameters(streams genericclioptions.IOStreams) *AddEnvParameters {
+	return &AddEnvParameters{IOStreams: streams}
 }
 
 // Complete completes AddEnvParameters after they've been created.
@@ -48,8 +56,28 @@ func (eo *AddEnvParameters) Complete(name string, cmd *cobra.Command, args []str
 }
 
 // Validate validates the parameters of the EnvParameters.
+//
+// The environment name is not validated if skipNameValidation is set, this
+// allows for environments that target destinations that are not namespaces,
+// the validate hook is always run.
 func (eo *AddEnvParameters) Validate() error {
-	return nil
+	if err := ui.ValidateManifestFile(eo.manifestFile, "pipelines folder"); err != nil {
+		return err
+	}
+	if _, err := config.ParseSyncPolicy(eo.syncPolicy); err != nil {
+		return err
+	}
+	if eo.skipNameValidation {
+		eo.Warningf("Skipping validation of the environment name %q, it may not be a valid Kubernetes namespace", eo.envName)
+	} else {
+		if err := ui.ValidateEnvironmentName("", eo.envName); err != nil {
+			return err
+		}
+		if err := ui.CheckSystemNamespace("", "", eo.envName, eo.strict, eo.Warningf); err != nil {
+			return err
+		}
+	}
+	return ui.RunValidateHook(eo.validateHook, "environment add", map[string]string{"env-name": eo.envName})
 }
 
 // Run runs the project bootstrap command.
@@ -57,19 +85,23 @@ func (eo *AddEnvParameters) Run() error {
 	options := pipelines.EnvParameters{
 		EnvName:             eo.envName,
 		PipelinesFolderPath: eo.pipelinesFolder,
+		ManifestFile:        eo.manifestFile,
 		Cluster:             eo.cluster,
+		SyncPolicy:          eo.syncPolicy,
+		NoDeploy:            eo.noDeploy,
 	}
 	err := pipelines.AddEnv(&options, ioutils.NewFilesystem())
 	if err != nil {
 		return err
 	}
-	log.Successf("Created Environment %s sucessfully.", eo.envName)
+	eo.Successf("Created Environment %s sucessfully.", eo.envName)
 	return nil
 }
 
-// NewCmdAddEnv creates the project add environment command.
-func NewCmdAddEnv(name, fullName string) *cobra.Command {
-	o := NewAddEnvParameters()
+// NewCmdAddEnv creates the project add environment command, which writes its
+// output to the streams.
+func NewCmdAddEnv(name, fullName string, streams genericclioptions.IOStreams) *cobra.Command {
+	o := NewAddEnvParameters(streams)
 
 	addEnvCmd := &cobra.Command{
 		Use:     name,
@@ -84,6 +116,12 @@ func NewCmdAddEnv(name, fullName string) *cobra.Command {
 	addEnvCmd.Flags().StringVar(&o.envName, "env-name", "", "Name of the environment/namespace")
 	_ = addEnvCmd.MarkFlagRequired("env-name")
 	addEnvCmd.Flags().StringVar(&o.pipelinesFolder, "pipelines-folder", ".", "Folder path to retrieve manifest, eg. /test where manifest exists at /test/pipelines.yaml")
+	addEnvCmd.Flags().StringVar(&o.manifestFile, "manifest-file", "pipelines.yaml", "Path of the manifest file within the --pipelines-folder e.g. gitops/pipelines.yaml")
 	addEnvCmd.Flags().StringVar(&o.cluster, "cluster", "", "Deployment cluster e.g. https://kubernetes.local.svc")
+	addEnvCmd.Flags().StringVar(&o.syncPolicy, "sync-policy", "", "Argo CD sync policy for the environment, manual, or auto optionally followed by +prune and +selfheal e.g. auto+prune+selfheal, by default it's automated with pruning and self-healing")
+	addEnvCmd.Flags().BoolVar(&o.noDeploy, "no-deploy", false, "Don't generate Argo CD Applications to deploy the environment, for environments that are only used for pipeline runs")
+	addEnvCmd.Flags().BoolVar(&o.skipNameValidation, "skip-name-validation", false, "Skip the DNS-1123 validation of the environment name, for environments that target destinations other than Kubernetes namespaces")
+	addEnvCmd.Flags().BoolVar(&o.strict, "strict", false, "Fail rather than warn if the environment collides with a Kubernetes or OpenShift system namespace")
+	addEnvCmd.Flags().StringVar(&o.validateHook, "validate-hook", "", "Executable to run with the environment name as JSON on stdin, a non-zero exit status rejects it, with its stderr as the error")
 	return addEnvCmd
 }
@@ -2,9 +2,15 @@ package environment
 
 import (
 	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
 	"testing"
 
 	"github.com/spf13/cobra"
+
+	"github.com/rhd-gitops-example/gitops-cli/pkg/cmd/genericclioptions"
 )
 
 type keyValuePair struct {
@@ -24,13 +30,105 @@ func TestAddCommandWithMissingParams(t *testing.T) {
 	}
 	for _, tt := range cmdTests {
 		t.Run(tt.desc, func(rt *testing.T) {
-			_, _, err := executeCommand(NewCmdAddEnv("add", "odo pipelines environment"), tt.flags...)
+			_, _, err := executeCommand(NewCmdAddEnv("add", "odo pipelines environment", genericclioptions.NewIOStreams()), tt.flags...)
 			if err.Error() != tt.wantErr {
 				rt.Errorf("got %s, want %s", err, tt.wantErr)
 			}
 		})
 	}
 }
+
+func TestAddEnvValidateWithSkipNameValidation(t *testing.T) {
+	validateTests := []struct {
+		desc               string
+		skipNameValidation bool
+		wantErr            string
+	}{
+		{"name is validated by default", false, "Dev.Cluster is not a valid name"},
+		{"name is not validated when skipped", true, ""},
+	}
+	for _, tt := range validateTests {
+		t.Run(tt.desc, func(rt *testing.T) {
+			errOut := &bytes.Buffer{}
+			o := AddEnvParameters{
+				IOStreams:          genericclioptions.IOStreams{Out: &bytes.Buffer{}, ErrOut: errOut},
+				envName:            "Dev.Cluster",
+				skipNameValidation: tt.skipNameValidation,
+			}
+			err := o.Validate()
+			if tt.wantErr == "" {
+				if err != nil {
+					rt.Fatalf("got error %s, want nil", err)
+				}
+				if !strings.Contains(errOut.String(), `Skipping validation of the environment name "Dev.Cluster"`) {
+					rt.Fatalf("got warnings %q, want the skipped validation warning", errOut)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				rt.Fatalf("got error %v, want %s", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestAddEnvValidateWithValidateHook(t *testing.T) {
+	dir, err := ioutil.TempDir("", "validate-hook")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	hook := filepath.Join(dir, "validate.sh")
+	script := "#!/bin/sh\nif grep -q '\"env-name\":\"prod\"'; then echo 'environments must be named <team>-<stage>' >&2; exit 1; fi\n"
+	if err := ioutil.WriteFile(hook, []byte(script), 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, name := range []string{"payments-prod", "prod"} {
+		o := AddEnvParameters{
+			IOStreams:    genericclioptions.IOStreams{Out: &bytes.Buffer{}, ErrOut: &bytes.Buffer{}},
+			envName:      name,
+			validateHook: hook,
+		}
+		err := o.Validate()
+		if name == "payments-prod" {
+			if err != nil {
+				t.Fatalf("got error %s, want nil", err)
+			}
+			continue
+		}
+		want := "rejected by the validate hook " + hook + ": environments must be named <team>-<stage>"
+		if err == nil || err.Error() != want {
+			t.Fatalf("got error %v, want %s", err, want)
+		}
+	}
+}
+
+func TestAddCommandOutput(t *testing.T) {
+	dir, err := ioutil.TempDir("", "pipelines")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	err = ioutil.WriteFile(filepath.Join(dir, "pipelines.yaml"), []byte("environments:\n"), 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	out := &bytes.Buffer{}
+	streams := genericclioptions.IOStreams{Out: out, ErrOut: &bytes.Buffer{}}
+
+	_, _, err = executeCommand(NewCmdAddEnv("add", "odo pipelines environment", streams),
+		flag("env-name", "new-env"), flag("pipelines-folder", dir))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := " ✓  Created Environment new-env sucessfully.\n"
+	if out.String() != want {
+		t.Fatalf("got output %q, want %q", out, want)
+	}
+}
+
 func executeCommand(cmd *cobra.Command, flags ...keyValuePair) (c *cobra.Command, output string, err error) {
 	buf := new(bytes.Buffer)
 	cmd.SetOutput(buf)
@@ -3,6 +3,7 @@ package environment
 import (
 	"fmt"
 
+	"github.com/rhd-gitops-example/gitops-cli/pkg/cmd/genericclioptions"
 	"github.com/rhd-gitops-example/gitops-cli/pkg/cmd/utility"
 	"github.com/spf13/cobra"
 )
@@ -11,21 +12,23 @@ import (
 const EnvRecommendedCommandName = "environment"
 
 // NewCmdEnv create a new environment command
-func NewCmdEnv(name, fullName string) *cobra.Command {
+func NewCmdEnv(name, fullName string, streams genericclioptions.IOStreams) *cobra.Command {
 
-	addEnvCmd := NewCmdAddEnv(AddEnvRecommendedCommandName, utility.GetFullName(fullName, AddEnvRecommendedCommandName))
+	addEnvCmd := NewCmdAddEnv(AddEnvRecommendedCommandName, utility.GetFullName(fullName, AddEnvRecommendedCommandName), streams)
+	promoteEnvCmd := NewCmdPromoteEnv(PromoteEnvRecommendedCommandName, utility.GetFullName(fullName, PromoteEnvRecommendedCommandName), streams)
 
 	var envCmd = &cobra.Command{
 		Use:   name,
 		Short: "Manage an environment in GitOps",
-		Example: fmt.Sprintf("%s\n%s\n\n  See sub-commands individually for more examples",
-			fullName, AddEnvRecommendedCommandName),
+		Example: fmt.Sprintf("%s\n%s\n%s\n\n  See sub-commands individually for more examples",
+			fullName, AddEnvRecommendedCommandName, PromoteEnvRecommendedCommandName),
 		Run: func(cmd *cobra.Command, args []string) {
 		},
 	}
 
 	envCmd.Flags().AddFlagSet(addEnvCmd.Flags())
 	envCmd.AddCommand(addEnvCmd)
+	envCmd.AddCommand(promoteEnvCmd)
 
 	envCmd.Annotations = map[string]string{"command": "main"}
 	// envCmd.SetUsageTemplate(odoutil.CmdUsageTemplate)
@@ -0,0 +1,112 @@
+package environment
+
+import (
+	"fmt"
+
+	"github.com/rhd-gitops-example/gitops-cli/pkg/cmd/genericclioptions"
+	"github.com/rhd-gitops-example/gitops-cli/pkg/cmd/ui"
+	"github.com/rhd-gitops-example/gitops-cli/pkg/pipelines"
+	"github.com/rhd-gitops-example/gitops-cli/pkg/pipelines/ioutils"
+	"github.com/spf13/cobra"
+
+	ktemplates "k8s.io/kubectl/pkg/util/templates"
+)
+
+const (
+	// PromoteEnvRecommendedCommandName the recommended command name
+	PromoteEnvRecommendedCommandName = "promote"
+)
+
+var (
+	promoteEnvExample = ktemplates.Examples(`
+	# Promote the api service from the stage environment to prod
+	%[1]s --from stage --to prod --service api
+	`)
+
+	promoteEnvLongDesc = ktemplates.LongDesc(`Promote a service between environments.
+
+	The service's source_url in the target environment is updated to match the
+	source environment in pipelines.yaml, and the environments' resources are
+	regenerated. The service must exist in the same application in both
+	environments.`)
+	promoteEnvShortDesc = `Promote a service between environments`
+)
+
+// PromoteEnvParameters encapsulates the parameters for the environment
+// promote command.
+type PromoteEnvParameters struct {
+	genericclioptions.IOStreams
+	fromEnvName     string
+	toEnvName       string
+	appName         string
+	serviceName     string
+	pipelinesFolder string
+	manifestFile    string
+}
+
+// NewPromoteEnvParameters bootstraps a PromoteEnvParameters instance.
+func NewPromoteEnvParameters(streams genericclioptions.IOStreams) *PromoteEnvParameters {
+	return &PromoteEnvParameters{IOStreams: streams}
+}
+
+// Complete completes PromoteEnvParameters after they've been created.
+func (po *PromoteEnvParameters) Complete(name string, cmd *cobra.Command, args []string) error {
+	return nil
+}
+
+// Validate validates the parameters of the PromoteEnvParameters.
+func (po *PromoteEnvParameters) Validate() error {
+	if po.fromEnvName == po.toEnvName {
+		return fmt.Errorf("cannot promote from environment %s to itself", po.fromEnvName)
+	}
+	return ui.ValidateManifestFile(po.manifestFile, "pipelines folder")
+}
+
+// Run runs the environment promote command.
+func (po *PromoteEnvParameters) Run() error {
+	options := pipelines.PromoteParameters{
+		PipelinesFolderPath: po.pipelinesFolder,
+		ManifestFile:        po.manifestFile,
+		FromEnvName:         po.fromEnvName,
+		ToEnvName:           po.toEnvName,
+		AppName:             po.appName,
+		ServiceName:         po.serviceName,
+	}
+	promoted, err := pipelines.PromoteService(&options, ioutils.NewFilesystem())
+	if err != nil {
+		return err
+	}
+	if !promoted {
+		po.Warningf("Service %s in environment %s already matches environment %s, no changes were made.", po.serviceName, po.toEnvName, po.fromEnvName)
+		return nil
+	}
+	po.Successf("Promoted Service %s from environment %s to %s sucessfully.", po.serviceName, po.fromEnvName, po.toEnvName)
+	return nil
+}
+
+// NewCmdPromoteEnv creates the environment promote command, which writes its
+// output to the streams.
+func NewCmdPromoteEnv(name, fullName string, streams genericclioptions.IOStreams) *cobra.Command {
+	o := NewPromoteEnvParameters(streams)
+
+	promoteEnvCmd := &cobra.Command{
+		Use:     name,
+		Short:   promoteEnvShortDesc,
+		Long:    promoteEnvLongDesc,
+		Example: fmt.Sprintf(promoteEnvExample, fullName),
+		Run: func(cmd *cobra.Command, args []string) {
+			genericclioptions.GenericRun(o, cmd, args)
+		},
+	}
+
+	promoteEnvCmd.Flags().StringVar(&o.fromEnvName, "from", "", "Name of the environment to promote the service from")
+	promoteEnvCmd.Flags().StringVar(&o.toEnvName, "to", "", "Name of the environment to promote the service to")
+	promoteEnvCmd.Flags().StringVar(&o.serviceName, "service", "", "Name of the service to promote")
+	promoteEnvCmd.Flags().StringVar(&o.appName, "app-name", "", "Name of the application of the service, only needed if the service is in more than one application")
+	promoteEnvCmd.Flags().StringVar(&o.pipelinesFolder, "pipelines-folder", ".", "Folder path to retrieve manifest, eg. /test where manifest exists at /test/pipelines.yaml")
+	promoteEnvCmd.Flags().StringVar(&o.manifestFile, "manifest-file", "pipelines.yaml", "Path of the manifest file within the --pipelines-folder e.g. gitops/pipelines.yaml")
+	_ = promoteEnvCmd.MarkFlagRequired("from")
+	_ = promoteEnvCmd.MarkFlagRequired("to")
+	_ = promoteEnvCmd.MarkFlagRequired("service")
+	return promoteEnvCmd
+}
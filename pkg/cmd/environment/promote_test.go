@@ -0,0 +1,40 @@
+package environment
+
+import (
+	"testing"
+
+	"github.com/rhd-gitops-example/gitops-cli/pkg/cmd/genericclioptions"
+)
+
+func TestPromoteCommandWithMissingParams(t *testing.T) {
+	cmdTests := []struct {
+		desc    string
+		flags   []keyValuePair
+		wantErr string
+	}{
+		{"Missing service flag",
+			[]keyValuePair{flag("from", "stage"), flag("to", "prod")},
+			`required flag(s) "service" not set`},
+		{"Missing from and to flags",
+			[]keyValuePair{flag("service", "api")},
+			`required flag(s) "from", "to" not set`},
+	}
+	for _, tt := range cmdTests {
+		t.Run(tt.desc, func(rt *testing.T) {
+			_, _, err := executeCommand(NewCmdPromoteEnv("promote", "odo pipelines environment", genericclioptions.NewIOStreams()), tt.flags...)
+			if err.Error() != tt.wantErr {
+				rt.Errorf("got %s, want %s", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestPromoteEnvValidate(t *testing.T) {
+	o := PromoteEnvParameters{fromEnvName: "stage", toEnvName: "stage", serviceName: "api"}
+
+	err := o.Validate()
+
+	if err == nil || err.Error() != "cannot promote from environment stage to itself" {
+		t.Fatalf("got error %v, want the same environment error", err)
+	}
+}
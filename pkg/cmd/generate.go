@@ -0,0 +1,105 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/openshift/odo/pkg/log"
+	"github.com/rhd-gitops-example/gitops-cli/pkg/cmd/genericclioptions"
+	"github.com/rhd-gitops-example/gitops-cli/pkg/pipelines"
+	"github.com/rhd-gitops-example/gitops-cli/pkg/pipelines/ioutils"
+	"github.com/spf13/cobra"
+
+	ktemplates "k8s.io/kubectl/pkg/util/templates"
+)
+
+const (
+	// GenerateRecommendedCommandName the recommended command name
+	GenerateRecommendedCommandName = "generate"
+)
+
+var (
+	generateExample = ktemplates.Examples(`
+	# Regenerate the manifests from the pipelines.yaml in the current directory
+	%[1]s --overwrite
+
+	# List the files that would be regenerated
+	%[1]s --dry-run
+	`)
+
+	generateLongDesc = ktemplates.LongDesc(`Regenerate the GitOps manifests from an existing pipelines.yaml.
+
+	This is useful after upgrading, to update the generated files to match the
+	current templates.`)
+	generateShortDesc = `Regenerate manifests from pipelines.yaml`
+)
+
+// GenerateParameters encapsulates the parameters for the generate command.
+type GenerateParameters struct {
+	pipelinesFolderPath string
+	output              string // path to add Gitops resources
+	outputRoot          string // if set, output must be within this directory
+	useApplicationSet   bool
+	dryRun              bool
+	overwrite           bool
+}
+
+// NewGenerateParameters bootstraps a GenerateParameters instance.
+func NewGenerateParameters() *GenerateParameters {
+	return &GenerateParameters{}
+}
+
+// Complete completes GenerateParameters after they've been created.
+func (io *GenerateParameters) Complete(name string, cmd *cobra.Command, args []string) error {
+	return nil
+}
+
+// Validate validates the parameters of the GenerateParameters.
+func (io *GenerateParameters) Validate() error {
+	return nil
+}
+
+// Run runs the generate command.
+func (io *GenerateParameters) Run() error {
+	options := pipelines.GenerateParameters{
+		PipelinesFolderPath: io.pipelinesFolderPath,
+		OutputPath:          io.output,
+		OutputRoot:          io.outputRoot,
+		UseApplicationSet:   io.useApplicationSet,
+		DryRun:              io.dryRun,
+		Overwrite:           io.overwrite,
+	}
+	files, unchanged, err := pipelines.Generate(&options, ioutils.NewFilesystem())
+	if err != nil {
+		return err
+	}
+	if io.dryRun {
+		for _, f := range files {
+			log.Infof("Would write %s", f)
+		}
+		return nil
+	}
+	log.Successf("Generated %d files, skipped %d unchanged files.", len(files), len(unchanged))
+	return nil
+}
+
+// NewCmdGenerate creates the generate command.
+func NewCmdGenerate(name, fullName string) *cobra.Command {
+	o := NewGenerateParameters()
+	generateCmd := &cobra.Command{
+		Use:     name,
+		Short:   generateShortDesc,
+		Long:    generateLongDesc,
+		Example: fmt.Sprintf(generateExample, fullName),
+		Run: func(cmd *cobra.Command, args []string) {
+			genericclioptions.GenericRun(o, cmd, args)
+		},
+	}
+
+	generateCmd.Flags().StringVar(&o.output, "output", ".", "Folder path to add GitOps resources")
+	generateCmd.Flags().StringVar(&o.outputRoot, "output-root", "", "If provided, the output path must be within this directory")
+	generateCmd.Flags().BoolVar(&o.useApplicationSet, "use-applicationset", false, "Generate a single Argo CD ApplicationSet rather than an Application per environment and application")
+	generateCmd.Flags().StringVar(&o.pipelinesFolderPath, "pipelines-folder", ".", "Folder path to retrieve manifest, eg. /test where manifest exists at /test/pipelines.yaml")
+	generateCmd.Flags().BoolVar(&o.dryRun, "dry-run", false, "List the files that would be generated without writing them")
+	generateCmd.Flags().BoolVar(&o.overwrite, "overwrite", false, "Overwrites previously generated files (if any)")
+	return generateCmd
+}
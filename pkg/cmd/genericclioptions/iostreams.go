@@ -0,0 +1,40 @@
+package genericclioptions
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"sync/atomic"
+
+	"github.com/openshift/odo/pkg/log"
+)
+
+// IOStreams are the writers that a command writes its output to, this allows
+// callers that embed the commands to capture the output.
+type IOStreams struct {
+	Out    io.Writer // The results of the command.
+	ErrOut io.Writer // Warnings and errors.
+}
+
+// NewIOStreams returns IOStreams that write to os.Stdout and os.Stderr.
+func NewIOStreams() IOStreams {
+	return IOStreams{Out: os.Stdout, ErrOut: os.Stderr}
+}
+
+// Successf writes a success message to Out, in the same format as log.Successf.
+func (s IOStreams) Successf(format string, a ...interface{}) {
+	if log.IsJSON() {
+		return
+	}
+	fmt.Fprintf(s.Out, " ✓  %s\n", fmt.Sprintf(format, a...))
+}
+
+// Warningf writes a warning to ErrOut, in the same format as log.Warningf, and
+// records it for the FailOnWarning check.
+func (s IOStreams) Warningf(format string, a ...interface{}) {
+	atomic.AddInt32(&warnings, 1)
+	if log.IsJSON() {
+		return
+	}
+	fmt.Fprintf(s.ErrOut, " ⚠  %s\n", fmt.Sprintf(format, a...))
+}
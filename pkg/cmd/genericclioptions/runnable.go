@@ -19,10 +19,22 @@ type Runnable interface {
 
 // GenericRun executes the Runnable methods in the right order
 func GenericRun(o Runnable, cmd *cobra.Command, args []string) {
-	// Run completion, validation and run.
-	logErrorAndExit(o.Complete(cmd.Name(), cmd, args), "")
-	logErrorAndExit(o.Validate(), "")
-	logErrorAndExit(o.Run(), "")
+	logErrorAndExit(run(o, cmd, args), "")
+}
+
+// run runs completion, validation and run, and returns the first error, if
+// FailOnWarning is set, reported warnings are an error once it has run.
+func run(o Runnable, cmd *cobra.Command, args []string) error {
+	if err := o.Complete(cmd.Name(), cmd, args); err != nil {
+		return err
+	}
+	if err := o.Validate(); err != nil {
+		return err
+	}
+	if err := o.Run(); err != nil {
+		return err
+	}
+	return checkWarnings()
 }
 
 // LogErrorAndExit prints the cause of the given error and exits the code with an
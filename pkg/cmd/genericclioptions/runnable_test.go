@@ -0,0 +1,66 @@
+package genericclioptions
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/spf13/cobra"
+)
+
+type warningRunnable struct {
+	IOStreams
+	warn bool
+}
+
+func (o *warningRunnable) Complete(name string, cmd *cobra.Command, args []string) error {
+	return nil
+}
+
+func (o *warningRunnable) Validate() error {
+	return nil
+}
+
+func (o *warningRunnable) Run() error {
+	if o.warn {
+		o.Warningf("the namespace %q may not be safe to use", "default")
+	}
+	return nil
+}
+
+func TestRunWithFailOnWarning(t *testing.T) {
+	runTests := []struct {
+		name          string
+		warn          bool
+		failOnWarning bool
+		wantErr       string
+	}{
+		{"no warnings", false, true, ""},
+		{"warning without fail-on-warning", true, false, ""},
+		{"warning with fail-on-warning", true, true, "1 warning(s) reported, failing as --fail-on-warning is set"},
+	}
+
+	for _, tt := range runTests {
+		t.Run(tt.name, func(rt *testing.T) {
+			defer func(orig bool) {
+				FailOnWarning = orig
+				warnings = 0
+			}(FailOnWarning)
+			FailOnWarning = tt.failOnWarning
+			errOut := &bytes.Buffer{}
+			o := &warningRunnable{IOStreams: IOStreams{Out: &bytes.Buffer{}, ErrOut: errOut}, warn: tt.warn}
+
+			err := run(o, &cobra.Command{Use: "test"}, nil)
+
+			if tt.wantErr == "" && err != nil {
+				rt.Fatalf("got error %s, want nil", err)
+			}
+			if tt.wantErr != "" && (err == nil || err.Error() != tt.wantErr) {
+				rt.Fatalf("got error %v, want %s", err, tt.wantErr)
+			}
+			if tt.warn && !strings.Contains(errOut.String(), `the namespace "default" may not be safe to use`) {
+				rt.Fatalf("warning not written, got %q", errOut)
+			}
+		})
+	}
+}
@@ -0,0 +1,34 @@
+package genericclioptions
+
+import (
+	"fmt"
+	"sync/atomic"
+
+	"github.com/openshift/odo/pkg/log"
+)
+
+// FailOnWarning is set from the --fail-on-warning flag, if true, a command
+// that reports a warning exits with a non-zero status once it has completed.
+var FailOnWarning bool
+
+// warnings is the number of warnings reported by the command, they can be
+// reported from several goroutines.
+var warnings int32
+
+// Warningf writes a warning with log.Warningf, and records it for the
+// FailOnWarning check, warnings must be reported with this, or with
+// IOStreams.Warningf, rather than log.Warningf.
+func Warningf(format string, a ...interface{}) {
+	atomic.AddInt32(&warnings, 1)
+	log.Warningf(format, a...)
+}
+
+// checkWarnings returns an error if FailOnWarning is set, and warnings have
+// been reported.
+func checkWarnings() error {
+	n := atomic.LoadInt32(&warnings)
+	if !FailOnWarning || n == 0 {
+		return nil
+	}
+	return fmt.Errorf("%d warning(s) reported, failing as --fail-on-warning is set", n)
+}
@@ -1,19 +1,33 @@
 package cmd
 
 import (
+	"fmt"
 	"log"
 
 	"github.com/rhd-gitops-example/gitops-cli/pkg/cmd/environment"
+	"github.com/rhd-gitops-example/gitops-cli/pkg/cmd/genericclioptions"
+	"github.com/rhd-gitops-example/gitops-cli/pkg/cmd/hooks"
+	"github.com/rhd-gitops-example/gitops-cli/pkg/cmd/manifest"
+	"github.com/rhd-gitops-example/gitops-cli/pkg/cmd/profile"
+	"github.com/rhd-gitops-example/gitops-cli/pkg/cmd/secret"
 	"github.com/rhd-gitops-example/gitops-cli/pkg/cmd/service"
+	"github.com/rhd-gitops-example/gitops-cli/pkg/cmd/task"
+	"github.com/rhd-gitops-example/gitops-cli/pkg/cmd/token"
 	"github.com/rhd-gitops-example/gitops-cli/pkg/cmd/utility"
 	"github.com/rhd-gitops-example/gitops-cli/pkg/cmd/version"
 	"github.com/rhd-gitops-example/gitops-cli/pkg/cmd/webhook"
+	"github.com/rhd-gitops-example/gitops-cli/pkg/pipelines/clientconfig"
+	"github.com/rhd-gitops-example/gitops-cli/pkg/pipelines/ioutils"
+	"github.com/rhd-gitops-example/gitops-cli/pkg/pipelines/network"
 	"github.com/spf13/cobra"
+	"k8s.io/apimachinery/pkg/util/validation"
 )
 
 var (
 	gitopsLong = "CLI tool to scaffold your GitOps repository"
 	fullName   = "gitops"
+
+	profileName string
 )
 
 func makeRootCmd() *cobra.Command {
@@ -21,16 +35,56 @@ func makeRootCmd() *cobra.Command {
 		Use:   "gitops",
 		Short: "gitops",
 		Long:  gitopsLong,
+		// Enables the --version flag on the root command.
+		Version: version.Get().Version,
+		PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+			if utility.MaxNameLength < validation.DNS1123LabelMaxLength {
+				return fmt.Errorf("invalid --max-name-length %d, the limit can't be less than %d characters", utility.MaxNameLength, validation.DNS1123LabelMaxLength)
+			}
+			if err := profile.Use(ioutils.NewFilesystem(), profile.DefaultConfigPath, profileName, cmd); err != nil {
+				return err
+			}
+			if utility.RepoRootDetection {
+				return utility.DetectRepoRoot(ioutils.NewFilesystem(), ".", cmd)
+			}
+			return nil
+		},
 	}
+	rootCmd.SetVersionTemplate(version.Get().String() + "\n")
+	rootCmd.PersistentFlags().StringVar(&clientconfig.CAFile, "k8s-ca-file", "", "Path to a PEM file of additional certificate authorities to trust for the Kubernetes API")
+	rootCmd.PersistentFlags().StringVar(&clientconfig.Impersonate.UserName, "as", "", "Username to impersonate for requests to the Kubernetes API, the user can be a regular user or a service account in a namespace")
+	rootCmd.PersistentFlags().StringArrayVar(&clientconfig.Impersonate.Groups, "as-group", nil, "Group to impersonate for requests to the Kubernetes API, this flag can be repeated to specify multiple groups")
+	rootCmd.PersistentFlags().StringVar(&clientconfig.ImpersonateUID, "as-uid", "", "UID to impersonate for requests to the Kubernetes API")
+	rootCmd.PersistentFlags().StringVar(&clientconfig.Context, "context", "", "kubeconfig context to use for requests to the Kubernetes API, if not provided, the current context is used")
+	rootCmd.PersistentFlags().StringVar(&profileName, "profile", "", fmt.Sprintf("Profile from %s to provide the values of flags that are not provided", profile.DefaultConfigPath))
+	rootCmd.PersistentFlags().BoolVar(&genericclioptions.FailOnWarning, "fail-on-warning", false, "Exit with a non-zero status if the command reports any warnings, once it has completed")
+	rootCmd.PersistentFlags().BoolVar(&network.Disabled, "no-network", false, "Fail any attempt to connect to the Git hosting service or Kubernetes API, rather than making the connection")
+	rootCmd.PersistentFlags().StringSliceVar(&network.AllowedHosts, "allowed-hosts", nil, "Hosts that the Git hosting service, Kubernetes API and template clients may connect to, a leading *. allows any subdomain, if not provided, all hosts are allowed")
+	rootCmd.PersistentFlags().BoolVar(&utility.RepoRootDetection, "repo-root-detection", false, "Default --pipelines-folder and --output to the closest directory with a pipelines.yaml, or the root of the Git repository, found from the current directory")
+	rootCmd.PersistentFlags().IntVar(&utility.MaxNameLength, "max-name-length", validation.DNS1123LabelMaxLength, "Length limit for names and namespaces, only increase this for destinations that accept longer identifiers, Kubernetes rejects namespaces and labels longer than 63 characters")
 
 	// Add all subcommands to base command
+	streams := genericclioptions.NewIOStreams()
 	rootCmd.AddCommand(
 		NewCmdBootstrap(BootstrapRecommendedCommandName, utility.GetFullName(fullName, BootstrapRecommendedCommandName)),
-		environment.NewCmdEnv(environment.EnvRecommendedCommandName, utility.GetFullName(fullName, environment.EnvRecommendedCommandName)),
-		service.NewCmd(service.RecommendedCommandName, utility.GetFullName(fullName, service.RecommendedCommandName)),
+		environment.NewCmdEnv(environment.EnvRecommendedCommandName, utility.GetFullName(fullName, environment.EnvRecommendedCommandName), streams),
+		service.NewCmd(service.RecommendedCommandName, utility.GetFullName(fullName, service.RecommendedCommandName), streams),
 		version.NewCmd(version.RecommendedCommandName, utility.GetFullName(fullName, version.RecommendedCommandName)),
 		webhook.NewCmdWebhook(webhook.RecommendedCommandName, utility.GetFullName(fullName, webhook.RecommendedCommandName)),
 		NewCmdBuild(BuildRecommendedCommandName, utility.GetFullName(fullName, BuildRecommendedCommandName)),
+		NewCmdGenerate(GenerateRecommendedCommandName, utility.GetFullName(fullName, GenerateRecommendedCommandName)),
+		NewCmdRepair(RepairRecommendedCommandName, utility.GetFullName(fullName, RepairRecommendedCommandName)),
+		NewCmdLint(LintRecommendedCommandName, utility.GetFullName(fullName, LintRecommendedCommandName)),
+		NewCmdSchema(SchemaRecommendedCommandName, utility.GetFullName(fullName, SchemaRecommendedCommandName)),
+		NewCmdList(ListRecommendedCommandName, utility.GetFullName(fullName, ListRecommendedCommandName), streams),
+		NewCmdStatus(StatusRecommendedCommandName, utility.GetFullName(fullName, StatusRecommendedCommandName), streams),
+		NewCmdDiff(DiffRecommendedCommandName, utility.GetFullName(fullName, DiffRecommendedCommandName), streams),
+		manifest.NewCmdManifest(manifest.RecommendedCommandName, utility.GetFullName(fullName, manifest.RecommendedCommandName), streams),
+		hooks.NewCmdHooks(hooks.RecommendedCommandName, utility.GetFullName(fullName, hooks.RecommendedCommandName)),
+		secret.NewCmdSecret(secret.RecommendedCommandName, utility.GetFullName(fullName, secret.RecommendedCommandName)),
+		token.NewCmdToken(token.RecommendedCommandName, utility.GetFullName(fullName, token.RecommendedCommandName)),
+		task.NewCmdTask(task.RecommendedCommandName, utility.GetFullName(fullName, task.RecommendedCommandName)),
+		profile.NewCmdProfile(profile.RecommendedCommandName, utility.GetFullName(fullName, profile.RecommendedCommandName), streams),
 	)
 
 	return rootCmd
@@ -0,0 +1,32 @@
+package hooks
+
+import (
+	"fmt"
+
+	"github.com/rhd-gitops-example/gitops-cli/pkg/cmd/utility"
+	"github.com/spf13/cobra"
+)
+
+// RecommendedCommandName is the recommended hooks command name.
+const RecommendedCommandName = "hooks"
+
+// NewCmdHooks creates a new hooks command
+func NewCmdHooks(name, fullName string) *cobra.Command {
+	installCmd := newCmdInstall(installRecommendedCommandName, utility.GetFullName(fullName, installRecommendedCommandName))
+
+	var hooksCmd = &cobra.Command{
+		Use:   name,
+		Short: "Manage git hooks in the GitOps repository",
+		Long:  "Install git hooks that check the GitOps repository before changes are committed.",
+		Example: fmt.Sprintf("%s\n%s\n\n  See sub-commands individually for more examples",
+			fullName,
+			installRecommendedCommandName),
+		Run: func(cmd *cobra.Command, args []string) {
+		},
+	}
+
+	hooksCmd.AddCommand(installCmd)
+
+	hooksCmd.Annotations = map[string]string{"command": "main"}
+	return hooksCmd
+}
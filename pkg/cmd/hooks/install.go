@@ -0,0 +1,65 @@
+package hooks
+
+import (
+	"fmt"
+
+	"github.com/openshift/odo/pkg/log"
+	"github.com/rhd-gitops-example/gitops-cli/pkg/cmd/genericclioptions"
+	"github.com/rhd-gitops-example/gitops-cli/pkg/pipelines/hooks"
+	"github.com/rhd-gitops-example/gitops-cli/pkg/pipelines/ioutils"
+	"github.com/spf13/cobra"
+	ktemplates "k8s.io/kubectl/pkg/util/templates"
+)
+
+const installRecommendedCommandName = "install"
+
+var (
+	installExample = ktemplates.Examples(`
+	# Install a pre-commit hook that lints the manifest
+	%[1]s --repo-path /path/to/gitops`)
+
+	installLongDesc = ktemplates.LongDesc(`Install a git pre-commit hook that lints the manifest
+	before each commit.  An existing hook that was not installed by this command
+	is only replaced if --force is provided.`)
+)
+
+type installOptions struct {
+	repoPath string
+	force    bool
+}
+
+// Complete completes installOptions after they've been created
+func (o *installOptions) Complete(name string, cmd *cobra.Command, args []string) error {
+	return nil
+}
+
+// Validate validates the installOptions based on completed values
+func (o *installOptions) Validate() error {
+	return nil
+}
+
+// Run contains the logic for the install command
+func (o *installOptions) Run() error {
+	hookPath, err := hooks.InstallPreCommit(ioutils.NewFilesystem(), o.repoPath, o.force)
+	if err != nil {
+		return err
+	}
+	log.Successf("Installed pre-commit hook at %s", hookPath)
+	return nil
+}
+
+func newCmdInstall(name, fullName string) *cobra.Command {
+	o := &installOptions{}
+	command := &cobra.Command{
+		Use:     name,
+		Short:   "Install a pre-commit hook",
+		Long:    installLongDesc,
+		Example: fmt.Sprintf(installExample, fullName),
+		Run: func(cmd *cobra.Command, args []string) {
+			genericclioptions.GenericRun(o, cmd, args)
+		},
+	}
+	command.Flags().StringVar(&o.repoPath, "repo-path", ".", "Path to the root of the GitOps repository to install the hook into")
+	command.Flags().BoolVar(&o.force, "force", false, "Overwrite an existing pre-commit hook that was not installed by this command")
+	return command
+}
@@ -0,0 +1,78 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/openshift/odo/pkg/log"
+	"github.com/rhd-gitops-example/gitops-cli/pkg/cmd/genericclioptions"
+	"github.com/rhd-gitops-example/gitops-cli/pkg/pipelines"
+	"github.com/rhd-gitops-example/gitops-cli/pkg/pipelines/ioutils"
+	"github.com/spf13/cobra"
+
+	ktemplates "k8s.io/kubectl/pkg/util/templates"
+)
+
+const (
+	// LintRecommendedCommandName the recommended command name
+	LintRecommendedCommandName = "lint"
+)
+
+var (
+	lintExample = ktemplates.Examples(`
+	# Lint the manifest in the current directory
+	%[1]s 
+	`)
+
+	lintLongDesc  = ktemplates.LongDesc(`Validate the GitOps manifest and report any problems`)
+	lintShortDesc = `Validate the manifest`
+)
+
+// LintParameters encapsulates the parameters for the lint command.
+type LintParameters struct {
+	pipelinesFolderPath string
+}
+
+// NewLintParameters bootstraps a LintParameters instance.
+func NewLintParameters() *LintParameters {
+	return &LintParameters{}
+}
+
+// Complete completes LintParameters after they've been created.
+func (io *LintParameters) Complete(name string, cmd *cobra.Command, args []string) error {
+	return nil
+}
+
+// Validate validates the parameters of the LintParameters.
+func (io *LintParameters) Validate() error {
+	return nil
+}
+
+// Run runs the lint command.
+func (io *LintParameters) Run() error {
+	options := pipelines.LintParameters{
+		PipelinesFolderPath: io.pipelinesFolderPath,
+	}
+	err := pipelines.Lint(&options, ioutils.NewFilesystem())
+	if err != nil {
+		return err
+	}
+	log.Success("Manifest is valid.")
+	return nil
+}
+
+// NewCmdLint creates the lint command.
+func NewCmdLint(name, fullName string) *cobra.Command {
+	o := NewLintParameters()
+	lintCmd := &cobra.Command{
+		Use:     name,
+		Short:   lintShortDesc,
+		Long:    lintLongDesc,
+		Example: fmt.Sprintf(lintExample, fullName),
+		Run: func(cmd *cobra.Command, args []string) {
+			genericclioptions.GenericRun(o, cmd, args)
+		},
+	}
+
+	lintCmd.Flags().StringVar(&o.pipelinesFolderPath, "pipelines-folder", ".", "Folder path to retrieve manifest, eg. /test where manifest exists at /test/pipelines.yaml")
+	return lintCmd
+}
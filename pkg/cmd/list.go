@@ -0,0 +1,82 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/rhd-gitops-example/gitops-cli/pkg/cmd/genericclioptions"
+	"github.com/rhd-gitops-example/gitops-cli/pkg/pipelines"
+	"github.com/rhd-gitops-example/gitops-cli/pkg/pipelines/ioutils"
+	"github.com/spf13/cobra"
+
+	ktemplates "k8s.io/kubectl/pkg/util/templates"
+)
+
+const (
+	// ListRecommendedCommandName the recommended command name
+	ListRecommendedCommandName = "list"
+)
+
+var (
+	listExample = ktemplates.Examples(`
+	# List everything managed by the manifest in the current directory
+	%[1]s
+
+	# List the managed resources as JSON
+	%[1]s -o json
+	`)
+
+	listLongDesc = ktemplates.LongDesc(`List the environments, applications and services managed by a pipelines.yaml.
+
+	The source and configuration repositories, and the webhook secrets for the
+	services are included.`)
+	listShortDesc = `List the resources managed by pipelines.yaml`
+)
+
+// ListParameters encapsulates the parameters for the list command.
+type ListParameters struct {
+	genericclioptions.IOStreams
+	pipelinesFolderPath string
+	output              string
+}
+
+// NewListParameters bootstraps a ListParameters instance.
+func NewListParameters(streams genericclioptions.IOStreams) *ListParameters {
+	return &ListParameters{IOStreams: streams}
+}
+
+// Complete completes ListParameters after they've been created.
+func (io *ListParameters) Complete(name string, cmd *cobra.Command, args []string) error {
+	return nil
+}
+
+// Validate validates the parameters of the ListParameters.
+func (io *ListParameters) Validate() error {
+	return nil
+}
+
+// Run runs the list command.
+func (io *ListParameters) Run() error {
+	options := pipelines.ListParameters{
+		PipelinesFolderPath: io.pipelinesFolderPath,
+		Output:              io.output,
+	}
+	return pipelines.List(&options, ioutils.NewFilesystem(), io.Out)
+}
+
+// NewCmdList creates the list command, which writes its output to the streams.
+func NewCmdList(name, fullName string, streams genericclioptions.IOStreams) *cobra.Command {
+	o := NewListParameters(streams)
+	listCmd := &cobra.Command{
+		Use:     name,
+		Short:   listShortDesc,
+		Long:    listLongDesc,
+		Example: fmt.Sprintf(listExample, fullName),
+		Run: func(cmd *cobra.Command, args []string) {
+			genericclioptions.GenericRun(o, cmd, args)
+		},
+	}
+
+	listCmd.Flags().StringVar(&o.pipelinesFolderPath, "pipelines-folder", ".", "Folder path to retrieve manifest, eg. /test where manifest exists at /test/pipelines.yaml")
+	listCmd.Flags().StringVarP(&o.output, "output", "o", "", "Output format, either empty for a tree, json or yaml")
+	return listCmd
+}
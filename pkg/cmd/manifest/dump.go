@@ -0,0 +1,71 @@
+package manifest
+
+import (
+	"fmt"
+
+	"github.com/rhd-gitops-example/gitops-cli/pkg/cmd/genericclioptions"
+	"github.com/rhd-gitops-example/gitops-cli/pkg/pipelines"
+	"github.com/rhd-gitops-example/gitops-cli/pkg/pipelines/ioutils"
+	"github.com/spf13/cobra"
+	ktemplates "k8s.io/kubectl/pkg/util/templates"
+)
+
+const dumpRecommendedCommandName = "dump"
+
+var (
+	dumpExample = ktemplates.Examples(`
+	# Output the manifest in the current directory with the defaults filled in
+	%[1]s
+
+	# Output the manifest as JSON
+	%[1]s -o json`)
+
+	dumpLongDesc = ktemplates.LongDesc(`Output the manifest in a canonical form for scripts
+	and other tools.  The environments, applications and services are sorted by
+	name, and the defaults that are applied when generating resources are filled
+	in.  The manifest is not modified.`)
+)
+
+type dumpOptions struct {
+	genericclioptions.IOStreams
+	pipelinesFolderPath string
+	output              string
+}
+
+// Complete completes dumpOptions after they've been created
+func (o *dumpOptions) Complete(name string, cmd *cobra.Command, args []string) error {
+	return nil
+}
+
+// Validate validates the dumpOptions based on completed values
+func (o *dumpOptions) Validate() error {
+	if o.output != "yaml" && o.output != "json" {
+		return fmt.Errorf("unsupported output format %q, must be json or yaml", o.output)
+	}
+	return nil
+}
+
+// Run contains the logic for the dump command
+func (o *dumpOptions) Run() error {
+	options := pipelines.DumpParameters{
+		PipelinesFolderPath: o.pipelinesFolderPath,
+		Output:              o.output,
+	}
+	return pipelines.DumpManifest(&options, ioutils.NewFilesystem(), o.Out)
+}
+
+func newCmdDump(name, fullName string, streams genericclioptions.IOStreams) *cobra.Command {
+	o := &dumpOptions{IOStreams: streams}
+	command := &cobra.Command{
+		Use:     name,
+		Short:   "Output the manifest with the defaults filled in",
+		Long:    dumpLongDesc,
+		Example: fmt.Sprintf(dumpExample, fullName),
+		Run: func(cmd *cobra.Command, args []string) {
+			genericclioptions.GenericRun(o, cmd, args)
+		},
+	}
+	command.Flags().StringVar(&o.pipelinesFolderPath, "pipelines-folder", ".", "Folder path to retrieve manifest, eg. /test where manifest exists at /test/pipelines.yaml")
+	command.Flags().StringVarP(&o.output, "output", "o", "yaml", "Output format, either yaml or json")
+	return command
+}
@@ -0,0 +1,33 @@
+package manifest
+
+import (
+	"fmt"
+
+	"github.com/rhd-gitops-example/gitops-cli/pkg/cmd/genericclioptions"
+	"github.com/rhd-gitops-example/gitops-cli/pkg/cmd/utility"
+	"github.com/spf13/cobra"
+)
+
+// RecommendedCommandName is the recommended manifest command name.
+const RecommendedCommandName = "manifest"
+
+// NewCmdManifest creates a new manifest command
+func NewCmdManifest(name, fullName string, streams genericclioptions.IOStreams) *cobra.Command {
+	dumpCmd := newCmdDump(dumpRecommendedCommandName, utility.GetFullName(fullName, dumpRecommendedCommandName), streams)
+
+	var manifestCmd = &cobra.Command{
+		Use:   name,
+		Short: "Inspect the manifest",
+		Long:  "Inspect the pipelines.yaml manifest of the GitOps repository.",
+		Example: fmt.Sprintf("%s\n%s\n\n  See sub-commands individually for more examples",
+			fullName,
+			dumpRecommendedCommandName),
+		Run: func(cmd *cobra.Command, args []string) {
+		},
+	}
+
+	manifestCmd.AddCommand(dumpCmd)
+
+	manifestCmd.Annotations = map[string]string{"command": "main"}
+	return manifestCmd
+}
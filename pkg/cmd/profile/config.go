@@ -0,0 +1,149 @@
+package profile
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/mitchellh/go-homedir"
+	"github.com/spf13/afero"
+	"github.com/spf13/cobra"
+	"sigs.k8s.io/yaml"
+)
+
+// DefaultConfigPath is where profiles are stored.
+const DefaultConfigPath = "~/.gitops-cli/config.yaml"
+
+// Profile is a named set of values for the flags that are repeated when
+// working with a cluster and Git hosting service.
+type Profile struct {
+	GitHost         string `json:"git_host,omitempty"`
+	TokenSource     string `json:"token_source,omitempty"` // env:<VARIABLE> or file:<path> to read the token for the GitHost from.
+	Context         string `json:"context,omitempty"`
+	SealedSecretsNS string `json:"sealed_secrets_ns,omitempty"`
+}
+
+// Config is the file that profiles are stored in.
+type Config struct {
+	Profiles map[string]*Profile `json:"profiles,omitempty"`
+}
+
+// LoadConfig reads the profiles from the path, if the file doesn't exist, an
+// empty Config is returned.
+func LoadConfig(fs afero.Fs, path string) (*Config, error) {
+	path, err := homedir.Expand(path)
+	if err != nil {
+		return nil, err
+	}
+	cfg := &Config{}
+	b, err := afero.ReadFile(fs, path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return cfg, nil
+		}
+		return nil, fmt.Errorf("failed to read profiles from %s: %w", path, err)
+	}
+	if err := yaml.Unmarshal(b, cfg); err != nil {
+		return nil, fmt.Errorf("failed to parse profiles in %s: %w", path, err)
+	}
+	return cfg, nil
+}
+
+// SaveConfig writes the profiles to the path.
+func SaveConfig(fs afero.Fs, path string, cfg *Config) error {
+	path, err := homedir.Expand(path)
+	if err != nil {
+		return err
+	}
+	b, err := yaml.Marshal(cfg)
+	if err != nil {
+		return err
+	}
+	if err := fs.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return err
+	}
+	return afero.WriteFile(fs, path, b, 0600)
+}
+
+// Use applies the named profile from the config file to the command's flags,
+// if the name is empty, the flags are unchanged.
+func Use(fs afero.Fs, path, name string, cmd *cobra.Command) error {
+	if name == "" {
+		return nil
+	}
+	cfg, err := LoadConfig(fs, path)
+	if err != nil {
+		return err
+	}
+	p, ok := cfg.Profiles[name]
+	if !ok {
+		return fmt.Errorf("profile %q not found in %s", name, path)
+	}
+	return Apply(p, cmd)
+}
+
+// Apply sets the command's flags from the profile, flags that were provided on
+// the command-line keep their values, and values for flags that the command
+// doesn't have are ignored.
+//
+// The token for the GitHost is provided as a --credential for the host.
+func Apply(p *Profile, cmd *cobra.Command) error {
+	if err := setDefault(cmd, "context", p.Context); err != nil {
+		return err
+	}
+	if err := setDefault(cmd, "sealed-secrets-ns", p.SealedSecretsNS); err != nil {
+		return err
+	}
+	if p.GitHost == "" || p.TokenSource == "" || cmd.Flags().Lookup("credential") == nil {
+		return nil
+	}
+	token, err := p.Token()
+	if err != nil {
+		return err
+	}
+	return setDefault(cmd, "credential", p.GitHost+"="+token)
+}
+
+// Token reads the token from the TokenSource.
+func (p *Profile) Token() (string, error) {
+	var token string
+	switch {
+	case strings.HasPrefix(p.TokenSource, "env:"):
+		token = os.Getenv(strings.TrimPrefix(p.TokenSource, "env:"))
+	case strings.HasPrefix(p.TokenSource, "file:"):
+		path, err := homedir.Expand(strings.TrimPrefix(p.TokenSource, "file:"))
+		if err != nil {
+			return "", err
+		}
+		b, err := ioutil.ReadFile(path)
+		if err != nil {
+			return "", fmt.Errorf("failed to read the token for %s: %w", p.GitHost, err)
+		}
+		token = string(b)
+	default:
+		return "", fmt.Errorf("invalid token source %q, must be env:<VARIABLE> or file:<path>", p.TokenSource)
+	}
+	token = strings.TrimSpace(token)
+	if token == "" {
+		return "", fmt.Errorf("no token for %s found in %s", p.GitHost, p.TokenSource)
+	}
+	return token, nil
+}
+
+// setDefault sets the flag to the value, unless the value is empty, the flag
+// was changed on the command-line, or the command has no such flag.
+//
+// The flag's Value is set directly so that the flag is not marked as changed,
+// commands that check for provided flags are unaffected by the profile.
+func setDefault(cmd *cobra.Command, name, value string) error {
+	f := cmd.Flags().Lookup(name)
+	if value == "" || f == nil || f.Changed {
+		return nil
+	}
+	if err := f.Value.Set(value); err != nil {
+		return fmt.Errorf("invalid profile value for --%s: %w", name, err)
+	}
+	return nil
+}
@@ -0,0 +1,176 @@
+package profile
+
+import (
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/spf13/afero"
+	"github.com/spf13/cobra"
+)
+
+const testConfigPath = "/home/test/.gitops-cli/config.yaml"
+
+func TestApply(t *testing.T) {
+	defer stubEnv(t, "TEST_GITOPS_TOKEN", "test-token")()
+	p := &Profile{
+		GitHost:         "github.example.com",
+		TokenSource:     "env:TEST_GITOPS_TOKEN",
+		Context:         "work-cluster",
+		SealedSecretsNS: "sealed-secrets",
+	}
+	cmd, values := testCommand()
+
+	if err := Apply(p, cmd); err != nil {
+		t.Fatal(err)
+	}
+
+	want := testValues{
+		context:         "work-cluster",
+		sealedSecretsNS: "sealed-secrets",
+		credentials:     []string{"github.example.com=test-token"},
+	}
+	if diff := cmp.Diff(want, *values, cmp.AllowUnexported(testValues{})); diff != "" {
+		t.Fatalf("Apply() failed:\n%s", diff)
+	}
+	if cmd.Flags().NFlag() != 0 {
+		t.Fatalf("Apply() marked %d flags as changed, want 0", cmd.Flags().NFlag())
+	}
+}
+
+func TestApplyWithProvidedFlags(t *testing.T) {
+	defer stubEnv(t, "TEST_GITOPS_TOKEN", "test-token")()
+	p := &Profile{
+		GitHost:         "github.example.com",
+		TokenSource:     "env:TEST_GITOPS_TOKEN",
+		Context:         "work-cluster",
+		SealedSecretsNS: "sealed-secrets",
+	}
+	cmd, values := testCommand()
+	err := cmd.Flags().Parse([]string{"--context", "other-cluster", "--credential", "gitlab.com=other-token"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := Apply(p, cmd); err != nil {
+		t.Fatal(err)
+	}
+
+	want := testValues{
+		context:         "other-cluster",
+		sealedSecretsNS: "sealed-secrets",
+		credentials:     []string{"gitlab.com=other-token"},
+	}
+	if diff := cmp.Diff(want, *values, cmp.AllowUnexported(testValues{})); diff != "" {
+		t.Fatalf("Apply() failed:\n%s", diff)
+	}
+}
+
+func TestApplyWithMissingFlags(t *testing.T) {
+	p := &Profile{GitHost: "github.com", TokenSource: "env:TEST_GITOPS_UNSET_TOKEN", Context: "work-cluster"}
+
+	if err := Apply(p, &cobra.Command{}); err != nil {
+		t.Fatalf("Apply() got error %s, want flags the command doesn't have to be ignored", err)
+	}
+}
+
+func TestToken(t *testing.T) {
+	defer stubEnv(t, "TEST_GITOPS_TOKEN", "")()
+	tokenTests := []struct {
+		source  string
+		wantErr string
+	}{
+		{"env:TEST_GITOPS_TOKEN", "no token for github.com found in env:TEST_GITOPS_TOKEN"},
+		{"file:/no/such/token", "failed to read the token for github.com"},
+		{"TEST_GITOPS_TOKEN", `invalid token source "TEST_GITOPS_TOKEN"`},
+	}
+
+	for _, tt := range tokenTests {
+		p := &Profile{GitHost: "github.com", TokenSource: tt.source}
+		_, err := p.Token()
+		if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+			t.Errorf("Token() with %q got error %v, want %q", tt.source, err, tt.wantErr)
+		}
+	}
+}
+
+func TestUse(t *testing.T) {
+	fs := afero.NewMemMapFs()
+	err := SaveConfig(fs, testConfigPath, &Config{
+		Profiles: map[string]*Profile{
+			"work": {Context: "work-cluster"},
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	cmd, values := testCommand()
+
+	if err := Use(fs, testConfigPath, "work", cmd); err != nil {
+		t.Fatal(err)
+	}
+	if values.context != "work-cluster" {
+		t.Fatalf("Use() got context %q, want %q", values.context, "work-cluster")
+	}
+
+	err = Use(fs, testConfigPath, "home", cmd)
+	if err == nil || err.Error() != `profile "home" not found in `+testConfigPath {
+		t.Fatalf("Use() got error %v, want the profile not to be found", err)
+	}
+}
+
+func TestSetUpdatesProfile(t *testing.T) {
+	fs := afero.NewMemMapFs()
+	o := &setOptions{name: "work", profile: Profile{GitHost: "github.example.com", Context: "work-cluster"}}
+	if err := o.set(fs); err != nil {
+		t.Fatal(err)
+	}
+	o = &setOptions{name: "work", profile: Profile{Context: "new-cluster"}}
+	if err := o.set(fs); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg, err := LoadConfig(fs, DefaultConfigPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := &Config{
+		Profiles: map[string]*Profile{
+			"work": {GitHost: "github.example.com", Context: "new-cluster"},
+		},
+	}
+	if diff := cmp.Diff(want, cfg); diff != "" {
+		t.Fatalf("set failed:\n%s", diff)
+	}
+}
+
+type testValues struct {
+	context         string
+	sealedSecretsNS string
+	credentials     []string
+}
+
+func testCommand() (*cobra.Command, *testValues) {
+	values := &testValues{}
+	cmd := &cobra.Command{}
+	cmd.Flags().StringVar(&values.context, "context", "", "")
+	cmd.Flags().StringVar(&values.sealedSecretsNS, "sealed-secrets-ns", "", "")
+	cmd.Flags().StringArrayVar(&values.credentials, "credential", nil, "")
+	return cmd, values
+}
+
+func stubEnv(t *testing.T, name, value string) func() {
+	t.Helper()
+	orig, ok := os.LookupEnv(name)
+	if err := os.Setenv(name, value); err != nil {
+		t.Fatal(err)
+	}
+	return func() {
+		if ok {
+			os.Setenv(name, orig)
+			return
+		}
+		os.Unsetenv(name)
+	}
+}
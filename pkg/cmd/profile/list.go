@@ -0,0 +1,77 @@
+package profile
+
+import (
+	"fmt"
+	"sort"
+	"text/tabwriter"
+
+	"github.com/rhd-gitops-example/gitops-cli/pkg/cmd/genericclioptions"
+	"github.com/rhd-gitops-example/gitops-cli/pkg/pipelines/ioutils"
+	"github.com/spf13/afero"
+	"github.com/spf13/cobra"
+	ktemplates "k8s.io/kubectl/pkg/util/templates"
+)
+
+const listRecommendedCommandName = "list"
+
+var (
+	listExample = ktemplates.Examples(`
+	# List the profiles
+	%[1]s`)
+)
+
+type listOptions struct {
+	genericclioptions.IOStreams
+}
+
+// Complete completes listOptions after they've been created
+func (o *listOptions) Complete(name string, cmd *cobra.Command, args []string) error {
+	return nil
+}
+
+// Validate validates the listOptions based on completed values
+func (o *listOptions) Validate() error {
+	return nil
+}
+
+// Run contains the logic for the list command
+func (o *listOptions) Run() error {
+	return o.list(ioutils.NewFilesystem())
+}
+
+func (o *listOptions) list(fs afero.Fs) error {
+	cfg, err := LoadConfig(fs, DefaultConfigPath)
+	if err != nil {
+		return err
+	}
+	if len(cfg.Profiles) == 0 {
+		o.Warningf("No profiles found in %s", DefaultConfigPath)
+		return nil
+	}
+	names := make([]string, 0, len(cfg.Profiles))
+	for name := range cfg.Profiles {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	w := tabwriter.NewWriter(o.Out, 5, 2, 3, ' ', tabwriter.TabIndent)
+	fmt.Fprintln(w, "NAME\tGIT HOST\tTOKEN SOURCE\tCONTEXT\tSEALED SECRETS NS")
+	for _, name := range names {
+		p := cfg.Profiles[name]
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n", name, p.GitHost, p.TokenSource, p.Context, p.SealedSecretsNS)
+	}
+	return w.Flush()
+}
+
+func newCmdList(name, fullName string, streams genericclioptions.IOStreams) *cobra.Command {
+	o := &listOptions{IOStreams: streams}
+	return &cobra.Command{
+		Use:     name,
+		Short:   "List the profiles",
+		Example: fmt.Sprintf(listExample, fullName),
+		Args:    cobra.NoArgs,
+		Run: func(cmd *cobra.Command, args []string) {
+			genericclioptions.GenericRun(o, cmd, args)
+		},
+	}
+}
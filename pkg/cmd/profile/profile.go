@@ -0,0 +1,40 @@
+package profile
+
+import (
+	"fmt"
+
+	"github.com/rhd-gitops-example/gitops-cli/pkg/cmd/genericclioptions"
+	"github.com/rhd-gitops-example/gitops-cli/pkg/cmd/utility"
+	"github.com/spf13/cobra"
+)
+
+// RecommendedCommandName is the recommended profile command name.
+const RecommendedCommandName = "profile"
+
+// NewCmdProfile creates a new profile command
+func NewCmdProfile(name, fullName string, streams genericclioptions.IOStreams) *cobra.Command {
+	listCmd := newCmdList(listRecommendedCommandName, utility.GetFullName(fullName, listRecommendedCommandName), streams)
+	setCmd := newCmdSet(setRecommendedCommandName, utility.GetFullName(fullName, setRecommendedCommandName), streams)
+
+	var profileCmd = &cobra.Command{
+		Use:   name,
+		Short: "Manage the profiles used with --profile",
+		Long:  fmt.Sprintf("Manage named sets of values for the Git host, token, kubeconfig context and Sealed Secrets namespace flags, these are stored in %s.", DefaultConfigPath),
+		Example: fmt.Sprintf("%s\n%s\n%s\n\n  See sub-commands individually for more examples",
+			fullName,
+			listRecommendedCommandName,
+			setRecommendedCommandName),
+		Run: func(cmd *cobra.Command, args []string) {
+		},
+		// Profiles are not applied when managing them, as the set flags have
+		// the same names as the flags they provide values for.
+		PersistentPreRun: func(cmd *cobra.Command, args []string) {
+		},
+	}
+
+	profileCmd.AddCommand(listCmd)
+	profileCmd.AddCommand(setCmd)
+
+	profileCmd.Annotations = map[string]string{"command": "main"}
+	return profileCmd
+}
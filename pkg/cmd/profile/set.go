@@ -0,0 +1,106 @@
+package profile
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/rhd-gitops-example/gitops-cli/pkg/cmd/genericclioptions"
+	"github.com/rhd-gitops-example/gitops-cli/pkg/pipelines/ioutils"
+	"github.com/spf13/afero"
+	"github.com/spf13/cobra"
+	ktemplates "k8s.io/kubectl/pkg/util/templates"
+)
+
+const setRecommendedCommandName = "set"
+
+var (
+	setExample = ktemplates.Examples(`
+	# Create or update the work profile
+	%[1]s work --git-host github.example.com --token-source env:WORK_TOKEN --context work-cluster
+
+	# Use the profile
+	gitops bootstrap --profile work ...`)
+
+	setLongDesc = ktemplates.LongDesc(`Create or update a profile.
+
+	Only the values that are provided are changed in an existing profile. When
+	the profile is used, the token for the Git host is provided as a --credential
+	for the host, and values are only used for the flags that are not provided.`)
+)
+
+type setOptions struct {
+	genericclioptions.IOStreams
+	name    string
+	profile Profile
+}
+
+// Complete completes setOptions after they've been created
+func (o *setOptions) Complete(name string, cmd *cobra.Command, args []string) error {
+	o.name = args[0]
+	return nil
+}
+
+// Validate validates the setOptions based on completed values
+func (o *setOptions) Validate() error {
+	if ts := o.profile.TokenSource; ts != "" && !strings.HasPrefix(ts, "env:") && !strings.HasPrefix(ts, "file:") {
+		return fmt.Errorf("invalid token source %q, must be env:<VARIABLE> or file:<path>", ts)
+	}
+	return nil
+}
+
+// Run contains the logic for the set command
+func (o *setOptions) Run() error {
+	if err := o.set(ioutils.NewFilesystem()); err != nil {
+		return err
+	}
+	o.Successf("Profile %s saved to %s", o.name, DefaultConfigPath)
+	return nil
+}
+
+func (o *setOptions) set(fs afero.Fs) error {
+	cfg, err := LoadConfig(fs, DefaultConfigPath)
+	if err != nil {
+		return err
+	}
+	if cfg.Profiles == nil {
+		cfg.Profiles = map[string]*Profile{}
+	}
+	p, ok := cfg.Profiles[o.name]
+	if !ok {
+		p = &Profile{}
+		cfg.Profiles[o.name] = p
+	}
+	if o.profile.GitHost != "" {
+		p.GitHost = o.profile.GitHost
+	}
+	if o.profile.TokenSource != "" {
+		p.TokenSource = o.profile.TokenSource
+	}
+	if o.profile.Context != "" {
+		p.Context = o.profile.Context
+	}
+	if o.profile.SealedSecretsNS != "" {
+		p.SealedSecretsNS = o.profile.SealedSecretsNS
+	}
+	return SaveConfig(fs, DefaultConfigPath, cfg)
+}
+
+func newCmdSet(name, fullName string, streams genericclioptions.IOStreams) *cobra.Command {
+	o := &setOptions{IOStreams: streams}
+	command := &cobra.Command{
+		Use:     name + " NAME",
+		Short:   "Create or update a profile",
+		Long:    setLongDesc,
+		Example: fmt.Sprintf(setExample, fullName),
+		Args:    cobra.ExactArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			genericclioptions.GenericRun(o, cmd, args)
+		},
+	}
+
+	command.Flags().StringVar(&o.profile.GitHost, "git-host", "", "Git hosting service host that the token is used for e.g. github.com")
+	command.Flags().StringVar(&o.profile.TokenSource, "token-source", "", "Where to read the token for the Git host from, env:<VARIABLE> or file:<path>")
+	command.Flags().StringVar(&o.profile.Context, "context", "", "kubeconfig context to use for requests to the Kubernetes API")
+	command.Flags().StringVar(&o.profile.SealedSecretsNS, "sealed-secrets-ns", "", "Namespace in which the Sealed Secrets operator is installed")
+	return command
+}
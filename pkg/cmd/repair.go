@@ -0,0 +1,141 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/openshift/odo/pkg/log"
+	"github.com/rhd-gitops-example/gitops-cli/pkg/cmd/genericclioptions"
+	"github.com/rhd-gitops-example/gitops-cli/pkg/cmd/ui"
+	"github.com/rhd-gitops-example/gitops-cli/pkg/pipelines"
+	"github.com/rhd-gitops-example/gitops-cli/pkg/pipelines/ioutils"
+	"github.com/spf13/cobra"
+
+	ktemplates "k8s.io/kubectl/pkg/util/templates"
+)
+
+const (
+	// RepairRecommendedCommandName the recommended command name
+	RepairRecommendedCommandName = "repair"
+)
+
+var (
+	repairExample = ktemplates.Examples(`
+	# Report the differences between the manifests and the pipelines.yaml in the current directory
+	%[1]s
+
+	# Regenerate the missing and drifted files, and remove the orphaned files
+	%[1]s --fix
+	`)
+
+	repairLongDesc = ktemplates.LongDesc(`Reconcile the GitOps manifests with an existing pipelines.yaml.
+
+	Reports generated files that are missing or have been changed, and files
+	for environments, applications and services that are no longer in the
+	pipelines.yaml.
+
+	With --fix, the missing and changed files are regenerated, and after
+	confirmation, the orphaned files are removed.`)
+	repairShortDesc = `Reconcile manifests with pipelines.yaml`
+)
+
+// confirmRemoveOrphans is used to confirm the removal of orphaned files, it's
+// a var so that it can be replaced in tests.
+var confirmRemoveOrphans = ui.ConfirmSummary
+
+// RepairParameters encapsulates the parameters for the repair command.
+type RepairParameters struct {
+	pipelinesFolderPath string
+	output              string // path to the GitOps resources
+	useApplicationSet   bool
+	fix                 bool
+	yes                 bool // remove orphaned files without confirmation
+}
+
+// NewRepairParameters bootstraps a RepairParameters instance.
+func NewRepairParameters() *RepairParameters {
+	return &RepairParameters{}
+}
+
+// Complete completes RepairParameters after they've been created.
+func (io *RepairParameters) Complete(name string, cmd *cobra.Command, args []string) error {
+	return nil
+}
+
+// Validate validates the parameters of the RepairParameters.
+func (io *RepairParameters) Validate() error {
+	return nil
+}
+
+// Run runs the repair command.
+func (io *RepairParameters) Run() error {
+	options := pipelines.RepairParameters{
+		PipelinesFolderPath: io.pipelinesFolderPath,
+		OutputPath:          io.output,
+		UseApplicationSet:   io.useApplicationSet,
+	}
+	appFs := ioutils.NewFilesystem()
+	report, err := pipelines.Diagnose(&options, appFs)
+	if err != nil {
+		return err
+	}
+	if report.Clean() {
+		log.Success("The manifests match the pipelines.yaml.")
+		return nil
+	}
+	summary := repairSummary(report)
+	if !io.fix {
+		for _, s := range summary {
+			log.Info(s)
+		}
+		return fmt.Errorf("found %d differences from the pipelines.yaml, rerun with --fix to repair them", len(summary))
+	}
+
+	removeOrphans := len(report.Orphaned) > 0 && (io.yes || confirmRemoveOrphans(summary))
+	report, err = pipelines.Repair(&options, appFs, removeOrphans)
+	if err != nil {
+		return err
+	}
+	repaired := len(report.Missing) + len(report.Drifted)
+	if removeOrphans {
+		repaired += len(report.Orphaned)
+	} else if len(report.Orphaned) > 0 {
+		genericclioptions.Warningf("%d orphaned files were not removed.", len(report.Orphaned))
+	}
+	log.Successf("Repaired %d files.", repaired)
+	return nil
+}
+
+func repairSummary(report *pipelines.RepairReport) []string {
+	summary := []string{}
+	for _, f := range report.Missing {
+		summary = append(summary, fmt.Sprintf("Missing: %s", f))
+	}
+	for _, f := range report.Drifted {
+		summary = append(summary, fmt.Sprintf("Drifted: %s", f))
+	}
+	for _, f := range report.Orphaned {
+		summary = append(summary, fmt.Sprintf("Orphaned: %s", f))
+	}
+	return summary
+}
+
+// NewCmdRepair creates the repair command.
+func NewCmdRepair(name, fullName string) *cobra.Command {
+	o := NewRepairParameters()
+	repairCmd := &cobra.Command{
+		Use:     name,
+		Short:   repairShortDesc,
+		Long:    repairLongDesc,
+		Example: fmt.Sprintf(repairExample, fullName),
+		Run: func(cmd *cobra.Command, args []string) {
+			genericclioptions.GenericRun(o, cmd, args)
+		},
+	}
+
+	repairCmd.Flags().StringVar(&o.output, "output", ".", "Folder path to the GitOps resources")
+	repairCmd.Flags().BoolVar(&o.useApplicationSet, "use-applicationset", false, "Expect a single Argo CD ApplicationSet rather than an Application per environment and application")
+	repairCmd.Flags().StringVar(&o.pipelinesFolderPath, "pipelines-folder", ".", "Folder path to retrieve manifest, eg. /test where manifest exists at /test/pipelines.yaml")
+	repairCmd.Flags().BoolVar(&o.fix, "fix", false, "Regenerate missing and drifted files, and remove orphaned files")
+	repairCmd.Flags().BoolVarP(&o.yes, "yes", "y", false, "Remove orphaned files without asking for confirmation")
+	return repairCmd
+}
@@ -0,0 +1,101 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/openshift/odo/pkg/log"
+	"github.com/rhd-gitops-example/gitops-cli/pkg/cmd/genericclioptions"
+	"github.com/rhd-gitops-example/gitops-cli/pkg/pipelines/config"
+	"github.com/rhd-gitops-example/gitops-cli/pkg/pipelines/ioutils"
+	"github.com/spf13/afero"
+	"github.com/spf13/cobra"
+
+	ktemplates "k8s.io/kubectl/pkg/util/templates"
+)
+
+const (
+	// SchemaRecommendedCommandName the recommended command name
+	SchemaRecommendedCommandName = "schema"
+)
+
+var (
+	schemaExample = ktemplates.Examples(`
+	# Output the JSON Schema for pipelines.yaml
+	%[1]s
+
+	# Write the JSON Schema to a file for an editor's YAML language server
+	%[1]s --output pipelines.schema.json
+	`)
+
+	schemaLongDesc = ktemplates.LongDesc(`Output a JSON Schema for pipelines.yaml.
+
+	The schema is generated from the manifest that this version of the CLI
+	understands, it can be used by editors and CI to validate pipelines.yaml.`)
+	schemaShortDesc = `Output a JSON Schema for pipelines.yaml`
+)
+
+// SchemaParameters encapsulates the parameters for the schema command.
+type SchemaParameters struct {
+	output string // Path to write the schema to, by default it's written to stdout.
+}
+
+// NewSchemaParameters bootstraps a SchemaParameters instance.
+func NewSchemaParameters() *SchemaParameters {
+	return &SchemaParameters{}
+}
+
+// Complete completes SchemaParameters after they've been created.
+func (io *SchemaParameters) Complete(name string, cmd *cobra.Command, args []string) error {
+	return nil
+}
+
+// Validate validates the parameters of the SchemaParameters.
+func (io *SchemaParameters) Validate() error {
+	return nil
+}
+
+// Run runs the schema command.
+func (io *SchemaParameters) Run() error {
+	return io.write(ioutils.NewFilesystem(), os.Stdout)
+}
+
+func (io *SchemaParameters) write(fs afero.Fs, out io.Writer) error {
+	b, err := json.MarshalIndent(config.Schema(), "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal the schema: %w", err)
+	}
+	b = append(b, '\n')
+	if io.output == "" || io.output == "-" {
+		_, err = out.Write(b)
+		return err
+	}
+	if err := fs.MkdirAll(filepath.Dir(io.output), 0755); err != nil {
+		return fmt.Errorf("failed to create the directory for %s: %w", io.output, err)
+	}
+	if err := afero.WriteFile(fs, io.output, b, 0644); err != nil {
+		return fmt.Errorf("failed to write the schema to %s: %w", io.output, err)
+	}
+	log.Successf("Wrote the schema to %s", io.output)
+	return nil
+}
+
+// NewCmdSchema creates the schema command.
+func NewCmdSchema(name, fullName string) *cobra.Command {
+	o := NewSchemaParameters()
+	schemaCmd := &cobra.Command{
+		Use:     name,
+		Short:   schemaShortDesc,
+		Long:    schemaLongDesc,
+		Example: fmt.Sprintf(schemaExample, fullName),
+		Run: func(cmd *cobra.Command, args []string) {
+			genericclioptions.GenericRun(o, cmd, args)
+		},
+	}
+
+	schemaCmd.Flags().StringVar(&o.output, "output", "", "Path to write the schema to, by default it's written to stdout")
+	return schemaCmd
+}
@@ -0,0 +1,91 @@
+package secret
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/openshift/odo/pkg/log"
+	"github.com/rhd-gitops-example/gitops-cli/pkg/cmd/genericclioptions"
+	"github.com/rhd-gitops-example/gitops-cli/pkg/pipelines/ioutils"
+	"github.com/rhd-gitops-example/gitops-cli/pkg/pipelines/secrets"
+	"github.com/spf13/afero"
+	"github.com/spf13/cobra"
+	"k8s.io/apimachinery/pkg/types"
+	ktemplates "k8s.io/kubectl/pkg/util/templates"
+)
+
+const fetchCertRecommendedCommandName = "fetch-cert"
+
+var (
+	fetchCertExample = ktemplates.Examples(`
+	# Write the sealing certificate to a file
+	%[1]s --output sealed-secrets.pem
+
+	# Print the sealing certificate
+	%[1]s`)
+
+	fetchCertLongDesc = ktemplates.LongDesc(`Fetch the certificate that the Sealed Secrets
+	controller seals secrets with, the PEM encoded certificate can be used to
+	seal secrets without access to the cluster.`)
+)
+
+// var to allow replacement in tests.
+var fetchCert = secrets.GetClusterCert
+
+type fetchCertOptions struct {
+	sealedSecretsService types.NamespacedName
+	output               string
+}
+
+// Complete completes fetchCertOptions after they've been created
+func (o *fetchCertOptions) Complete(name string, cmd *cobra.Command, args []string) error {
+	return nil
+}
+
+// Validate validates the fetchCertOptions based on completed values
+func (o *fetchCertOptions) Validate() error {
+	return nil
+}
+
+// Run contains the logic for the fetch-cert command
+func (o *fetchCertOptions) Run() error {
+	return o.fetch(ioutils.NewFilesystem(), os.Stdout)
+}
+
+func (o *fetchCertOptions) fetch(fs afero.Fs, out io.Writer) error {
+	cert, err := fetchCert(o.sealedSecretsService)
+	if err != nil {
+		return fmt.Errorf("failed to fetch the certificate from %s: %w", o.sealedSecretsService, err)
+	}
+	if o.output == "" || o.output == "-" {
+		_, err = out.Write(cert)
+		return err
+	}
+	if err := fs.MkdirAll(filepath.Dir(o.output), 0755); err != nil {
+		return fmt.Errorf("failed to create the directory for %s: %w", o.output, err)
+	}
+	if err := afero.WriteFile(fs, o.output, cert, 0644); err != nil {
+		return fmt.Errorf("failed to write the certificate to %s: %w", o.output, err)
+	}
+	log.Successf("Wrote the certificate from %s to %s", o.sealedSecretsService, o.output)
+	return nil
+}
+
+func newCmdFetchCert(name, fullName string) *cobra.Command {
+	o := &fetchCertOptions{}
+	command := &cobra.Command{
+		Use:     name,
+		Short:   "Fetch the Sealed Secrets certificate",
+		Long:    fetchCertLongDesc,
+		Example: fmt.Sprintf(fetchCertExample, fullName),
+		Run: func(cmd *cobra.Command, args []string) {
+			genericclioptions.GenericRun(o, cmd, args)
+		},
+	}
+	command.Flags().StringVar(&o.sealedSecretsService.Namespace, "sealed-secrets-ns", "cicd", "Namespace in which the Sealed Secrets operator is installed")
+	command.Flags().StringVar(&o.sealedSecretsService.Name, "sealed-secrets-svc", "sealedsecretcontroller-sealed-secrets", "Name of the Sealed Secrets Services that encrypts secrets")
+	command.Flags().StringVar(&o.output, "output", "", "Path to write the PEM encoded certificate to, by default it's written to stdout")
+	return command
+}
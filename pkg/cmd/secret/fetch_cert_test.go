@@ -0,0 +1,103 @@
+package secret
+
+import (
+	"bytes"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"errors"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/rhd-gitops-example/gitops-cli/pkg/pipelines/helper"
+	"github.com/rhd-gitops-example/gitops-cli/pkg/pipelines/ioutils"
+	"github.com/spf13/afero"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+var testService = types.NamespacedName{Namespace: "cicd", Name: "sealed-secrets"}
+
+func TestFetchCertWritesPEM(t *testing.T) {
+	cert := makeCert(t)
+	defer stubFetchCert(t, cert, nil)()
+	fs := ioutils.NewMemoryFilesystem()
+	o := &fetchCertOptions{sealedSecretsService: testService, output: "/tmp/certs/sealed-secrets.pem"}
+
+	if err := o.fetch(fs, &bytes.Buffer{}); err != nil {
+		t.Fatal(err)
+	}
+
+	b, err := afero.ReadFile(fs, "/tmp/certs/sealed-secrets.pem")
+	if err != nil {
+		t.Fatal(err)
+	}
+	block, rest := pem.Decode(b)
+	if block == nil || block.Type != "CERTIFICATE" {
+		t.Fatalf("failed to decode a PEM certificate from %s", b)
+	}
+	if len(bytes.TrimSpace(rest)) != 0 {
+		t.Fatalf("unexpected data after the certificate: %s", rest)
+	}
+	if _, err := x509.ParseCertificate(block.Bytes); err != nil {
+		t.Fatalf("failed to parse the certificate: %s", err)
+	}
+}
+
+func TestFetchCertToStdout(t *testing.T) {
+	cert := makeCert(t)
+	defer stubFetchCert(t, cert, nil)()
+	var out bytes.Buffer
+	o := &fetchCertOptions{sealedSecretsService: testService}
+
+	if err := o.fetch(ioutils.NewMemoryFilesystem(), &out); err != nil {
+		t.Fatal(err)
+	}
+
+	if diff := cmp.Diff(string(cert), out.String()); diff != "" {
+		t.Fatalf("certificate output didn't match:\n%s", diff)
+	}
+}
+
+func TestFetchCertWithError(t *testing.T) {
+	defer stubFetchCert(t, nil, errors.New("cannot fetch certificate: not found"))()
+	o := &fetchCertOptions{sealedSecretsService: testService, output: "/tmp/sealed-secrets.pem"}
+
+	err := o.fetch(ioutils.NewMemoryFilesystem(), &bytes.Buffer{})
+	helper.AssertErrorMatch(t, "failed to fetch the certificate from cicd/sealed-secrets: cannot fetch certificate", err)
+}
+
+func stubFetchCert(t *testing.T, cert []byte, err error) func() {
+	orig := fetchCert
+	fetchCert = func(service types.NamespacedName) ([]byte, error) {
+		if service != testService {
+			t.Fatalf("got service %s, want %s", service, testService)
+		}
+		return cert, err
+	}
+	return func() {
+		fetchCert = orig
+	}
+}
+
+func makeCert(t *testing.T) []byte {
+	t.Helper()
+	key, err := rsa.GenerateKey(rand.Reader, 1024)
+	if err != nil {
+		t.Fatal(err)
+	}
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "sealed-secret"},
+		NotBefore:    time.Now(),
+		NotAfter:     time.Now().Add(time.Hour),
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})
+}
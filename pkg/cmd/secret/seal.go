@@ -0,0 +1,175 @@
+package secret
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/openshift/odo/pkg/log"
+	"github.com/rhd-gitops-example/gitops-cli/pkg/cmd/genericclioptions"
+	"github.com/rhd-gitops-example/gitops-cli/pkg/cmd/ui"
+	"github.com/rhd-gitops-example/gitops-cli/pkg/cmd/utility"
+	"github.com/rhd-gitops-example/gitops-cli/pkg/pipelines/ioutils"
+	"github.com/rhd-gitops-example/gitops-cli/pkg/pipelines/secrets"
+	"github.com/spf13/afero"
+	"github.com/spf13/cobra"
+	"k8s.io/apimachinery/pkg/types"
+	ktemplates "k8s.io/kubectl/pkg/util/templates"
+	"sigs.k8s.io/yaml"
+)
+
+const sealRecommendedCommandName = "seal"
+
+var (
+	sealExample = ktemplates.Examples(`
+	# Output a SealedSecret for the value read from stdin
+	echo -n my-token | %[1]s --name github-auth --namespace cicd --key token --value -
+
+	# Output only the sealed value, to add it to the encryptedData of an existing SealedSecret
+	echo -n my-token | %[1]s --name github-auth --namespace cicd --value - --seal-raw
+
+	# Write a SealedSecret for each of the namespaces to <output-dir>/<namespace>/github-auth.yaml
+	echo -n my-token | %[1]s --name github-auth --secret-namespaces dev,stage --key token --value - --output-dir secrets`)
+
+	sealLongDesc = ktemplates.LongDesc(`Seal a value with the certificate of the Sealed
+	Secrets controller, and output a SealedSecret, or with --seal-raw, only the
+	base64 encoded sealed value.`)
+)
+
+type sealOptions struct {
+	sealedSecretsService types.NamespacedName
+	name                 types.NamespacedName
+	key                  string
+	value                string
+	scope                string
+	raw                  bool     // If true, only the sealed value is output, rather than a SealedSecret.
+	namespaces           []string // Namespaces to seal a SealedSecret for each of, rather than the name's namespace.
+	outputDir            string   // If set, the SealedSecrets are written to a directory for each namespace, rather than output.
+}
+
+// Complete completes sealOptions after they've been created
+func (o *sealOptions) Complete(name string, cmd *cobra.Command, args []string) error {
+	return utility.ReadStdinSecrets(map[string]*string{"value": &o.value}, nil)
+}
+
+// Validate validates the sealOptions based on completed values
+func (o *sealOptions) Validate() error {
+	if (o.name.Namespace == "") == (len(o.namespaces) == 0) {
+		return fmt.Errorf("one of --namespace or --secret-namespaces must be provided")
+	}
+	for _, ns := range o.namespaces {
+		if err := ui.ValidateName(ns); err != nil {
+			return fmt.Errorf("invalid --secret-namespaces: %w", err)
+		}
+	}
+	if o.raw && (len(o.namespaces) > 0 || o.outputDir != "") {
+		return fmt.Errorf("--seal-raw can't be used with --secret-namespaces or --output-dir")
+	}
+	if !secrets.IsSealingScope(o.scope) {
+		return fmt.Errorf("invalid scope: %q, must be one of %s", o.scope, strings.Join(secrets.SealingScopes, ", "))
+	}
+	if o.raw && o.key != "" {
+		return fmt.Errorf("--key can't be used with --seal-raw, only the sealed value is output")
+	}
+	if !o.raw && o.key == "" {
+		return fmt.Errorf("--key must be provided to output a SealedSecret")
+	}
+	return nil
+}
+
+// Run contains the logic for the seal command
+func (o *sealOptions) Run() error {
+	if len(o.namespaces) > 0 || o.outputDir != "" {
+		return o.sealNamespaces(ioutils.NewFilesystem(), os.Stdout)
+	}
+	return o.seal(os.Stdout)
+}
+
+func (o *sealOptions) seal(out io.Writer) error {
+	if o.raw {
+		sealed, err := secrets.SealRaw(o.name, o.sealedSecretsService, []byte(o.value), o.scope)
+		if err != nil {
+			return fmt.Errorf("failed to seal the value: %w", err)
+		}
+		_, err = fmt.Fprintln(out, sealed)
+		return err
+	}
+	sealed, err := secrets.CreateScopedSealedSecret(o.name, o.sealedSecretsService, o.value, o.key, o.scope)
+	if err != nil {
+		return fmt.Errorf("failed to seal the secret: %w", err)
+	}
+	b, err := yaml.Marshal(sealed)
+	if err != nil {
+		return fmt.Errorf("failed to marshal the SealedSecret: %w", err)
+	}
+	_, err = out.Write(b)
+	return err
+}
+
+// sealNamespaces seals the value for each of the namespaces with the same
+// public key, the SealedSecrets are written to the outputDir, in a directory
+// for each namespace, or output as a multi-document YAML stream.
+func (o *sealOptions) sealNamespaces(fs afero.Fs, out io.Writer) error {
+	namespaces := o.namespaces
+	if len(namespaces) == 0 {
+		namespaces = []string{o.name.Namespace}
+	}
+	sealed, err := secrets.CreateScopedSealedSecrets(o.name.Name, namespaces, o.sealedSecretsService, o.value, o.key, o.scope)
+	if err != nil {
+		return fmt.Errorf("failed to seal the secret: %w", err)
+	}
+	for i, s := range sealed {
+		b, err := yaml.Marshal(s)
+		if err != nil {
+			return fmt.Errorf("failed to marshal the SealedSecret: %w", err)
+		}
+		if o.outputDir != "" {
+			filename := filepath.Join(o.outputDir, s.Namespace, s.Name+".yaml")
+			if err := fs.MkdirAll(filepath.Dir(filename), 0755); err != nil {
+				return fmt.Errorf("failed to create the directory for %s: %w", filename, err)
+			}
+			if err := afero.WriteFile(fs, filename, b, 0644); err != nil {
+				return fmt.Errorf("failed to write the SealedSecret to %s: %w", filename, err)
+			}
+			log.Successf("Wrote the SealedSecret for namespace %s to %s", s.Namespace, filename)
+			continue
+		}
+		if i > 0 {
+			if _, err := fmt.Fprintln(out, "---"); err != nil {
+				return err
+			}
+		}
+		if _, err := out.Write(b); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func newCmdSeal(name, fullName string) *cobra.Command {
+	o := &sealOptions{}
+	command := &cobra.Command{
+		Use:     name,
+		Short:   "Seal a secret value",
+		Long:    sealLongDesc,
+		Example: fmt.Sprintf(sealExample, fullName),
+		Run: func(cmd *cobra.Command, args []string) {
+			genericclioptions.GenericRun(o, cmd, args)
+		},
+	}
+	command.Flags().StringVar(&o.sealedSecretsService.Namespace, "sealed-secrets-ns", "cicd", "Namespace in which the Sealed Secrets operator is installed")
+	command.Flags().StringVar(&o.sealedSecretsService.Name, "sealed-secrets-svc", "sealedsecretcontroller-sealed-secrets", "Name of the Sealed Secrets Services that encrypts secrets")
+	command.Flags().StringVar(&o.name.Name, "name", "", "Name of the Secret that the value is sealed for")
+	command.Flags().StringVar(&o.name.Namespace, "namespace", "", "Namespace of the Secret that the value is sealed for")
+	command.Flags().StringSliceVar(&o.namespaces, "secret-namespaces", nil, "Namespaces to seal a SealedSecret for each of e.g. dev,stage, rather than the --namespace")
+	command.Flags().StringVar(&o.outputDir, "output-dir", "", "Directory to write the SealedSecrets to, in a directory for each namespace, rather than outputting them")
+	command.Flags().StringVar(&o.key, "key", "", "Key of the value in the generated SealedSecret")
+	command.Flags().StringVar(&o.value, "value", "", "Value to seal, or - to read it from stdin")
+	command.Flags().StringVar(&o.scope, "scope", secrets.StrictScope, fmt.Sprintf("Scope of the Secrets that the value can be unsealed for, one of %s", strings.Join(secrets.SealingScopes, ", ")))
+	command.Flags().BoolVar(&o.raw, "seal-raw", false, "Output only the base64 encoded sealed value, like kubeseal --raw, to add it to an existing SealedSecret")
+	_ = command.MarkFlagRequired("name")
+	_ = command.MarkFlagRequired("value")
+	return command
+}
@@ -0,0 +1,119 @@
+package secret
+
+import (
+	"bytes"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	ssv1alpha1 "github.com/bitnami-labs/sealed-secrets/pkg/apis/sealed-secrets/v1alpha1"
+	"github.com/rhd-gitops-example/gitops-cli/pkg/pipelines/helper"
+	"github.com/rhd-gitops-example/gitops-cli/pkg/pipelines/secrets"
+	"github.com/spf13/afero"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/yaml"
+)
+
+var testName = types.NamespacedName{Namespace: "cicd", Name: "github-auth"}
+
+func TestSealRawOutputsOnlyTheSealedValue(t *testing.T) {
+	defer stubPublicKey(t)()
+	name := types.NamespacedName{Namespace: "cicd", Name: "github-auth"}
+
+	var full bytes.Buffer
+	o := &sealOptions{sealedSecretsService: testService, name: name, key: "token", value: "my-token", scope: secrets.StrictScope}
+	if err := o.seal(&full); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(full.String(), "kind: SealedSecret") {
+		t.Fatalf("got %s, want a SealedSecret", full.String())
+	}
+
+	var raw bytes.Buffer
+	o = &sealOptions{sealedSecretsService: testService, name: name, value: "my-token", scope: secrets.StrictScope, raw: true}
+	if err := o.seal(&raw); err != nil {
+		t.Fatal(err)
+	}
+	sealed := strings.TrimSpace(raw.String())
+	if _, err := base64.StdEncoding.DecodeString(sealed); err != nil {
+		t.Fatalf("raw output %q is not a base64 encoded value: %v", sealed, err)
+	}
+	if sealed == strings.TrimSpace(full.String()) || strings.Contains(sealed, "SealedSecret") {
+		t.Fatalf("raw output is the full SealedSecret: %s", sealed)
+	}
+}
+
+func TestSealValidate(t *testing.T) {
+	validateTests := []struct {
+		options *sealOptions
+		wantErr string
+	}{
+		{&sealOptions{name: testName, key: "token", scope: secrets.StrictScope}, ""},
+		{&sealOptions{name: testName, scope: secrets.ClusterWideScope, raw: true}, ""},
+		{&sealOptions{name: testName, key: "token", scope: "global"}, `invalid scope: "global"`},
+		{&sealOptions{name: testName, key: "token", scope: secrets.StrictScope, raw: true}, "--key can't be used with --seal-raw"},
+		{&sealOptions{name: testName, scope: secrets.StrictScope}, "--key must be provided"},
+		{&sealOptions{name: types.NamespacedName{Name: "github-auth"}, namespaces: []string{"dev", "stage"}, key: "token", scope: secrets.StrictScope}, ""},
+		{&sealOptions{name: types.NamespacedName{Name: "github-auth"}, key: "token", scope: secrets.StrictScope}, "one of --namespace or --secret-namespaces must be provided"},
+		{&sealOptions{name: testName, namespaces: []string{"dev"}, key: "token", scope: secrets.StrictScope}, "one of --namespace or --secret-namespaces must be provided"},
+		{&sealOptions{name: types.NamespacedName{Name: "github-auth"}, namespaces: []string{"dev", "Stage_1"}, key: "token", scope: secrets.StrictScope}, "invalid --secret-namespaces: Stage_1 is not a valid name"},
+		{&sealOptions{name: types.NamespacedName{Name: "github-auth"}, namespaces: []string{"dev"}, scope: secrets.StrictScope, raw: true}, "--seal-raw can't be used with --secret-namespaces"},
+	}
+
+	for _, tt := range validateTests {
+		err := tt.options.Validate()
+		if !helper.ErrorMatch(t, tt.wantErr, err) {
+			t.Errorf("Validate() got error %v, want %q", err, tt.wantErr)
+		}
+	}
+}
+
+func TestSealNamespacesWritesASealedSecretForEachNamespace(t *testing.T) {
+	defer stubPublicKey(t)()
+	fs := afero.NewMemMapFs()
+	o := &sealOptions{
+		sealedSecretsService: testService,
+		name:                 types.NamespacedName{Name: "github-auth"},
+		namespaces:           []string{"dev", "stage"},
+		key:                  "token",
+		value:                "my-token",
+		scope:                secrets.StrictScope,
+		outputDir:            "/tmp/secrets",
+	}
+	if err := o.sealNamespaces(fs, ioutil.Discard); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, ns := range []string{"dev", "stage"} {
+		b, err := afero.ReadFile(fs, filepath.Join("/tmp/secrets", ns, "github-auth.yaml"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		sealed := &ssv1alpha1.SealedSecret{}
+		if err := yaml.Unmarshal(b, sealed); err != nil {
+			t.Fatal(err)
+		}
+		if sealed.Namespace != ns || sealed.Name != "github-auth" {
+			t.Fatalf("got SealedSecret %s/%s, want %s/github-auth", sealed.Namespace, sealed.Name, ns)
+		}
+	}
+}
+
+func stubPublicKey(t *testing.T) func() {
+	t.Helper()
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	f := secrets.DefaultPublicKeyFunc
+	secrets.DefaultPublicKeyFunc = func(service types.NamespacedName) (*rsa.PublicKey, error) {
+		return &key.PublicKey, nil
+	}
+	return func() {
+		secrets.DefaultPublicKeyFunc = f
+	}
+}
@@ -0,0 +1,35 @@
+package secret
+
+import (
+	"fmt"
+
+	"github.com/rhd-gitops-example/gitops-cli/pkg/cmd/utility"
+	"github.com/spf13/cobra"
+)
+
+// RecommendedCommandName is the recommended secret command name.
+const RecommendedCommandName = "secret"
+
+// NewCmdSecret creates a new secret command
+func NewCmdSecret(name, fullName string) *cobra.Command {
+	fetchCertCmd := newCmdFetchCert(fetchCertRecommendedCommandName, utility.GetFullName(fullName, fetchCertRecommendedCommandName))
+	sealCmd := newCmdSeal(sealRecommendedCommandName, utility.GetFullName(fullName, sealRecommendedCommandName))
+
+	var secretCmd = &cobra.Command{
+		Use:   name,
+		Short: "Manage the sealing of secrets",
+		Long:  "Work with the Sealed Secrets controller that seals the secrets in the GitOps repository.",
+		Example: fmt.Sprintf("%s\n%s\n%s\n\n  See sub-commands individually for more examples",
+			fullName,
+			fetchCertRecommendedCommandName,
+			sealRecommendedCommandName),
+		Run: func(cmd *cobra.Command, args []string) {
+		},
+	}
+
+	secretCmd.AddCommand(fetchCertCmd)
+	secretCmd.AddCommand(sealCmd)
+
+	secretCmd.Annotations = map[string]string{"command": "main"}
+	return secretCmd
+}
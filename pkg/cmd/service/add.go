@@ -2,12 +2,17 @@ package service
 
 import (
 	"fmt"
+	"strings"
 
-	"github.com/openshift/odo/pkg/log"
 	"github.com/rhd-gitops-example/gitops-cli/pkg/cmd/genericclioptions"
+	"github.com/rhd-gitops-example/gitops-cli/pkg/cmd/ui"
 	"github.com/rhd-gitops-example/gitops-cli/pkg/cmd/utility"
 	"github.com/rhd-gitops-example/gitops-cli/pkg/pipelines"
+	"github.com/rhd-gitops-example/gitops-cli/pkg/pipelines/config"
+	"github.com/rhd-gitops-example/gitops-cli/pkg/pipelines/git"
 	"github.com/rhd-gitops-example/gitops-cli/pkg/pipelines/ioutils"
+	"github.com/rhd-gitops-example/gitops-cli/pkg/pipelines/network"
+	"github.com/spf13/afero"
 	"github.com/spf13/cobra"
 
 	ktemplates "k8s.io/kubectl/pkg/util/templates"
@@ -25,35 +30,128 @@ var (
 	addShortDesc = `Add a new service`
 )
 
+// defaultBranch is a var so that it can be replaced in tests.
+var defaultBranch = func(repoURL, token string) (string, error) {
+	repo, err := git.NewRepository(repoURL, token)
+	if err != nil {
+		return "", err
+	}
+	return repo.DefaultBranch()
+}
+
 // AddServiceOptions encapsulates the parameters for service add command
 type AddServiceOptions struct {
 	*pipelines.AddServiceOptions
+	genericclioptions.IOStreams
+	printWebhookSecret bool   // print the webhook secret, if it was generated
+	createNamespace    bool   // create the CI/CD namespace, if it doesn't exist
+	accessToken        string // token to find the default branch of a private GitRepoURL
+	validateHook       string // executable that's run with the names of the service, to reject them
 }
 
 // Complete is called when the command is completed
 func (o *AddServiceOptions) Complete(name string, cmd *cobra.Command, args []string) error {
 	o.GitRepoURL = utility.AddGitSuffixIfNecessary(o.GitRepoURL)
-	return nil
+	return utility.ReadStdinSecrets(map[string]*string{"webhook-secret": &o.WebhookSecret}, nil)
 }
 
 // Validate validates the parameters of the EnvParameters.
 func (o *AddServiceOptions) Validate() error {
-	return nil
+	if err := ui.ValidateManifestFile(o.ManifestFile, "pipelines folder"); err != nil {
+		return err
+	}
+	if !config.IsPipelineType(o.PipelineType) {
+		return fmt.Errorf("invalid pipeline type: %q, must be one of %s", o.PipelineType, strings.Join(config.PipelineTypes, ", "))
+	}
+	if o.WebhookSecretLength < ui.MinSecretLength {
+		return fmt.Errorf("invalid webhook secret length %d, generated secrets must be at least %d characters", o.WebhookSecretLength, ui.MinSecretLength)
+	}
+	if errs := ui.ValidateFlags(ui.SecretFlag("webhook-secret", o.WebhookSecret)); len(errs) > 0 {
+		return errs[0]
+	}
+	if o.TriggerBranch != "" {
+		if o.GitRepoURL == "" {
+			return fmt.Errorf("--trigger-branch can only be used with --git-repo-url")
+		}
+		if !config.IsBranchName(o.TriggerBranch) {
+			return fmt.Errorf("invalid --trigger-branch %q", o.TriggerBranch)
+		}
+	}
+	if o.ContextDir != "" {
+		if o.ImageRepo == "" || o.GitRepoURL == "" {
+			return fmt.Errorf("--context-dir can only be used with --git-repo-url and --image-repo")
+		}
+		if !config.IsContextDir(o.ContextDir) {
+			return fmt.Errorf("invalid --context-dir %q, must be a relative path within the Git repository", o.ContextDir)
+		}
+	}
+	return ui.RunValidateHook(o.validateHook, "service add", map[string]string{
+		"app-name":     o.AppName,
+		"service-name": o.ServiceName,
+		"env-name":     o.EnvName,
+		"git-repo-url": o.GitRepoURL,
+		"image-repo":   o.ImageRepo,
+	})
 }
 
 // Run runs the project bootstrap command.
 func (o *AddServiceOptions) Run() error {
-	err := pipelines.AddService(o.AddServiceOptions, ioutils.NewFilesystem())
+	generated := o.WebhookSecret == ""
+	fs := ioutils.NewFilesystem()
+	if err := o.checkSecretNamespace(fs); err != nil {
+		return err
+	}
+	o.completeTriggerBranch()
+	err := pipelines.AddService(o.AddServiceOptions, fs)
 
 	if err != nil {
 		return err
 	}
-	log.Successf("Created Service %s sucessfully at environment %s.", o.ServiceName, o.EnvName)
+	o.Successf("Created Service %s sucessfully at environment %s.", o.ServiceName, o.EnvName)
+	// The secret is only generated for services with a source repository in
+	// a manifest with a CI/CD configuration.
+	if generated && o.WebhookSecret != "" && o.printWebhookSecret {
+		fmt.Fprintf(o.Out, "Webhook secret for %s: %s\n", o.ServiceName, o.WebhookSecret)
+	}
 	return nil
 }
 
-func newCmdAdd(name, fullName string) *cobra.Command {
-	o := &AddServiceOptions{AddServiceOptions: &pipelines.AddServiceOptions{}}
+// completeTriggerBranch defaults the TriggerBranch of a built service to the
+// default branch of its repository, if it can't be found, the pipeline is
+// started for pushes to any branch.
+func (o *AddServiceOptions) completeTriggerBranch() {
+	if o.TriggerBranch != "" || o.GitRepoURL == "" || o.PipelineType == config.DeployPipeline || network.Disabled {
+		return
+	}
+	branch, err := defaultBranch(o.GitRepoURL, o.accessToken)
+	if err != nil {
+		o.Warningf("Unable to find the default branch of %s, pushes to any branch will start the pipeline, use --trigger-branch to set it: %v", o.GitRepoURL, err)
+		return
+	}
+	o.TriggerBranch = branch
+}
+
+// checkSecretNamespace checks that the CI/CD namespace that the webhook secret
+// is sealed for exists, the secret is only sealed if the manifest has a CI/CD
+// configuration.
+func (o *AddServiceOptions) checkSecretNamespace(fs afero.Fs) error {
+	m, err := config.LoadManifestFile(fs, o.PipelinesFolderPath, o.ManifestFile)
+	if err != nil {
+		return err
+	}
+	cfg := m.GetPipelinesConfig()
+	if cfg == nil || network.Disabled {
+		return nil
+	}
+	client, err := utility.NewClient()
+	if err != nil {
+		return err
+	}
+	return client.CheckSecretNamespace(cfg.Name, o.createNamespace, o.Warningf)
+}
+
+func newCmdAdd(name, fullName string, streams genericclioptions.IOStreams) *cobra.Command {
+	o := &AddServiceOptions{AddServiceOptions: &pipelines.AddServiceOptions{}, IOStreams: streams}
 
 	cmd := &cobra.Command{
 		Use:     name,
@@ -66,13 +164,22 @@ func newCmdAdd(name, fullName string) *cobra.Command {
 	}
 
 	cmd.Flags().StringVar(&o.GitRepoURL, "git-repo-url", "", "GitOps repository e.g. https://github.com/organisation/repository")
-	cmd.Flags().StringVar(&o.WebhookSecret, "webhook-secret", "", "Source Git repository webhook secret (if not provided, it will be auto-generated)")
+	cmd.Flags().StringVar(&o.WebhookSecret, "webhook-secret", "", "Source Git repository webhook secret, or - to read it from stdin (if not provided, it will be auto-generated)")
+	cmd.Flags().IntVar(&o.WebhookSecretLength, "webhook-secret-length", pipelines.DefaultWebhookSecretLength, "Length of the auto-generated webhook secret")
+	cmd.Flags().BoolVar(&o.printWebhookSecret, "print-webhook-secret", false, "Print the auto-generated webhook secret, it isn't shown again, and is only stored sealed")
+	cmd.Flags().BoolVar(&o.createNamespace, "create-namespace", false, "Create the CI/CD namespace if it doesn't exist, the webhook secret is sealed for it and can't be unsealed until it's created")
 	cmd.Flags().StringVar(&o.AppName, "app-name", "", "Name of the application where the service will be added")
 	cmd.Flags().StringVar(&o.ServiceName, "service-name", "", "Name of the service to be added")
 	cmd.Flags().StringVar(&o.EnvName, "env-name", "", "Name of the environment where the service will be added")
 	cmd.Flags().StringVar(&o.ImageRepo, "image-repo", "", "Image repository of the form <registry>/<username>/<repository> or <project>/<app> which is used to push newly built images")
 	cmd.Flags().StringVar(&o.InternalRegistryHostname, "image-repo-internal-registry-hostname", "image-registry.openshift-image-registry.svc:5000", "Host-name for internal image registry e.g. docker-registry.default.svc.cluster.local:5000, used if you are pushing your images to the internal image registry")
+	cmd.Flags().StringVar(&o.ContextDir, "context-dir", "", "Directory within the Git repository that the service is built from, for repositories with several services e.g. services/frontend")
+	cmd.Flags().StringVar(&o.TriggerBranch, "trigger-branch", "", "Branch of the Git repository that pushes to start the service's pipeline for, by default it's the repository's default branch")
+	cmd.Flags().StringVar(&o.accessToken, "access-token", "", "Token used to find the default branch of a private Git repository")
+	cmd.Flags().StringVar(&o.validateHook, "validate-hook", "", "Executable to run with the names of the service as JSON on stdin, a non-zero exit status rejects them, with its stderr as the error")
+	cmd.Flags().StringVar(&o.PipelineType, "pipeline-type", config.BuildDeployPipeline, "Pipeline for the service, build only builds the service, deploy only deploys it, and build-deploy does both")
 	cmd.Flags().StringVar(&o.PipelinesFolderPath, "pipelines-folder", ".", "Folder path to retrieve manifest, eg. /test where manifest exists at /test/pipelines.yaml")
+	cmd.Flags().StringVar(&o.ManifestFile, "manifest-file", "pipelines.yaml", "Path of the manifest file within the --pipelines-folder e.g. gitops/pipelines.yaml")
 
 	cmd.Flags().StringVar(&o.SealedSecretsService.Namespace, "sealed-secrets-ns", "kube-system", "Namespace in which the Sealed Secrets operator is installed, automatically generated secrets are encrypted with this operator")
 	cmd.Flags().StringVar(&o.SealedSecretsService.Name, "sealed-secrets-svc", "sealed-secrets-controller", "Name of the Sealed Secrets services that encrypts secrets")
@@ -2,10 +2,21 @@ package service
 
 import (
 	"bytes"
+	"crypto/rand"
+	"crypto/rsa"
+	"io"
+	"strings"
 	"testing"
 
+	"github.com/rhd-gitops-example/gitops-cli/pkg/cmd/genericclioptions"
+	"github.com/rhd-gitops-example/gitops-cli/pkg/cmd/utility"
 	"github.com/rhd-gitops-example/gitops-cli/pkg/pipelines"
+	"github.com/rhd-gitops-example/gitops-cli/pkg/pipelines/eventlisteners"
+	"github.com/rhd-gitops-example/gitops-cli/pkg/pipelines/meta"
+	"github.com/rhd-gitops-example/gitops-cli/pkg/pipelines/secrets"
 	"github.com/spf13/cobra"
+	"k8s.io/apimachinery/pkg/runtime/serializer"
+	"k8s.io/apimachinery/pkg/types"
 )
 
 type keyValuePair struct {
@@ -60,7 +71,7 @@ func TestAddCommandWithMissingParams(t *testing.T) {
 	}
 	for _, tt := range cmdTests {
 		t.Run(tt.desc, func(t *testing.T) {
-			_, _, err := executeCommand(newCmdAdd("add", "odo pipelines service"), tt.flags...)
+			_, _, err := executeCommand(newCmdAdd("add", "odo pipelines service", genericclioptions.NewIOStreams()), tt.flags...)
 			if err.Error() != tt.wantErr {
 				t.Errorf("got %s, want %s", err, tt.wantErr)
 			}
@@ -68,6 +79,87 @@ func TestAddCommandWithMissingParams(t *testing.T) {
 	}
 }
 
+func TestValidateAddOptionsWebhookSecretLength(t *testing.T) {
+	lengthTests := []struct {
+		length  int
+		wantErr string
+	}{
+		{16, ""},
+		{20, ""},
+		{15, "invalid webhook secret length 15, generated secrets must be at least 16 characters"},
+	}
+
+	for _, tt := range lengthTests {
+		o := AddServiceOptions{AddServiceOptions: &pipelines.AddServiceOptions{
+			PipelineType:        "build-deploy",
+			WebhookSecretLength: tt.length,
+		}}
+		err := o.Validate()
+		if tt.wantErr == "" && err != nil {
+			t.Errorf("Validate() with length %d failed: %s", tt.length, err)
+		}
+		if tt.wantErr != "" && (err == nil || err.Error() != tt.wantErr) {
+			t.Errorf("Validate() with length %d got error %v, want %s", tt.length, err, tt.wantErr)
+		}
+	}
+}
+
+func TestCompleteAddOptionsWithStdinWebhookSecret(t *testing.T) {
+	defer func(r io.Reader) {
+		utility.Stdin = r
+	}(utility.Stdin)
+	utility.Stdin = strings.NewReader("a-secret-from-a-password-manager\n")
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func(f secrets.PublicKeyFunc) {
+		secrets.DefaultPublicKeyFunc = f
+	}(secrets.DefaultPublicKeyFunc)
+	secrets.DefaultPublicKeyFunc = func(service types.NamespacedName) (*rsa.PublicKey, error) {
+		return &key.PublicKey, nil
+	}
+
+	o := AddServiceOptions{AddServiceOptions: &pipelines.AddServiceOptions{
+		PipelineType:        "build-deploy",
+		WebhookSecret:       "-",
+		WebhookSecretLength: pipelines.DefaultWebhookSecretLength,
+	}}
+	if err := o.Complete("test", &cobra.Command{}, nil); err != nil {
+		t.Fatal(err)
+	}
+	if err := o.Validate(); err != nil {
+		t.Fatal(err)
+	}
+
+	sealed, err := secrets.CreateSealedSecret(meta.NamespacedName("cicd", "webhook-secret"),
+		meta.NamespacedName("cicd", "sealed-secrets-controller"), o.WebhookSecret, eventlisteners.WebhookSecretKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	unsealed, err := sealed.Unseal(serializer.CodecFactory{}, map[string]*rsa.PrivateKey{"test": key})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := string(unsealed.Data[eventlisteners.WebhookSecretKey]); got != "a-secret-from-a-password-manager" {
+		t.Fatalf("sealed secret got %q, want %q", got, "a-secret-from-a-password-manager")
+	}
+}
+
+func TestValidateAddOptionsWithShortWebhookSecret(t *testing.T) {
+	o := AddServiceOptions{AddServiceOptions: &pipelines.AddServiceOptions{
+		PipelineType:        "build-deploy",
+		WebhookSecret:       "too-short",
+		WebhookSecretLength: pipelines.DefaultWebhookSecretLength,
+	}}
+
+	err := o.Validate()
+	want := "invalid value for --webhook-secret: The secret length should 16 or more "
+	if err == nil || err.Error() != want {
+		t.Fatalf("Validate() got error %v, want %s", err, want)
+	}
+}
+
 func executeCommand(cmd *cobra.Command, flags ...keyValuePair) (c *cobra.Command, output string, err error) {
 	buf := new(bytes.Buffer)
 	cmd.SetOutput(buf)
@@ -86,3 +178,37 @@ func flag(k, v string) keyValuePair {
 		value: v,
 	}
 }
+
+func TestCompleteTriggerBranch(t *testing.T) {
+	defer func(f func(string, string) (string, error)) {
+		defaultBranch = f
+	}(defaultBranch)
+	defaultBranch = func(repoURL, token string) (string, error) {
+		return "develop", nil
+	}
+
+	branchTests := []struct {
+		name          string
+		triggerBranch string
+		pipelineType  string
+		want          string
+	}{
+		{"defaults to the default branch", "", "build-deploy", "develop"},
+		{"trigger branch is provided", "release", "build-deploy", "release"},
+		{"deploy only service", "", "deploy", ""},
+	}
+
+	for _, tt := range branchTests {
+		t.Run(tt.name, func(rt *testing.T) {
+			o := AddServiceOptions{AddServiceOptions: &pipelines.AddServiceOptions{
+				GitRepoURL:    "https://github.com/test/org.git",
+				TriggerBranch: tt.triggerBranch,
+				PipelineType:  tt.pipelineType,
+			}}
+			o.completeTriggerBranch()
+			if o.TriggerBranch != tt.want {
+				rt.Fatalf("got trigger branch %q, want %q", o.TriggerBranch, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,92 @@
+package service
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/rhd-gitops-example/gitops-cli/pkg/cmd/genericclioptions"
+	"github.com/rhd-gitops-example/gitops-cli/pkg/pipelines"
+	"github.com/rhd-gitops-example/gitops-cli/pkg/pipelines/config"
+	"github.com/rhd-gitops-example/gitops-cli/pkg/pipelines/ioutils"
+	"github.com/spf13/cobra"
+
+	ktemplates "k8s.io/kubectl/pkg/util/templates"
+)
+
+const (
+	importRecommendedCommandName = "import"
+)
+
+var (
+	importExample = ktemplates.Examples(`
+	# Import the Components in the catalog-info.yaml files under a directory
+	%[1]s --from-catalog ./catalog --app-name payments --env-name dev
+
+	# Import the Components from the catalog-info.yaml files in a Git repository
+	%[1]s --from-catalog https://github.com/org/catalog.git --app-name payments --env-name dev
+	`)
+
+	importLongDesc = ktemplates.LongDesc(`Import services from a Backstage catalog.
+
+	Each Component entity in the catalog-info.yaml files is added as a service,
+	with the Git repository from its backstage.io/source-location annotation.
+	Other kinds of entity are skipped.`)
+	importShortDesc = `Import services from a Backstage catalog`
+)
+
+// ImportServiceOptions encapsulates the parameters for service import command
+type ImportServiceOptions struct {
+	*pipelines.ImportServiceOptions
+	genericclioptions.IOStreams
+}
+
+// Complete is called when the command is completed
+func (o *ImportServiceOptions) Complete(name string, cmd *cobra.Command, args []string) error {
+	return nil
+}
+
+// Validate validates the parameters of the ImportServiceOptions.
+func (o *ImportServiceOptions) Validate() error {
+	if !config.IsPipelineType(o.PipelineType) {
+		return fmt.Errorf("invalid pipeline type: %q, must be one of %s", o.PipelineType, strings.Join(config.PipelineTypes, ", "))
+	}
+	return nil
+}
+
+// Run runs the service import command.
+func (o *ImportServiceOptions) Run() error {
+	names, err := pipelines.ImportServices(o.ImportServiceOptions, ioutils.NewFilesystem())
+	for _, name := range names {
+		o.Successf("Imported Service %s sucessfully at environment %s.", name, o.EnvName)
+	}
+	return err
+}
+
+func newCmdImport(name, fullName string, streams genericclioptions.IOStreams) *cobra.Command {
+	o := &ImportServiceOptions{ImportServiceOptions: &pipelines.ImportServiceOptions{}, IOStreams: streams}
+
+	cmd := &cobra.Command{
+		Use:     name,
+		Short:   importShortDesc,
+		Long:    importLongDesc,
+		Example: fmt.Sprintf(importExample, fullName),
+		Run: func(cmd *cobra.Command, args []string) {
+			genericclioptions.GenericRun(o, cmd, args)
+		},
+	}
+
+	cmd.Flags().StringVar(&o.FromCatalog, "from-catalog", "", "A Backstage catalog-info.yaml file, a directory containing them, or a Git repository URL")
+	cmd.Flags().StringVar(&o.AppName, "app-name", "", "Name of the application where the services will be added")
+	cmd.Flags().StringVar(&o.EnvName, "env-name", "", "Name of the environment where the services will be added")
+	cmd.Flags().StringVar(&o.PipelineType, "pipeline-type", config.BuildDeployPipeline, "Pipeline for the services, build only builds the services, deploy only deploys them, and build-deploy does both")
+	cmd.Flags().StringVar(&o.PipelinesFolderPath, "pipelines-folder", ".", "Folder path to retrieve manifest, eg. /test where manifest exists at /test/pipelines.yaml")
+
+	cmd.Flags().StringVar(&o.SealedSecretsService.Namespace, "sealed-secrets-ns", "kube-system", "Namespace in which the Sealed Secrets operator is installed, automatically generated secrets are encrypted with this operator")
+	cmd.Flags().StringVar(&o.SealedSecretsService.Name, "sealed-secrets-svc", "sealed-secrets-controller", "Name of the Sealed Secrets services that encrypts secrets")
+
+	// required flags
+	_ = cmd.MarkFlagRequired("from-catalog")
+	_ = cmd.MarkFlagRequired("app-name")
+	_ = cmd.MarkFlagRequired("env-name")
+	return cmd
+}
@@ -3,6 +3,7 @@ package service
 import (
 	"fmt"
 
+	"github.com/rhd-gitops-example/gitops-cli/pkg/cmd/genericclioptions"
 	"github.com/rhd-gitops-example/gitops-cli/pkg/cmd/utility"
 	"github.com/spf13/cobra"
 )
@@ -11,22 +12,24 @@ import (
 const RecommendedCommandName = "service"
 
 // NewCmd creates a new environment command
-func NewCmd(name, fullName string) *cobra.Command {
+func NewCmd(name, fullName string, streams genericclioptions.IOStreams) *cobra.Command {
 
-	addCmd := newCmdAdd(addRecommendedCommandName, utility.GetFullName(fullName, addRecommendedCommandName))
+	addCmd := newCmdAdd(addRecommendedCommandName, utility.GetFullName(fullName, addRecommendedCommandName), streams)
+	importCmd := newCmdImport(importRecommendedCommandName, utility.GetFullName(fullName, importRecommendedCommandName), streams)
 
 	var cmd = &cobra.Command{
 		Use:   name,
 		Short: "Manage services in an environment",
 		Long:  "Manage services in a GitOps environment where service source repositories are synchronized",
-		Example: fmt.Sprintf("%s\n%s\n\n  See sub-commands individually for more examples",
-			fullName, addRecommendedCommandName),
+		Example: fmt.Sprintf("%s\n%s\n%s\n\n  See sub-commands individually for more examples",
+			fullName, addRecommendedCommandName, importRecommendedCommandName),
 		Run: func(cmd *cobra.Command, args []string) {
 		},
 	}
 
 	cmd.Flags().AddFlagSet(addCmd.Flags())
 	cmd.AddCommand(addCmd)
+	cmd.AddCommand(importCmd)
 
 	cmd.Annotations = map[string]string{"command": "main"}
 	// cmd.SetUsageTemplate(odoutil.CmdUsageTemplate)
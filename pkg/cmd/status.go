@@ -0,0 +1,86 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/rhd-gitops-example/gitops-cli/pkg/cmd/genericclioptions"
+	"github.com/rhd-gitops-example/gitops-cli/pkg/pipelines"
+	"github.com/rhd-gitops-example/gitops-cli/pkg/pipelines/ioutils"
+	"github.com/rhd-gitops-example/gitops-cli/pkg/pipelines/namespaces"
+	"github.com/spf13/cobra"
+
+	ktemplates "k8s.io/kubectl/pkg/util/templates"
+)
+
+const (
+	// StatusRecommendedCommandName the recommended command name
+	StatusRecommendedCommandName = "status"
+)
+
+var (
+	statusExample = ktemplates.Examples(`
+	# Show which of the environment namespaces exist in the current cluster
+	%[1]s
+
+	# Check the namespaces in the cluster of another kubeconfig context
+	%[1]s --context staging
+	`)
+
+	statusLongDesc = ktemplates.LongDesc(`Show the status of the namespaces of the environments in
+	pipelines.yaml, and its CI/CD namespace, in the cluster.
+
+	Namespaces that are missing belong to environments that have not been
+	synced, environments that are deployed to another cluster are skipped.`)
+	statusShortDesc = `Show the status of the environment namespaces in the cluster`
+)
+
+// StatusParameters encapsulates the parameters for the status command.
+type StatusParameters struct {
+	genericclioptions.IOStreams
+	pipelinesFolderPath string
+}
+
+// NewStatusParameters bootstraps a StatusParameters instance.
+func NewStatusParameters(streams genericclioptions.IOStreams) *StatusParameters {
+	return &StatusParameters{IOStreams: streams}
+}
+
+// Complete completes StatusParameters after they've been created.
+func (io *StatusParameters) Complete(name string, cmd *cobra.Command, args []string) error {
+	return nil
+}
+
+// Validate validates the parameters of the StatusParameters.
+func (io *StatusParameters) Validate() error {
+	return nil
+}
+
+// Run runs the status command.
+func (io *StatusParameters) Run() error {
+	client, err := namespaces.GetClientSet()
+	if err != nil {
+		return err
+	}
+	options := pipelines.StatusParameters{
+		PipelinesFolderPath: io.pipelinesFolderPath,
+	}
+	return pipelines.Status(&options, ioutils.NewFilesystem(), client, io.Out)
+}
+
+// NewCmdStatus creates the status command, which writes its output to the
+// streams.
+func NewCmdStatus(name, fullName string, streams genericclioptions.IOStreams) *cobra.Command {
+	o := NewStatusParameters(streams)
+	statusCmd := &cobra.Command{
+		Use:     name,
+		Short:   statusShortDesc,
+		Long:    statusLongDesc,
+		Example: fmt.Sprintf(statusExample, fullName),
+		Run: func(cmd *cobra.Command, args []string) {
+			genericclioptions.GenericRun(o, cmd, args)
+		},
+	}
+
+	statusCmd.Flags().StringVar(&o.pipelinesFolderPath, "pipelines-folder", ".", "Folder path to retrieve manifest, eg. /test where manifest exists at /test/pipelines.yaml")
+	return statusCmd
+}
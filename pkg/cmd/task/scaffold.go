@@ -0,0 +1,90 @@
+package task
+
+import (
+	"fmt"
+
+	"github.com/openshift/odo/pkg/log"
+	"github.com/rhd-gitops-example/gitops-cli/pkg/cmd/genericclioptions"
+	"github.com/rhd-gitops-example/gitops-cli/pkg/cmd/ui"
+	"github.com/rhd-gitops-example/gitops-cli/pkg/pipelines"
+	"github.com/rhd-gitops-example/gitops-cli/pkg/pipelines/ioutils"
+	"github.com/spf13/cobra"
+	ktemplates "k8s.io/kubectl/pkg/util/templates"
+)
+
+const scaffoldRecommendedCommandName = "scaffold"
+
+var (
+	scaffoldExample = ktemplates.Examples(`
+	# Scaffold a Task with two steps that share a workspace
+	%[1]s --name build-rust --steps 2 --workspaces 1
+
+	# Scaffold a Task and run it at the end of the app-ci-pipeline
+	%[1]s --name build-rust --pipeline app-ci-pipeline`)
+
+	scaffoldLongDesc = ktemplates.LongDesc(`Scaffold a Tekton Task with placeholder steps
+	and workspaces in the CI/CD configuration, the Task is added to the
+	kustomization, and can optionally be run at the end of an existing
+	Pipeline.  The steps only echo a message until they're implemented.
+
+	When the Task is run by a Pipeline, its workspaces are bound to an emptyDir
+	in the TriggerTemplates that run the Pipeline.`)
+)
+
+type scaffoldOptions struct {
+	*pipelines.ScaffoldTaskOptions
+}
+
+// Complete completes scaffoldOptions after they've been created
+func (o *scaffoldOptions) Complete(name string, cmd *cobra.Command, args []string) error {
+	return nil
+}
+
+// Validate validates the scaffoldOptions based on completed values
+func (o *scaffoldOptions) Validate() error {
+	if err := ui.ValidateName(o.TaskName); err != nil {
+		return fmt.Errorf("invalid --name: %w", err)
+	}
+	if o.Steps < 1 {
+		return fmt.Errorf("invalid --steps %d, the Task must have at least one step", o.Steps)
+	}
+	if o.Workspaces < 0 {
+		return fmt.Errorf("invalid --workspaces %d, must not be negative", o.Workspaces)
+	}
+	if o.Pipeline != "" {
+		if err := ui.ValidateName(o.Pipeline); err != nil {
+			return fmt.Errorf("invalid --pipeline: %w", err)
+		}
+	}
+	return nil
+}
+
+// Run contains the logic for the scaffold command
+func (o *scaffoldOptions) Run() error {
+	path, err := pipelines.ScaffoldTask(o.ScaffoldTaskOptions, ioutils.NewFilesystem())
+	if err != nil {
+		return err
+	}
+	log.Successf("Scaffolded Task %s at %s", o.TaskName, path)
+	return nil
+}
+
+func newCmdScaffold(name, fullName string) *cobra.Command {
+	o := &scaffoldOptions{ScaffoldTaskOptions: &pipelines.ScaffoldTaskOptions{}}
+	command := &cobra.Command{
+		Use:     name,
+		Short:   "Scaffold a Tekton Task",
+		Long:    scaffoldLongDesc,
+		Example: fmt.Sprintf(scaffoldExample, fullName),
+		Run: func(cmd *cobra.Command, args []string) {
+			genericclioptions.GenericRun(o, cmd, args)
+		},
+	}
+	command.Flags().StringVar(&o.TaskName, "name", "", "Name of the Task")
+	command.Flags().IntVar(&o.Steps, "steps", 1, "Number of placeholder steps in the Task")
+	command.Flags().IntVar(&o.Workspaces, "workspaces", 0, "Number of placeholder workspaces in the Task, the steps run in the first workspace")
+	command.Flags().StringVar(&o.Pipeline, "pipeline", "", "Name of a Pipeline in the CI/CD configuration to run the Task at the end of e.g. app-ci-pipeline")
+	command.Flags().StringVar(&o.PipelinesFolderPath, "pipelines-folder", ".", "Folder path to retrieve manifest, eg. /test where manifest exists at /test/pipelines.yaml")
+	_ = command.MarkFlagRequired("name")
+	return command
+}
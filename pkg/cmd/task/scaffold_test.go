@@ -0,0 +1,30 @@
+package task
+
+import (
+	"testing"
+
+	"github.com/rhd-gitops-example/gitops-cli/pkg/pipelines"
+	"github.com/rhd-gitops-example/gitops-cli/pkg/pipelines/helper"
+)
+
+func TestValidateScaffoldOptions(t *testing.T) {
+	validateTests := []struct {
+		name    string
+		options pipelines.ScaffoldTaskOptions
+		wantErr string
+	}{
+		{"valid options", pipelines.ScaffoldTaskOptions{TaskName: "build-rust", Steps: 2, Workspaces: 1, Pipeline: "app-ci-pipeline"}, ""},
+		{"invalid name", pipelines.ScaffoldTaskOptions{TaskName: "Build_Rust", Steps: 1}, "invalid --name: Build_Rust is not a valid name"},
+		{"no steps", pipelines.ScaffoldTaskOptions{TaskName: "build-rust"}, "invalid --steps 0, the Task must have at least one step"},
+		{"negative workspaces", pipelines.ScaffoldTaskOptions{TaskName: "build-rust", Steps: 1, Workspaces: -1}, "invalid --workspaces -1, must not be negative"},
+		{"invalid pipeline", pipelines.ScaffoldTaskOptions{TaskName: "build-rust", Steps: 1, Pipeline: "App CI"}, "invalid --pipeline: App CI is not a valid name"},
+	}
+
+	for _, tt := range validateTests {
+		t.Run(tt.name, func(rt *testing.T) {
+			options := tt.options
+			o := &scaffoldOptions{ScaffoldTaskOptions: &options}
+			helper.AssertErrorMatch(rt, tt.wantErr, o.Validate())
+		})
+	}
+}
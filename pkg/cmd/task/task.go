@@ -0,0 +1,32 @@
+package task
+
+import (
+	"fmt"
+
+	"github.com/rhd-gitops-example/gitops-cli/pkg/cmd/utility"
+	"github.com/spf13/cobra"
+)
+
+// RecommendedCommandName is the recommended task command name.
+const RecommendedCommandName = "task"
+
+// NewCmdTask creates a new task command
+func NewCmdTask(name, fullName string) *cobra.Command {
+	scaffoldCmd := newCmdScaffold(scaffoldRecommendedCommandName, utility.GetFullName(fullName, scaffoldRecommendedCommandName))
+
+	var taskCmd = &cobra.Command{
+		Use:   name,
+		Short: "Manage Tekton Tasks",
+		Long:  "Manage the Tekton Tasks in the CI/CD configuration of the GitOps repository.",
+		Example: fmt.Sprintf("%s\n%s\n\n  See sub-commands individually for more examples",
+			fullName,
+			scaffoldRecommendedCommandName),
+		Run: func(cmd *cobra.Command, args []string) {
+		},
+	}
+
+	taskCmd.AddCommand(scaffoldCmd)
+
+	taskCmd.Annotations = map[string]string{"command": "main"}
+	return taskCmd
+}
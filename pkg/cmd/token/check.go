@@ -0,0 +1,151 @@
+package token
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"sync"
+	"text/tabwriter"
+
+	"github.com/rhd-gitops-example/gitops-cli/pkg/cmd/genericclioptions"
+	"github.com/rhd-gitops-example/gitops-cli/pkg/cmd/ui"
+	"github.com/rhd-gitops-example/gitops-cli/pkg/cmd/utility"
+	"github.com/rhd-gitops-example/gitops-cli/pkg/pipelines/config"
+	"github.com/rhd-gitops-example/gitops-cli/pkg/pipelines/git"
+	"github.com/rhd-gitops-example/gitops-cli/pkg/pipelines/ioutils"
+	"github.com/rhd-gitops-example/gitops-cli/pkg/pipelines/scm"
+	"github.com/spf13/afero"
+	"github.com/spf13/cobra"
+	ktemplates "k8s.io/kubectl/pkg/util/templates"
+)
+
+const checkRecommendedCommandName = "check"
+
+var (
+	checkExample = ktemplates.Examples(`
+	# Check that the access token can read every service repository in the manifest
+	%[1]s --access-token <token>
+
+	# Check the tokens for repositories on different Git hosts
+	%[1]s --access-token <token> --credential gitlab.example.com=<token>`)
+
+	checkLongDesc = ktemplates.LongDesc(`Check that the access tokens can read every service
+	repository in the manifest, and output the result for each repository.  The
+	command fails if any of the repositories can't be accessed.`)
+)
+
+// checkAccess is a var so that it can be replaced in tests.
+var checkAccess = ui.CheckRepositoryAccess
+
+type checkOptions struct {
+	accessToken         string
+	credentialValues    []string
+	credentials         git.Credentials
+	pipelinesFolderPath string
+}
+
+// accessResult is the result of checking the access to a repository.
+type accessResult struct {
+	repoURL string
+	err     error
+}
+
+// Complete completes checkOptions after they've been created
+func (o *checkOptions) Complete(name string, cmd *cobra.Command, args []string) (err error) {
+	o.credentials, err = git.ParseCredentials(o.credentialValues)
+	if err != nil {
+		return err
+	}
+	return utility.ReadStdinSecrets(map[string]*string{"access-token": &o.accessToken}, o.credentials)
+}
+
+// Validate validates the checkOptions based on completed values
+func (o *checkOptions) Validate() error {
+	if o.accessToken == "" && len(o.credentials) == 0 {
+		return fmt.Errorf("one of --access-token or --credential must be provided")
+	}
+	return nil
+}
+
+// Run contains the logic for the check command
+func (o *checkOptions) Run() error {
+	return o.check(ioutils.NewFilesystem(), os.Stdout)
+}
+
+func (o *checkOptions) check(fs afero.Fs, out io.Writer) error {
+	m, err := config.LoadManifest(fs, o.pipelinesFolderPath)
+	if err != nil {
+		return err
+	}
+	repos := serviceRepos(m)
+	results := make([]accessResult, len(repos))
+	var wg sync.WaitGroup
+	for i, repoURL := range repos {
+		wg.Add(1)
+		go func(i int, repoURL string) {
+			defer wg.Done()
+			results[i] = accessResult{repoURL: repoURL, err: checkAccess(o.credentials.Token(repoURL, o.accessToken), repoURL)}
+		}(i, repoURL)
+	}
+	wg.Wait()
+
+	failed := 0
+	w := tabwriter.NewWriter(out, 5, 2, 3, ' ', tabwriter.TabIndent)
+	fmt.Fprintln(w, "REPOSITORY\tRESULT\tREASON")
+	for _, r := range results {
+		if r.err != nil {
+			failed++
+			fmt.Fprintf(w, "%s\tFAIL\t%s\n", r.repoURL, r.err)
+			continue
+		}
+		fmt.Fprintf(w, "%s\tPASS\t\n", r.repoURL)
+	}
+	if err := w.Flush(); err != nil {
+		return err
+	}
+	if failed > 0 {
+		return fmt.Errorf("%d of %d repositories can't be accessed", failed, len(results))
+	}
+	return nil
+}
+
+// serviceRepos returns the source repositories of the services in the
+// manifest, a repository that's used in several environments is only returned
+// once.
+func serviceRepos(m *config.Manifest) []string {
+	seen := map[string]bool{}
+	repos := []string{}
+	for _, env := range m.Environments {
+		for _, app := range env.Apps {
+			for _, svc := range app.Services {
+				if svc.SourceURL == "" {
+					continue
+				}
+				key := scm.NormalizeRepoURL(svc.SourceURL)
+				if seen[key] {
+					continue
+				}
+				seen[key] = true
+				repos = append(repos, svc.SourceURL)
+			}
+		}
+	}
+	return repos
+}
+
+func newCmdCheck(name, fullName string) *cobra.Command {
+	o := &checkOptions{}
+	command := &cobra.Command{
+		Use:     name,
+		Short:   "Check access to the service repositories",
+		Long:    checkLongDesc,
+		Example: fmt.Sprintf(checkExample, fullName),
+		Run: func(cmd *cobra.Command, args []string) {
+			genericclioptions.GenericRun(o, cmd, args)
+		},
+	}
+	command.Flags().StringVar(&o.pipelinesFolderPath, "pipelines-folder", ".", "Folder path to retrieve manifest, eg. /test where manifest exists at /test/pipelines.yaml")
+	command.Flags().StringVar(&o.accessToken, "access-token", "", "Access token to check the repositories with, or - to read it from stdin")
+	command.Flags().StringArrayVar(&o.credentialValues, "credential", nil, "Access token for a specific Git host in the form host=token, used instead of the access-token for repositories on that host, can be repeated, a token of - is read from stdin")
+	return command
+}
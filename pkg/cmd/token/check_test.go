@@ -0,0 +1,110 @@
+package token
+
+import (
+	"bytes"
+	"errors"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/rhd-gitops-example/gitops-cli/pkg/pipelines/git"
+	"github.com/rhd-gitops-example/gitops-cli/pkg/pipelines/ioutils"
+	"github.com/spf13/afero"
+)
+
+const testManifest = `environments:
+  - name: dev
+    apps:
+      - name: taxi
+        services:
+          - name: gateway
+            source_url: https://github.com/example/gateway.git
+          - name: missing
+            source_url: https://github.com/example/missing.git
+          - name: builder
+            source_url: https://gitlab.com/example/builder.git
+  - name: stage
+    apps:
+      - name: taxi
+        services:
+          - name: gateway
+            source_url: https://github.com/example/gateway.git
+`
+
+func TestCheckWithValidAndInvalidRepos(t *testing.T) {
+	var mu sync.Mutex
+	checked := map[string]string{}
+	defer stubCheckAccess(func(token, repoURL string) error {
+		mu.Lock()
+		defer mu.Unlock()
+		checked[repoURL] = token
+		if strings.Contains(repoURL, "missing") {
+			return errors.New("repository not found, or the token can't access the private repository")
+		}
+		return nil
+	})()
+	fs := ioutils.NewMemoryFilesystem()
+	if err := afero.WriteFile(fs, filepath.Join("/gitops", "pipelines.yaml"), []byte(testManifest), 0644); err != nil {
+		t.Fatal(err)
+	}
+	o := &checkOptions{
+		accessToken:         "github-token",
+		credentials:         git.Credentials{"gitlab.com": "gitlab-token"},
+		pipelinesFolderPath: "/gitops",
+	}
+	var out bytes.Buffer
+
+	err := o.check(fs, &out)
+
+	if err == nil || err.Error() != "1 of 3 repositories can't be accessed" {
+		t.Fatalf("got error %v, want 1 of 3 repositories can't be accessed", err)
+	}
+	want := map[string]string{
+		"https://github.com/example/gateway.git": "github-token",
+		"https://github.com/example/missing.git": "github-token",
+		"https://gitlab.com/example/builder.git": "gitlab-token",
+	}
+	if diff := cmp.Diff(want, checked); diff != "" {
+		t.Fatalf("repositories checked with the wrong tokens:\n%s", diff)
+	}
+	wantOut := `REPOSITORY                               RESULT   REASON
+https://github.com/example/gateway.git   PASS     
+https://github.com/example/missing.git   FAIL     repository not found, or the token can't access the private repository
+https://gitlab.com/example/builder.git   PASS     
+`
+	if diff := cmp.Diff(wantOut, out.String()); diff != "" {
+		t.Fatalf("check output failed:\n%s", diff)
+	}
+}
+
+func TestCheckWithAllReposValid(t *testing.T) {
+	defer stubCheckAccess(func(token, repoURL string) error {
+		return nil
+	})()
+	fs := ioutils.NewMemoryFilesystem()
+	if err := afero.WriteFile(fs, filepath.Join("/gitops", "pipelines.yaml"), []byte(testManifest), 0644); err != nil {
+		t.Fatal(err)
+	}
+	o := &checkOptions{accessToken: "token", pipelinesFolderPath: "/gitops"}
+
+	if err := o.check(fs, &bytes.Buffer{}); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestValidateCheckWithoutTokens(t *testing.T) {
+	err := (&checkOptions{}).Validate()
+	if err == nil || err.Error() != "one of --access-token or --credential must be provided" {
+		t.Fatalf("got error %v", err)
+	}
+}
+
+func stubCheckAccess(f func(string, string) error) func() {
+	orig := checkAccess
+	checkAccess = f
+	return func() {
+		checkAccess = orig
+	}
+}
@@ -0,0 +1,32 @@
+package token
+
+import (
+	"fmt"
+
+	"github.com/rhd-gitops-example/gitops-cli/pkg/cmd/utility"
+	"github.com/spf13/cobra"
+)
+
+// RecommendedCommandName is the recommended token command name.
+const RecommendedCommandName = "token"
+
+// NewCmdToken creates a new token command
+func NewCmdToken(name, fullName string) *cobra.Command {
+	checkCmd := newCmdCheck(checkRecommendedCommandName, utility.GetFullName(fullName, checkRecommendedCommandName))
+
+	var tokenCmd = &cobra.Command{
+		Use:   name,
+		Short: "Manage Git access tokens",
+		Long:  "Check the access tokens that are used to access the Git repositories in the manifest.",
+		Example: fmt.Sprintf("%s\n%s\n\n  See sub-commands individually for more examples",
+			fullName,
+			checkRecommendedCommandName),
+		Run: func(cmd *cobra.Command, args []string) {
+		},
+	}
+
+	tokenCmd.AddCommand(checkCmd)
+
+	tokenCmd.Annotations = map[string]string{"command": "main"}
+	return tokenCmd
+}
@@ -0,0 +1,56 @@
+package ui
+
+import (
+	"os"
+
+	"github.com/rhd-gitops-example/gitops-cli/pkg/cmd/genericclioptions"
+	"gopkg.in/AlecAivazis/survey.v1"
+)
+
+// Environment variables that provide the defaults for the prompts, this allows
+// semi-automated runs where the user only needs to confirm the values.
+const (
+	GitOpsRepoEnvVar           = "GITOPS_REPO"
+	InternalRegistryEnvVar     = "GITOPS_INTERNAL_REGISTRY"
+	ImageRepoEnvVar            = "GITOPS_IMAGE_REPO"
+	DockercfgEnvVar            = "GITOPS_DOCKERCFGJSON"
+	OutputPathEnvVar           = "GITOPS_OUTPUT"
+	GitOpsWebhookSecretEnvVar  = "GITOPS_WEBHOOK_SECRET"
+	SealedSecretsSvcEnvVar     = "GITOPS_SEALED_SECRETS_SVC"
+	SealedSecretsNSEnvVar      = "GITOPS_SEALED_SECRETS_NS"
+	AccessTokenEnvVar          = "GITOPS_ACCESS_TOKEN"
+	PrefixEnvVar               = "GITOPS_PREFIX"
+	ServiceRepoEnvVar          = "GITOPS_SERVICE_REPO"
+	ServiceWebhookSecretEnvVar = "GITOPS_SERVICE_WEBHOOK_SECRET"
+)
+
+// envDefault returns the value of the environment variable to be used as the
+// default for a prompt, or fallback if it's not set.
+//
+// If a validator is provided, values that fail validation are ignored, so
+// that the user isn't offered a default that can't be accepted.
+func envDefault(name, fallback string, v survey.Validator) string {
+	value := os.Getenv(name)
+	if value == "" {
+		return fallback
+	}
+	if v != nil {
+		if err := v(value); err != nil {
+			genericclioptions.Warningf("Ignoring the value of %s: %v", name, err)
+			return fallback
+		}
+	}
+	return value
+}
+
+// defaultOnEmpty returns a validator for prompts that can't have a default,
+// e.g. passwords, where an empty answer accepts the default, the default is
+// validated in place of the empty answer.
+func defaultOnEmpty(value string, v survey.Validator) survey.Validator {
+	return func(ans interface{}) error {
+		if s, ok := ans.(string); ok && s == "" && value != "" {
+			return v(value)
+		}
+		return v(ans)
+	}
+}
@@ -0,0 +1,93 @@
+package ui
+
+import (
+	"errors"
+	"os"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestEnvDefault(t *testing.T) {
+	envTests := []struct {
+		desc  string
+		value string
+		want  string
+	}{
+		{"valid prefix from environment", "tst", "tst"},
+		{"invalid prefix from environment", "Test@", "fallback"},
+		{"no prefix in environment", "", "fallback"},
+	}
+
+	for _, tt := range envTests {
+		t.Run(tt.desc, func(t *testing.T) {
+			defer setEnv(t, PrefixEnvVar, tt.value)()
+
+			got := envDefault(PrefixEnvVar, "fallback", makePrefixValidator())
+			if got != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestEnvDefaultWithNoValidator(t *testing.T) {
+	defer setEnv(t, ServiceRepoEnvVar, "https://github.com/org/service.git")()
+
+	got := envDefault(ServiceRepoEnvVar, "", nil)
+	if got != "https://github.com/org/service.git" {
+		t.Errorf("got %q, want %q", got, "https://github.com/org/service.git")
+	}
+}
+
+func TestDefaultOnEmpty(t *testing.T) {
+	var validated []interface{}
+	v := func(ans interface{}) error {
+		validated = append(validated, ans)
+		if ans == "invalid" {
+			return errors.New("invalid value")
+		}
+		return nil
+	}
+
+	defaultTests := []struct {
+		desc          string
+		defaultValue  string
+		ans           string
+		wantValidated string
+		wantErr       bool
+	}{
+		{"empty answer validates the default", "default", "", "default", false},
+		{"empty answer with an invalid default", "invalid", "", "invalid", true},
+		{"answer is validated", "default", "invalid", "invalid", true},
+		{"empty answer with no default", "", "", "", false},
+	}
+
+	for _, tt := range defaultTests {
+		t.Run(tt.desc, func(t *testing.T) {
+			validated = nil
+			err := defaultOnEmpty(tt.defaultValue, v)(tt.ans)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("got error %v, want error %v", err, tt.wantErr)
+			}
+			if diff := cmp.Diff([]interface{}{tt.wantValidated}, validated); diff != "" {
+				t.Fatalf("validated values:\n%s", diff)
+			}
+		})
+	}
+}
+
+func setEnv(t *testing.T, name, value string) func() {
+	t.Helper()
+	orig, ok := os.LookupEnv(name)
+	if err := os.Setenv(name, value); err != nil {
+		t.Fatal(err)
+	}
+	return func() {
+		if ok {
+			os.Setenv(name, orig)
+			return
+		}
+		os.Unsetenv(name)
+	}
+}
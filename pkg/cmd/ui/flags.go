@@ -0,0 +1,37 @@
+package ui
+
+import (
+	"fmt"
+
+	"gopkg.in/AlecAivazis/survey.v1"
+)
+
+// FlagValue is a value provided with a flag, and the validator used to check
+// the same value when it's entered at a prompt.
+type FlagValue struct {
+	Flag      string
+	Value     string
+	Validator survey.Validator
+}
+
+// PrefixFlag validates a prefix provided with a flag.
+func PrefixFlag(flag, value string) FlagValue {
+	return FlagValue{Flag: flag, Value: value, Validator: makePrefixValidator()}
+}
+
+// SecretFlag validates a webhook secret provided with a flag.
+func SecretFlag(flag, value string) FlagValue {
+	return FlagValue{Flag: flag, Value: value, Validator: makeSecretValidator()}
+}
+
+// ValidateFlags validates all the flag values, rather than stopping at the
+// first failure, and returns an error for each failure naming the flag.
+func ValidateFlags(flags ...FlagValue) []error {
+	errs := []error{}
+	for _, f := range flags {
+		if err := f.Validator(f.Value); err != nil {
+			errs = append(errs, fmt.Errorf("invalid value for --%s: %w", f.Flag, err))
+		}
+	}
+	return errs
+}
@@ -0,0 +1,52 @@
+package ui
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os/exec"
+	"strings"
+)
+
+// ValidateHookInput is written to the stdin of a validate hook as JSON.
+//
+// The Command is the name of the command that's being run e.g. bootstrap, and
+// the Inputs are the names and values that it would create, keyed by the flag
+// that provides them, or the name of the generated value e.g. dev-namespace.
+type ValidateHookInput struct {
+	Command string            `json:"command"`
+	Inputs  map[string]string `json:"inputs"`
+}
+
+// RunValidateHook runs the executable with the inputs for the command as JSON
+// on its stdin, to apply an organization's own naming and policy rules.
+//
+// The inputs are rejected if the executable exits with a non-zero status, its
+// stderr is returned as the error.
+func RunValidateHook(executable, command string, inputs map[string]string) error {
+	if executable == "" {
+		return nil
+	}
+	b, err := json.Marshal(ValidateHookInput{Command: command, Inputs: inputs})
+	if err != nil {
+		return fmt.Errorf("failed to marshal the inputs for the validate hook: %w", err)
+	}
+	var stderr bytes.Buffer
+	cmd := exec.Command(executable)
+	cmd.Stdin = bytes.NewReader(b)
+	cmd.Stderr = &stderr
+	err = cmd.Run()
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) {
+		msg := strings.TrimSpace(stderr.String())
+		if msg == "" {
+			msg = exitErr.Error()
+		}
+		return fmt.Errorf("rejected by the validate hook %s: %s", executable, msg)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to run the validate hook %s: %w", executable, err)
+	}
+	return nil
+}
@@ -0,0 +1,54 @@
+package ui
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestRunValidateHook(t *testing.T) {
+	dir, err := ioutil.TempDir("", "validate-hook")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	hook := filepath.Join(dir, "validate.sh")
+	script := `#!/bin/sh
+if grep -q '"service-name":"payments"'; then
+  echo "service names must start with the team name" >&2
+  exit 1
+fi
+`
+	if err := ioutil.WriteFile(hook, []byte(script), 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	hookTests := []struct {
+		name       string
+		executable string
+		inputs     map[string]string
+		wantErr    string
+	}{
+		{"accepted", hook, map[string]string{"service-name": "team-payments"}, ""},
+		{"rejected", hook, map[string]string{"service-name": "payments"}, "rejected by the validate hook " + hook + ": service names must start with the team name"},
+		{"missing hook", filepath.Join(dir, "missing.sh"), map[string]string{}, "failed to run the validate hook " + filepath.Join(dir, "missing.sh") + ": "},
+		{"no hook", "", map[string]string{"service-name": "payments"}, ""},
+	}
+
+	for _, tt := range hookTests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := RunValidateHook(tt.executable, "service add", tt.inputs)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Fatal(err)
+				}
+				return
+			}
+			if err == nil || !strings.HasPrefix(err.Error(), tt.wantErr) {
+				t.Fatalf("got %v, want %s", err, tt.wantErr)
+			}
+		})
+	}
+}
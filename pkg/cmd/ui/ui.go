@@ -5,6 +5,7 @@ import (
 	"net/url"
 	"path/filepath"
 
+	"github.com/openshift/odo/pkg/log"
 	"gopkg.in/AlecAivazis/survey.v1"
 
 	"github.com/rhd-gitops-example/gitops-cli/pkg/pipelines/ioutils"
@@ -17,6 +18,7 @@ func EnterGitRepo() string {
 	prompt := &survey.Input{
 		Message: "Provide the URL for your GitOps repository",
 		Help:    "The GitOps repository stores your GitOps configuration files, including your Openshift Pipelines resources for driving automated deployments and builds.  Please enter a valid git repository e.g. https://github.com/example/myorg.git",
+		Default: envDefault(GitOpsRepoEnvVar, "", nil),
 	}
 	err := survey.AskOne(prompt, &gitOpsURL, survey.Required)
 	handleError(err)
@@ -34,7 +36,7 @@ func EnterInternalRegistry() string {
 	var internalRegistry string
 	prompt := &survey.Input{
 		Message: "Host-name for internal image registry to be used if you are pushing your images to the internal image registry",
-		Default: "image-registry.openshift-image-registry.svc:5000",
+		Default: envDefault(InternalRegistryEnvVar, "image-registry.openshift-image-registry.svc:5000", nil),
 	}
 
 	err := survey.AskOne(prompt, &internalRegistry, nil)
@@ -48,6 +50,7 @@ func EnterImageRepoInternalRegistry() string {
 	prompt := &survey.Input{
 		Message: "Image repository of the form <project>/<app> which is used to push newly built images.",
 		Help:    "By default images are built from source, whenever there is a push to the repository for your service source code and this image will be pushed to the image repository specified in this parameter, if the value is of the form <registry>/<username>/<repository>, then it assumed that it is an upstream image repository e.g. Quay, if its of the form <project>/<app> the internal registry present on the current cluster will be used as the image repository.",
+		Default: envDefault(ImageRepoEnvVar, "", nil),
 	}
 
 	err := survey.AskOne(prompt, &imageRepo, survey.Required)
@@ -61,7 +64,7 @@ func EnterDockercfg() string {
 	prompt := &survey.Input{
 		Message: "Path to config.json which authenticates image pushes to the desired image registry",
 		Help:    "The secret present in the file path generates a secure secret that authenticates the push of the image built when the app-ci pipeline is run. The image along with the necessary labels will be present on the upstream image repository of choice.",
-		Default: "~/.docker/config.json",
+		Default: envDefault(DockercfgEnvVar, "~/.docker/config.json", nil),
 	}
 
 	err := survey.AskOne(prompt, &dockerCfg, nil)
@@ -75,6 +78,7 @@ func EnterImageRepoExternalRepository() string {
 	prompt := &survey.Input{
 		Message: "Image repository of the form <registry>/<username>/<repository> which is used to push newly built images.",
 		Help:    "By default images are built from source, whenever there is a push to the repository for your service source code and this image will be pushed to the image repository specified in this parameter, if the value is of the form <registry>/<username>/<repository>, then it assumed that it is an upstream image repository e.g. Quay, if its of the form <project>/<app> the internal registry present on the current cluster will be used as the image repository.",
+		Default: envDefault(ImageRepoEnvVar, "", nil),
 	}
 
 	err := survey.AskOne(prompt, &imageRepoExt, survey.Required)
@@ -83,18 +87,21 @@ func EnterImageRepoExternalRepository() string {
 }
 
 // EnterOutputPath allows the user to specify the path where the gitops configuration must reside locally in a UI prompt.
-func EnterOutputPath() string {
+//
+// If the manifestFile already exists in the path, the user is asked whether to
+// overwrite it.
+func EnterOutputPath(manifestFile string) string {
 	var outputPath string
 	prompt := &survey.Input{
 		Message: "Provide a path to write GitOps resources?",
 		Help:    "This is the path where the GitOps repository configuration is stored locally before you push it to the repository GitopsRepoURL",
-		Default: ".",
+		Default: envDefault(OutputPathEnvVar, ".", nil),
 	}
 
 	err := survey.AskOne(prompt, &outputPath, nil)
-	exists, filePathError := ioutils.IsExisting(ioutils.NewFilesystem(), filepath.Join(outputPath, "pipelines.yaml"))
+	exists, filePathError := ioutils.IsExisting(ioutils.NewFilesystem(), filepath.Join(outputPath, manifestFile))
 	if exists {
-		SelectOptionOverwrite(outputPath)
+		SelectOptionOverwrite(outputPath, manifestFile)
 	}
 	if !exists {
 		err = filePathError
@@ -109,6 +116,7 @@ func EnterGitWebhookSecret() string {
 	prompt := &survey.Input{
 		Message: "Provide a secret (minimum 16 characters) that we can use to authenticate incoming hooks from your Git hosting service for the Service repository. (if not provided, it will be auto-generated)",
 		Help:    "You can provide a string that is used as a shared secret to authenticate the origin of hook notifications from your git host.",
+		Default: envDefault(GitOpsWebhookSecretEnvVar, "", makeSecretValidator()),
 	}
 
 	err := survey.AskOne(prompt, &gitWebhookSecret, makeSecretValidator())
@@ -122,18 +130,39 @@ func EnterSealedSecretService(sealedSecretService *types.NamespacedName) string
 	prompt := &survey.Input{
 		Message: "Name of the Sealed Secrets Service that encrypts secrets",
 		Help:    "If you have a custom installation of the Sealed Secrets operator, we need to know where to communicate with it to seal your secrets.",
+		Default: envDefault(SealedSecretsSvcEnvVar, "", nil),
 	}
 	err := survey.AskOne(prompt, &sealedSecret, makeSealedSecretsService(sealedSecretService))
 	handleError(err)
 	return sealedSecret
 }
 
+// SelectSealedSecretService lets the user choose between the Sealed Secrets
+// services discovered in the cluster, when there's more than one of them.
+func SelectSealedSecretService(services []types.NamespacedName) types.NamespacedName {
+	var selected string
+	options := make([]string, len(services))
+	for i, svc := range services {
+		options[i] = svc.String()
+	}
+	prompt := &survey.Select{
+		Message: "Select the Sealed Secrets Service that encrypts secrets",
+		Help:    "More than one Sealed Secrets controller is installed, the selected controller's certificate is used to seal your secrets.",
+		Options: options,
+	}
+
+	err := survey.AskOne(prompt, &selected, makeSelectedSealedSecretsService())
+	handleError(err)
+	return parseSealedSecretOption(selected)
+}
+
 // EnterSealedSecretNamespace , if the secret isnt installed using the operator it is necessary to manually add the sealed-secrets-namepsace in which its installed through this UI prompt.
 func EnterSealedSecretNamespace() string {
 	var sealedNs string
 	prompt := &survey.Input{
 		Message: "Provide a namespace in which the Sealed Secrets operator is installed, automatically generated secrets are encrypted with this operator?",
 		Help:    "If you have a custom installation of the Sealed Secrets operator, we need to know how to communicate with it to seal your secrets",
+		Default: envDefault(SealedSecretsNSEnvVar, "", nil),
 	}
 
 	err := survey.AskOne(prompt, &sealedNs, survey.Required)
@@ -143,14 +172,26 @@ func EnterSealedSecretNamespace() string {
 
 // EnterGitHostAccessToken , it becomes necessary to add the personal access
 // token to access upstream git hosts.
+//
+// Password prompts can't display a default, so a valid token from the
+// environment is accepted by entering nothing.
 func EnterGitHostAccessToken(serviceRepo string) string {
+	check := makeAccessTokenCheck(serviceRepo)
+	defaultToken := envDefault(AccessTokenEnvVar, "", check)
+	message := fmt.Sprintf("Please provide a token used to authenticate requests to %q", serviceRepo)
+	if defaultToken != "" {
+		message += fmt.Sprintf(" (press Enter to use the token from %s)", AccessTokenEnvVar)
+	}
 	var accessToken string
 	prompt := &survey.Password{
-		Message: fmt.Sprintf("Please provide a token used to authenticate requests to %q", serviceRepo),
+		Message: message,
 		Help:    "commit-status-tracker reports the completion status of OpenShift pipeline runs to your Git hosting status on success or failure, this token will be encrypted as a secret in your cluster.\nIf you are using Github, please see here for how to generate a token https://docs.github.com/en/github/authenticating-to-github/creating-a-personal-access-token\nIf you are using GitLab, please see here for how to generate a token https://docs.gitlab.com/ee/user/profile/personal_access_tokens.html",
 	}
-	err := survey.AskOne(prompt, &accessToken, makeAccessTokenCheck(serviceRepo))
+	err := survey.AskOne(prompt, &accessToken, defaultOnEmpty(defaultToken, check))
 	handleError(err)
+	if accessToken == "" {
+		return defaultToken
+	}
 	return accessToken
 }
 
@@ -159,10 +200,14 @@ func EnterPrefix() string {
 	var prefix string
 	prompt := &survey.Input{
 		Message: "Add a prefix to the environment names(dev, stage, cicd etc.) to distinguish and identify individual environments?",
-		Help:    "The prefix helps differentiate between the different namespaces on the cluster, the default namespace cicd will appear as test-cicd if the prefix passed is test.",
+		Help:    fmt.Sprintf("The prefix helps differentiate between the different namespaces on the cluster, the default namespace cicd will appear as test-cicd if the prefix passed is test, %s.", prefixExample("test")),
+		Default: envDefault(PrefixEnvVar, "", makePrefixValidator()),
 	}
 	err := survey.AskOne(prompt, &prefix, makePrefixValidator())
 	handleError(err)
+	if prefix != "" {
+		log.Infof("Using prefix %q, %s", prefix, prefixExample(prefix))
+	}
 	return prefix
 }
 
@@ -172,6 +217,7 @@ func EnterServiceRepoURL() string {
 	prompt := &survey.Input{
 		Message: "Provide the URL for your Service repository e.g. https://github.com/organisation/service.git",
 		Help:    "The repository name where the source code of your service is situated, this will configure a very basic CI for this repository using OpenShift pipelines.",
+		Default: envDefault(ServiceRepoEnvVar, "", nil),
 	}
 	err := survey.AskOne(prompt, &serviceRepo, survey.Required)
 	handleError(err)
@@ -184,12 +230,27 @@ func EnterServiceRepoURL() string {
 	return serviceRepo
 }
 
+// EnterServiceName allows the user to name the service, the default is derived
+// from the name of the service repository.
+func EnterServiceName(defaultName string) string {
+	var serviceName string
+	prompt := &survey.Input{
+		Message: "Provide a name for your Service",
+		Help:    "The name of the service in the dev environment, it must be a valid DNS label, by default it's the name of the service repository, lowercased with invalid characters replaced by hyphens.",
+		Default: defaultName,
+	}
+	err := survey.AskOne(prompt, &serviceName, makeNameValidator())
+	handleError(err)
+	return serviceName
+}
+
 // EnterServiceWebhookSecret allows the user to specify the webhook secret string they wish to authenticate push/pull to service repo in a UI prompt.
 func EnterServiceWebhookSecret() string {
 	var serviceWebhookSecret string
 	prompt := &survey.Input{
 		Message: "Provide a secret (minimum 16 characters) that we can use to authenticate incoming hooks from your Git hosting service for the Service repository. (if not provided, it will be auto-generated)",
 		Help:    "You can provide a string that is used as a shared secret to authenticate the origin of hook notifications from your git host.",
+		Default: envDefault(ServiceWebhookSecretEnvVar, "", makeSecretValidator()),
 	}
 	err := survey.AskOne(prompt, &serviceWebhookSecret, makeSecretValidator())
 
@@ -212,14 +273,14 @@ func SelectOptionImageRepository() string {
 }
 
 // SelectOptionOverwrite allows users the option to overwrite the current gitops configuration locally through the UI prompt.
-func SelectOptionOverwrite(path string) string {
+func SelectOptionOverwrite(path, manifestFile string) string {
 	var overwrite string
 	prompt := &survey.Select{
 		Message: "Do you want to overwrite your output path?",
 		Options: []string{"yes", "no"},
 		Default: "no",
 	}
-	err := survey.AskOne(prompt, &overwrite, makeOverWriteValidator(path))
+	err := survey.AskOne(prompt, &overwrite, makeOverWriteValidator(path, manifestFile))
 	handleError(err)
 	return overwrite
 }
@@ -264,3 +325,20 @@ func IsPrivateRepo() bool {
 	handleError(err)
 	return response == "yes"
 }
+
+// ConfirmSummary displays the summary of the changes to be made, and asks the
+// user to confirm that they want to continue.
+func ConfirmSummary(summary []string) bool {
+	log.Info("The following changes will be made:")
+	for _, s := range summary {
+		log.Infof("  - %s", s)
+	}
+	var proceed bool
+	prompt := &survey.Confirm{
+		Message: "Do you want to continue?",
+		Default: false,
+	}
+	err := survey.AskOne(prompt, &proceed, nil)
+	handleError(err)
+	return proceed
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"net/http"
 	"net/url"
 	"os"
 	"path/filepath"
@@ -12,6 +13,7 @@ import (
 	"github.com/rhd-gitops-example/gitops-cli/pkg/cmd/utility"
 	"github.com/rhd-gitops-example/gitops-cli/pkg/pipelines/git"
 	"github.com/rhd-gitops-example/gitops-cli/pkg/pipelines/ioutils"
+	"github.com/rhd-gitops-example/gitops-cli/pkg/pipelines/network"
 	"github.com/rhd-gitops-example/gitops-cli/pkg/pipelines/secrets"
 	"gopkg.in/AlecAivazis/survey.v1"
 	"gopkg.in/AlecAivazis/survey.v1/terminal"
@@ -20,6 +22,10 @@ import (
 	"k8s.io/klog"
 )
 
+// newRepository is used to create the repository that access tokens are
+// checked against, it's a var to allow replacement in tests.
+var newRepository = git.NewRepository
+
 func makePrefixValidator() survey.Validator {
 	return func(input interface{}) error {
 		return validatePrefix(input)
@@ -32,9 +38,18 @@ func makeSecretValidator() survey.Validator {
 	}
 }
 
-func makeOverWriteValidator(path string) survey.Validator {
+func makeNameValidator() survey.Validator {
 	return func(input interface{}) error {
-		return validateOverwriteOption(input, path)
+		if s, ok := input.(string); ok {
+			return ValidateName(s)
+		}
+		return nil
+	}
+}
+
+func makeOverWriteValidator(path, manifestFile string) survey.Validator {
+	return func(input interface{}) error {
+		return validateOverwriteOption(input, path, manifestFile)
 	}
 }
 
@@ -44,35 +59,122 @@ func makeSealedSecretsService(sealedSecretService *types.NamespacedName) survey.
 	}
 }
 
+func makeSelectedSealedSecretsService() survey.Validator {
+	return func(input interface{}) error {
+		if s, ok := input.(string); ok {
+			return checkSealedSecretService(parseSealedSecretOption(s))
+		}
+		return nil
+	}
+}
+
 func makeAccessTokenCheck(serviceRepo string) survey.Validator {
 	return func(input interface{}) error {
 		return validateAccessToken(input, serviceRepo)
 	}
 }
 
-// ValidatePrefix checks the length of the prefix with the env crosses 63 chars or not
+// ValidatePrefix checks the length of the prefix with the env crosses the
+// utility.MaxNameLength, 63 chars by default, or not
 func validatePrefix(input interface{}) error {
 	if s, ok := input.(string); ok {
 		prefix := utility.MaybeCompletePrefix(s)
-		s = prefix + "stage"
-		if len(s) < 64 {
-			err := ValidateName(s)
-			if err != nil {
-				return err
-			}
-		} else {
-			return fmt.Errorf("The prefix %s, must be less than 58 characters", prefix)
+		if l := len(prefix) + len("stage"); l > utility.MaxNameLength {
+			return fmt.Errorf("The prefix %s, must be less than %d characters, %s which is %d characters, the limit is %d",
+				prefix, utility.MaxNameLength-len("stage"), prefixExample(prefix), l, utility.MaxNameLength)
 		}
-		return nil
+		return ValidateName(utility.EnvironmentNamespace(prefix, "stage"))
+	}
+	return nil
+}
+
+// prefixExample returns a description of the stage namespace that would be
+// created with the prefix.
+func prefixExample(prefix string) string {
+	return fmt.Sprintf("with this prefix, your stage namespace will be '%sstage'", utility.MaybeCompletePrefix(prefix))
+}
+
+// ValidateEnvironmentName checks that the namespace for the environment, the
+// completed prefix followed by the environment name, fits within the
+// utility.MaxNameLength, the 63 character limit for a DNS label by default.
+func ValidateEnvironmentName(prefix, envName string) error {
+	prefix = utility.MaybeCompletePrefix(prefix)
+	if l := len(prefix) + len(envName); l > utility.MaxNameLength {
+		return fmt.Errorf("The environment %q is too long, the namespace %q is %d characters, with the prefix %q environment names can be at most %d characters",
+			envName, prefix+envName, l, prefix, utility.MaxNameLength-len(prefix))
+	}
+	return ValidateName(utility.EnvironmentNamespace(prefix, envName))
+}
+
+// ValidateNamespacePattern checks that the namespace rendered from the pattern
+// for the environment is a valid DNS label.
+func ValidateNamespacePattern(pattern, prefix, envName string) error {
+	if pattern == "" || pattern == utility.DefaultNamespacePattern {
+		return ValidateEnvironmentName(prefix, envName)
+	}
+	ns, err := utility.NamespaceFromPattern(pattern, prefix, envName)
+	if err != nil {
+		return err
+	}
+	if err := ValidateName(ns); err != nil {
+		return fmt.Errorf("The namespace pattern %q is invalid for the %q environment: %w", pattern, envName, err)
+	}
+	return nil
+}
+
+// systemNamespacePrefixes are the prefixes of the namespaces that Kubernetes
+// and OpenShift create for their own components.
+var systemNamespacePrefixes = []string{"kube-", "openshift-"}
+
+// ValidateNotSystemNamespace returns an error if the namespace is the default
+// namespace, or has the prefix of a Kubernetes or OpenShift system namespace,
+// generating an environment in one of these would mix it with the cluster's
+// own resources.
+func ValidateNotSystemNamespace(ns string) error {
+	if ns == "default" {
+		return fmt.Errorf("the namespace %q is the Kubernetes default namespace", ns)
+	}
+	for _, p := range systemNamespacePrefixes {
+		if strings.HasPrefix(ns, p) {
+			return fmt.Errorf("the namespace %q collides with the %s* system namespaces", ns, p)
+		}
+	}
+	return nil
+}
+
+// CheckSystemNamespace checks that the namespace rendered from the pattern for
+// the environment isn't a system namespace, if it is, the error is returned
+// when strict is true, otherwise it's passed to warn.
+func CheckSystemNamespace(pattern, prefix, envName string, strict bool, warn func(string, ...interface{})) error {
+	ns, err := utility.NamespaceFromPattern(pattern, prefix, envName)
+	if err != nil {
+		return err
+	}
+	if err := ValidateNotSystemNamespace(ns); err != nil {
+		if strict {
+			return fmt.Errorf("invalid namespace for the %q environment: %w", envName, err)
+		}
+		warn("The namespace for the %q environment may not be safe to use, %s", envName, err)
 	}
 	return nil
 }
 
 // ValidateName will do validation of application & component names according to DNS (RFC 1123) rules
 // Criteria for valid name in kubernetes: https://github.com/kubernetes/community/blob/master/contributors/design-proposals/architecture/identifiers.md
+//
+// Names can be up to the utility.MaxNameLength, rather than the 63 character
+// limit for a DNS label, if it has been increased.
 func ValidateName(name string) error {
 
-	errorList := validation.IsDNS1123Label(name)
+	errorList := []string{}
+	for _, msg := range validation.IsDNS1123Label(name) {
+		if msg != validation.MaxLenError(validation.DNS1123LabelMaxLength) {
+			errorList = append(errorList, msg)
+		}
+	}
+	if len(name) > utility.MaxNameLength {
+		errorList = append(errorList, validation.MaxLenError(utility.MaxNameLength))
+	}
 
 	if len(errorList) != 0 {
 		return fmt.Errorf("%s is not a valid name:  %s", name, strings.Join(errorList, " "))
@@ -81,6 +183,16 @@ func ValidateName(name string) error {
 	return nil
 }
 
+// ValidateManifestFile checks that the manifest file is a relative path that
+// stays within the dir, which describes the directory in the error e.g.
+// "output path".
+func ValidateManifestFile(manifestFile, dir string) error {
+	if filepath.IsAbs(manifestFile) || strings.HasPrefix(filepath.Clean(manifestFile), "..") {
+		return fmt.Errorf("manifest file %q must be a relative path within the %s", manifestFile, dir)
+	}
+	return nil
+}
+
 func validateSecretLength(input interface{}) error {
 	if s, ok := input.(string); ok {
 		err := CheckSecretLength(s)
@@ -93,12 +205,12 @@ func validateSecretLength(input interface{}) error {
 }
 
 // validateOverwriteOption(  validates the URL
-func validateOverwriteOption(input interface{}, path string) error {
+func validateOverwriteOption(input interface{}, path, manifestFile string) error {
 	if s, ok := input.(string); ok {
 		if s == "no" {
-			exists, _ := ioutils.IsExisting(ioutils.NewFilesystem(), filepath.Join(path, "pipelines.yaml"))
+			exists, _ := ioutils.IsExisting(ioutils.NewFilesystem(), filepath.Join(path, manifestFile))
 			if exists {
-				EnterOutputPath()
+				EnterOutputPath(manifestFile)
 			}
 		}
 		return nil
@@ -110,25 +222,88 @@ func validateOverwriteOption(input interface{}, path string) error {
 // validateAccessToken validates if the access token is correct for a particular service repo
 func validateAccessToken(input interface{}, serviceRepo string) error {
 	if s, ok := input.(string); ok {
-		repo, err := git.NewRepository(serviceRepo, s)
-		if err != nil {
-			return err
-		}
-		parsedURL, err := url.Parse(serviceRepo)
-		if err != nil {
-			return fmt.Errorf("failed to parse the provided URL %q: %w", serviceRepo, err)
+		_, err := checkAccessToken(s, serviceRepo)
+		return err
+	}
+	return nil
+}
+
+// SelectAccessToken returns the first of the tokens that authenticates for the
+// service repo, the tokens are tried in order.
+//
+// The next token is only tried if the repository can't be accessed with the
+// previous one, other errors e.g. from the network, are returned immediately.
+func SelectAccessToken(tokens []string, serviceRepo string) (string, error) {
+	var err error
+	for i, token := range tokens {
+		var status int
+		status, err = checkAccessToken(token, serviceRepo)
+		if err == nil {
+			klog.V(4).Infof("Using access token %d of %d for %s", i+1, len(tokens), serviceRepo)
+			return token, nil
 		}
-		repoName, err := git.GetRepoName(parsedURL)
-		if err != nil {
-			return fmt.Errorf("failed to get the repository name from %q: %w", serviceRepo, err)
+		if !isUnauthorized(status) {
+			return "", err
 		}
-		_, _, err = repo.Client.Repositories.Find(context.Background(), repoName)
-		if err != nil {
-			return fmt.Errorf("The token passed is incorrect for repository %s", repoName)
+		klog.V(4).Infof("Access token %d of %d is unauthorized for %s", i+1, len(tokens), serviceRepo)
+	}
+	return "", err
+}
+
+// checkAccessToken finds the service repo with the token, the status of the
+// response is returned, this is 0 if no response was received.
+func checkAccessToken(token, serviceRepo string) (int, error) {
+	repo, err := newRepository(serviceRepo, token)
+	if err != nil {
+		return 0, err
+	}
+	parsedURL, err := url.Parse(serviceRepo)
+	if err != nil {
+		return 0, fmt.Errorf("failed to parse the provided URL %q: %w", serviceRepo, err)
+	}
+	repoName, err := git.GetRepoName(parsedURL)
+	if err != nil {
+		return 0, fmt.Errorf("failed to get the repository name from %q: %w", serviceRepo, err)
+	}
+	_, res, err := repo.Client.Repositories.Find(context.Background(), repoName)
+	status := 0
+	if res != nil {
+		status = res.Status
+	}
+	if err != nil {
+		if errors.Is(err, network.ErrNetworkDisabled) || errors.Is(err, network.ErrHostNotAllowed) {
+			return status, fmt.Errorf("failed to validate the token for repository %s: %w", repoName, err)
 		}
+		return status, fmt.Errorf("The token passed is incorrect for repository %s", repoName)
+	}
+	return status, nil
+}
+
+// CheckRepositoryAccess returns an error if the token can't access the service
+// repo, the error distinguishes a token that's rejected by the Git host, from a
+// repository that isn't found, which is also the response for a private
+// repository that the token isn't granted access to.
+func CheckRepositoryAccess(token, serviceRepo string) error {
+	status, err := checkAccessToken(token, serviceRepo)
+	if err == nil {
 		return nil
 	}
-	return nil
+	switch status {
+	case http.StatusUnauthorized:
+		return errors.New("bad token, the token was rejected by the Git host")
+	case http.StatusForbidden:
+		return errors.New("access denied, the token doesn't have permission to read the repository")
+	case http.StatusNotFound:
+		return errors.New("repository not found, or the token can't access the private repository")
+	}
+	return err
+}
+
+// isUnauthorized returns true if the status is from a request that the token
+// wasn't authorized for, GitHub responds with not found for private
+// repositories that can't be accessed.
+func isUnauthorized(status int) bool {
+	return status == http.StatusUnauthorized || status == http.StatusForbidden || status == http.StatusNotFound
 }
 
 // validateSealedSecretService validates to see if the sealed secret service is present in the correct namespace.
@@ -136,27 +311,46 @@ func validateSealedSecretService(input interface{}, sealedSecretService *types.N
 	if s, ok := input.(string); ok {
 		sealedSecretService.Name = s
 		sealedSecretService.Namespace = EnterSealedSecretNamespace()
-		_, err := secrets.GetClusterPublicKey(*sealedSecretService)
-		if err != nil {
-			if compareError(err, sealedSecretService.Name) {
-				return fmt.Errorf("The given service %q is not installed in the right namespace %q", sealedSecretService.Name, sealedSecretService.Namespace)
-			}
-			return errors.New("sealed secrets could not be configured sucessfully")
+		return checkSealedSecretService(*sealedSecretService)
+	}
+	return nil
+}
+
+// checkSealedSecretService checks that the public key can be fetched from the
+// sealed secret service.
+func checkSealedSecretService(sealedSecretService types.NamespacedName) error {
+	_, err := secrets.GetClusterPublicKey(sealedSecretService)
+	if err != nil {
+		if compareError(err, sealedSecretService.Name) {
+			return fmt.Errorf("The given service %q is not installed in the right namespace %q", sealedSecretService.Name, sealedSecretService.Namespace)
 		}
-		return nil
+		return errors.New("sealed secrets could not be configured sucessfully")
 	}
 	return nil
 }
 
+// parseSealedSecretOption parses a "namespace/name" option selected from the
+// discovered sealed secret services.
+func parseSealedSecretOption(s string) types.NamespacedName {
+	parts := strings.SplitN(s, "/", 2)
+	if len(parts) != 2 {
+		return types.NamespacedName{Name: s}
+	}
+	return types.NamespacedName{Namespace: parts[0], Name: parts[1]}
+}
+
 func compareError(err error, sealedSecretService string) bool {
 	createdError := fmt.Errorf("cannot fetch certificate: services \"%s\" not found", sealedSecretService)
 	return err.Error() == createdError.Error()
 }
 
+// MinSecretLength is the minimum length of a webhook secret.
+const MinSecretLength = 16
+
 // check if the length of secret is less than 16 chars
 func CheckSecretLength(secret string) bool {
 	if secret != "" {
-		if len(secret) < 16 {
+		if len(secret) < MinSecretLength {
 			return true
 		}
 	}
@@ -1,7 +1,19 @@
 package ui
 
 import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
 	"testing"
+
+	"github.com/jenkins-x/go-scm/scm"
+	"k8s.io/apimachinery/pkg/types"
+
+	"github.com/rhd-gitops-example/gitops-cli/pkg/cmd/utility"
+	"github.com/rhd-gitops-example/gitops-cli/pkg/pipelines/git"
+	"github.com/rhd-gitops-example/gitops-cli/pkg/pipelines/network"
 )
 
 func TestValidatePrefix(t *testing.T) {
@@ -17,7 +29,7 @@ func TestValidatePrefix(t *testing.T) {
 			`Test@-stage is not a valid name:  a DNS-1123 label must consist of lower case alphanumeric characters or '-', and must start and end with an alphanumeric character (e.g. 'my-name',  or '123-abc', regex used for validation is '[a-z0-9]([-a-z0-9]*[a-z0-9])?')`},
 		{"Prefix too long",
 			"abcdefghijklmnopqrstuvwxyzabcdefghijklmnopqrstuvwxyzabcdefghijklmnopqrstuvwxyzabcdefghijklmnopqrstuvwxyzabcdefghijklmnopqrstuvwxyz",
-			"The prefix abcdefghijklmnopqrstuvwxyzabcdefghijklmnopqrstuvwxyzabcdefghijklmnopqrstuvwxyzabcdefghijklmnopqrstuvwxyzabcdefghijklmnopqrstuvwxyz-, must be less than 58 characters",
+			"The prefix abcdefghijklmnopqrstuvwxyzabcdefghijklmnopqrstuvwxyzabcdefghijklmnopqrstuvwxyzabcdefghijklmnopqrstuvwxyzabcdefghijklmnopqrstuvwxyz-, must be less than 58 characters, with this prefix, your stage namespace will be 'abcdefghijklmnopqrstuvwxyzabcdefghijklmnopqrstuvwxyzabcdefghijklmnopqrstuvwxyzabcdefghijklmnopqrstuvwxyzabcdefghijklmnopqrstuvwxyz-stage' which is 136 characters, the limit is 63",
 		},
 	}
 
@@ -31,6 +43,62 @@ func TestValidatePrefix(t *testing.T) {
 	}
 }
 
+func TestValidatePrefixWithMaxNameLength(t *testing.T) {
+	defer stubMaxNameLength(80)()
+	validator := makePrefixValidator()
+
+	if err := validator(strings.Repeat("a", 69)); err != nil {
+		t.Fatalf("got %v, want a prefix within the increased limit to be valid", err)
+	}
+	err := validator(strings.Repeat("a", 75))
+	want := "The prefix " + strings.Repeat("a", 75) + "-, must be less than 75 characters, with this prefix, your stage namespace will be '" + strings.Repeat("a", 75) + "-stage' which is 81 characters, the limit is 80"
+	if err == nil || err.Error() != want {
+		t.Fatalf("got %v, want %s", err, want)
+	}
+}
+
+func TestValidateName(t *testing.T) {
+	nameTests := []struct {
+		desc          string
+		maxNameLength int
+		name          string
+		wantErr       string
+	}{
+		{"default limit", 63, strings.Repeat("a", 63), ""},
+		{"over the default limit", 63, strings.Repeat("a", 64), strings.Repeat("a", 64) + " is not a valid name:  must be no more than 63 characters"},
+		{"increased limit", 80, strings.Repeat("a", 80), ""},
+		{"over the increased limit", 80, strings.Repeat("a", 81), strings.Repeat("a", 81) + " is not a valid name:  must be no more than 80 characters"},
+		{
+			"invalid name with the increased limit", 80, "Test@",
+			`Test@ is not a valid name:  a DNS-1123 label must consist of lower case alphanumeric characters or '-', and must start and end with an alphanumeric character (e.g. 'my-name',  or '123-abc', regex used for validation is '[a-z0-9]([-a-z0-9]*[a-z0-9])?')`,
+		},
+	}
+
+	for _, tt := range nameTests {
+		t.Run(tt.desc, func(rt *testing.T) {
+			defer stubMaxNameLength(tt.maxNameLength)()
+			err := ValidateName(tt.name)
+			if tt.wantErr == "" {
+				if err != nil {
+					rt.Fatalf("got %v, want no error", err)
+				}
+				return
+			}
+			if err == nil || err.Error() != tt.wantErr {
+				rt.Fatalf("got %v, want %s", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func stubMaxNameLength(l int) func() {
+	orig := utility.MaxNameLength
+	utility.MaxNameLength = l
+	return func() {
+		utility.MaxNameLength = orig
+	}
+}
+
 func TestValidateSecretLength(t *testing.T) {
 	validator := makeSecretValidator()
 	cmdTests := []struct {
@@ -75,3 +143,285 @@ func TestAccessToken(t *testing.T) {
 		})
 	}
 }
+
+func TestAccessTokenWithNoNetwork(t *testing.T) {
+	defer func(d bool) {
+		network.Disabled = d
+	}(network.Disabled)
+	network.Disabled = true
+
+	validator := makeAccessTokenCheck("https://github.com/example/test.git")
+	err := validator("demo-token")
+
+	if err == nil || !strings.Contains(err.Error(), "network disabled") {
+		t.Fatalf("got %v, want a network disabled error", err)
+	}
+}
+
+func TestValidateAccessToken(t *testing.T) {
+	tokenTests := []struct {
+		desc    string
+		status  int
+		wantErr string
+	}{
+		{"token is valid", http.StatusOK, ""},
+		{"token is unauthorized", http.StatusUnauthorized, "The token passed is incorrect for repository example/test"},
+		{"repository is not found", http.StatusNotFound, "The token passed is incorrect for repository example/test"},
+	}
+
+	for _, tt := range tokenTests {
+		t.Run(tt.desc, func(rt *testing.T) {
+			repos := &stubRepositoryService{status: tt.status}
+			defer stubNewRepository(rt, repos)()
+
+			err := validateAccessToken("demo-token", "https://github.com/example/test.git")
+
+			if tt.wantErr == "" && err != nil {
+				rt.Fatalf("got error %s, want no error", err)
+			}
+			if tt.wantErr != "" && (err == nil || err.Error() != tt.wantErr) {
+				rt.Fatalf("got error %v, want %s", err, tt.wantErr)
+			}
+			if repos.token != "demo-token" || repos.found != "example/test" {
+				rt.Fatalf("got repository %q with token %q, want example/test with demo-token", repos.found, repos.token)
+			}
+		})
+	}
+}
+
+func TestCheckRepositoryAccess(t *testing.T) {
+	accessTests := []struct {
+		desc    string
+		status  int
+		wantErr string
+	}{
+		{"token is valid", http.StatusOK, ""},
+		{"token is unauthorized", http.StatusUnauthorized, "bad token, the token was rejected by the Git host"},
+		{"token is forbidden", http.StatusForbidden, "access denied, the token doesn't have permission to read the repository"},
+		{"repository is not found", http.StatusNotFound, "repository not found, or the token can't access the private repository"},
+		{"server error", http.StatusBadGateway, "The token passed is incorrect for repository example/test"},
+	}
+
+	for _, tt := range accessTests {
+		t.Run(tt.desc, func(rt *testing.T) {
+			defer stubNewRepository(rt, &stubRepositoryService{status: tt.status})()
+
+			err := CheckRepositoryAccess("demo-token", "https://github.com/example/test.git")
+
+			if tt.wantErr == "" && err != nil {
+				rt.Fatalf("got error %s, want no error", err)
+			}
+			if tt.wantErr != "" && (err == nil || err.Error() != tt.wantErr) {
+				rt.Fatalf("got error %v, want %s", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestSelectAccessToken(t *testing.T) {
+	tokenTests := []struct {
+		desc      string
+		statuses  map[string]int
+		wantToken string
+		wantErr   string
+	}{
+		{"first token is valid", map[string]int{"first": http.StatusOK, "second": http.StatusOK}, "first", ""},
+		{"first token is unauthorized", map[string]int{"first": http.StatusUnauthorized, "second": http.StatusOK}, "second", ""},
+		{"first token is forbidden", map[string]int{"first": http.StatusForbidden, "second": http.StatusOK}, "second", ""},
+		{"first token fails with a server error", map[string]int{"first": http.StatusBadGateway, "second": http.StatusOK}, "", "The token passed is incorrect for repository example/test"},
+		{"all tokens are unauthorized", map[string]int{"first": http.StatusUnauthorized, "second": http.StatusUnauthorized}, "", "The token passed is incorrect for repository example/test"},
+	}
+
+	for _, tt := range tokenTests {
+		t.Run(tt.desc, func(rt *testing.T) {
+			repos := &stubRepositoryService{tokenStatuses: tt.statuses}
+			defer stubNewRepository(rt, repos)()
+
+			token, err := SelectAccessToken([]string{"first", "second"}, "https://github.com/example/test.git")
+
+			if tt.wantErr == "" && err != nil {
+				rt.Fatalf("got error %s, want no error", err)
+			}
+			if tt.wantErr != "" && (err == nil || err.Error() != tt.wantErr) {
+				rt.Fatalf("got error %v, want %s", err, tt.wantErr)
+			}
+			if token != tt.wantToken {
+				rt.Fatalf("got token %q, want %q", token, tt.wantToken)
+			}
+		})
+	}
+}
+
+func TestValidateEnvironmentName(t *testing.T) {
+	cmdTests := []struct {
+		desc    string
+		prefix  string
+		envName string
+		wantErr string
+	}{
+		{"Short prefix and environment", "tst", "stage", ""},
+		{"Short prefix with long environment",
+			"tst",
+			"a-very-long-environment-name-that-is-used-for-staging-deploy",
+			`The environment "a-very-long-environment-name-that-is-used-for-staging-deploy" is too long, the namespace "tst-a-very-long-environment-name-that-is-used-for-staging-deploy" is 64 characters, with the prefix "tst-" environment names can be at most 59 characters`},
+		{"Invalid environment name", "tst", "Stage", `tst-Stage is not a valid name:  a DNS-1123 label must consist of lower case alphanumeric characters or '-', and must start and end with an alphanumeric character (e.g. 'my-name',  or '123-abc', regex used for validation is '[a-z0-9]([-a-z0-9]*[a-z0-9])?')`},
+	}
+
+	for _, tt := range cmdTests {
+		t.Run(tt.desc, func(t *testing.T) {
+			err := ValidateEnvironmentName(tt.prefix, tt.envName)
+			gotErr := ""
+			if err != nil {
+				gotErr = err.Error()
+			}
+			if gotErr != tt.wantErr {
+				t.Errorf("got %s, want %s", gotErr, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestValidateNamespacePattern(t *testing.T) {
+	cmdTests := []struct {
+		desc    string
+		pattern string
+		wantErr string
+	}{
+		{"Default pattern", "", ""},
+		{"Environment before prefix", "{{.Env}}-{{.Prefix}}", ""},
+		{"Underscore separator", "{{.Prefix}}_{{.Env}}", `The namespace pattern "{{.Prefix}}_{{.Env}}" is invalid for the "stage" environment: tst_stage is not a valid name:  a DNS-1123 label must consist of lower case alphanumeric characters or '-', and must start and end with an alphanumeric character (e.g. 'my-name',  or '123-abc', regex used for validation is '[a-z0-9]([-a-z0-9]*[a-z0-9])?')`},
+	}
+
+	for _, tt := range cmdTests {
+		t.Run(tt.desc, func(t *testing.T) {
+			err := ValidateNamespacePattern(tt.pattern, "tst-", "stage")
+			gotErr := ""
+			if err != nil {
+				gotErr = err.Error()
+			}
+			if gotErr != tt.wantErr {
+				t.Errorf("got %s, want %s", gotErr, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestValidateNotSystemNamespace(t *testing.T) {
+	cmdTests := []struct {
+		ns      string
+		wantErr string
+	}{
+		{"tst-stage", ""},
+		{"kube-system", `the namespace "kube-system" collides with the kube-* system namespaces`},
+		{"openshift-gitops", `the namespace "openshift-gitops" collides with the openshift-* system namespaces`},
+		{"default", `the namespace "default" is the Kubernetes default namespace`},
+		{"kubernetes", ""},
+	}
+
+	for _, tt := range cmdTests {
+		t.Run(tt.ns, func(t *testing.T) {
+			err := ValidateNotSystemNamespace(tt.ns)
+			gotErr := ""
+			if err != nil {
+				gotErr = err.Error()
+			}
+			if gotErr != tt.wantErr {
+				t.Errorf("got %s, want %s", gotErr, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestCheckSystemNamespace(t *testing.T) {
+	var warnings []string
+	warn := func(format string, a ...interface{}) {
+		warnings = append(warnings, fmt.Sprintf(format, a...))
+	}
+
+	err := CheckSystemNamespace("", "kube", "system", true, warn)
+	wantErr := `invalid namespace for the "system" environment: the namespace "kube-system" collides with the kube-* system namespaces`
+	if err == nil || err.Error() != wantErr {
+		t.Fatalf("got %v, want %s", err, wantErr)
+	}
+	if len(warnings) != 0 {
+		t.Fatalf("got warnings %v when strict", warnings)
+	}
+
+	if err := CheckSystemNamespace("", "kube", "system", false, warn); err != nil {
+		t.Fatal(err)
+	}
+	want := `The namespace for the "system" environment may not be safe to use, the namespace "kube-system" collides with the kube-* system namespaces`
+	if len(warnings) != 1 || warnings[0] != want {
+		t.Fatalf("got warnings %v, want %s", warnings, want)
+	}
+}
+
+func TestPrefixExample(t *testing.T) {
+	cmdTests := []struct {
+		prefix string
+		want   string
+	}{
+		{"team", "with this prefix, your stage namespace will be 'team-stage'"},
+		{"team-", "with this prefix, your stage namespace will be 'team-stage'"},
+		{"", "with this prefix, your stage namespace will be 'stage'"},
+	}
+
+	for _, tt := range cmdTests {
+		t.Run(tt.prefix, func(t *testing.T) {
+			if got := prefixExample(tt.prefix); got != tt.want {
+				t.Errorf("got %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseSealedSecretOption(t *testing.T) {
+	optionTests := []struct {
+		option string
+		want   types.NamespacedName
+	}{
+		{"kube-system/sealed-secrets-controller", types.NamespacedName{Namespace: "kube-system", Name: "sealed-secrets-controller"}},
+		{"sealed-secrets-controller", types.NamespacedName{Name: "sealed-secrets-controller"}},
+	}
+
+	for _, tt := range optionTests {
+		if got := parseSealedSecretOption(tt.option); got != tt.want {
+			t.Errorf("parseSealedSecretOption(%q) got %v, want %v", tt.option, got, tt.want)
+		}
+	}
+}
+
+// stubRepositoryService responds to Find with the status, or the status for
+// the token if there's one, the other methods of the scm.RepositoryService are
+// not implemented.
+type stubRepositoryService struct {
+	scm.RepositoryService
+	status        int
+	tokenStatuses map[string]int
+	token         string
+	found         string
+}
+
+func (s *stubRepositoryService) Find(ctx context.Context, repo string) (*scm.Repository, *scm.Response, error) {
+	s.found = repo
+	status := s.status
+	if tokenStatus, ok := s.tokenStatuses[s.token]; ok {
+		status = tokenStatus
+	}
+	res := &scm.Response{Status: status}
+	if status != http.StatusOK {
+		return nil, res, errors.New(http.StatusText(status))
+	}
+	return &scm.Repository{FullName: repo}, res, nil
+}
+
+func stubNewRepository(t *testing.T, repos *stubRepositoryService) func() {
+	origFunc := newRepository
+	newRepository = func(rawURL, token string) (*git.Repository, error) {
+		repos.token = token
+		return &git.Repository{Client: &scm.Client{Repositories: repos}}, nil
+	}
+	return func() {
+		newRepository = origFunc
+	}
+}
@@ -0,0 +1,42 @@
+package utility
+
+import (
+	"fmt"
+
+	"github.com/rhd-gitops-example/gitops-cli/pkg/pipelines/config"
+	"github.com/rhd-gitops-example/gitops-cli/pkg/pipelines/ioutils"
+	"github.com/spf13/afero"
+	"github.com/spf13/cobra"
+)
+
+// RepoRootDetection is set from the --repo-root-detection flag, when true,
+// the directory flags that default to the current directory default to the
+// directory of the GitOps repository that the current directory is in.
+var RepoRootDetection bool
+
+// repoRootFlags are the flags for the directories of the pipelines.yaml and
+// generated files.
+var repoRootFlags = []string{"pipelines-folder", "output"}
+
+// DetectRepoRoot finds the closest directory with a pipelines.yaml, or the root
+// of the Git checkout, walking up from the dir, and uses it for the flags in
+// repoRootFlags that weren't provided.
+//
+// Only flags that default to the current directory are changed, the output
+// flags of some commands are files or formats.
+func DetectRepoRoot(fs afero.Fs, dir string, cmd *cobra.Command) error {
+	root, err := ioutils.FindRepositoryDir(fs, dir, config.PipelinesFile)
+	if err != nil {
+		return fmt.Errorf("failed to detect the repository root: %w", err)
+	}
+	for _, name := range repoRootFlags {
+		f := cmd.Flags().Lookup(name)
+		if f == nil || f.Changed || f.DefValue != "." {
+			continue
+		}
+		if err := f.Value.Set(root); err != nil {
+			return fmt.Errorf("invalid detected value for --%s: %w", name, err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,64 @@
+package utility
+
+import (
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/rhd-gitops-example/gitops-cli/pkg/pipelines/git"
+)
+
+// StdinValue is the value of a secret flag that reads the secret from stdin.
+const StdinValue = "-"
+
+// Stdin is where secrets are read from, var to allow replacement in tests.
+var Stdin io.Reader = os.Stdin
+
+// ReadStdinSecrets reads the value for the secret flag whose value is
+// StdinValue from Stdin, the secrets are keyed by their flag names, and the
+// tokens of the credentials are read too, e.g. --credential github.com=-.
+//
+// Stdin is read until EOF, and a trailing newline is trimmed, so only one
+// secret can be read from it.
+func ReadStdinSecrets(secrets map[string]*string, creds git.Credentials) error {
+	flags := []string{}
+	for name, value := range secrets {
+		if *value == StdinValue {
+			flags = append(flags, "--"+name)
+		}
+	}
+	for host, token := range creds {
+		if token == StdinValue {
+			flags = append(flags, "--credential "+host)
+		}
+	}
+	if len(flags) == 0 {
+		return nil
+	}
+	sort.Strings(flags)
+	if len(flags) > 1 {
+		return fmt.Errorf("only one secret can be read from stdin, got %q for %s", StdinValue, strings.Join(flags, ", "))
+	}
+	b, err := ioutil.ReadAll(Stdin)
+	if err != nil {
+		return fmt.Errorf("failed to read %s from stdin: %w", flags[0], err)
+	}
+	value := strings.TrimSuffix(strings.TrimSuffix(string(b), "\n"), "\r")
+	if value == "" {
+		return fmt.Errorf("failed to read %s from stdin: no value was provided", flags[0])
+	}
+	for _, secret := range secrets {
+		if *secret == StdinValue {
+			*secret = value
+		}
+	}
+	for host, token := range creds {
+		if token == StdinValue {
+			creds[host] = value
+		}
+	}
+	return nil
+}
@@ -0,0 +1,81 @@
+package utility
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/rhd-gitops-example/gitops-cli/pkg/pipelines/git"
+)
+
+func TestReadStdinSecrets(t *testing.T) {
+	stdinTests := []struct {
+		name      string
+		stdin     string
+		secret    string
+		creds     git.Credentials
+		want      string
+		wantCreds git.Credentials
+	}{
+		{"trailing newline", "a-secret-from-stdin\n", "-", nil, "a-secret-from-stdin", nil},
+		{"trailing CRLF", "a-secret-from-stdin\r\n", "-", nil, "a-secret-from-stdin", nil},
+		{"no trailing newline", "a-secret-from-stdin", "-", nil, "a-secret-from-stdin", nil},
+		{"only the last newline is trimmed", "a-secret\n\n", "-", nil, "a-secret\n", nil},
+		{"secret not from stdin", "ignored", "a-secret-from-flag", nil, "a-secret-from-flag", nil},
+		{
+			"credential from stdin", "a-token\n", "a-secret-from-flag",
+			git.Credentials{"github.com": "-", "gitlab.com": "other-token"}, "a-secret-from-flag",
+			git.Credentials{"github.com": "a-token", "gitlab.com": "other-token"},
+		},
+	}
+
+	for _, tt := range stdinTests {
+		t.Run(tt.name, func(rt *testing.T) {
+			defer stubStdin(tt.stdin)()
+			secret := tt.secret
+
+			err := ReadStdinSecrets(map[string]*string{"webhook-secret": &secret}, tt.creds)
+			if err != nil {
+				rt.Fatal(err)
+			}
+			if secret != tt.want {
+				rt.Errorf("secret got %q, want %q", secret, tt.want)
+			}
+			if diff := cmp.Diff(tt.wantCreds, tt.creds); diff != "" {
+				rt.Errorf("credentials failed:\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestReadStdinSecretsErrors(t *testing.T) {
+	errorTests := []struct {
+		name    string
+		stdin   string
+		creds   git.Credentials
+		wantErr string
+	}{
+		{"empty stdin", "\n", nil, "failed to read --webhook-secret from stdin: no value was provided"},
+		{"multiple secrets", "a-secret", git.Credentials{"github.com": "-"}, `only one secret can be read from stdin, got "-" for --credential github.com, --webhook-secret`},
+	}
+
+	for _, tt := range errorTests {
+		t.Run(tt.name, func(rt *testing.T) {
+			defer stubStdin(tt.stdin)()
+			secret := StdinValue
+
+			err := ReadStdinSecrets(map[string]*string{"webhook-secret": &secret}, tt.creds)
+			if err == nil || err.Error() != tt.wantErr {
+				rt.Fatalf("got error %v, want %s", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func stubStdin(s string) func() {
+	orig := Stdin
+	Stdin = strings.NewReader(s)
+	return func() {
+		Stdin = orig
+	}
+}
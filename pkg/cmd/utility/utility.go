@@ -1,29 +1,32 @@
 package utility
 
 import (
-	"strings"
+	"fmt"
+	"sort"
 
-	"github.com/openshift/odo/pkg/log"
 	operatorsclientset "github.com/operator-framework/operator-lifecycle-manager/pkg/api/client/clientset/versioned/typed/operators/v1alpha1"
 	"github.com/rhd-gitops-example/gitops-cli/pkg/pipelines/clientconfig"
+	"github.com/rhd-gitops-example/gitops-cli/pkg/pipelines/git"
+	"github.com/rhd-gitops-example/gitops-cli/pkg/pipelines/namespaces"
+	"github.com/rhd-gitops-example/gitops-cli/pkg/pipelines/network"
+	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/errors"
 	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/validation"
 	"k8s.io/client-go/kubernetes"
 )
 
 const (
-	argocdCRD = "argocds.argoproj.io"
+	argocdCRD                = "argocds.argoproj.io"
+	sealedSecretsServiceName = "sealed-secrets-controller"
 )
 
-// AddGitSuffixIfNecessary will append .git to URL if necessary
+// AddGitSuffixIfNecessary will append .git to URL if necessary, see
+// git.AddGitSuffixIfNecessary.
 func AddGitSuffixIfNecessary(url string) string {
-	if url == "" || strings.HasSuffix(strings.ToLower(url), ".git") {
-		return url
-	}
-	log.Infof("Adding .git to %s", url)
-	return url + ".git"
+	return git.AddGitSuffixIfNecessary(url)
 }
 
 // RemoveEmptyStrings returns a slice with all the empty strings removed from the
@@ -39,12 +42,44 @@ func RemoveEmptyStrings(s []string) []string {
 }
 
 // MaybeCompletePrefix adds a hyphen on the end of the prefix if it doesn't have
-// one to make prefix-generated names look a bit nicer.
+// one, see namespaces.MaybeCompletePrefix.
 func MaybeCompletePrefix(s string) string {
-	if s != "" && !strings.HasSuffix(s, "-") {
-		return s + "-"
-	}
-	return s
+	return namespaces.MaybeCompletePrefix(s)
+}
+
+// MaxNameLength is set from the --max-name-length flag, it's the length limit
+// for names and namespaces, by default the 63 character limit for a DNS-1123
+// label.
+//
+// Kubernetes rejects namespaces and labels that are longer than 63 characters,
+// so it should only be increased when the names are used by destinations that
+// accept longer identifiers, e.g. custom controllers or Argo CD destinations
+// that aren't Kubernetes clusters.
+//
+// The generation code in the pipelines package doesn't read this, commands
+// pass it in their options.
+var MaxNameLength = validation.DNS1123LabelMaxLength
+
+// EnvironmentNamespace returns the namespace name for an environment, truncated
+// to the MaxNameLength, see namespaces.EnvironmentNamespace.
+func EnvironmentNamespace(prefix, envName string) string {
+	return namespaces.EnvironmentNamespace(prefix, envName, MaxNameLength)
+}
+
+// SuggestName returns a valid name derived from the name, truncated to the
+// MaxNameLength, see namespaces.SuggestName.
+func SuggestName(name string) string {
+	return namespaces.SuggestName(name, MaxNameLength)
+}
+
+// DefaultNamespacePattern is the pattern for environment namespaces that
+// produces the EnvironmentNamespace.
+const DefaultNamespacePattern = namespaces.DefaultNamespacePattern
+
+// NamespaceFromPattern renders the namespace for an environment from the
+// pattern, see namespaces.NamespaceFromPattern.
+func NamespaceFromPattern(pattern, prefix, envName string) (string, error) {
+	return namespaces.NamespaceFromPattern(pattern, prefix, envName, MaxNameLength)
 }
 
 // Client represents a client for K8s
@@ -79,6 +114,28 @@ func (c *Client) CheckIfSealedSecretsExists(secret types.NamespacedName) error {
 	return nil
 }
 
+// FindSealedSecretsServices returns the Sealed Secrets controller services in
+// all namespaces, sorted by namespace and name.
+//
+// Services are recognised by the name used by the controller manifests, or
+// the labels applied by the manifests and the Helm chart.
+func (c *Client) FindSealedSecretsServices() ([]types.NamespacedName, error) {
+	services, err := c.KubeClient.CoreV1().Services("").List(v1.ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+	found := []types.NamespacedName{}
+	for _, svc := range services.Items {
+		if svc.Name == sealedSecretsServiceName || svc.Labels["name"] == sealedSecretsServiceName || svc.Labels["app.kubernetes.io/name"] == "sealed-secrets" {
+			found = append(found, types.NamespacedName{Namespace: svc.Namespace, Name: svc.Name})
+		}
+	}
+	sort.Slice(found, func(i, j int) bool {
+		return found[i].String() < found[j].String()
+	})
+	return found, nil
+}
+
 // CheckIfArgoCDExists checks if ArgoCD operator is installed
 func (c *Client) CheckIfArgoCDExists(ns string) error {
 	csvList, err := c.OperatorClient.ClusterServiceVersions(ns).List(v1.ListOptions{})
@@ -102,6 +159,35 @@ func (c *Client) CheckIfPipelinesExists(ns string) error {
 	return nil
 }
 
+// CheckSecretNamespace checks that the namespace that secrets are sealed for
+// exists, strict-scope sealed secrets can only be unsealed in that namespace,
+// so they can't be unsealed until it's created.
+//
+// If the namespace doesn't exist, it's created if create is true, otherwise a
+// warning is written with warnf. The check is skipped if the network is
+// disabled.
+func (c *Client) CheckSecretNamespace(ns string, create bool, warnf func(string, ...interface{})) error {
+	if network.Disabled {
+		return nil
+	}
+	_, err := c.KubeClient.CoreV1().Namespaces().Get(ns, v1.GetOptions{})
+	if err == nil {
+		return nil
+	}
+	if !errors.IsNotFound(err) {
+		return fmt.Errorf("failed to check namespace %s exists: %w", ns, err)
+	}
+	if !create {
+		warnf("Namespace %s does not exist, secrets sealed for it can't be unsealed until it's created, use --create-namespace to create it", ns)
+		return nil
+	}
+	_, err = c.KubeClient.CoreV1().Namespaces().Create(&corev1.Namespace{ObjectMeta: v1.ObjectMeta{Name: ns}})
+	if err != nil {
+		return fmt.Errorf("failed to create namespace %s: %w", ns, err)
+	}
+	return nil
+}
+
 // GetFullName generates a command's full name based on its parent's full name and its own name
 func GetFullName(parentName, name string) string {
 	return parentName + " " + name
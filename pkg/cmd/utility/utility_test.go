@@ -1,41 +1,24 @@
 package utility
 
 import (
+	"fmt"
 	"testing"
 
 	"github.com/google/go-cmp/cmp"
 	v1alpha1 "github.com/operator-framework/operator-lifecycle-manager/pkg/api/apis/operators/v1alpha1"
 	operatorsfake "github.com/operator-framework/operator-lifecycle-manager/pkg/api/client/clientset/versioned/fake"
+	"github.com/rhd-gitops-example/gitops-cli/pkg/pipelines/network"
+	"github.com/spf13/afero"
+	"github.com/spf13/cobra"
 	appsv1 "k8s.io/api/apps/v1"
 	v1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
 
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/client-go/kubernetes/fake"
 )
 
-func TestAddGitSuffix(t *testing.T) {
-	addSuffixTests := []struct {
-		name string
-		url  string
-		want string
-	}{
-		{"missing git suffix", "https://github.com/test/org", "https://github.com/test/org.git"},
-		{"suffix for empty string", "", ""},
-		{"suffix already present", "https://github.com/test/org.git", "https://github.com/test/org.git"},
-		{"suffix with a different case", "https://github.com/test/org.GIT", "https://github.com/test/org.GIT"},
-	}
-
-	for _, tt := range addSuffixTests {
-		t.Run(tt.name, func(rt *testing.T) {
-			got := AddGitSuffixIfNecessary(tt.url)
-			if tt.want != got {
-				rt.Fatalf("URL mismatch: got %s, want %s", got, tt.want)
-			}
-		})
-	}
-}
-
 func TestRemoveEmptyStrings(t *testing.T) {
 	stringsTests := []struct {
 		name   string
@@ -57,26 +40,6 @@ func TestRemoveEmptyStrings(t *testing.T) {
 	}
 }
 
-func TestMaybeCompletePrefix(t *testing.T) {
-	stringsTests := []struct {
-		name   string
-		prefix string
-		want   string
-	}{
-		{"with dash on end", "testing-", "testing-"},
-		{"with no dash on end", "testing", "testing-"},
-	}
-
-	for _, tt := range stringsTests {
-		t.Run(tt.name, func(rt *testing.T) {
-			got := MaybeCompletePrefix(tt.prefix)
-			if tt.want != got {
-				rt.Fatalf("prefixing failed, got %#v, want %#v", got, tt.want)
-			}
-		})
-	}
-}
-
 func TestCheckIfSealedSecretsExists(t *testing.T) {
 	fakeClientSet := fake.NewSimpleClientset(&v1.Service{
 		ObjectMeta: metav1.ObjectMeta{
@@ -97,6 +60,38 @@ func TestCheckIfSealedSecretsExists(t *testing.T) {
 	}
 }
 
+func TestFindSealedSecretsServices(t *testing.T) {
+	fakeClientSet := fake.NewSimpleClientset(
+		&v1.Service{
+			ObjectMeta: metav1.ObjectMeta{Name: "sealed-secrets-controller", Namespace: "kube-system"},
+		},
+		&v1.Service{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "sealed-secrets",
+				Namespace: "cicd",
+				Labels:    map[string]string{"app.kubernetes.io/name": "sealed-secrets"},
+			},
+		},
+		&v1.Service{
+			ObjectMeta: metav1.ObjectMeta{Name: "argocd-server", Namespace: "argocd"},
+		},
+	)
+
+	fakeClient := Client{KubeClient: fakeClientSet}
+	services, err := fakeClient.FindSealedSecretsServices()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := []types.NamespacedName{
+		{Namespace: "cicd", Name: "sealed-secrets"},
+		{Namespace: "kube-system", Name: "sealed-secrets-controller"},
+	}
+	if diff := cmp.Diff(want, services); diff != "" {
+		t.Fatalf("FindSealedSecretsServices() failed:\n%s", diff)
+	}
+}
+
 func TestCheckIfArgoCDExists(t *testing.T) {
 	operatorClient := operatorsfake.NewSimpleClientset(&v1alpha1.ClusterServiceVersion{
 		ObjectMeta: metav1.ObjectMeta{
@@ -146,3 +141,99 @@ func TestCheckIfPipelinesExists(t *testing.T) {
 		t.Fatalf("CheckIfPipelinesExists failed: got %v,want %v", nil, wantErr)
 	}
 }
+
+func TestCheckSecretNamespace(t *testing.T) {
+	nsTests := []struct {
+		name         string
+		existing     []runtime.Object
+		create       bool
+		wantWarnings []string
+		wantCreated  bool
+	}{
+		{"namespace exists", []runtime.Object{&v1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "cicd"}}}, false, nil, true},
+		{
+			"missing namespace", nil, false,
+			[]string{"Namespace cicd does not exist, secrets sealed for it can't be unsealed until it's created, use --create-namespace to create it"},
+			false,
+		},
+		{"missing namespace is created", nil, true, nil, true},
+	}
+
+	for _, tt := range nsTests {
+		t.Run(tt.name, func(rt *testing.T) {
+			fakeClient := Client{KubeClient: fake.NewSimpleClientset(tt.existing...)}
+			var warnings []string
+			warnf := func(format string, a ...interface{}) {
+				warnings = append(warnings, fmt.Sprintf(format, a...))
+			}
+
+			if err := fakeClient.CheckSecretNamespace("cicd", tt.create, warnf); err != nil {
+				rt.Fatal(err)
+			}
+			if diff := cmp.Diff(tt.wantWarnings, warnings); diff != "" {
+				rt.Errorf("warnings failed:\n%s", diff)
+			}
+			_, err := fakeClient.KubeClient.CoreV1().Namespaces().Get("cicd", metav1.GetOptions{})
+			if created := err == nil; created != tt.wantCreated {
+				rt.Errorf("namespace exists got %v, want %v", created, tt.wantCreated)
+			}
+		})
+	}
+}
+
+func TestCheckSecretNamespaceWithNetworkDisabled(t *testing.T) {
+	defer func(d bool) {
+		network.Disabled = d
+	}(network.Disabled)
+	network.Disabled = true
+	fakeClient := Client{KubeClient: fake.NewSimpleClientset()}
+	warnf := func(format string, a ...interface{}) {
+		t.Errorf("unexpected warning: "+format, a...)
+	}
+
+	if err := fakeClient.CheckSecretNamespace("cicd", true, warnf); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := fakeClient.KubeClient.CoreV1().Namespaces().Get("cicd", metav1.GetOptions{}); err == nil {
+		t.Fatal("namespace was created with the network disabled")
+	}
+}
+
+func TestDetectRepoRoot(t *testing.T) {
+	fs := afero.NewMemMapFs()
+	for _, d := range []string{"/repo/.git", "/repo/gitops/environments/dev/env"} {
+		if err := fs.MkdirAll(d, 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := afero.WriteFile(fs, "/repo/gitops/pipelines.yaml", []byte("environments:"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	var pipelinesFolder, output, format string
+	cmd := &cobra.Command{}
+	cmd.Flags().StringVar(&pipelinesFolder, "pipelines-folder", ".", "")
+	cmd.Flags().StringVar(&output, "output", ".", "")
+	if err := cmd.Flags().Set("output", "/tmp/out"); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := DetectRepoRoot(fs, "/repo/gitops/environments/dev/env", cmd); err != nil {
+		t.Fatal(err)
+	}
+
+	if pipelinesFolder != "/repo/gitops" {
+		t.Errorf("got --pipelines-folder %q, want %q", pipelinesFolder, "/repo/gitops")
+	}
+	if output != "/tmp/out" {
+		t.Errorf("got --output %q, want the provided %q", output, "/tmp/out")
+	}
+
+	formatCmd := &cobra.Command{}
+	formatCmd.Flags().StringVar(&format, "output", "", "")
+	if err := DetectRepoRoot(fs, "/repo/gitops", formatCmd); err != nil {
+		t.Fatal(err)
+	}
+	if format != "" {
+		t.Errorf("got --output %q for a format flag, want it unchanged", format)
+	}
+}
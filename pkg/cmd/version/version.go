@@ -1,7 +1,9 @@
 package version
 
 import (
+	"encoding/json"
 	"fmt"
+	"io"
 
 	"github.com/spf13/cobra"
 )
@@ -9,17 +11,73 @@ import (
 // RecommendedCommandName is the recommended command name.
 const RecommendedCommandName = "version"
 
-// Version is populated by the versioning information at compile time.  See the VERSION marco in Makefile.
-var Version string
+// These are populated by the versioning information at compile time.  See the
+// LD_FLAGS macro in the Makefile.
+var (
+	// Version is the semantic version of the build.
+	Version string
+	// Commit is the git commit the binary was built from.
+	Commit string
+	// BuildDate is the date the binary was built in RFC3339 format.
+	BuildDate string
+)
+
+// Info is the build metadata reported by the version command.
+type Info struct {
+	Version   string `json:"version"`
+	Commit    string `json:"commit"`
+	BuildDate string `json:"buildDate"`
+}
+
+// Get returns the build metadata for the running binary.
+func Get() Info {
+	return Info{
+		Version:   valueOrUnknown(Version),
+		Commit:    valueOrUnknown(Commit),
+		BuildDate: valueOrUnknown(BuildDate),
+	}
+}
+
+// String returns the human readable form of the build metadata.
+func (i Info) String() string {
+	return fmt.Sprintf("gitops version %s (commit: %s, built: %s)", i.Version, i.Commit, i.BuildDate)
+}
 
 // NewCmd creates a new command
 func NewCmd(name, fullName string) *cobra.Command {
-	return &cobra.Command{
+	var output string
+	command := &cobra.Command{
 		Use:   name,
 		Short: "Print the version information",
-		Long:  "Print the version information",
-		Run: func(cmd *cobra.Command, args []string) {
-			fmt.Printf("gitops version %s\n", Version)
+		Long:  "Print the version, git commit and build date of the CLI",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return printInfo(cmd.OutOrStdout(), Get(), output)
 		},
 	}
+	command.Flags().StringVarP(&output, "output", "o", "", "Output format, either empty for human readable output or json")
+	return command
+}
+
+func printInfo(out io.Writer, info Info, format string) error {
+	switch format {
+	case "":
+		_, err := fmt.Fprintln(out, info)
+		return err
+	case "json":
+		b, err := json.MarshalIndent(info, "", "	")
+		if err != nil {
+			return fmt.Errorf("failed to marshal version information: %w", err)
+		}
+		_, err = fmt.Fprintf(out, "%s\n", b)
+		return err
+	}
+	return fmt.Errorf("unsupported output format %q, must be json", format)
+}
+
+func valueOrUnknown(s string) string {
+	if s == "" {
+		return "unknown"
+	}
+	return s
 }
@@ -0,0 +1,67 @@
+package version
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/spf13/cobra"
+)
+
+func TestVersionCommand(t *testing.T) {
+	defer stubVersion("v0.0.1", "abc1234", "2020-07-01T10:00:00Z")()
+
+	out, err := executeCommand(NewCmd("version", "gitops version"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := "gitops version v0.0.1 (commit: abc1234, built: 2020-07-01T10:00:00Z)\n"
+	if diff := cmp.Diff(want, out); diff != "" {
+		t.Fatalf("version output failed:\n%s", diff)
+	}
+}
+
+func TestVersionCommandJSON(t *testing.T) {
+	defer stubVersion("v0.0.1", "", "2020-07-01T10:00:00Z")()
+
+	out, err := executeCommand(NewCmd("version", "gitops version"), "-o", "json")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var got Info
+	if err := json.Unmarshal([]byte(out), &got); err != nil {
+		t.Fatal(err)
+	}
+	want := Info{Version: "v0.0.1", Commit: "unknown", BuildDate: "2020-07-01T10:00:00Z"}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Fatalf("version json output failed:\n%s", diff)
+	}
+}
+
+func TestVersionCommandUnknownFormat(t *testing.T) {
+	_, err := executeCommand(NewCmd("version", "gitops version"), "-o", "yaml")
+
+	want := `unsupported output format "yaml", must be json`
+	if err == nil || err.Error() != want {
+		t.Fatalf("got error %v, want %s", err, want)
+	}
+}
+
+func executeCommand(cmd *cobra.Command, args ...string) (string, error) {
+	buf := new(bytes.Buffer)
+	cmd.SetOutput(buf)
+	cmd.SetArgs(args)
+	err := cmd.Execute()
+	return buf.String(), err
+}
+
+func stubVersion(v, commit, date string) func() {
+	origVersion, origCommit, origDate := Version, Commit, BuildDate
+	Version, Commit, BuildDate = v, commit, date
+	return func() {
+		Version, Commit, BuildDate = origVersion, origCommit, origDate
+	}
+}
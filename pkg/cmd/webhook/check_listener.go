@@ -0,0 +1,81 @@
+package webhook
+
+import (
+	"fmt"
+
+	"github.com/openshift/odo/pkg/log"
+	"github.com/spf13/cobra"
+
+	"github.com/rhd-gitops-example/gitops-cli/pkg/cmd/genericclioptions"
+	backend "github.com/rhd-gitops-example/gitops-cli/pkg/pipelines/webhook"
+	ktemplates "k8s.io/kubectl/pkg/util/templates"
+)
+
+const checkListenerRecommendedCommandName = "check-listener"
+
+var (
+	checkListenerExample = ktemplates.Examples(`	# Check that the EventListener route can be reached
+	%[1]s --url https://gitops-webhook-event-listener-route-cicd.apps.example.com`)
+)
+
+// checkListener is a var so that it can be replaced in tests.
+var checkListener = backend.CheckListener
+
+type checkListenerOptions struct {
+	listenerURL string
+	caFile      string
+}
+
+// Complete completes checkListenerOptions after they've been created
+func (o *checkListenerOptions) Complete(name string, cmd *cobra.Command, args []string) error {
+	return nil
+}
+
+// Validate validates the checkListenerOptions based on completed values
+func (o *checkListenerOptions) Validate() error {
+	return nil
+}
+
+// Run contains the logic for the odo command
+func (o *checkListenerOptions) Run() error {
+	report, err := checkListener(o.listenerURL, o.caFile)
+	if err != nil {
+		return err
+	}
+	if log.IsJSON() {
+		outputSuccess(report)
+	} else {
+		log.Successf("The EventListener at %s is reachable", report.URL)
+		switch {
+		case !report.TLS:
+			warningf("The EventListener is not served over HTTPS, webhook payloads are sent unencrypted")
+		case report.TLSError != "":
+			warningf("The EventListener certificate is not trusted, Git hosting services may refuse to deliver webhooks: %s", report.TLSError)
+		default:
+			log.Successf("The EventListener certificate is trusted")
+		}
+	}
+	if report.StatusCode >= 400 {
+		return fmt.Errorf("the EventListener at %s responded with %s, check that the EventListener is running", report.URL, report.Status)
+	}
+	log.Successf("The EventListener responded with %s", report.Status)
+	return nil
+}
+
+func newCmdCheckListener(name, fullName string) *cobra.Command {
+	o := &checkListenerOptions{}
+	command := &cobra.Command{
+		Use:     name,
+		Short:   "Check that the EventListener can be reached.",
+		Long:    "Send a ping event to the EventListener URL, and report whether it's reachable, its certificate is trusted, and the status of the response.",
+		Example: fmt.Sprintf(checkListenerExample, fullName),
+		Run: func(cmd *cobra.Command, args []string) {
+			genericclioptions.GenericRun(o, cmd, args)
+		},
+	}
+
+	command.Flags().StringVar(&o.listenerURL, "url", "", "URL of the EventListener route that webhooks are delivered to")
+	_ = command.MarkFlagRequired("url")
+	command.Flags().StringVar(&o.caFile, "ca-file", "", "Path to a PEM file of additional certificate authorities to trust for the EventListener")
+	return command
+}
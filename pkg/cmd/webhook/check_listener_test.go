@@ -0,0 +1,62 @@
+package webhook
+
+import (
+	"fmt"
+	"testing"
+
+	backend "github.com/rhd-gitops-example/gitops-cli/pkg/pipelines/webhook"
+)
+
+func TestCheckListenerRun(t *testing.T) {
+	reportTests := []struct {
+		report       *backend.ListenerReport
+		wantWarnings []string
+		wantErr      string
+	}{
+		{
+			&backend.ListenerReport{URL: "https://listener.example.com", TLS: true, StatusCode: 202, Status: "202 Accepted"},
+			[]string{},
+			"",
+		},
+		{
+			&backend.ListenerReport{URL: "https://listener.example.com", TLS: true, TLSError: "x509: certificate signed by unknown authority", StatusCode: 202, Status: "202 Accepted"},
+			[]string{"The EventListener certificate is not trusted, Git hosting services may refuse to deliver webhooks: x509: certificate signed by unknown authority"},
+			"",
+		},
+		{
+			&backend.ListenerReport{URL: "http://listener.example.com", StatusCode: 503, Status: "503 Service Unavailable"},
+			[]string{"The EventListener is not served over HTTPS, webhook payloads are sent unencrypted"},
+			"the EventListener at http://listener.example.com responded with 503 Service Unavailable",
+		},
+	}
+
+	for i, tt := range reportTests {
+		t.Run(fmt.Sprintf("Test %d", i), func(rt *testing.T) {
+			defer stubCheckListener(func(listenerURL, caFile string) (*backend.ListenerReport, error) {
+				return tt.report, nil
+			})()
+			warnings := []string{}
+			defer stubWarningf(func(format string, a ...interface{}) {
+				warnings = append(warnings, fmt.Sprintf(format, a...))
+			})()
+			o := &checkListenerOptions{listenerURL: tt.report.URL}
+
+			err := o.Run()
+
+			if !matchError(rt, tt.wantErr, err) {
+				rt.Errorf("Run() failed to match error: got %v, want %s", err, tt.wantErr)
+			}
+			if fmt.Sprint(warnings) != fmt.Sprint(tt.wantWarnings) {
+				rt.Errorf("got warnings %v, want %v", warnings, tt.wantWarnings)
+			}
+		})
+	}
+}
+
+func stubCheckListener(f func(string, string) (*backend.ListenerReport, error)) func() {
+	orig := checkListener
+	checkListener = f
+	return func() {
+		checkListener = orig
+	}
+}
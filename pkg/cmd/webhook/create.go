@@ -3,13 +3,18 @@ package webhook
 import (
 	"encoding/json"
 	"fmt"
+	"io/ioutil"
 	"os"
+	"strings"
 	"text/tabwriter"
+	"time"
 
 	"github.com/openshift/odo/pkg/log"
 	"github.com/spf13/cobra"
 
 	"github.com/rhd-gitops-example/gitops-cli/pkg/cmd/genericclioptions"
+	"github.com/rhd-gitops-example/gitops-cli/pkg/cmd/ui"
+	"github.com/rhd-gitops-example/gitops-cli/pkg/pipelines/git"
 	backend "github.com/rhd-gitops-example/gitops-cli/pkg/pipelines/webhook"
 	ktemplates "k8s.io/kubectl/pkg/util/templates"
 )
@@ -18,16 +23,108 @@ const createRecommendedCommandName = "create"
 
 var (
 	createExample = ktemplates.Examples(`	# Create a new Git repository webhook 
-	%[1]s`)
+	%[1]s
+
+	# List the actions that would be taken to create the webhook
+	%[1]s --dry-run
+
+	# Create a GitLab webhook that only sends pushes to release branches, GitLab can't filter pushes by path
+	%[1]s --webhook-branch-filter 'release/*'
+
+	# Validate that a GitHub App, whose webhook delivers the events, is installed for the repository
+	%[1]s --github-app 1234 --github-app-private-key app.private-key.pem
+
+	# Create the missing webhooks for the repositories in the manifest, and delete the webhooks of a removed service
+	%[1]s --reconcile-webhooks --repo-url https://github.com/org/removed-service.git`)
+)
+
+// verifyTimeout is how long to wait for the Git hosting service to deliver
+// the ping to a new webhook.
+const verifyTimeout = 30 * time.Second
+
+// These are vars so that they can be replaced in tests.
+var (
+	createWebhook = backend.Create
+	validateApp   = backend.ValidateApp
+	verifyWebhook = backend.Verify
+	checkReach    = backend.CheckReach
+	planWebhook   = backend.Plan
+	reconcileAll  = backend.Reconcile
+	confirmDelete = ui.ConfirmSummary
+	warningf      = genericclioptions.Warningf
 )
 
 type createOptions struct {
 	options
+	branchFilter  string
+	contentType   string // The content type of the webhook's payloads, json or form.
+	verifyWebhook bool
+	dryRun        bool
+	appID         string // The ID of the GitHub App that delivers the events, rather than a webhook.
+	appKeyFile    string
+	reconcile     bool     // If true, the webhooks for all the repositories in the manifest are reconciled.
+	repoURLs      []string // Repositories that aren't in the manifest to delete the webhooks from when reconciling.
+	yes           bool     // If true, the deletions are made without confirmation.
+	strict        bool     // If true, the webhook isn't created if the EventListener is unlikely to be reachable.
+}
+
+// Validate validates the createOptions, a GitHub App has no per-repository
+// webhook to filter, verify or plan.
+func (o *createOptions) Validate() error {
+	if o.contentType != "" && !git.IsContentType(o.contentType) {
+		return fmt.Errorf("invalid webhook content type %q, must be one of %s", o.contentType, strings.Join(git.ContentTypes, ", "))
+	}
+	if o.reconcile {
+		return o.validateReconcile()
+	}
+	if len(o.repoURLs) > 0 || o.yes {
+		return fmt.Errorf("--repo-url and --yes can only be used with --reconcile-webhooks")
+	}
+	if err := o.options.Validate(); err != nil {
+		return err
+	}
+	if o.appID == "" {
+		if o.appKeyFile != "" {
+			return fmt.Errorf("--github-app-private-key can only be used with --github-app")
+		}
+		return nil
+	}
+	if o.appKeyFile == "" {
+		return fmt.Errorf("--github-app-private-key must be provided with --github-app")
+	}
+	if o.branchFilter != "" || o.verifyWebhook || o.dryRun {
+		return fmt.Errorf("--github-app can't be used with --webhook-branch-filter, --verify-webhook or --dry-run")
+	}
+	return nil
+}
+
+// validateReconcile validates the createOptions for reconciling, the webhooks
+// for all the repositories are reconciled, so no repository can be selected.
+func (o *createOptions) validateReconcile() error {
+	if o.isCICD || o.serviceName != "" || o.envName != "" {
+		return fmt.Errorf("--reconcile-webhooks can't be used with 'cicd' or 'env-name/service-name'")
+	}
+	if o.appID != "" || o.branchFilter != "" || o.verifyWebhook || o.dryRun {
+		return fmt.Errorf("--reconcile-webhooks can't be used with --github-app, --webhook-branch-filter, --verify-webhook or --dry-run")
+	}
+	return nil
 }
 
 // Run contains the logic for the odo command
 func (o *createOptions) Run() error {
-	id, err := backend.Create(o.accessToken, o.pipelinesFolderPath, o.getAppServiceNames(), o.isCICD)
+	if o.reconcile {
+		return o.reconcileWebhooks()
+	}
+	if o.appID != "" {
+		return o.validateApp()
+	}
+	if err := o.checkReach(); err != nil {
+		return err
+	}
+	if o.dryRun {
+		return o.plan()
+	}
+	id, err := createWebhook(o.accessToken, o.credentials, o.manifestPath(), o.getAppServiceNames(), o.isCICD, o.branchFilter, o.contentType)
 
 	if err != nil {
 		return fmt.Errorf("Unable to create webhook: %v", err)
@@ -43,11 +140,111 @@ func (o *createOptions) Run() error {
 			fmt.Fprintln(w, id)
 			w.Flush()
 		}
+		if o.verifyWebhook {
+			o.verify(id)
+		}
+	}
+
+	return nil
+}
+
+// reconcileWebhooks creates the missing webhooks, and deletes the orphaned
+// webhooks once the deletions are confirmed.
+func (o *createOptions) reconcileWebhooks() error {
+	confirm := confirmDelete
+	if o.yes {
+		confirm = func([]string) bool { return true }
+	}
+	result, err := reconcileAll(o.accessToken, o.credentials, o.manifestPath(), o.repoURLs, confirm)
+	if result != nil {
+		if log.IsJSON() {
+			outputSuccess(result)
+		} else {
+			w := tabwriter.NewWriter(os.Stdout, 5, 2, 3, ' ', tabwriter.TabIndent)
+			fmt.Fprintln(w, "CREATED ID")
+			fmt.Fprintln(w, "==========")
+			for _, id := range result.Created {
+				fmt.Fprintln(w, id)
+			}
+			fmt.Fprintln(w, "DELETED ID")
+			fmt.Fprintln(w, "==========")
+			for _, id := range result.Deleted {
+				fmt.Fprintln(w, id)
+			}
+			w.Flush()
+		}
+	}
+	if err != nil {
+		return fmt.Errorf("Unable to reconcile webhooks: %v", err)
+	}
+	return nil
+}
+
+// checkReach warns if the Git hosting service is unlikely to be able to
+// deliver webhooks to the EventListener, with --strict, it fails instead.
+func (o *createOptions) checkReach() error {
+	err := checkReach(o.manifestPath(), o.getAppServiceNames(), o.isCICD)
+	if err == nil {
+		return nil
+	}
+	if o.strict {
+		return fmt.Errorf("Unable to create webhook: %v", err)
+	}
+	warningf("The webhook may not be delivered: %v", err)
+	return nil
+}
+
+// plan outputs the actions that would be taken to create the webhook.
+func (o *createOptions) plan() error {
+	actions, err := planWebhook(o.accessToken, o.credentials, o.manifestPath(), o.getAppServiceNames(), o.isCICD, o.branchFilter, o.contentType)
+	if err != nil {
+		return fmt.Errorf("Unable to plan webhook: %v", err)
+	}
+	if log.IsJSON() {
+		outputSuccess(actions)
+		return nil
 	}
+	fmt.Fprintln(log.GetStdout(), "Planned actions:")
+	for _, a := range actions {
+		fmt.Fprintf(log.GetStdout(), "  %s\n", a)
+	}
+	return nil
+}
 
+// validateApp validates that the GitHub App is installed for the repository,
+// rather than creating a webhook on it.
+func (o *createOptions) validateApp() error {
+	key, err := ioutil.ReadFile(o.appKeyFile)
+	if err != nil {
+		return fmt.Errorf("failed to read the GitHub App private key: %v", err)
+	}
+	app, err := git.NewGitHubApp(o.appID, key)
+	if err != nil {
+		return err
+	}
+	listenerURL, err := validateApp(app, o.manifestPath(), o.getAppServiceNames(), o.isCICD)
+	if err != nil {
+		return fmt.Errorf("Unable to validate GitHub App: %v", err)
+	}
+	if log.IsJSON() {
+		outputSuccess(listenerURL)
+		return nil
+	}
+	log.Successf("GitHub App %s is installed for the repository, no webhook was created, the app's webhook must deliver to %s", o.appID, listenerURL)
 	return nil
 }
 
+// verify reports whether the ping to the webhook was delivered, a failed
+// delivery is only a warning as the webhook has been created.
+func (o *createOptions) verify(id string) {
+	err := verifyWebhook(o.accessToken, o.credentials, o.manifestPath(), o.getAppServiceNames(), o.isCICD, id, verifyTimeout)
+	if err != nil {
+		warningf("Unable to verify webhook %s, check that the EventListener route is reachable from the Git hosting service: %v", id, err)
+		return
+	}
+	log.Successf("Webhook %s ping was delivered successfully", id)
+}
+
 func newCmdCreate(name, fullName string) *cobra.Command {
 	o := &createOptions{}
 	command := &cobra.Command{
@@ -55,12 +252,28 @@ func newCmdCreate(name, fullName string) *cobra.Command {
 		Short:   "Create a new webhook.",
 		Long:    "Create a new Git repository webhook that triggers CI/CD pipeline runs.",
 		Example: fmt.Sprintf(createExample, fullName),
+		PreRun: func(cmd *cobra.Command, args []string) {
+			// The app's installation token is used rather than an access token.
+			if o.appID != "" {
+				_ = cmd.Flags().SetAnnotation("access-token", cobra.BashCompOneRequiredFlag, []string{"false"})
+			}
+		},
 		Run: func(cmd *cobra.Command, args []string) {
 			genericclioptions.GenericRun(o, cmd, args)
 		},
 	}
 
 	o.setFlags(command)
+	command.Flags().BoolVar(&o.verifyWebhook, "verify-webhook", false, "Wait for the Git hosting service to deliver a ping to the new webhook, and warn if the delivery failed, only supported for GitHub repositories")
+	command.Flags().BoolVar(&o.dryRun, "dry-run", false, "List the actions that would be taken to create the webhook, without creating it")
+	command.Flags().StringVar(&o.branchFilter, "webhook-branch-filter", "", "Only send push events for branches matching this filter e.g. release/*, only supported for GitLab repositories, GitLab webhooks can filter pushes by branch, but not by the paths that they change")
+	command.Flags().StringVar(&o.contentType, "webhook-content-type", git.JSONContentType, fmt.Sprintf("Content type of the webhook's payloads, one of %s, the EventListener expects json, form is only supported for GitHub repositories", strings.Join(git.ContentTypes, ", ")))
+	command.Flags().StringVar(&o.appID, "github-app", "", "ID of a GitHub App whose webhook delivers the events for the repository, the app's installation is validated rather than creating a webhook, and no access-token is needed")
+	command.Flags().StringVar(&o.appKeyFile, "github-app-private-key", "", "Path to the private key of the GitHub App, used to authenticate as the app")
+	command.Flags().BoolVar(&o.reconcile, "reconcile-webhooks", false, "Create the missing webhooks for the GitOps and service repositories in the manifest, and delete the other webhooks that deliver to the EventListener, the deletions must be confirmed")
+	command.Flags().StringArrayVar(&o.repoURLs, "repo-url", nil, "Repository that's no longer in the manifest to delete the webhooks that deliver to the EventListener from when reconciling, can be repeated")
+	command.Flags().BoolVarP(&o.yes, "yes", "y", false, "Delete the webhooks when reconciling without confirmation")
+	command.Flags().BoolVar(&o.strict, "strict", false, "Fail rather than warn if the repository is on a SaaS Git hosting service but the EventListener resolves to a private address")
 	return command
 }
 
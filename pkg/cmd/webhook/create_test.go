@@ -2,11 +2,22 @@ package webhook
 
 import (
 	"bytes"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
 	"fmt"
+	"io/ioutil"
+	"os"
 	"regexp"
 	"testing"
+	"time"
 
 	"github.com/spf13/cobra"
+
+	"github.com/rhd-gitops-example/gitops-cli/pkg/pipelines/git"
+	backend "github.com/rhd-gitops-example/gitops-cli/pkg/pipelines/webhook"
 )
 
 type keyValuePair struct {
@@ -47,46 +58,112 @@ func TestValidateForCreate(t *testing.T) {
 	}{
 		{
 			&createOptions{
-				options{isCICD: true, serviceName: "foo"},
+				options: options{isCICD: true, serviceName: "foo"},
 			},
 			"Only one of 'cicd' or 'env-name/service-name' can be specified",
 		},
 		{
 			&createOptions{
-				options{isCICD: true, envName: "foo"},
+				options: options{isCICD: true, envName: "foo"},
 			},
 			"Only one of 'cicd' or 'env-name/service-name' can be specified",
 		},
 		{
 			&createOptions{
-				options{isCICD: true, envName: "foo", serviceName: "bar"},
+				options: options{isCICD: true, envName: "foo", serviceName: "bar"},
 			},
 			"Only one of 'cicd' or 'env-name/service-name' can be specified",
 		},
 		{
 			&createOptions{
-				options{isCICD: false},
+				options: options{isCICD: false},
 			},
 			"One of 'cicd' or 'env-name/service-name' must be specified",
 		},
 		{
 			&createOptions{
-				options{isCICD: false, serviceName: "foo"},
+				options: options{isCICD: false, serviceName: "foo"},
 			},
 			"One of 'cicd' or 'env-name/service-name' must be specified",
 		},
 		{
 			&createOptions{
-				options{isCICD: false, serviceName: "foo", envName: "gau"},
+				options: options{isCICD: false, serviceName: "foo", envName: "gau"},
 			},
 			"",
 		},
 		{
 			&createOptions{
-				options{isCICD: true, serviceName: ""},
+				options: options{isCICD: true, serviceName: ""},
 			},
 			"",
 		},
+		{
+			&createOptions{
+				options:     options{isCICD: true},
+				contentType: "xml",
+			},
+			`invalid webhook content type "xml", must be one of json, form`,
+		},
+		{
+			&createOptions{
+				options: options{isCICD: true},
+				appID:   "1234",
+			},
+			"--github-app-private-key must be provided with --github-app",
+		},
+		{
+			&createOptions{
+				options:    options{isCICD: true},
+				appKeyFile: "app.pem",
+			},
+			"--github-app-private-key can only be used with --github-app",
+		},
+		{
+			&createOptions{
+				options:      options{isCICD: true},
+				appID:        "1234",
+				appKeyFile:   "app.pem",
+				branchFilter: "release/*",
+			},
+			"--github-app can't be used with --webhook-branch-filter, --verify-webhook or --dry-run",
+		},
+		{
+			&createOptions{
+				options:    options{isCICD: true},
+				appID:      "1234",
+				appKeyFile: "app.pem",
+			},
+			"",
+		},
+		{
+			&createOptions{
+				reconcile: true,
+				repoURLs:  []string{"https://github.com/org/removed.git"},
+			},
+			"",
+		},
+		{
+			&createOptions{
+				options:   options{isCICD: true},
+				reconcile: true,
+			},
+			"--reconcile-webhooks can't be used with 'cicd' or 'env-name/service-name'",
+		},
+		{
+			&createOptions{
+				reconcile: true,
+				dryRun:    true,
+			},
+			"--reconcile-webhooks can't be used with --github-app, --webhook-branch-filter, --verify-webhook or --dry-run",
+		},
+		{
+			&createOptions{
+				options:  options{isCICD: true},
+				repoURLs: []string{"https://github.com/org/removed.git"},
+			},
+			"--repo-url and --yes can only be used with --reconcile-webhooks",
+		},
 	}
 
 	for i, tt := range testcases {
@@ -103,6 +180,145 @@ func TestValidateForCreate(t *testing.T) {
 	}
 }
 
+func TestCreateWithGitHubAppDoesNotCreateWebhook(t *testing.T) {
+	keyFile := writeAppKey(t)
+	defer os.Remove(keyFile)
+	defer stubCreateWebhook(func(accessToken string, credentials git.Credentials, pipelinesFile string, serviceName *backend.QualifiedServiceName, isCICD bool, branchFilter, contentType string) (string, error) {
+		t.Fatal("a webhook was created for the repository in GitHub App mode")
+		return "", nil
+	})()
+	var validated *git.GitHubApp
+	defer stubValidateApp(func(app *git.GitHubApp, pipelinesFile string, serviceName *backend.QualifiedServiceName, isCICD bool) (string, error) {
+		validated = app
+		return "https://gitops-webhook-event-listener-route-cicd.example.com", nil
+	})()
+	o := &createOptions{options: options{isCICD: true}, appID: "1234", appKeyFile: keyFile}
+
+	if err := o.Run(); err != nil {
+		t.Fatal(err)
+	}
+
+	if validated == nil || validated.ID != "1234" {
+		t.Fatalf("got validated app %v, want app 1234", validated)
+	}
+}
+
+func TestVerifyWarnsOnFailedDelivery(t *testing.T) {
+	var verified string
+	defer stubVerifyWebhook(func(accessToken string, credentials git.Credentials, pipelinesFile string, serviceName *backend.QualifiedServiceName, isCICD bool, id string, timeout time.Duration) error {
+		verified = id
+		return errors.New("the last delivery to webhook 1 failed with status 503: Application is not available")
+	})()
+	warnings := []string{}
+	defer stubWarningf(func(format string, a ...interface{}) {
+		warnings = append(warnings, fmt.Sprintf(format, a...))
+	})()
+	o := &createOptions{options: options{isCICD: true}, verifyWebhook: true}
+
+	o.verify("1")
+
+	if verified != "1" {
+		t.Fatalf("got verified webhook %q, want %q", verified, "1")
+	}
+	if len(warnings) != 1 || !matchError(t, "Unable to verify webhook 1.*failed with status 503", errors.New(warnings[0])) {
+		t.Fatalf("got warnings %v, want a failed delivery warning", warnings)
+	}
+}
+
+func TestCreateWarnsOnUnreachableListener(t *testing.T) {
+	defer stubCheckReach(func(pipelinesFile string, serviceName *backend.QualifiedServiceName, isCICD bool) error {
+		return errors.New("the EventListener at https://listener.internal.example.com resolves to the private address 10.0.12.34, github.com is unlikely to be able to deliver webhooks to it")
+	})()
+	created := false
+	defer stubCreateWebhook(func(accessToken string, credentials git.Credentials, pipelinesFile string, serviceName *backend.QualifiedServiceName, isCICD bool, branchFilter, contentType string) (string, error) {
+		created = true
+		return "", nil
+	})()
+	warnings := []string{}
+	defer stubWarningf(func(format string, a ...interface{}) {
+		warnings = append(warnings, fmt.Sprintf(format, a...))
+	})()
+
+	o := &createOptions{options: options{isCICD: true}}
+	if err := o.Run(); err != nil {
+		t.Fatal(err)
+	}
+	if !created {
+		t.Fatal("no webhook was created")
+	}
+	if len(warnings) != 1 || !matchError(t, "private address 10.0.12.34, github.com is unlikely", errors.New(warnings[0])) {
+		t.Fatalf("got warnings %v, want an unreachable EventListener warning", warnings)
+	}
+
+	created = false
+	o.strict = true
+	err := o.Run()
+	if !matchError(t, "Unable to create webhook: .*private address 10.0.12.34", err) {
+		t.Fatalf("got error %v, want an unreachable EventListener error", err)
+	}
+	if created {
+		t.Fatal("a webhook was created with --strict")
+	}
+}
+
+func writeAppKey(t *testing.T) string {
+	t.Helper()
+	key, err := rsa.GenerateKey(rand.Reader, 1024)
+	if err != nil {
+		t.Fatal(err)
+	}
+	f, err := ioutil.TempFile("", "app-key")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	err = pem.Encode(f, &pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(key)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return f.Name()
+}
+
+func stubCreateWebhook(f func(string, git.Credentials, string, *backend.QualifiedServiceName, bool, string, string) (string, error)) func() {
+	orig := createWebhook
+	createWebhook = f
+	return func() {
+		createWebhook = orig
+	}
+}
+
+func stubValidateApp(f func(*git.GitHubApp, string, *backend.QualifiedServiceName, bool) (string, error)) func() {
+	orig := validateApp
+	validateApp = f
+	return func() {
+		validateApp = orig
+	}
+}
+
+func stubVerifyWebhook(f func(string, git.Credentials, string, *backend.QualifiedServiceName, bool, string, time.Duration) error) func() {
+	orig := verifyWebhook
+	verifyWebhook = f
+	return func() {
+		verifyWebhook = orig
+	}
+}
+
+func stubCheckReach(f func(string, *backend.QualifiedServiceName, bool) error) func() {
+	orig := checkReach
+	checkReach = f
+	return func() {
+		checkReach = orig
+	}
+}
+
+func stubWarningf(f func(string, ...interface{})) func() {
+	orig := warningf
+	warningf = f
+	return func() {
+		warningf = orig
+	}
+}
+
 func executeCommand(cmd *cobra.Command, flags ...keyValuePair) (c *cobra.Command, output string, err error) {
 	buf := new(bytes.Buffer)
 	cmd.SetOutput(buf)
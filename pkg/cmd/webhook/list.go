@@ -1,9 +1,11 @@
 package webhook
 
 import (
+	"errors"
 	"fmt"
 	"os"
 	"text/tabwriter"
+	"time"
 
 	"github.com/openshift/odo/pkg/log"
 	"github.com/spf13/cobra"
@@ -17,17 +19,45 @@ const listRecommendedCommandName = "list"
 
 var (
 	listExample = ktemplates.Examples(`	# List Git repository webhook IDs 
-	%[1]s`)
+	%[1]s
+
+	# List the webhooks whose most recent delivery failed in the last day
+	%[1]s --failing --since 24h`)
 )
 
+// listFailingWebhooks is used to list the webhooks with failed deliveries,
+// it's a var so that it can be replaced in tests.
+var listFailingWebhooks = backend.ListFailing
+
 type listOptions struct {
 	options
+	failing bool
+	since   time.Duration
+}
+
+// Validate validates the listOptions based on completed values
+func (o *listOptions) Validate() error {
+	if err := o.options.Validate(); err != nil {
+		return err
+	}
+	if o.since < 0 {
+		return fmt.Errorf("invalid --since %s, the duration must be positive", o.since)
+	}
+	if o.since != 0 && !o.failing {
+		return errors.New("--since can only be used with --failing")
+	}
+	return nil
 }
 
 // Run contains the logic for the odo command
 func (o *listOptions) Run() error {
-
-	ids, err := backend.List(o.accessToken, o.pipelinesFolderPath, o.getAppServiceNames(), o.isCICD)
+	var ids []string
+	var err error
+	if o.failing {
+		ids, err = listFailingWebhooks(o.accessToken, o.credentials, o.manifestPath(), o.getAppServiceNames(), o.isCICD, o.since)
+	} else {
+		ids, err = backend.List(o.accessToken, o.credentials, o.manifestPath(), o.getAppServiceNames(), o.isCICD)
+	}
 	if err != nil {
 		return fmt.Errorf("Unable to a get list of webhook IDs: %v", err)
 	}
@@ -63,5 +93,7 @@ func newCmdList(name, fullName string) *cobra.Command {
 	}
 
 	o.setFlags(command)
+	command.Flags().BoolVar(&o.failing, "failing", false, "Only list the webhooks whose most recent delivery failed, this is only supported for GitHub repositories")
+	command.Flags().DurationVar(&o.since, "since", 0, "With --failing, only list the webhooks whose most recent delivery was within this duration, e.g. 24h")
 	return command
 }
@@ -3,6 +3,10 @@ package webhook
 import (
 	"fmt"
 	"testing"
+	"time"
+
+	"github.com/rhd-gitops-example/gitops-cli/pkg/pipelines/git"
+	backend "github.com/rhd-gitops-example/gitops-cli/pkg/pipelines/webhook"
 )
 
 func TestMissingRequiredFlagsForList(t *testing.T) {
@@ -41,43 +45,66 @@ func TestValidateForList(t *testing.T) {
 	}{
 		{
 			&listOptions{
-				options{isCICD: true, serviceName: "foo"},
+				options: options{isCICD: true, serviceName: "foo"},
 			},
 			"Only one of 'cicd' or 'env-name/service-name' can be specified",
 		},
 		{
 			&listOptions{
-				options{isCICD: true, envName: "foo"},
+				options: options{isCICD: true, envName: "foo"},
 			},
 			"Only one of 'cicd' or 'env-name/service-name' can be specified",
 		},
 		{
 			&listOptions{
-				options{isCICD: true, envName: "foo", serviceName: "bar"},
+				options: options{isCICD: true, envName: "foo", serviceName: "bar"},
 			},
 			"Only one of 'cicd' or 'env-name/service-name' can be specified",
 		},
 		{
 			&listOptions{
-				options{isCICD: false},
+				options: options{isCICD: false},
 			},
 			"One of 'cicd' or 'env-name/service-name' must be specified",
 		},
 		{
 			&listOptions{
-				options{isCICD: false, serviceName: "foo"},
+				options: options{isCICD: false, serviceName: "foo"},
 			},
 			"One of 'cicd' or 'env-name/service-name' must be specified",
 		},
 		{
 			&listOptions{
-				options{isCICD: false, serviceName: "foo", envName: "gau"},
+				options: options{isCICD: false, serviceName: "foo", envName: "gau"},
 			},
 			"",
 		},
 		{
 			&listOptions{
-				options{isCICD: true, serviceName: ""},
+				options: options{isCICD: true, serviceName: ""},
+			},
+			"",
+		},
+		{
+			&listOptions{
+				options: options{isCICD: true},
+				since:   time.Hour,
+			},
+			"--since can only be used with --failing",
+		},
+		{
+			&listOptions{
+				options: options{isCICD: true},
+				failing: true,
+				since:   -time.Hour,
+			},
+			"invalid --since -1h0m0s, the duration must be positive",
+		},
+		{
+			&listOptions{
+				options: options{isCICD: true},
+				failing: true,
+				since:   time.Hour,
 			},
 			"",
 		},
@@ -98,3 +125,28 @@ func TestValidateForList(t *testing.T) {
 		})
 	}
 }
+
+func TestListRunWithFailing(t *testing.T) {
+	var gotSince time.Duration
+	defer stubListFailingWebhooks(func(accessToken string, credentials git.Credentials, pipelinesFile string, serviceName *backend.QualifiedServiceName, isCICD bool, since time.Duration) ([]string, error) {
+		gotSince = since
+		return []string{"2"}, nil
+	})()
+	o := &listOptions{options: options{isCICD: true}, failing: true, since: 24 * time.Hour}
+
+	if err := o.Run(); err != nil {
+		t.Fatal(err)
+	}
+
+	if gotSince != 24*time.Hour {
+		t.Fatalf("failing webhooks listed since %s, want 24h", gotSince)
+	}
+}
+
+func stubListFailingWebhooks(f func(string, git.Credentials, string, *backend.QualifiedServiceName, bool, time.Duration) ([]string, error)) func() {
+	orig := listFailingWebhooks
+	listFailingWebhooks = f
+	return func() {
+		listFailingWebhooks = orig
+	}
+}
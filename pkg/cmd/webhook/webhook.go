@@ -15,16 +15,18 @@ func NewCmdWebhook(name, fullName string) *cobra.Command {
 	createCmd := newCmdCreate(createRecommendedCommandName, utility.GetFullName(fullName, createRecommendedCommandName))
 	deleteCmd := newCmdDelete(deleteRecommendedCommandName, utility.GetFullName(fullName, deleteRecommendedCommandName))
 	listCmd := newCmdList(listRecommendedCommandName, utility.GetFullName(fullName, listRecommendedCommandName))
+	checkListenerCmd := newCmdCheckListener(checkListenerRecommendedCommandName, utility.GetFullName(fullName, checkListenerRecommendedCommandName))
 
 	var webhookCmd = &cobra.Command{
 		Use:   name,
 		Short: "Manage Git repository webhooks",
 		Long:  "Add/Delete/list Git repository webhooks that trigger CI/CD pipeline runs.",
-		Example: fmt.Sprintf("%s\n%s\n%s\n%s\n\n  See sub-commands individually for more examples",
+		Example: fmt.Sprintf("%s\n%s\n%s\n%s\n%s\n\n  See sub-commands individually for more examples",
 			fullName,
 			createRecommendedCommandName,
 			deleteRecommendedCommandName,
-			listRecommendedCommandName),
+			listRecommendedCommandName,
+			checkListenerRecommendedCommandName),
 		Run: func(cmd *cobra.Command, args []string) {
 		},
 	}
@@ -32,6 +34,7 @@ func NewCmdWebhook(name, fullName string) *cobra.Command {
 	webhookCmd.AddCommand(createCmd)
 	webhookCmd.AddCommand(deleteCmd)
 	webhookCmd.AddCommand(listCmd)
+	webhookCmd.AddCommand(checkListenerCmd)
 
 	webhookCmd.Annotations = map[string]string{"command": "main"}
 	// webhookCmd.SetUsageTemplate(odoutil.CmdUsageTemplate)
@@ -2,25 +2,34 @@ package webhook
 
 import (
 	"fmt"
+	"path/filepath"
 
 	"github.com/spf13/cobra"
 
+	"github.com/rhd-gitops-example/gitops-cli/pkg/cmd/ui"
+	"github.com/rhd-gitops-example/gitops-cli/pkg/cmd/utility"
+	"github.com/rhd-gitops-example/gitops-cli/pkg/pipelines/git"
 	backend "github.com/rhd-gitops-example/gitops-cli/pkg/pipelines/webhook"
 )
 
 type options struct {
 	accessToken         string
+	credentialValues    []string
+	credentials         git.Credentials
 	envName             string
 	isCICD              bool
 	pipelinesFolderPath string
+	manifestFile        string
 	serviceName         string
 }
 
 // Complete completes createOptions after they've been created
 func (o *options) Complete(name string, cmd *cobra.Command, args []string) (err error) {
-
-	return nil
-
+	o.credentials, err = git.ParseCredentials(o.credentialValues)
+	if err != nil {
+		return err
+	}
+	return utility.ReadStdinSecrets(map[string]*string{"access-token": &o.accessToken}, o.credentials)
 }
 
 // Validate validates the createOptions based on completed values
@@ -36,17 +45,19 @@ func (o *options) Validate() (err error) {
 		}
 	}
 
-	return nil
+	return ui.ValidateManifestFile(o.manifestFile, "pipelines folder")
 }
 
 func (o *options) setFlags(command *cobra.Command) {
 
 	// pipeline option
 	command.Flags().StringVar(&o.pipelinesFolderPath, "pipelines-folder", ".", "Folder path to retrieve manifest, eg. /test where manifest exists at /test/pipelines.yaml")
+	command.Flags().StringVar(&o.manifestFile, "manifest-file", "pipelines.yaml", "Path of the manifest file within the --pipelines-folder e.g. gitops/pipelines.yaml")
 
 	// access-token option
-	command.Flags().StringVar(&o.accessToken, "access-token", "", "Access token to be used to create Git repository webhook")
+	command.Flags().StringVar(&o.accessToken, "access-token", "", "Access token to be used to create Git repository webhook, or - to read it from stdin")
 	_ = command.MarkFlagRequired("access-token")
+	command.Flags().StringArrayVar(&o.credentialValues, "credential", nil, "Access token for a specific Git host in the form host=token, used instead of the access-token for repositories on that host, can be repeated, a token of - is read from stdin")
 
 	// cicd option
 	command.Flags().BoolVar(&o.isCICD, "cicd", false, "Provide this flag if the target Git repository is a CI/CD configuration repository")
@@ -64,3 +75,8 @@ func (o *options) getAppServiceNames() *backend.QualifiedServiceName {
 		ServiceName:     o.serviceName,
 	}
 }
+
+// manifestPath returns the path of the manifest file.
+func (o *options) manifestPath() string {
+	return filepath.Join(o.pipelinesFolderPath, o.manifestFile)
+}
@@ -0,0 +1,144 @@
+package argocd
+
+import (
+	"fmt"
+	"path/filepath"
+	"sort"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	argoappv1 "github.com/rhd-gitops-example/gitops-cli/pkg/pipelines/argocd/v1alpha1"
+	"github.com/rhd-gitops-example/gitops-cli/pkg/pipelines/config"
+	"github.com/rhd-gitops-example/gitops-cli/pkg/pipelines/meta"
+	res "github.com/rhd-gitops-example/gitops-cli/pkg/pipelines/resources"
+)
+
+const (
+	applicationSetName = "apps"
+	applicationSetFile = "apps-appset.yaml"
+)
+
+var applicationSetTypeMeta = meta.TypeMeta(
+	"ApplicationSet",
+	config.DefaultArgoCDAPIVersion,
+)
+
+// ApplicationSet generates Applications from a template, there are no
+// compatible upstream types, so only the fields we generate are defined here.
+type ApplicationSet struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata"`
+	Spec              ApplicationSetSpec `json:"spec"`
+}
+
+// ApplicationSetSpec is the specification of an ApplicationSet.
+type ApplicationSetSpec struct {
+	Generators []ApplicationSetGenerator `json:"generators"`
+	Template   ApplicationSetTemplate    `json:"template"`
+}
+
+// ApplicationSetGenerator provides the parameters for the template.
+type ApplicationSetGenerator struct {
+	List *ListGenerator `json:"list,omitempty"`
+}
+
+// ListGenerator generates parameters from a fixed list of elements.
+type ListGenerator struct {
+	Elements []map[string]string `json:"elements"`
+}
+
+// ApplicationSetTemplate is the template for the generated Applications.
+type ApplicationSetTemplate struct {
+	ApplicationSetTemplateMeta `json:"metadata"`
+	Spec                       argoappv1.ApplicationSpec `json:"spec"`
+}
+
+// ApplicationSetTemplateMeta is the metadata for the generated Applications.
+type ApplicationSetTemplateMeta struct {
+	Name string `json:"name"`
+}
+
+// BuildApplicationSet is like Build, but generates a single ApplicationSet
+// with an element for each environment and application, rather than an
+// Application per pair.
+func BuildApplicationSet(argoNS, repoURL string, m *config.Manifest) (res.Resources, error) {
+	// Without a RepositoryURL we can't do anything.
+	if repoURL == "" {
+		return res.Resources{}, nil
+	}
+	argoCDConfig := m.GetArgoCDConfig()
+	if argoCDConfig == nil {
+		return res.Resources{}, nil
+	}
+
+	// The template has a single sync policy for all the Applications.
+	for _, env := range m.Environments {
+		if env.SyncPolicy != "" {
+			return nil, fmt.Errorf("environment %s has a sync policy, which can't be generated in an ApplicationSet", env.Name)
+		}
+	}
+	eb := &argocdBuilder{repoURL: repoURL, gitOpsPath: m.GitOpsPath, files: res.Resources{}, argoCDConfig: argoCDConfig, argoNS: argoNS}
+	err := m.Walk(eb)
+	if err != nil {
+		return nil, err
+	}
+	files := res.Resources{}
+	if len(eb.files) > 0 {
+		files[filepath.Join(config.PathForArgoCD(), applicationSetFile)] = makeApplicationSet(argoCDConfig.GetAPIVersion(), applicationSetName, argoNS, eb.files)
+	}
+	err = argoCDConfigResources(m, files)
+	if err != nil {
+		return nil, err
+	}
+	return files, nil
+}
+
+// makeApplicationSet creates an ApplicationSet with a list generator element
+// for each of the Applications, the template reproduces the Applications.
+func makeApplicationSet(apiVersion, name, argoNS string, apps res.Resources) *ApplicationSet {
+	filenames := []string{}
+	for k := range apps {
+		filenames = append(filenames, k)
+	}
+	sort.Strings(filenames)
+
+	elements := []map[string]string{}
+	for _, k := range filenames {
+		app := apps[k].(*argoappv1.Application)
+		elements = append(elements, map[string]string{
+			"name":           app.Name,
+			"project":        app.Spec.Project,
+			"server":         app.Spec.Destination.Server,
+			"namespace":      app.Spec.Destination.Namespace,
+			"repoURL":        app.Spec.Source.RepoURL,
+			"path":           app.Spec.Source.Path,
+			"targetRevision": app.Spec.Source.TargetRevision,
+		})
+	}
+
+	return &ApplicationSet{
+		TypeMeta:   meta.TypeMeta(applicationSetTypeMeta.Kind, apiVersion),
+		ObjectMeta: meta.ObjectMeta(meta.NamespacedName(argoNS, name)),
+		Spec: ApplicationSetSpec{
+			Generators: []ApplicationSetGenerator{
+				{List: &ListGenerator{Elements: elements}},
+			},
+			Template: ApplicationSetTemplate{
+				ApplicationSetTemplateMeta: ApplicationSetTemplateMeta{Name: "{{name}}"},
+				Spec: argoappv1.ApplicationSpec{
+					Project: "{{project}}",
+					Destination: argoappv1.ApplicationDestination{
+						Namespace: "{{namespace}}",
+						Server:    "{{server}}",
+					},
+					Source: argoappv1.ApplicationSource{
+						RepoURL:        "{{repoURL}}",
+						Path:           "{{path}}",
+						TargetRevision: "{{targetRevision}}",
+					},
+					SyncPolicy: syncPolicy,
+				},
+			},
+		},
+	}
+}
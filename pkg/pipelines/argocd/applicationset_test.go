@@ -0,0 +1,98 @@
+package argocd
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	argoappv1 "github.com/rhd-gitops-example/gitops-cli/pkg/pipelines/argocd/v1alpha1"
+	"github.com/rhd-gitops-example/gitops-cli/pkg/pipelines/config"
+	"github.com/rhd-gitops-example/gitops-cli/pkg/pipelines/meta"
+	res "github.com/rhd-gitops-example/gitops-cli/pkg/pipelines/resources"
+)
+
+func TestBuildApplicationSetExpandsToApplications(t *testing.T) {
+	prodEnv := &config.Environment{
+		Name:    "test-production",
+		Cluster: "https://prod.example.com",
+		Apps:    []*config.Application{configRepoApp},
+	}
+	m := &config.Manifest{
+		Environments: []*config.Environment{testEnv, prodEnv},
+		Config: &config.Config{
+			ArgoCD: &config.ArgoCDConfig{Namespace: "argocd"},
+		},
+	}
+
+	files, err := BuildApplicationSet(ArgoCDNamespace, testRepoURL, m)
+	if err != nil {
+		t.Fatal(err)
+	}
+	apps, err := Build(ArgoCDNamespace, testRepoURL, m)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := map[string]*argoappv1.Application{}
+	for _, v := range apps {
+		if app, ok := v.(*argoappv1.Application); ok && strings.HasPrefix(app.Name, "test-") {
+			want[app.Name] = app
+		}
+	}
+	appSet := files["config/argocd/apps-appset.yaml"].(*ApplicationSet)
+	got := expandApplicationSet(t, appSet)
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Fatalf("expanded applications didn't match: %s\n", diff)
+	}
+
+	wantKustomization := &res.Kustomization{Resources: []string{"apps-appset.yaml", "argo-app.yaml", "argocd.yaml"}}
+	if diff := cmp.Diff(wantKustomization, files["config/argocd/kustomization.yaml"]); diff != "" {
+		t.Fatalf("kustomization didn't match: %s\n", diff)
+	}
+}
+
+func TestBuildApplicationSetWithNoRepoURL(t *testing.T) {
+	m := &config.Manifest{
+		Environments: []*config.Environment{testEnv},
+		Config: &config.Config{
+			ArgoCD: &config.ArgoCDConfig{Namespace: "argocd"},
+		},
+	}
+
+	files, err := BuildApplicationSet(ArgoCDNamespace, "", m)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff(res.Resources{}, files); diff != "" {
+		t.Fatalf("files didn't match: %s\n", diff)
+	}
+}
+
+// expandApplicationSet renders the template for each list element, in the
+// same way as the ApplicationSet controller.
+func expandApplicationSet(t *testing.T, appSet *ApplicationSet) map[string]*argoappv1.Application {
+	t.Helper()
+	b, err := json.Marshal(appSet.Spec.Template)
+	if err != nil {
+		t.Fatal(err)
+	}
+	apps := map[string]*argoappv1.Application{}
+	for _, element := range appSet.Spec.Generators[0].List.Elements {
+		rendered := string(b)
+		for k, v := range element {
+			rendered = strings.ReplaceAll(rendered, "{{"+k+"}}", v)
+		}
+		var template ApplicationSetTemplate
+		if err := json.Unmarshal([]byte(rendered), &template); err != nil {
+			t.Fatal(err)
+		}
+		apps[template.Name] = &argoappv1.Application{
+			TypeMeta:   applicationTypeMeta,
+			ObjectMeta: meta.ObjectMeta(meta.NamespacedName(appSet.Namespace, template.Name)),
+			Spec:       template.Spec,
+		}
+	}
+	return apps
+}
@@ -1,6 +1,7 @@
 package argocd
 
 import (
+	"fmt"
 	"path/filepath"
 	"sort"
 
@@ -12,6 +13,7 @@ import (
 	"sigs.k8s.io/yaml"
 
 	"github.com/rhd-gitops-example/gitops-cli/pkg/pipelines/config"
+	"github.com/rhd-gitops-example/gitops-cli/pkg/pipelines/helm"
 	"github.com/rhd-gitops-example/gitops-cli/pkg/pipelines/meta"
 	res "github.com/rhd-gitops-example/gitops-cli/pkg/pipelines/resources"
 )
@@ -19,7 +21,7 @@ import (
 var (
 	applicationTypeMeta = meta.TypeMeta(
 		"Application",
-		"argoproj.io/v1alpha1",
+		config.DefaultArgoCDAPIVersion,
 	)
 
 	syncPolicy = &argoappv1.SyncPolicy{
@@ -67,6 +69,17 @@ const (
 )
 
 func Build(argoNS, repoURL string, m *config.Manifest) (res.Resources, error) {
+	return build(argoNS, repoURL, m, false)
+}
+
+// BuildHelm is like Build, but the Applications deploy the Helm charts that
+// are generated by helm.Build for the applications, rather than the
+// kustomizations.
+func BuildHelm(argoNS, repoURL string, m *config.Manifest) (res.Resources, error) {
+	return build(argoNS, repoURL, m, true)
+}
+
+func build(argoNS, repoURL string, m *config.Manifest, helmCharts bool) (res.Resources, error) {
 	// Without a RepositoryURL we can't do anything.
 	if repoURL == "" {
 		return res.Resources{}, nil
@@ -77,12 +90,12 @@ func Build(argoNS, repoURL string, m *config.Manifest) (res.Resources, error) {
 	}
 
 	files := make(res.Resources)
-	eb := &argocdBuilder{repoURL: repoURL, files: files, argoCDConfig: argoCDConfig, argoNS: argoNS}
+	eb := &argocdBuilder{repoURL: repoURL, gitOpsPath: m.GitOpsPath, files: files, argoCDConfig: argoCDConfig, argoNS: argoNS, helmCharts: helmCharts}
 	err := m.Walk(eb)
 	if err != nil {
 		return nil, err
 	}
-	err = argoCDConfigResources(m.Config, m.GitOpsURL, eb.files)
+	err = argoCDConfigResources(m, eb.files)
 	if err != nil {
 		return nil, err
 	}
@@ -91,35 +104,51 @@ func Build(argoNS, repoURL string, m *config.Manifest) (res.Resources, error) {
 
 type argocdBuilder struct {
 	repoURL      string
+	gitOpsPath   string // The directory within the repoURL that the configuration is in.
 	argoCDConfig *config.ArgoCDConfig
 	files        res.Resources
 	argoNS       string
+	helmCharts   bool // If true, the Applications deploy the generated Helm charts.
 }
 
 func (b *argocdBuilder) Application(env *config.Environment, app *config.Application) error {
+	if !env.Deploys() || !app.Deployed() {
+		return nil
+	}
 	basePath := filepath.Join(config.PathForArgoCD())
 	argoFiles := res.Resources{}
 	filename := filepath.Join(basePath, env.Name+"-"+app.Name+"-app.yaml")
 
-	argoFiles[filename] = makeApplication(env.Name+"-"+app.Name, b.argoNS,
+	policy, err := syncPolicyForEnv(env)
+	if err != nil {
+		return err
+	}
+	source := makeSource(env, app, b.repoURL, b.gitOpsPath)
+	if b.helmCharts && app.ConfigRepo == nil {
+		source = makeHelmSource(env, app, b.repoURL, b.gitOpsPath)
+	}
+	argoApp := makeApplication(b.argoCDConfig.GetAPIVersion(), env.Name+"-"+app.Name, b.argoNS,
 		defaultProject,
 		env.Name,
 		clusterForEnv(env),
-		makeSource(env, app, b.repoURL))
+		source)
+	argoApp.Spec.SyncPolicy = policy
+	argoFiles[filename] = argoApp
 	b.files = res.Merge(argoFiles, b.files)
 	return nil
 }
 
-func argoCDConfigResources(cfg *config.Config, repoURL string, files res.Resources) error {
+func argoCDConfigResources(m *config.Manifest, files res.Resources) error {
+	cfg, repoURL := m.Config, m.GitOpsURL
 	if cfg.ArgoCD.Namespace == "" {
 		return nil
 	}
 	basePath := filepath.Join(config.PathForArgoCD())
 	filename := filepath.Join(basePath, "kustomization.yaml")
-	files[filepath.Join(basePath, "argo-app.yaml")] = ignoreDifferences(makeApplication("argo-app", cfg.ArgoCD.Namespace, defaultProject, cfg.ArgoCD.Namespace, defaultServer, argoappv1.ApplicationSource{RepoURL: repoURL, Path: basePath}))
+	files[filepath.Join(basePath, "argo-app.yaml")] = ignoreDifferences(makeApplication(cfg.ArgoCD.GetAPIVersion(), "argo-app", cfg.ArgoCD.Namespace, defaultProject, cfg.ArgoCD.Namespace, defaultServer, argoappv1.ApplicationSource{RepoURL: repoURL, Path: m.GitOpsRepoPath(basePath)}))
 	if cfg.Pipelines != nil {
-		files[filepath.Join(basePath, "cicd-app.yaml")] = ignoreDifferences(makeApplication("cicd-app", cfg.ArgoCD.Namespace, defaultProject, cfg.Pipelines.Name, defaultServer,
-			argoappv1.ApplicationSource{RepoURL: repoURL, Path: filepath.Join(config.PathForPipelines(cfg.Pipelines), "overlays")}))
+		files[filepath.Join(basePath, "cicd-app.yaml")] = ignoreDifferences(makeApplication(cfg.ArgoCD.GetAPIVersion(), "cicd-app", cfg.ArgoCD.Namespace, defaultProject, cfg.Pipelines.Name, defaultServer,
+			argoappv1.ApplicationSource{RepoURL: repoURL, Path: m.GitOpsRepoPath(filepath.Join(config.PathForPipelines(cfg.Pipelines), "overlays"))}))
 	}
 	argoResource, err := argoCDResource(cfg.ArgoCD.Namespace)
 	if err != nil {
@@ -135,11 +164,11 @@ func argoCDConfigResources(cfg *config.Config, repoURL string, files res.Resourc
 	return nil
 }
 
-func makeSource(env *config.Environment, app *config.Application, repoURL string) argoappv1.ApplicationSource {
+func makeSource(env *config.Environment, app *config.Application, repoURL, gitOpsPath string) argoappv1.ApplicationSource {
 	if app.ConfigRepo == nil {
 		return argoappv1.ApplicationSource{
 			RepoURL: repoURL,
-			Path:    filepath.Join(config.PathForApplication(env, app), "base"),
+			Path:    filepath.Join(gitOpsPath, config.PathForApplication(env, app), "base"),
 		}
 	}
 	return argoappv1.ApplicationSource{
@@ -149,6 +178,15 @@ func makeSource(env *config.Environment, app *config.Application, repoURL string
 	}
 }
 
+// makeHelmSource returns a source for the Helm chart of the application.
+func makeHelmSource(env *config.Environment, app *config.Application, repoURL, gitOpsPath string) argoappv1.ApplicationSource {
+	return argoappv1.ApplicationSource{
+		RepoURL: repoURL,
+		Path:    filepath.Join(gitOpsPath, helm.PathForChart(env, app)),
+		Helm:    &argoappv1.ApplicationSourceHelm{ReleaseName: app.Name},
+	}
+}
+
 func ignoreDifferences(app *argoappv1.Application) *argoappv1.Application {
 	app.Spec.IgnoreDifferences = ignoreDifferencesFields
 	return app
@@ -171,9 +209,9 @@ func argoCDResource(ns string) (*argov1.ArgoCD, error) {
 	}, nil
 }
 
-func makeApplication(appName, argoNS, project, ns, server string, source argoappv1.ApplicationSource) *argoappv1.Application {
+func makeApplication(apiVersion, appName, argoNS, project, ns, server string, source argoappv1.ApplicationSource) *argoappv1.Application {
 	return &argoappv1.Application{
-		TypeMeta:   applicationTypeMeta,
+		TypeMeta:   meta.TypeMeta(applicationTypeMeta.Kind, apiVersion),
 		ObjectMeta: meta.ObjectMeta(meta.NamespacedName(argoNS, appName)),
 		Spec: argoappv1.ApplicationSpec{
 			Project: project,
@@ -187,6 +225,27 @@ func makeApplication(appName, argoNS, project, ns, server string, source argoapp
 	}
 }
 
+// syncPolicyForEnv returns the sync policy for the environment's Applications,
+// a manual policy has no sync policy.
+func syncPolicyForEnv(env *config.Environment) (*argoappv1.SyncPolicy, error) {
+	if env.SyncPolicy == "" {
+		return syncPolicy, nil
+	}
+	p, err := config.ParseSyncPolicy(env.SyncPolicy)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate Applications for environment %s: %w", env.Name, err)
+	}
+	if !p.Automated {
+		return nil, nil
+	}
+	return &argoappv1.SyncPolicy{
+		Automated: &argoappv1.SyncPolicyAutomated{
+			Prune:    p.Prune,
+			SelfHeal: p.SelfHeal,
+		},
+	}, nil
+}
+
 func clusterForEnv(env *config.Environment) string {
 	if env.Cluster != "" {
 		return env.Cluster
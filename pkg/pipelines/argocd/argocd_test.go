@@ -4,6 +4,8 @@ import (
 	"testing"
 
 	"github.com/google/go-cmp/cmp"
+	"sigs.k8s.io/yaml"
+
 	"github.com/rhd-gitops-example/gitops-cli/pkg/pipelines/config"
 	"github.com/rhd-gitops-example/gitops-cli/pkg/pipelines/meta"
 
@@ -57,7 +59,7 @@ func TestBuildCreatesArgoCD(t *testing.T) {
 			TypeMeta:   applicationTypeMeta,
 			ObjectMeta: meta.ObjectMeta(meta.NamespacedName(ArgoCDNamespace, "test-dev-http-api")),
 			Spec: argoappv1.ApplicationSpec{
-				Source: makeSource(testEnv, testEnv.Apps[0], testRepoURL),
+				Source: makeSource(testEnv, testEnv.Apps[0], testRepoURL, ""),
 				Destination: argoappv1.ApplicationDestination{
 					Server:    defaultServer,
 					Namespace: "test-dev",
@@ -76,6 +78,37 @@ func TestBuildCreatesArgoCD(t *testing.T) {
 	}
 }
 
+func TestBuildHelmUsesHelmSource(t *testing.T) {
+	m := &config.Manifest{
+		Environments: []*config.Environment{
+			{Name: "test-dev", Apps: []*config.Application{testApp, configRepoApp}},
+		},
+		Config: &config.Config{
+			ArgoCD: &config.ArgoCDConfig{Namespace: "argocd"},
+		},
+	}
+
+	files, err := BuildHelm(ArgoCDNamespace, testRepoURL, m)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := argoappv1.ApplicationSource{
+		RepoURL: testRepoURL,
+		Path:    "environments/test-dev/apps/http-api/chart",
+		Helm:    &argoappv1.ApplicationSourceHelm{ReleaseName: "http-api"},
+	}
+	app := files["config/argocd/test-dev-http-api-app.yaml"].(*argoappv1.Application)
+	if diff := cmp.Diff(want, app.Spec.Source); diff != "" {
+		t.Fatalf("helm source didn't match: %s\n", diff)
+	}
+	// Applications deployed from a config repository are unchanged.
+	app = files["config/argocd/test-dev-prod-api-app.yaml"].(*argoappv1.Application)
+	if app.Spec.Source.Helm != nil || app.Spec.Source.Path != "deploys" {
+		t.Fatalf("config repository source was changed: %#v", app.Spec.Source)
+	}
+}
+
 func TestBuildCreatesArgoCDWithMultipleApps(t *testing.T) {
 	prodEnv := &config.Environment{
 		Name: "test-production",
@@ -170,7 +203,7 @@ func TestBuildWithRepoConfig(t *testing.T) {
 			TypeMeta:   applicationTypeMeta,
 			ObjectMeta: meta.ObjectMeta(meta.NamespacedName(ArgoCDNamespace, "test-production-prod-api")),
 			Spec: argoappv1.ApplicationSpec{
-				Source: makeSource(prodEnv, prodEnv.Apps[0], testRepoURL),
+				Source: makeSource(prodEnv, prodEnv.Apps[0], testRepoURL, ""),
 				Destination: argoappv1.ApplicationDestination{
 					Server:    defaultServer,
 					Namespace: "test-production",
@@ -217,7 +250,7 @@ func TestBuildAddsClusterToApp(t *testing.T) {
 			TypeMeta:   applicationTypeMeta,
 			ObjectMeta: meta.ObjectMeta(meta.NamespacedName(ArgoCDNamespace, "test-dev-http-api")),
 			Spec: argoappv1.ApplicationSpec{
-				Source: makeSource(testEnv, testEnv.Apps[0], testRepoURL),
+				Source: makeSource(testEnv, testEnv.Apps[0], testRepoURL, ""),
 				Destination: argoappv1.ApplicationDestination{
 					Server:    "not.real.cluster",
 					Namespace: "test-dev",
@@ -274,3 +307,123 @@ func fakeArgoCDResource(t *testing.T, ns string) *argov1.ArgoCD {
 	}
 	return res
 }
+
+func TestBuildWithAPIVersion(t *testing.T) {
+	m := &config.Manifest{
+		Environments: []*config.Environment{
+			testEnv,
+		},
+		Config: &config.Config{
+			ArgoCD: &config.ArgoCDConfig{Namespace: "argocd", APIVersion: "argoproj.io/v1beta1"},
+		},
+	}
+
+	buildTests := []struct {
+		name  string
+		build func(string, string, *config.Manifest) (res.Resources, error)
+		files []string
+	}{
+		{"applications", Build, []string{"config/argocd/test-dev-http-api-app.yaml", "config/argocd/argo-app.yaml"}},
+		{"applicationset", BuildApplicationSet, []string{"config/argocd/apps-appset.yaml", "config/argocd/argo-app.yaml"}},
+	}
+	for _, tt := range buildTests {
+		t.Run(tt.name, func(rt *testing.T) {
+			files, err := tt.build(ArgoCDNamespace, testRepoURL, m)
+			if err != nil {
+				rt.Fatal(err)
+			}
+			for _, f := range tt.files {
+				b, err := yaml.Marshal(files[f])
+				if err != nil {
+					rt.Fatal(err)
+				}
+				var got struct {
+					APIVersion string `json:"apiVersion"`
+				}
+				if err := yaml.Unmarshal(b, &got); err != nil {
+					rt.Fatal(err)
+				}
+				if got.APIVersion != "argoproj.io/v1beta1" {
+					rt.Errorf("%s got apiVersion %q, want %q", f, got.APIVersion, "argoproj.io/v1beta1")
+				}
+			}
+		})
+	}
+}
+
+func TestBuildWithSyncPolicies(t *testing.T) {
+	m := &config.Manifest{
+		Environments: []*config.Environment{
+			{Name: "dev", SyncPolicy: "auto+selfheal", Apps: []*config.Application{testApp}},
+			{Name: "prod", SyncPolicy: "manual", Apps: []*config.Application{testApp}},
+		},
+		Config: &config.Config{
+			ArgoCD: &config.ArgoCDConfig{Namespace: "argocd"},
+		},
+	}
+
+	files, err := Build(ArgoCDNamespace, testRepoURL, m)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := map[string]*argoappv1.SyncPolicy{
+		"config/argocd/dev-http-api-app.yaml":  {Automated: &argoappv1.SyncPolicyAutomated{SelfHeal: true}},
+		"config/argocd/prod-http-api-app.yaml": nil,
+	}
+	for f, policy := range want {
+		b, err := yaml.Marshal(files[f])
+		if err != nil {
+			t.Fatal(err)
+		}
+		var got struct {
+			Spec struct {
+				SyncPolicy *argoappv1.SyncPolicy `json:"syncPolicy"`
+			} `json:"spec"`
+		}
+		if err := yaml.Unmarshal(b, &got); err != nil {
+			t.Fatal(err)
+		}
+		if diff := cmp.Diff(policy, got.Spec.SyncPolicy); diff != "" {
+			t.Errorf("%s sync policy didn't match:\n%s", f, diff)
+		}
+	}
+}
+
+func TestBuildApplicationSetWithSyncPolicy(t *testing.T) {
+	m := &config.Manifest{
+		Environments: []*config.Environment{
+			{Name: "prod", SyncPolicy: "manual", Apps: []*config.Application{testApp}},
+		},
+		Config: &config.Config{
+			ArgoCD: &config.ArgoCDConfig{Namespace: "argocd"},
+		},
+	}
+
+	_, err := BuildApplicationSet(ArgoCDNamespace, testRepoURL, m)
+	if err == nil || err.Error() != "environment prod has a sync policy, which can't be generated in an ApplicationSet" {
+		t.Fatalf("got %v, want an error for the sync policy", err)
+	}
+}
+
+func TestBuildWithNoDeployEnvironment(t *testing.T) {
+	m := &config.Manifest{
+		Environments: []*config.Environment{
+			{Name: "ci", NoDeploy: true, Apps: []*config.Application{testApp}},
+			{Name: "dev", Apps: []*config.Application{testApp}},
+		},
+		Config: &config.Config{
+			ArgoCD: &config.ArgoCDConfig{Namespace: "argocd"},
+		},
+	}
+
+	files, err := Build(ArgoCDNamespace, testRepoURL, m)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := &res.Kustomization{Resources: []string{"argo-app.yaml", "argocd.yaml", "dev-http-api-app.yaml"}}
+	if diff := cmp.Diff(want, files["config/argocd/kustomization.yaml"]); diff != "" {
+		t.Fatalf("generated Applications didn't match:\n%s", diff)
+	}
+}
@@ -1,12 +1,15 @@
 package pipelines
 
 import (
+	"bytes"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"net/url"
 	"path/filepath"
 	"sort"
 	"strings"
+	"time"
 
 	ssv1alpha1 "github.com/bitnami-labs/sealed-secrets/pkg/apis/sealed-secrets/v1alpha1"
 	"github.com/mitchellh/go-homedir"
@@ -16,11 +19,14 @@ import (
 	v1rbac "k8s.io/api/rbac/v1"
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/apimachinery/pkg/util/validation"
 
 	"github.com/rhd-gitops-example/gitops-cli/pkg/pipelines/config"
 	"github.com/rhd-gitops-example/gitops-cli/pkg/pipelines/deployment"
 	"github.com/rhd-gitops-example/gitops-cli/pkg/pipelines/dryrun"
 	"github.com/rhd-gitops-example/gitops-cli/pkg/pipelines/eventlisteners"
+	fluxcd "github.com/rhd-gitops-example/gitops-cli/pkg/pipelines/flux"
+	"github.com/rhd-gitops-example/gitops-cli/pkg/pipelines/git"
 	"github.com/rhd-gitops-example/gitops-cli/pkg/pipelines/imagerepo"
 	"github.com/rhd-gitops-example/gitops-cli/pkg/pipelines/ioutils"
 	"github.com/rhd-gitops-example/gitops-cli/pkg/pipelines/meta"
@@ -49,6 +55,7 @@ const (
 	authTokenPath         = "03-secrets/git-host-access-token.yaml"
 	basicAuthTokenPath    = "03-secrets/git-host-basic-auth-token.yaml"
 	dockerConfigPath      = "03-secrets/docker-config.yaml"
+	imagePullSecretPath   = "03-secrets/image-pull-secret-%s.yaml"
 	gitopsTasksPath       = "04-tasks/deploy-from-source-task.yaml"
 	ciPipelinesPath       = "05-pipelines/ci-dryrun-from-push-pipeline.yaml"
 	appCiPipelinesPath    = "05-pipelines/app-ci-pipeline.yaml"
@@ -59,14 +66,21 @@ const (
 
 	dockerSecretName = "regcred"
 
-	saName              = "pipeline"
-	roleBindingName     = "pipelines-service-role-binding"
-	webhookSecretLength = 20
+	saName          = "pipeline"
+	roleBindingName = "pipelines-service-role-binding"
 
 	pipelinesFile     = "pipelines.yaml"
 	bootstrapImage    = "nginxinc/nginx-unprivileged:latest"
 	appCITemplateName = "app-ci-template"
-	version           = 1
+	defaultBuildTask  = "buildah"
+	version           = config.LatestVersion
+)
+
+// The supported GitOps engines, Argo CD Applications or Flux Kustomizations are
+// generated to deploy the environments.
+const (
+	ArgoCDEngine = "argocd"
+	FluxEngine   = "flux"
 )
 
 // BootstrapOptions is a struct that provides the optional flags
@@ -74,17 +88,69 @@ type BootstrapOptions struct {
 	GitOpsRepoURL            string // This is where the pipelines and configuration are.
 	GitOpsWebhookSecret      string // This is the secret for authenticating hooks from your GitOps repo.
 	Prefix                   string
+	NamespacePattern         string // Template for the environment namespaces, with the .Prefix and .Env, defaults to the prefix followed by the environment.
 	DockerConfigJSONFilename string
 	ImageRepo                string               // This is where built images are pushed to.
 	InternalRegistryHostname string               // This is the internal registry hostname used for pushing images.
 	OutputPath               string               // Where to write the bootstrapped files to?
+	OutputRoot               string               // If set, the OutputPath must be within this directory.
+	ManifestFile             string               // Path of the manifest within the OutputPath, defaults to pipelines.yaml.
 	SealedSecretsService     types.NamespacedName // SealedSecrets Services name
 	GitHostAccessToken       string               // The auth token to use to send commit-status notifications, and access private repositories.
+	Credentials              git.Credentials      // Per-host auth tokens, these are used instead of the GitHostAccessToken for repositories on matching hosts.
+	ServiceName              string               // Name of the bootstrapped service, defaults to the DefaultServiceName of the ServiceRepoURL.
 	Overwrite                bool                 // This allows to overwrite if there is an exixting gitops repository
+	Fresh                    bool                 // If true, the state of a previous failed bootstrap is ignored, rather than resuming it.
+	NoGitIgnore              bool                 // If true, no .gitignore is written to the OutputPath.
+	WithMakefile             bool                 // If true, a Makefile with targets for common operations is written to the OutputPath.
+	ForceMakefile            bool                 // If true, an existing Makefile in the OutputPath is replaced.
+	WithNamespaceDefaults    bool                 // If true, environment Namespaces are generated with Pod Security labels and NetworkPolicies.
+	PodSecurity              string               // Pod Security Standard level for the environment Namespaces with WithNamespaceDefaults.
+	Quota                    *config.QuotaConfig  // If set, a ResourceQuota and LimitRange are generated for the environment Namespaces.
+	UseApplicationSet        bool                 // Generate an ApplicationSet rather than individual Applications.
+	GitOpsEngine             string               // The GitOps engine to generate resources for, ArgoCDEngine or FluxEngine.
+	ArgoCDAPIVersion         string               // The apiVersion of the generated Argo CD resources, if not the default.
+	SyncPolicies             map[string]string    // Argo CD sync policies for the bootstrapped environments, keyed by environment name.
+	FromTemplate             string               // Repository to clone as the starting point for the GitOps repository.
+	TemplateWins             bool                 // If true, files from the FromTemplate repository replace generated files.
+	AllowLFSPointers         bool                 // If true, Git LFS pointer files in the FromTemplate repository are copied rather than failing.
+	TemplateVars             map[string]string    // Variables to render the FromTemplate files with, in addition to the built-in Prefix, Org and Environments, if set, all text files are rendered rather than only the .tmpl files.
+	AllowMissingVars         bool                 // If true, FromTemplate files that can't be parsed or reference undefined variables are copied unrendered rather than failing.
+	SSHKnownHostsFile        string               // known_hosts file to verify the host key of an SSH FromTemplate repository.
+	AcceptNewHostKeys        bool                 // If true, the keys of unknown SSH hosts are accepted when cloning the FromTemplate repository.
 	ServiceRepoURL           string               // This is the full URL to your GitHub repository for your app source.
 	ServiceWebhookSecret     string               // This is the secret for authenticating hooks from your app source.
 	PrivateRepoDriver        string               // Records the type of the GitOpsRepoURL driver if not a well-known host.
 	CommitStatusTracker      bool                 // If true, this is a "private repository", i.e. requires authentication to clone the repository.
+	ImageRegistry            string               // Registry to pull the images used by generated resources from e.g. a mirror.
+	TaskCatalogRef           string               // Name of the ClusterTask used to build images in the CI pipeline.
+	PipelineTimeout          time.Duration        // Timeout of the PipelineRuns started by the generated TriggerTemplates, if not the Tekton default.
+	BranchFilter             []string             // If set, the generated triggers only start pipelines for pushes to these branches.
+	GitOpsWebhookSecretName  string               // Name of the Secret for the GitOpsWebhookSecret, if not the default.
+	ImagePullSecrets         map[string]string    // Docker config files for the secrets that the pipeline service account pulls images with, keyed by secret name.
+	GitLabCIVariables        map[string]string    // CI/CD variables to set on the GitLab repositories, for pipelines run by GitLab CI.
+	GitOpsPath               string               // Directory of the OutputPath within the GitOps repository, if empty, it's found from the enclosing Git checkout.
+	MaxNameLength            int                  // Length limit for generated names and namespaces, defaults to the 63 character limit for a DNS-1123 label.
+
+	// Requests and limits for the steps of the generated Tasks, ClusterTasks
+	// referenced by the pipelines are not changed.
+	PipelineResources corev1.ResourceRequirements
+
+	// Warnf reports warnings, if it's nil, they're written with log.Warningf.
+	Warnf func(format string, a ...interface{}) `json:"-"`
+}
+
+// warningf reports a warning with the Warnf.
+func (o *BootstrapOptions) warningf(format string, a ...interface{}) {
+	warningf(o.Warnf, format, a...)
+}
+
+// warningf reports a warning with the warnf, or log.Warningf if it's nil.
+func warningf(warnf func(string, ...interface{}), format string, a ...interface{}) {
+	if warnf == nil {
+		warnf = log.Warningf
+	}
+	warnf(format, a...)
 }
 
 // PolicyRules to be bound to service account
@@ -125,19 +191,84 @@ var (
 
 // Bootstrap bootstraps a GitOps pipelines and repository structure.
 func Bootstrap(o *BootstrapOptions, appFs afero.Fs) error {
-	err := checkPipelinesFileExists(appFs, o.OutputPath, o.Overwrite)
+	if err := ioutils.ValidateOutputPath(appFs, o.OutputPath, o.OutputRoot); err != nil {
+		return err
+	}
+	inputs, err := bootstrapInputs(o)
 	if err != nil {
 		return err
 	}
+	state, err := loadBootstrapState(appFs, o.OutputPath, inputs, o.Fresh)
+	if err != nil {
+		return err
+	}
+	if state.resuming() {
+		log.Infof("Resuming the bootstrap to %s, rerun with --fresh to start again", o.OutputPath)
+	} else {
+		// Files written by the interrupted bootstrap are replaced when resuming.
+		if err := checkPipelinesFileExists(appFs, o.OutputPath, o.manifestFile(), o.Overwrite); err != nil {
+			return err
+		}
+	}
+	if !state.done(makefileStep) {
+		if err := checkMakefileExists(appFs, o); err != nil {
+			return err
+		}
+	}
+	// The .gitignore is written before any steps, so that the stateFile of an
+	// interrupted bootstrap isn't committed, it's merged again at the end, in
+	// case a template repository replaced it.
+	if !o.NoGitIgnore {
+		if err := writeGitIgnore(appFs, o.OutputPath); err != nil {
+			return err
+		}
+	}
+	if err := setGitLabCIVariables(o, state); err != nil {
+		return err
+	}
+	if !state.done(filesWrittenStep) {
+		if err := writeBootstrappedFiles(o, appFs); err != nil {
+			return err
+		}
+		if err := state.complete(filesWrittenStep); err != nil {
+			return err
+		}
+	}
+	if o.WithMakefile && !state.done(makefileStep) {
+		if err := writeMakefile(appFs, o); err != nil {
+			return err
+		}
+		if err := state.complete(makefileStep); err != nil {
+			return err
+		}
+	}
+	if !o.NoGitIgnore {
+		if err := writeGitIgnore(appFs, o.OutputPath); err != nil {
+			return err
+		}
+	}
+	return state.remove()
+}
+
+// writeBootstrappedFiles generates the resources for the bootstrapped
+// environments, and writes them to the OutputPath.
+func writeBootstrappedFiles(o *BootstrapOptions, appFs afero.Fs) error {
+	var err error
+	if o.GitOpsPath == "" {
+		o.GitOpsPath, err = ioutils.RepositorySubpath(appFs, o.OutputPath)
+		if err != nil {
+			return err
+		}
+	}
 	if o.GitOpsWebhookSecret == "" {
-		gitopsSecret, err := secrets.GenerateString(webhookSecretLength)
+		gitopsSecret, err := secrets.GenerateString(DefaultWebhookSecretLength)
 		if err != nil {
 			return fmt.Errorf("failed to generate GitOps webhook secret: %v", err)
 		}
 		o.GitOpsWebhookSecret = gitopsSecret
 	}
 	if o.ServiceWebhookSecret == "" {
-		appSecret, err := secrets.GenerateString(webhookSecretLength)
+		appSecret, err := secrets.GenerateString(DefaultWebhookSecretLength)
 		if err != nil {
 			return fmt.Errorf("failed to generate application webhook secret: %v", err)
 		}
@@ -149,17 +280,25 @@ func Bootstrap(o *BootstrapOptions, appFs afero.Fs) error {
 	}
 
 	buildParams := &BuildParameters{
-		PipelinesFolderPath: pipelinesFile,
+		PipelinesFolderPath: o.OutputPath,
+		ManifestFile:        o.manifestFile(),
 		OutputPath:          o.OutputPath,
+		UseApplicationSet:   o.UseApplicationSet,
 	}
 
-	m := bootstrapped[pipelinesFile].(*config.Manifest)
+	m := bootstrapped[o.manifestFile()].(*config.Manifest)
 	built, err := buildResources(appFs, buildParams, m)
 	if err != nil {
 		return fmt.Errorf("failed to build resources: %v", err)
 	}
 	log.Successf("Created dev,stage and cicd ennvironments")
 	bootstrapped = res.Merge(built, bootstrapped)
+	if o.FromTemplate != "" {
+		bootstrapped, err = writeTemplate(appFs, o, bootstrapped)
+		if err != nil {
+			return err
+		}
+	}
 	_, err = yaml.WriteResources(appFs, o.OutputPath, bootstrapped)
 	return err
 }
@@ -177,7 +316,7 @@ func bootstrapResources(o *BootstrapOptions, appFs afero.Fs) (res.Resources, err
 	if err != nil {
 		return nil, err
 	}
-	repoName, err := repoFromURL(appRepo.URL())
+	repoName, err := DefaultServiceName(appRepo.URL(), o.maxNameLength())
 	if err != nil {
 		return nil, fmt.Errorf("invalid app repo URL: %v", err)
 	}
@@ -189,12 +328,27 @@ func bootstrapResources(o *BootstrapOptions, appFs afero.Fs) (res.Resources, err
 	}
 	appName := repoToAppName(repoName)
 	serviceName := repoName
-	ns := namespaces.NamesWithPrefix(o.Prefix)
+	if o.ServiceName != "" {
+		serviceName = o.ServiceName
+	}
+	ns, err := namespaces.NamesWithPattern(o.NamespacePattern, o.Prefix, o.maxNameLength())
+	if err != nil {
+		return nil, err
+	}
 	secretName := secrets.MakeServiceWebhookSecretName(ns["dev"], serviceName)
-	envs, configEnv, err := bootstrapEnvironments(appRepo, o.Prefix, secretName, ns)
+	envs, configEnv, err := bootstrapEnvironments(appRepo, serviceName, secretName, ns, o.maxNameLength())
 	if err != nil {
 		return nil, err
 	}
+	if o.Quota != nil {
+		for _, env := range envs {
+			q := *o.Quota
+			env.Quota = &q
+		}
+	}
+	// The CI/CD configuration that the initial files were created with.
+	initial := bootstrapped[o.manifestFile()].(*config.Manifest)
+	configEnv.Pipelines = initial.Config.Pipelines
 	if o.PrivateRepoDriver != "" {
 		host, err := scm.HostnameFromURL(o.GitOpsRepoURL)
 		if err != nil {
@@ -202,18 +356,40 @@ func bootstrapResources(o *BootstrapOptions, appFs afero.Fs) (res.Resources, err
 		}
 		configEnv.Git = &config.GitConfig{Drivers: map[string]string{host: o.PrivateRepoDriver}}
 	}
+	// The default isn't recorded, so that the manifest follows the default.
+	if o.ArgoCDAPIVersion != config.DefaultArgoCDAPIVersion {
+		configEnv.ArgoCD.APIVersion = o.ArgoCDAPIVersion
+	}
+	if o.GitOpsEngine == FluxEngine {
+		configEnv.ArgoCD = nil
+		configEnv.Flux = &config.FluxConfig{Namespace: fluxcd.FluxNamespace}
+	}
+	if o.WithNamespaceDefaults {
+		configEnv.NamespaceDefaults = &config.NamespaceDefaultsConfig{}
+		if o.PodSecurity != config.PodSecurityBaseline {
+			configEnv.NamespaceDefaults.PodSecurity = o.PodSecurity
+		}
+	}
 	m := createManifest(gitOpsRepo.URL(), configEnv, envs...)
+	m.GitOpsPath = initial.GitOpsPath
 
 	devEnv := m.GetEnvironment(ns["dev"])
 	if devEnv == nil {
 		return nil, errors.New("unable to bootstrap without dev environment")
 	}
+	for name, policy := range o.SyncPolicies {
+		env := m.GetEnvironment(name)
+		if env == nil {
+			return nil, fmt.Errorf("failed to set the sync policy for environment %q, it's not one of the bootstrapped environments", name)
+		}
+		env.SyncPolicy = policy
+	}
 
 	app := m.GetApplication(ns["dev"], appName)
 	if app == nil {
 		return nil, errors.New("unable to bootstrap without application")
 	}
-	svcFiles, err := bootstrapServiceDeployment(devEnv, app)
+	svcFiles, err := bootstrapServiceDeployment(devEnv, app, o.ImageRegistry)
 	if err != nil {
 		return nil, err
 	}
@@ -234,7 +410,7 @@ func bootstrapResources(o *BootstrapOptions, appFs afero.Fs) (res.Resources, err
 	secretsPath := filepath.Join(config.PathForPipelines(cfg), "base", secretFilename)
 	bootstrapped[secretsPath] = hookSecret
 
-	bindingName, imageRepoBindingFilename, svcImageBinding := createSvcImageBinding(cfg, devEnv, appName, serviceName, imageRepo, !isInternalRegistry)
+	bindingName, imageRepoBindingFilename, svcImageBinding := createSvcImageBinding(cfg, devEnv, appName, serviceName, imageRepo, "", !isInternalRegistry)
 	bootstrapped = res.Merge(svcImageBinding, bootstrapped)
 
 	kustomizePath := filepath.Join(config.PathForPipelines(cfg), "base", "kustomization.yaml")
@@ -259,7 +435,7 @@ func bootstrapResources(o *BootstrapOptions, appFs afero.Fs) (res.Resources, err
 			Bindings: append([]string{bindingName}, devEnv.Pipelines.Integration.Bindings[:]...),
 		},
 	}
-	bootstrapped[pipelinesFile] = m
+	bootstrapped[o.manifestFile()] = m
 
 	k.Resources = append(k.Resources, secretFilename, imageRepoBindingFilename)
 	sort.Strings(k.Resources)
@@ -269,33 +445,30 @@ func bootstrapResources(o *BootstrapOptions, appFs afero.Fs) (res.Resources, err
 	return bootstrapped, nil
 }
 
-func bootstrapServiceDeployment(dev *config.Environment, app *config.Application) (res.Resources, error) {
+func bootstrapServiceDeployment(dev *config.Environment, app *config.Application, registry string) (res.Resources, error) {
 	svc := dev.Apps[0].Services[0]
 	svcBase := filepath.Join(config.PathForService(app, dev, svc.Name), "base", "config")
 	resources := res.Resources{}
 	// TODO: This should change if we add Namespace to Environment.
 	// We'd need to create the resources in the namespace _of_ the Environment.
-	resources[filepath.Join(svcBase, "100-deployment.yaml")] = deployment.Create(app.Name, dev.Name, svc.Name, bootstrapImage, deployment.ContainerPort(8080))
+	resources[filepath.Join(svcBase, "100-deployment.yaml")] = deployment.Create(app.Name, dev.Name, svc.Name, imagerepo.WithRegistry(bootstrapImage, registry), deployment.ContainerPort(8080))
 	resources[filepath.Join(svcBase, "200-service.yaml")] = createBootstrapService(app.Name, dev.Name, svc.Name)
 	resources[filepath.Join(svcBase, "kustomization.yaml")] = &res.Kustomization{Resources: []string{"100-deployment.yaml", "200-service.yaml"}}
 	return resources, nil
 }
 
-func bootstrapEnvironments(repo scm.Repository, prefix, secretName string, ns map[string]string) ([]*config.Environment, *config.Config, error) {
+func bootstrapEnvironments(repo scm.Repository, serviceName, secretName string, ns map[string]string, maxNameLength int) ([]*config.Environment, *config.Config, error) {
 	envs := []*config.Environment{}
 	var pipelinesConfig *config.PipelinesConfig
 	for _, k := range []string{"cicd", "dev", "stage"} {
 		v := ns[k]
 		if k == "cicd" {
-			pipelinesConfig = &config.PipelinesConfig{Name: prefix + "cicd"}
+			pipelinesConfig = &config.PipelinesConfig{Name: v}
 		} else {
 			env := &config.Environment{Name: v}
 			if k == "dev" {
-				svc, err := serviceFromRepo(repo.URL(), secretName, ns["cicd"])
-				if err != nil {
-					return nil, nil, err
-				}
-				app, err := applicationFromRepo(repo.URL(), svc)
+				svc := serviceFromRepo(serviceName, repo.URL(), secretName, ns["cicd"])
+				app, err := applicationFromRepo(repo.URL(), svc, maxNameLength)
 				if err != nil {
 					return nil, nil, err
 				}
@@ -310,13 +483,9 @@ func bootstrapEnvironments(repo scm.Repository, prefix, secretName string, ns ma
 	return envs, cfg, nil
 }
 
-func serviceFromRepo(repoURL, secretName, secretNS string) (*config.Service, error) {
-	repo, err := repoFromURL(repoURL)
-	if err != nil {
-		return nil, err
-	}
+func serviceFromRepo(name, repoURL, secretName, secretNS string) *config.Service {
 	return &config.Service{
-		Name:      repo,
+		Name:      name,
 		SourceURL: repoURL,
 		Webhook: &config.Webhook{
 			Secret: &config.Secret{
@@ -324,11 +493,11 @@ func serviceFromRepo(repoURL, secretName, secretNS string) (*config.Service, err
 				Namespace: secretNS,
 			},
 		},
-	}, nil
+	}
 }
 
-func applicationFromRepo(repoURL string, service *config.Service) (*config.Application, error) {
-	repo, err := repoFromURL(repoURL)
+func applicationFromRepo(repoURL string, service *config.Service, maxNameLength int) (*config.Application, error) {
+	repo, err := DefaultServiceName(repoURL, maxNameLength)
 	if err != nil {
 		return nil, err
 	}
@@ -347,6 +516,17 @@ func repoFromURL(raw string) (string, error) {
 	return strings.TrimSuffix(parts[len(parts)-1], ".git"), nil
 }
 
+// DefaultServiceName returns the name of the service for a repository, this
+// is the name of the repository, sanitized so that it's a valid name e.g.
+// My_Repo.git is my-repo, truncated to the maxNameLength.
+func DefaultServiceName(repoURL string, maxNameLength int) (string, error) {
+	repo, err := repoFromURL(repoURL)
+	if err != nil {
+		return "", err
+	}
+	return namespaces.SuggestName(repo, maxNameLength), nil
+}
+
 func orgRepoFromURL(raw string) (string, error) {
 	u, err := url.Parse(raw)
 	if err != nil {
@@ -393,21 +573,49 @@ func defaultPipelines(r scm.Repository) *config.Pipelines {
 	}
 }
 
-// Checks whether the pipelines.yaml is present in the output path specified.
-func checkPipelinesFileExists(appFs afero.Fs, outputPath string, overWrite bool) error {
-	exists, _ := ioutils.IsExisting(appFs, filepath.Join(outputPath, pipelinesFile))
+// Checks whether the manifest file is present in the output path specified.
+func checkPipelinesFileExists(appFs afero.Fs, outputPath, manifestFile string, overWrite bool) error {
+	exists, _ := ioutils.IsExisting(appFs, filepath.Join(outputPath, manifestFile))
 	if exists && !overWrite {
-		return fmt.Errorf("pipelines.yaml in output path already exists. If you want to replace your existing files, please rerun with --overwrite")
+		return fmt.Errorf("%s in output path already exists. If you want to replace your existing files, please rerun with --overwrite", manifestFile)
 	}
 	return nil
 }
 
+// manifestFile returns the path of the manifest within the OutputPath.
+func (o *BootstrapOptions) manifestFile() string {
+	return manifestFileOrDefault(o.ManifestFile)
+}
+
+// manifestFileOrDefault returns the manifestFile, or pipelines.yaml if it's
+// empty.
+func manifestFileOrDefault(manifestFile string) string {
+	if manifestFile == "" {
+		return pipelinesFile
+	}
+	return manifestFile
+}
+
+// maxNameLength returns the MaxNameLength, or the 63 character limit for a
+// DNS-1123 label if it's not set.
+func (o *BootstrapOptions) maxNameLength() int {
+	if o.MaxNameLength == 0 {
+		return validation.DNS1123LabelMaxLength
+	}
+	return o.MaxNameLength
+}
+
 func createInitialFiles(fs afero.Fs, repo scm.Repository, o *BootstrapOptions) (res.Resources, error) {
-	cicd := &config.PipelinesConfig{Name: o.Prefix + "cicd"}
+	cicdNamespace, err := namespaces.NamespaceFromPattern(o.NamespacePattern, o.Prefix, "cicd", o.maxNameLength())
+	if err != nil {
+		return nil, err
+	}
+	cicd := &config.PipelinesConfig{Name: cicdNamespace, Branches: o.BranchFilter, WebhookSecret: o.GitOpsWebhookSecretName}
 	pipelineConfig := &config.Config{Pipelines: cicd}
 	pipelines := createManifest(repo.URL(), pipelineConfig)
+	pipelines.GitOpsPath = o.GitOpsPath
 	initialFiles := res.Resources{
-		pipelinesFile: pipelines,
+		o.manifestFile(): pipelines,
 	}
 	resources, err := createCICDResources(fs, repo, cicd, o)
 	if err != nil {
@@ -455,7 +663,8 @@ func createCICDResources(fs afero.Fs, repo scm.Repository, pipelineConfig *confi
 	// key: path of the resource
 	// value: YAML content of the resource
 	outputs := map[string]interface{}{}
-	githubSecret, err := secrets.CreateSealedSecret(meta.NamespacedName(cicdNamespace, eventlisteners.GitOpsWebhookSecret),
+	webhookSecretName := eventlisteners.WebhookSecretName(pipelineConfig.WebhookSecret)
+	githubSecret, err := secrets.CreateSealedSecret(meta.NamespacedName(cicdNamespace, webhookSecretName),
 		o.SealedSecretsService, o.GitOpsWebhookSecret, eventlisteners.WebhookSecretKey)
 	if err != nil {
 		return nil, fmt.Errorf("failed to generate GitHub Webhook Secret: %w", err)
@@ -477,7 +686,14 @@ func createCICDResources(fs afero.Fs, repo scm.Repository, pipelineConfig *confi
 		outputs[serviceAccountPath] = roles.AddSecretToSA(sa, dockerSecretName)
 	}
 
-	if o.GitHostAccessToken != "" {
+	if len(o.ImagePullSecrets) > 0 {
+		err := addImagePullSecrets(fs, outputs, sa, cicdNamespace, o)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if o.accessToken(o.ServiceRepoURL) != "" {
 		err := generateSecrets(outputs, sa, cicdNamespace, o)
 		if err != nil {
 			return nil, err
@@ -485,7 +701,7 @@ func createCICDResources(fs afero.Fs, repo scm.Repository, pipelineConfig *confi
 	}
 
 	if o.CommitStatusTracker {
-		trackerResources, err := statustracker.Resources(cicdNamespace, o.GitOpsRepoURL, o.PrivateRepoDriver)
+		trackerResources, err := statustracker.Resources(cicdNamespace, o.GitOpsRepoURL, o.PrivateRepoDriver, o.ImageRegistry)
 		if err != nil {
 			return nil, err
 		}
@@ -494,18 +710,23 @@ func createCICDResources(fs afero.Fs, repo scm.Repository, pipelineConfig *confi
 	}
 
 	outputs[rolebindingsPath] = roles.CreateClusterRoleBinding(meta.NamespacedName("", roleBindingName), sa, "ClusterRole", roles.ClusterRoleName)
-	script, err := dryrun.MakeScript("kubectl", cicdNamespace)
+	script, err := dryrun.MakeScript("kubectl", cicdNamespace, o.GitOpsPath)
 	if err != nil {
 		return nil, err
 	}
-	outputs[gitopsTasksPath] = tasks.CreateDeployFromSourceTask(cicdNamespace, script)
+	outputs[gitopsTasksPath] = tasks.WithStepResources(tasks.CreateDeployFromSourceTask(cicdNamespace, script, o.ImageRegistry), o.PipelineResources)
 	outputs[ciPipelinesPath] = pipelines.CreateCIPipeline(meta.NamespacedName(cicdNamespace, "ci-dryrun-from-push-pipeline"), cicdNamespace)
-	outputs[appCiPipelinesPath] = pipelines.CreateAppCIPipeline(meta.NamespacedName(cicdNamespace, "app-ci-pipeline"))
+	buildTask := o.TaskCatalogRef
+	if buildTask == "" {
+		buildTask = defaultBuildTask
+	}
+	outputs[appCiPipelinesPath] = pipelines.CreateAppCIPipeline(meta.NamespacedName(cicdNamespace, "app-ci-pipeline"), buildTask)
 	pushBinding, pushBindingName := repo.CreatePushBinding(cicdNamespace)
 	outputs[filepath.Join("06-bindings", pushBindingName+".yaml")] = pushBinding
-	outputs[pushTemplatePath] = triggers.CreateCIDryRunTemplate(cicdNamespace, saName)
-	outputs[appCIPushTemplatePath] = triggers.CreateDevCIBuildPRTemplate(cicdNamespace, saName)
-	outputs[eventListenerPath] = eventlisteners.Generate(repo, cicdNamespace, saName, eventlisteners.GitOpsWebhookSecret)
+	timeout := triggers.PipelineRunTimeout(o.PipelineTimeout)
+	outputs[pushTemplatePath] = triggers.CreateCIDryRunTemplate(cicdNamespace, saName, timeout)
+	outputs[appCIPushTemplatePath] = triggers.CreateDevCIBuildPRTemplate(cicdNamespace, saName, timeout)
+	outputs[eventListenerPath] = eventlisteners.Generate(repo, cicdNamespace, saName, webhookSecretName, pipelineConfig.Branches...)
 	log.Success("OpenShift Pipelines resources created")
 	route, err := routes.Generate(cicdNamespace)
 	if err != nil {
@@ -557,10 +778,99 @@ func getResourceFiles(res res.Resources) []string {
 	return files
 }
 
+// accessToken returns the auth token to use for the repository, this is the
+// credential for the repository's host if there is one.
+func (o *BootstrapOptions) accessToken(repoURL string) string {
+	return o.Credentials.Token(repoURL, o.GitHostAccessToken)
+}
+
+// ciVariableSetter is implemented by repositories that CI/CD variables can be
+// set for.
+type ciVariableSetter interface {
+	SetCIVariable(key, value string) error
+}
+
+// newCIVariableSetter is a var to allow replacement in tests.
+var newCIVariableSetter = func(repoURL, token string) (ciVariableSetter, error) {
+	repo, err := git.NewRepository(repoURL, token)
+	if err != nil {
+		return nil, err
+	}
+	return repo, nil
+}
+
+// setGitLabCIVariables sets the GitLabCIVariables on the GitOps and service
+// repositories that are on GitLab, other repositories are left alone.
+func setGitLabCIVariables(o *BootstrapOptions, state *bootstrapState) error {
+	if len(o.GitLabCIVariables) == 0 {
+		return nil
+	}
+	keys := []string{}
+	for k := range o.GitLabCIVariables {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, repoURL := range []string{o.GitOpsRepoURL, o.ServiceRepoURL} {
+		driver, err := scm.GetDriverName(repoURL)
+		if err != nil {
+			return fmt.Errorf("failed to identify the driver for %s: %w", repoURL, err)
+		}
+		if driver != "gitlab" || state.done(ciVariablesStep+repoURL) {
+			continue
+		}
+		repo, err := newCIVariableSetter(repoURL, o.accessToken(repoURL))
+		if err != nil {
+			return err
+		}
+		for _, k := range keys {
+			if err := repo.SetCIVariable(k, o.GitLabCIVariables[k]); err != nil {
+				return fmt.Errorf("failed to set CI/CD variable %s for %s: %w", k, repoURL, err)
+			}
+		}
+		if err := state.complete(ciVariablesStep + repoURL); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// addImagePullSecrets seals the Docker configs of the ImagePullSecrets, and
+// allows the pipeline service account to pull images with them, so that the
+// pipelines can use images from private registries.
+func addImagePullSecrets(fs afero.Fs, outputs res.Resources, sa *corev1.ServiceAccount, ns string, o *BootstrapOptions) error {
+	names := []string{}
+	for name := range o.ImagePullSecrets {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		path, err := homedir.Expand(o.ImagePullSecrets[name])
+		if err != nil {
+			return fmt.Errorf("failed to generate path to file: %v", err)
+		}
+		b, err := afero.ReadFile(fs, path)
+		if err != nil {
+			return fmt.Errorf("failed to read Docker config %#v for image pull secret %s: %s", path, name, err)
+		}
+		var dockerConfig map[string]interface{}
+		if err := json.Unmarshal(b, &dockerConfig); err != nil {
+			return fmt.Errorf("failed to parse Docker config %#v for image pull secret %s: %w", path, name, err)
+		}
+		pullSecret, err := secrets.CreateSealedDockerConfigSecret(meta.NamespacedName(ns, name), o.SealedSecretsService, bytes.NewReader(b))
+		if err != nil {
+			return fmt.Errorf("failed to generate image pull secret %s: %w", name, err)
+		}
+		outputs[fmt.Sprintf(imagePullSecretPath, name)] = pullSecret
+		outputs[serviceAccountPath] = roles.AddImagePullSecretToSA(sa, name)
+	}
+	return nil
+}
+
 func generateSecrets(outputs res.Resources, sa *corev1.ServiceAccount, ns string, o *BootstrapOptions) error {
+	token := o.accessToken(o.ServiceRepoURL)
 	if o.CommitStatusTracker {
 		tokenSecret, err := secrets.CreateSealedSecret(meta.NamespacedName(
-			ns, "git-host-access-token"), o.SealedSecretsService, o.GitHostAccessToken, "token")
+			ns, "git-host-access-token"), o.SealedSecretsService, token, "token")
 		if err != nil {
 			return fmt.Errorf("failed to generate access token Secret: %w", err)
 		}
@@ -572,7 +882,7 @@ func generateSecrets(outputs res.Resources, sa *corev1.ServiceAccount, ns string
 		return fmt.Errorf("failed to parse the Service Repo URL %q: %w", o.ServiceRepoURL, err)
 	}
 	basicAuthSecret, err := secrets.CreateSealedBasicAuthSecret(meta.NamespacedName(
-		ns, "git-host-basic-auth-token"), o.SealedSecretsService, o.GitHostAccessToken, meta.AddAnnotations(map[string]string{
+		ns, "git-host-basic-auth-token"), o.SealedSecretsService, token, meta.AddAnnotations(map[string]string{
 		"tekton.dev/git-0": secretTargetHost,
 	}))
 	if err != nil {
@@ -0,0 +1,141 @@
+package pipelines
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/spf13/afero"
+)
+
+// stateFile records the steps of a bootstrap that have completed, so that a
+// bootstrap that fails part way through can be resumed by running it again.
+const stateFile = ".gitops-cli-state"
+
+// The steps of a bootstrap that are recorded in the stateFile, CI/CD variables
+// are recorded for each repository, as ciVariablesStep followed by the URL.
+const (
+	ciVariablesStep  = "ci-variables "
+	filesWrittenStep = "files-written"
+	makefileStep     = "makefile"
+)
+
+// bootstrapState is the progress of a bootstrap, it's saved to the stateFile
+// in the OutputPath after each step completes.
+//
+// The Inputs are a hash of the options of the bootstrap, a bootstrap with
+// different options isn't resumed, as the completed steps would not match
+// them.
+type bootstrapState struct {
+	Inputs    string   `json:"inputs"`
+	Completed []string `json:"completed"`
+
+	fs   afero.Fs
+	path string
+}
+
+// loadBootstrapState reads the state of a previous bootstrap to the
+// outputPath, if fresh is true, or there is no previous bootstrap, the state
+// is empty.
+//
+// The inputs are the hash of the options of this bootstrap, an error is
+// returned if the previous bootstrap had different inputs.
+func loadBootstrapState(fs afero.Fs, outputPath, inputs string, fresh bool) (*bootstrapState, error) {
+	s := &bootstrapState{Inputs: inputs, fs: fs, path: filepath.Join(outputPath, stateFile)}
+	if fresh {
+		return s, s.remove()
+	}
+	b, err := afero.ReadFile(fs, s.path)
+	if os.IsNotExist(err) {
+		return s, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read the bootstrap state from %s: %w", s.path, err)
+	}
+	if err := json.Unmarshal(b, s); err != nil {
+		return nil, fmt.Errorf("failed to parse the bootstrap state in %s, rerun with --fresh to ignore it: %w", s.path, err)
+	}
+	if s.resuming() && s.Inputs != inputs {
+		return nil, fmt.Errorf("the bootstrap state in %s was recorded with different options, rerun with the same options to resume it, or with --fresh to ignore it", s.path)
+	}
+	return s, nil
+}
+
+// bootstrapInputs returns a hash of the options that determine what the
+// bootstrap does.
+//
+// Secrets aren't hashed, as the state is written to the OutputPath, only the
+// names of the CI/CD variables and the hosts of the credentials are, and the
+// options that control whether previous output is replaced are ignored.
+func bootstrapInputs(o *BootstrapOptions) (string, error) {
+	inputs := *o
+	inputs.GitOpsWebhookSecret = ""
+	inputs.ServiceWebhookSecret = ""
+	inputs.GitHostAccessToken = ""
+	inputs.Credentials = keysOnly(o.Credentials)
+	inputs.GitLabCIVariables = keysOnly(o.GitLabCIVariables)
+	inputs.Overwrite = false
+	inputs.Fresh = false
+	inputs.ForceMakefile = false
+	b, err := json.Marshal(inputs)
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal the bootstrap options: %w", err)
+	}
+	sum := sha256.Sum256(b)
+	return hex.EncodeToString(sum[:]), nil
+}
+
+// keysOnly returns a copy of the map with the values removed.
+func keysOnly(m map[string]string) map[string]string {
+	if m == nil {
+		return nil
+	}
+	stripped := make(map[string]string, len(m))
+	for k := range m {
+		stripped[k] = ""
+	}
+	return stripped
+}
+
+// resuming returns true if steps of a previous bootstrap have completed.
+func (s *bootstrapState) resuming() bool {
+	return len(s.Completed) > 0
+}
+
+// done returns true if the step completed in a previous bootstrap.
+func (s *bootstrapState) done(step string) bool {
+	for _, c := range s.Completed {
+		if c == step {
+			return true
+		}
+	}
+	return false
+}
+
+// complete records that the step has completed, and saves the state.
+func (s *bootstrapState) complete(step string) error {
+	s.Completed = append(s.Completed, step)
+	b, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal the bootstrap state: %w", err)
+	}
+	if err := s.fs.MkdirAll(filepath.Dir(s.path), 0755); err != nil {
+		return fmt.Errorf("failed to create the directory for %s: %w", s.path, err)
+	}
+	if err := afero.WriteFile(s.fs, s.path, b, 0644); err != nil {
+		return fmt.Errorf("failed to write the bootstrap state to %s: %w", s.path, err)
+	}
+	return nil
+}
+
+// remove removes the stateFile, once the bootstrap has completed, there's
+// nothing to resume.
+func (s *bootstrapState) remove() error {
+	if err := s.fs.Remove(s.path); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to remove the bootstrap state %s: %w", s.path, err)
+	}
+	return nil
+}
@@ -3,6 +3,8 @@ package pipelines
 import (
 	"crypto/rand"
 	"crypto/rsa"
+	"errors"
+	"strings"
 	"testing"
 
 	ssv1alpha1 "github.com/bitnami-labs/sealed-secrets/pkg/apis/sealed-secrets/v1alpha1"
@@ -11,14 +13,20 @@ import (
 	"github.com/rhd-gitops-example/gitops-cli/pkg/pipelines/config"
 	"github.com/rhd-gitops-example/gitops-cli/pkg/pipelines/deployment"
 	"github.com/rhd-gitops-example/gitops-cli/pkg/pipelines/eventlisteners"
+	"github.com/rhd-gitops-example/gitops-cli/pkg/pipelines/git"
+	"github.com/rhd-gitops-example/gitops-cli/pkg/pipelines/helper"
 	"github.com/rhd-gitops-example/gitops-cli/pkg/pipelines/ioutils"
 	"github.com/rhd-gitops-example/gitops-cli/pkg/pipelines/meta"
 	res "github.com/rhd-gitops-example/gitops-cli/pkg/pipelines/resources"
 	"github.com/rhd-gitops-example/gitops-cli/pkg/pipelines/roles"
 	"github.com/rhd-gitops-example/gitops-cli/pkg/pipelines/scm"
 	"github.com/rhd-gitops-example/gitops-cli/pkg/pipelines/secrets"
+	"github.com/spf13/afero"
+	pipelinev1 "github.com/tektoncd/pipeline/pkg/apis/pipeline/v1beta1"
+	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/validation"
 )
 
 const (
@@ -152,6 +160,72 @@ func TestBootstrapManifest(t *testing.T) {
 	}
 }
 
+func TestBootstrapManifestRecordsPipelinesConfig(t *testing.T) {
+	defer stubDefaultPublicKeyFunc(t)()
+	params := &BootstrapOptions{
+		Prefix:                  "tst-",
+		GitOpsRepoURL:           testGitOpsRepo,
+		ImageRepo:               "image/repo",
+		ServiceRepoURL:          testSvcRepo,
+		BranchFilter:            []string{"main"},
+		GitOpsWebhookSecretName: "gitops-hooks",
+	}
+	r, err := bootstrapResources(params, ioutils.NewMemoryFilesystem())
+	fatalIfError(t, err)
+
+	m := r[pipelinesFile].(*config.Manifest)
+	want := &config.PipelinesConfig{Name: "tst-cicd", Branches: []string{"main"}, WebhookSecret: "gitops-hooks"}
+	if diff := cmp.Diff(want, m.Config.Pipelines); diff != "" {
+		t.Fatalf("manifest pipelines config:\n%s", diff)
+	}
+}
+
+func TestBootstrapWithNamespaceDefaults(t *testing.T) {
+	defer stubDefaultPublicKeyFunc(t)()
+	params := &BootstrapOptions{
+		Prefix:                "tst-",
+		GitOpsRepoURL:         testGitOpsRepo,
+		ImageRepo:             "image/repo",
+		ServiceRepoURL:        testSvcRepo,
+		WithNamespaceDefaults: true,
+		PodSecurity:           config.PodSecurityRestricted,
+	}
+	r, err := bootstrapResources(params, ioutils.NewMemoryFilesystem())
+	fatalIfError(t, err)
+
+	m := r[pipelinesFile].(*config.Manifest)
+	want := &config.NamespaceDefaultsConfig{PodSecurity: config.PodSecurityRestricted}
+	if diff := cmp.Diff(want, m.Config.NamespaceDefaults); diff != "" {
+		t.Fatalf("manifest namespace defaults:\n%s", diff)
+	}
+}
+
+func TestBootstrapAccessTokenPerHost(t *testing.T) {
+	o := &BootstrapOptions{
+		GitOpsRepoURL:      "https://github.com/my-org/gitops.git",
+		ServiceRepoURL:     "https://gitlab.com/my-org/http-api.git",
+		GitHostAccessToken: "default-token",
+		Credentials: git.Credentials{
+			"github.com": "github-token",
+			"gitlab.com": "gitlab-token",
+		},
+	}
+
+	tokenTests := []struct {
+		repoURL string
+		want    string
+	}{
+		{o.GitOpsRepoURL, "github-token"},
+		{o.ServiceRepoURL, "gitlab-token"},
+		{"https://bitbucket.org/my-org/template.git", "default-token"},
+	}
+	for _, tt := range tokenTests {
+		if got := o.accessToken(tt.repoURL); got != tt.want {
+			t.Errorf("accessToken(%q) got %q, want %q", tt.repoURL, got, tt.want)
+		}
+	}
+}
+
 func TestOrgRepoFromURL(t *testing.T) {
 	want := "my-org/gitops"
 	got, err := orgRepoFromURL(testGitOpsRepo)
@@ -175,7 +249,7 @@ func TestApplicationFromRepo(t *testing.T) {
 		Name: "http-api",
 	}
 
-	got, err := applicationFromRepo(testSvcRepo, svc)
+	got, err := applicationFromRepo(testSvcRepo, svc, validation.DNS1123LabelMaxLength)
 	fatalIfError(t, err)
 
 	if diff := cmp.Diff(want, got); diff != "" {
@@ -183,6 +257,27 @@ func TestApplicationFromRepo(t *testing.T) {
 	}
 }
 
+func TestDefaultServiceName(t *testing.T) {
+	longRepo := strings.Repeat("a", 80)
+	nameTests := []struct {
+		repoURL       string
+		maxNameLength int
+		want          string
+	}{
+		{"https://github.com/my-org/My_Repo.git", validation.DNS1123LabelMaxLength, "my-repo"},
+		{"https://github.com/my-org/" + longRepo + ".git", validation.DNS1123LabelMaxLength, longRepo[:63]},
+		{"https://github.com/my-org/" + longRepo + ".git", 80, longRepo},
+	}
+
+	for _, tt := range nameTests {
+		got, err := DefaultServiceName(tt.repoURL, tt.maxNameLength)
+		fatalIfError(t, err)
+		if got != tt.want {
+			t.Errorf("DefaultServiceName(%q, %d) got %q, want %q", tt.repoURL, tt.maxNameLength, got, tt.want)
+		}
+	}
+}
+
 func TestOverwriteFlag(t *testing.T) {
 	defer func(f secrets.PublicKeyFunc) {
 		secrets.DefaultPublicKeyFunc = f
@@ -215,6 +310,382 @@ func TestOverwriteFlag(t *testing.T) {
 
 }
 
+func TestBootstrapIntoRepositorySubpath(t *testing.T) {
+	defer func(f secrets.PublicKeyFunc) {
+		secrets.DefaultPublicKeyFunc = f
+	}(secrets.DefaultPublicKeyFunc)
+
+	secrets.DefaultPublicKeyFunc = func(service types.NamespacedName) (*rsa.PublicKey, error) {
+		key, err := rsa.GenerateKey(rand.Reader, 1024)
+		if err != nil {
+			t.Fatalf("failed to generate a private RSA key: %s", err)
+		}
+		return &key.PublicKey, nil
+	}
+	fakeFs := ioutils.NewMemoryFilesystem()
+	fatalIfError(t, fakeFs.MkdirAll("/repo/.git", 0755))
+	fatalIfError(t, afero.WriteFile(fakeFs, "/repo/pipelines.yaml", []byte("environments: []\n"), 0644))
+	params := &BootstrapOptions{
+		Prefix:               "tst-",
+		GitOpsRepoURL:        testGitOpsRepo,
+		ImageRepo:            "image/repo",
+		GitOpsWebhookSecret:  "123",
+		ServiceRepoURL:       testSvcRepo,
+		ServiceWebhookSecret: "456",
+		OutputPath:           "/repo/clusters/prod",
+	}
+	fatalIfError(t, Bootstrap(params, fakeFs))
+
+	manifest := mustReadFileAsMap(t, fakeFs, "/repo/clusters/prod/pipelines.yaml")
+	if manifest["gitops_path"] != "clusters/prod" {
+		t.Fatalf("got gitops_path %v, want %q", manifest["gitops_path"], "clusters/prod")
+	}
+	app := mustReadFileAsMap(t, fakeFs, "/repo/clusters/prod/config/argocd/tst-dev-app-http-api-app.yaml")
+	source := app["spec"].(map[string]interface{})["source"].(map[string]interface{})
+	if want := "clusters/prod/environments/tst-dev/apps/app-http-api/base"; source["path"] != want {
+		t.Fatalf("got application path %v, want %q", source["path"], want)
+	}
+
+	got := Bootstrap(params, fakeFs)
+	want := "pipelines.yaml in output path already exists. If you want to replace your existing files, please rerun with --overwrite"
+	if got == nil || got.Error() != want {
+		t.Fatalf("got error %v, want %s", got, want)
+	}
+}
+
+func TestBootstrapWithFluxEngine(t *testing.T) {
+	defer func(f secrets.PublicKeyFunc) {
+		secrets.DefaultPublicKeyFunc = f
+	}(secrets.DefaultPublicKeyFunc)
+
+	secrets.DefaultPublicKeyFunc = func(service types.NamespacedName) (*rsa.PublicKey, error) {
+		key, err := rsa.GenerateKey(rand.Reader, 1024)
+		if err != nil {
+			t.Fatalf("failed to generate a private RSA key: %s", err)
+		}
+		return &key.PublicKey, nil
+	}
+	fakeFs := ioutils.NewMemoryFilesystem()
+	params := &BootstrapOptions{
+		Prefix:               "tst-",
+		GitOpsRepoURL:        testGitOpsRepo,
+		ImageRepo:            "image/repo",
+		GitOpsWebhookSecret:  "123",
+		ServiceRepoURL:       testSvcRepo,
+		ServiceWebhookSecret: "456",
+		OutputPath:           "/tmp/gitops",
+		GitOpsEngine:         FluxEngine,
+	}
+	fatalIfError(t, Bootstrap(params, fakeFs))
+
+	m, err := config.LoadManifest(fakeFs, "/tmp/gitops")
+	fatalIfError(t, err)
+	want := &config.Config{
+		Pipelines: &config.PipelinesConfig{Name: "tst-cicd"},
+		Flux:      &config.FluxConfig{Namespace: "flux-system"},
+	}
+	if diff := cmp.Diff(want, m.Config); diff != "" {
+		t.Fatalf("manifest config didn't record the flux engine:\n%s", diff)
+	}
+
+	app := mustReadFileAsMap(t, fakeFs, "/tmp/gitops/config/flux/tst-dev-app-http-api-app.yaml")
+	if kind := app["kind"]; kind != "Kustomization" {
+		t.Fatalf("got kind %v, want Kustomization", kind)
+	}
+	exists, err := afero.DirExists(fakeFs, "/tmp/gitops/config/argocd")
+	fatalIfError(t, err)
+	if exists {
+		t.Fatal("Argo CD configuration was generated with the flux engine")
+	}
+}
+
+func TestBootstrapWithGitLabCIVariables(t *testing.T) {
+	defer func(f secrets.PublicKeyFunc) {
+		secrets.DefaultPublicKeyFunc = f
+	}(secrets.DefaultPublicKeyFunc)
+
+	secrets.DefaultPublicKeyFunc = func(service types.NamespacedName) (*rsa.PublicKey, error) {
+		key, err := rsa.GenerateKey(rand.Reader, 1024)
+		if err != nil {
+			t.Fatalf("failed to generate a private RSA key: %s", err)
+		}
+		return &key.PublicKey, nil
+	}
+	fake := &fakeCIVariables{variables: map[string]map[string]string{}, tokens: map[string]string{}}
+	defer stubCIVariableSetter(fake)()
+	fakeFs := ioutils.NewMemoryFilesystem()
+	params := &BootstrapOptions{
+		Prefix:               "tst-",
+		GitOpsRepoURL:        "https://gitlab.com/my-org/gitops.git",
+		ImageRepo:            "image/repo",
+		GitOpsWebhookSecret:  "123",
+		ServiceRepoURL:       testSvcRepo,
+		ServiceWebhookSecret: "456",
+		GitHostAccessToken:   "test-token",
+		GitLabCIVariables: map[string]string{
+			"CLUSTER_ENDPOINT": "https://api.example.com:6443",
+			"CLUSTER_NAME":     "production",
+		},
+	}
+	fatalIfError(t, Bootstrap(params, fakeFs))
+
+	want := map[string]map[string]string{
+		"https://gitlab.com/my-org/gitops.git": {
+			"CLUSTER_ENDPOINT": "https://api.example.com:6443",
+			"CLUSTER_NAME":     "production",
+		},
+	}
+	if diff := cmp.Diff(want, fake.variables); diff != "" {
+		t.Fatalf("CI/CD variables were not set on the GitLab repository:\n%s", diff)
+	}
+	if token := fake.tokens["https://gitlab.com/my-org/gitops.git"]; token != "test-token" {
+		t.Fatalf("got token %q, want %q", token, "test-token")
+	}
+}
+
+func TestBootstrapResumesAfterFailure(t *testing.T) {
+	defer stubDefaultPublicKeyFunc(t)()
+	fake := &fakeCIVariables{
+		variables: map[string]map[string]string{},
+		tokens:    map[string]string{},
+		failures:  map[string]error{"https://gitlab.com/my-org/http-api.git": errors.New("rate limited")},
+	}
+	defer stubCIVariableSetter(fake)()
+	fakeFs := ioutils.NewMemoryFilesystem()
+	params := &BootstrapOptions{
+		Prefix:               "tst-",
+		GitOpsRepoURL:        "https://gitlab.com/my-org/gitops.git",
+		ImageRepo:            "image/repo",
+		GitOpsWebhookSecret:  "123",
+		ServiceRepoURL:       "https://gitlab.com/my-org/http-api.git",
+		ServiceWebhookSecret: "456",
+		GitHostAccessToken:   "test-token",
+		OutputPath:           "/tmp/gitops",
+		GitLabCIVariables:    map[string]string{"CLUSTER_NAME": "production"},
+	}
+	err := Bootstrap(params, fakeFs)
+	helper.AssertErrorMatch(t, "failed to set CI/CD variable CLUSTER_NAME for https://gitlab.com/my-org/http-api.git: rate limited", err)
+	assertFileExists(t, fakeFs, "/tmp/gitops/.gitops-cli-state")
+	assertFileExists(t, fakeFs, "/tmp/gitops/.gitignore")
+	assertNoFile(t, fakeFs, "/tmp/gitops/pipelines.yaml")
+
+	// The variables of the GitOps repository were set by the failed bootstrap.
+	fake.variables = map[string]map[string]string{}
+	fake.failures = nil
+	fatalIfError(t, Bootstrap(params, fakeFs))
+
+	want := map[string]map[string]string{
+		"https://gitlab.com/my-org/http-api.git": {"CLUSTER_NAME": "production"},
+	}
+	if diff := cmp.Diff(want, fake.variables); diff != "" {
+		t.Fatalf("resumed bootstrap set the wrong CI/CD variables:\n%s", diff)
+	}
+	assertFileExists(t, fakeFs, "/tmp/gitops/pipelines.yaml")
+	assertNoFile(t, fakeFs, "/tmp/gitops/.gitops-cli-state")
+}
+
+func TestBootstrapWithDifferentOptionsIsNotResumed(t *testing.T) {
+	defer stubDefaultPublicKeyFunc(t)()
+	fake := &fakeCIVariables{
+		variables: map[string]map[string]string{},
+		tokens:    map[string]string{},
+		failures:  map[string]error{"https://gitlab.com/my-org/http-api.git": errors.New("rate limited")},
+	}
+	defer stubCIVariableSetter(fake)()
+	fakeFs := ioutils.NewMemoryFilesystem()
+	params := &BootstrapOptions{
+		Prefix:               "tst-",
+		GitOpsRepoURL:        "https://gitlab.com/my-org/gitops.git",
+		ImageRepo:            "image/repo",
+		GitOpsWebhookSecret:  "123",
+		ServiceRepoURL:       "https://gitlab.com/my-org/http-api.git",
+		ServiceWebhookSecret: "456",
+		GitHostAccessToken:   "test-token",
+		OutputPath:           "/tmp/gitops",
+		GitLabCIVariables:    map[string]string{"CLUSTER_NAME": "production"},
+	}
+	err := Bootstrap(params, fakeFs)
+	helper.AssertErrorMatch(t, "rate limited", err)
+
+	// Secrets aren't part of the recorded options.
+	fake.failures = nil
+	changed := *params
+	changed.GitHostAccessToken = "new-token"
+	changed.Prefix = "prod-"
+	err = Bootstrap(&changed, fakeFs)
+	helper.AssertErrorMatch(t, "the bootstrap state in /tmp/gitops/.gitops-cli-state was recorded with different options", err)
+	assertNoFile(t, fakeFs, "/tmp/gitops/pipelines.yaml")
+}
+
+func TestBootstrapInputs(t *testing.T) {
+	o := &BootstrapOptions{
+		Prefix:             "tst-",
+		GitHostAccessToken: "test-token",
+		Credentials:        map[string]string{"gitlab.com": "gitlab-token"},
+		GitLabCIVariables:  map[string]string{"CLUSTER_NAME": "production"},
+	}
+	want, err := bootstrapInputs(o)
+	fatalIfError(t, err)
+
+	inputTests := []struct {
+		desc    string
+		change  func(*BootstrapOptions)
+		changed bool
+	}{
+		{"access token", func(o *BootstrapOptions) { o.GitHostAccessToken = "other" }, false},
+		{"credential token", func(o *BootstrapOptions) { o.Credentials = map[string]string{"gitlab.com": "other"} }, false},
+		{"CI/CD variable value", func(o *BootstrapOptions) { o.GitLabCIVariables = map[string]string{"CLUSTER_NAME": "other"} }, false},
+		{"fresh", func(o *BootstrapOptions) { o.Fresh = true }, false},
+		{"prefix", func(o *BootstrapOptions) { o.Prefix = "prod-" }, true},
+		{"credential host", func(o *BootstrapOptions) { o.Credentials = map[string]string{"github.com": "gitlab-token"} }, true},
+		{"CI/CD variable name", func(o *BootstrapOptions) { o.GitLabCIVariables = map[string]string{"CLUSTER": "production"} }, true},
+	}
+
+	for _, tt := range inputTests {
+		t.Run(tt.desc, func(rt *testing.T) {
+			changed := *o
+			tt.change(&changed)
+			got, err := bootstrapInputs(&changed)
+			fatalIfError(rt, err)
+			if (got != want) != tt.changed {
+				rt.Fatalf("changing the %s changed the inputs got %v, want %v", tt.desc, got != want, tt.changed)
+			}
+		})
+	}
+}
+
+func TestBootstrapFreshIgnoresState(t *testing.T) {
+	defer stubDefaultPublicKeyFunc(t)()
+	fake := &fakeCIVariables{variables: map[string]map[string]string{}, tokens: map[string]string{}}
+	defer stubCIVariableSetter(fake)()
+	fakeFs := ioutils.NewMemoryFilesystem()
+	state := `{"completed": ["ci-variables https://gitlab.com/my-org/gitops.git", "files-written"]}`
+	fatalIfError(t, afero.WriteFile(fakeFs, "/tmp/gitops/.gitops-cli-state", []byte(state), 0644))
+	params := &BootstrapOptions{
+		Prefix:               "tst-",
+		GitOpsRepoURL:        "https://gitlab.com/my-org/gitops.git",
+		ImageRepo:            "image/repo",
+		GitOpsWebhookSecret:  "123",
+		ServiceRepoURL:       testSvcRepo,
+		ServiceWebhookSecret: "456",
+		OutputPath:           "/tmp/gitops",
+		GitLabCIVariables:    map[string]string{"CLUSTER_NAME": "production"},
+		Fresh:                true,
+	}
+	fatalIfError(t, Bootstrap(params, fakeFs))
+
+	want := map[string]map[string]string{
+		"https://gitlab.com/my-org/gitops.git": {"CLUSTER_NAME": "production"},
+	}
+	if diff := cmp.Diff(want, fake.variables); diff != "" {
+		t.Fatalf("fresh bootstrap set the wrong CI/CD variables:\n%s", diff)
+	}
+	assertFileExists(t, fakeFs, "/tmp/gitops/pipelines.yaml")
+}
+
+func TestBootstrapWithManifestFile(t *testing.T) {
+	defer func(f secrets.PublicKeyFunc) {
+		secrets.DefaultPublicKeyFunc = f
+	}(secrets.DefaultPublicKeyFunc)
+
+	secrets.DefaultPublicKeyFunc = func(service types.NamespacedName) (*rsa.PublicKey, error) {
+		key, err := rsa.GenerateKey(rand.Reader, 1024)
+		if err != nil {
+			t.Fatalf("failed to generate a private RSA key: %s", err)
+		}
+		return &key.PublicKey, nil
+	}
+	fakeFs := ioutils.NewMemoryFilesystem()
+	// An existing pipelines.yaml doesn't prevent bootstrapping to a different
+	// manifest file.
+	fatalIfError(t, afero.WriteFile(fakeFs, "/tmp/gitops/pipelines.yaml", []byte("environments:\n"), 0644))
+	params := &BootstrapOptions{
+		Prefix:               "tst-",
+		GitOpsRepoURL:        testGitOpsRepo,
+		ImageRepo:            "image/repo",
+		GitOpsWebhookSecret:  "123",
+		ServiceRepoURL:       testSvcRepo,
+		ServiceWebhookSecret: "456",
+		OutputPath:           "/tmp/gitops",
+		ManifestFile:         "gitops/index.yaml",
+	}
+	fatalIfError(t, Bootstrap(params, fakeFs))
+
+	m, err := config.ParseFile(fakeFs, "/tmp/gitops/gitops/index.yaml")
+	fatalIfError(t, err)
+	if diff := cmp.Diff(testpipelineConfig, m.Config.Pipelines); diff != "" {
+		t.Fatalf("manifest file didn't match:\n%s", diff)
+	}
+	b, err := afero.ReadFile(fakeFs, "/tmp/gitops/pipelines.yaml")
+	fatalIfError(t, err)
+	if string(b) != "environments:\n" {
+		t.Fatalf("pipelines.yaml was overwritten: %s", b)
+	}
+
+	err = Bootstrap(params, fakeFs)
+	helper.AssertErrorMatch(t, "gitops/index.yaml in output path already exists", err)
+
+	fatalIfError(t, AddEnv(&EnvParameters{
+		PipelinesFolderPath: "/tmp/gitops",
+		ManifestFile:        "gitops/index.yaml",
+		EnvName:             "tst-prod",
+	}, fakeFs))
+	fatalIfError(t, AddService(&AddServiceOptions{
+		PipelinesFolderPath: "/tmp/gitops",
+		ManifestFile:        "gitops/index.yaml",
+		AppName:             "app-new",
+		EnvName:             "tst-prod",
+		GitRepoURL:          "https://github.com/my-org/new-svc.git",
+		WebhookSecret:       "123",
+		ServiceName:         "new-svc",
+	}, fakeFs))
+
+	m, err = config.ParseFile(fakeFs, "/tmp/gitops/gitops/index.yaml")
+	fatalIfError(t, err)
+	env := m.GetEnvironment("tst-prod")
+	if env == nil || len(env.Apps) != 1 || env.Apps[0].Services[0].Name != "new-svc" {
+		t.Fatalf("environment and service were not added to the manifest file: %#v", env)
+	}
+	assertFileExists(t, fakeFs, "/tmp/gitops/environments/tst-prod/apps/app-new/services/new-svc/kustomization.yaml")
+	b, err = afero.ReadFile(fakeFs, "/tmp/gitops/pipelines.yaml")
+	fatalIfError(t, err)
+	if string(b) != "environments:\n" {
+		t.Fatalf("pipelines.yaml was overwritten: %s", b)
+	}
+}
+
+func TestBootstrapWithInvalidOutputPath(t *testing.T) {
+	fakeFs := ioutils.NewMemoryFilesystem()
+	fatalIfError(t, afero.WriteFile(fakeFs, "/tmp/output", []byte("not a directory"), 0644))
+
+	outputTests := []struct {
+		name       string
+		outputPath string
+		outputRoot string
+		wantErr    string
+	}{
+		{"output path is a file", "/tmp/output", "", `output path "/tmp/output" is a file, expected a directory`},
+		{"output path escapes root", "/tmp/gitops/../elsewhere", "/tmp/gitops", `output path "/tmp/gitops/../elsewhere" is outside of the output root "/tmp/gitops"`},
+	}
+
+	for _, tt := range outputTests {
+		t.Run(tt.name, func(rt *testing.T) {
+			params := &BootstrapOptions{
+				Prefix:         "tst-",
+				GitOpsRepoURL:  testGitOpsRepo,
+				ImageRepo:      "image/repo",
+				ServiceRepoURL: testSvcRepo,
+				OutputPath:     tt.outputPath,
+				OutputRoot:     tt.outputRoot,
+			}
+			err := Bootstrap(params, fakeFs)
+			if err == nil || err.Error() != tt.wantErr {
+				rt.Fatalf("Bootstrap() got %v, want %s", err, tt.wantErr)
+			}
+		})
+	}
+}
+
 func TestCreateManifest(t *testing.T) {
 	repoURL := "https://github.com/foo/bar.git"
 	want := &config.Manifest{
@@ -258,6 +729,93 @@ func TestInitialFiles(t *testing.T) {
 	}
 }
 
+func TestCICDResourcesWithOverrides(t *testing.T) {
+	defer stubDefaultPublicKeyFunc(t)()
+	o := BootstrapOptions{
+		Prefix:               "tst-",
+		GitOpsWebhookSecret:  "123",
+		SealedSecretsService: meta.NamespacedName("", ""),
+		CommitStatusTracker:  true,
+		ImageRegistry:        "registry.example.com/mirror",
+		TaskCatalogRef:       "buildah-v0-16",
+	}
+	repo, err := scm.NewRepository("https://github.com/foo/test-repo")
+	assertNoError(t, err)
+
+	resources, err := createCICDResources(ioutils.NewMemoryFilesystem(), repo, testpipelineConfig, &o)
+	fatalIfError(t, err)
+
+	task := resources[gitopsTasksPath].(pipelinev1.Task)
+	if diff := cmp.Diff("registry.example.com/mirror/redhat-developer/k8s-kubectl", task.Spec.Steps[0].Image); diff != "" {
+		t.Errorf("deploy-from-source task image failed:\n%s", diff)
+	}
+	pipeline := resources[appCiPipelinesPath].(*pipelinev1.Pipeline)
+	if diff := cmp.Diff("buildah-v0-16", pipeline.Spec.Tasks[0].TaskRef.Name); diff != "" {
+		t.Errorf("app-ci-pipeline build task failed:\n%s", diff)
+	}
+	tracker := resources["10-commit-status-tracker/operator.yaml"].(*appsv1.Deployment)
+	if diff := cmp.Diff("registry.example.com/mirror/redhat-developer/commit-status-tracker:v0.0.3", tracker.Spec.Template.Spec.Containers[0].Image); diff != "" {
+		t.Errorf("commit-status-tracker image failed:\n%s", diff)
+	}
+}
+
+func TestCICDResourcesWithImagePullSecrets(t *testing.T) {
+	defer stubDefaultPublicKeyFunc(t)()
+	fs := ioutils.NewMemoryFilesystem()
+	assertNoError(t, afero.WriteFile(fs, "/tmp/registry.json", []byte(`{"auths": {"registry.example.com": {"auth": "dXNlcjpwYXNz"}}}`), 0600))
+	o := BootstrapOptions{
+		Prefix:               "tst-",
+		GitOpsWebhookSecret:  "123",
+		SealedSecretsService: meta.NamespacedName("", ""),
+		ImagePullSecrets:     map[string]string{"private-registry": "/tmp/registry.json"},
+	}
+	repo, err := scm.NewRepository("https://github.com/foo/test-repo")
+	assertNoError(t, err)
+
+	resources, err := createCICDResources(fs, repo, testpipelineConfig, &o)
+	fatalIfError(t, err)
+
+	wantSecret := &ssv1alpha1.SealedSecret{
+		TypeMeta:   meta.TypeMeta("SealedSecret", "bitnami.com/v1alpha1"),
+		ObjectMeta: meta.ObjectMeta(types.NamespacedName{Name: "private-registry", Namespace: "tst-cicd"}),
+		Spec: ssv1alpha1.SealedSecretSpec{
+			Template: ssv1alpha1.SecretTemplateSpec{
+				ObjectMeta: meta.ObjectMeta(types.NamespacedName{Name: "private-registry", Namespace: "tst-cicd"}),
+				Type:       corev1.SecretTypeDockerConfigJson,
+			},
+		},
+	}
+	secret := resources["03-secrets/image-pull-secret-private-registry.yaml"]
+	if diff := cmp.Diff(wantSecret, secret,
+		cmpopts.IgnoreFields(ssv1alpha1.SealedSecret{}, "Spec.EncryptedData", "ObjectMeta.Annotations")); diff != "" {
+		t.Fatalf("image pull secret failed:\n%s", diff)
+	}
+	if _, ok := secret.(*ssv1alpha1.SealedSecret).Spec.EncryptedData[".dockerconfigjson"]; !ok {
+		t.Fatal("the Docker config was not sealed")
+	}
+	sa := resources[serviceAccountPath].(*corev1.ServiceAccount)
+	if diff := cmp.Diff([]corev1.LocalObjectReference{{Name: "private-registry"}}, sa.ImagePullSecrets); diff != "" {
+		t.Fatalf("service account image pull secrets failed:\n%s", diff)
+	}
+}
+
+func TestCICDResourcesWithInvalidImagePullSecret(t *testing.T) {
+	defer stubDefaultPublicKeyFunc(t)()
+	fs := ioutils.NewMemoryFilesystem()
+	assertNoError(t, afero.WriteFile(fs, "/tmp/registry.json", []byte(`{"auths": `), 0600))
+	o := BootstrapOptions{
+		Prefix:               "tst-",
+		GitOpsWebhookSecret:  "123",
+		SealedSecretsService: meta.NamespacedName("", ""),
+		ImagePullSecrets:     map[string]string{"private-registry": "/tmp/registry.json"},
+	}
+	repo, err := scm.NewRepository("https://github.com/foo/test-repo")
+	assertNoError(t, err)
+
+	_, err = createCICDResources(fs, repo, testpipelineConfig, &o)
+	helper.AssertErrorMatch(t, `failed to parse Docker config "/tmp/registry.json" for image pull secret private-registry`, err)
+}
+
 func ignoreSecrets(k string, v interface{}) bool {
 	return k == "config/tst-cicd/base/03-secrets/gitops-webhook-secret.yaml"
 }
@@ -421,9 +979,53 @@ func TestGenerateSecretsWithNoCommitStatusTracker(t *testing.T) {
 	}
 }
 
+func assertNoFile(t *testing.T, fs afero.Fs, path string) {
+	t.Helper()
+	exists, err := afero.Exists(fs, path)
+	fatalIfError(t, err)
+	if exists {
+		t.Fatalf("found file %q, it should not exist", path)
+	}
+}
+
 func fatalIfError(t *testing.T, err error) {
 	t.Helper()
 	if err != nil {
 		t.Fatal(err)
 	}
 }
+
+// fakeCIVariables records the CI/CD variables that are set, keyed by the
+// repository URL.
+type fakeCIVariables struct {
+	variables map[string]map[string]string
+	tokens    map[string]string
+	failures  map[string]error // Errors to return when setting variables, keyed by the repository URL.
+}
+
+type fakeCIVariableSetter struct {
+	repoURL string
+	fake    *fakeCIVariables
+}
+
+func (f *fakeCIVariableSetter) SetCIVariable(key, value string) error {
+	if err := f.fake.failures[f.repoURL]; err != nil {
+		return err
+	}
+	if f.fake.variables[f.repoURL] == nil {
+		f.fake.variables[f.repoURL] = map[string]string{}
+	}
+	f.fake.variables[f.repoURL][key] = value
+	return nil
+}
+
+func stubCIVariableSetter(fake *fakeCIVariables) func() {
+	orig := newCIVariableSetter
+	newCIVariableSetter = func(repoURL, token string) (ciVariableSetter, error) {
+		fake.tokens[repoURL] = token
+		return &fakeCIVariableSetter{repoURL: repoURL, fake: fake}, nil
+	}
+	return func() {
+		newCIVariableSetter = orig
+	}
+}
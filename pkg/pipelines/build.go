@@ -1,9 +1,18 @@
 package pipelines
 
 import (
+	"errors"
+	"path/filepath"
+	"strings"
+
+	"github.com/openshift/odo/pkg/log"
 	"github.com/rhd-gitops-example/gitops-cli/pkg/pipelines/argocd"
 	"github.com/rhd-gitops-example/gitops-cli/pkg/pipelines/config"
+	"github.com/rhd-gitops-example/gitops-cli/pkg/pipelines/dryrun"
 	"github.com/rhd-gitops-example/gitops-cli/pkg/pipelines/environments"
+	fluxcd "github.com/rhd-gitops-example/gitops-cli/pkg/pipelines/flux"
+	"github.com/rhd-gitops-example/gitops-cli/pkg/pipelines/helm"
+	"github.com/rhd-gitops-example/gitops-cli/pkg/pipelines/ioutils"
 	res "github.com/rhd-gitops-example/gitops-cli/pkg/pipelines/resources"
 	"github.com/rhd-gitops-example/gitops-cli/pkg/pipelines/yaml"
 	"github.com/spf13/afero"
@@ -13,12 +22,43 @@ import (
 // command.
 type BuildParameters struct {
 	PipelinesFolderPath string
+	ManifestFile        string // Path of the manifest within the PipelinesFolderPath, defaults to pipelines.yaml.
 	OutputPath          string
+	OutputRoot          string // If set, the OutputPath must be within this directory.
+	UseApplicationSet   bool   // Generate an ApplicationSet rather than individual Applications.
+	Package             string // How the applications are packaged, PackageKustomize by default, or PackageHelm.
+	OutputArchive       string // If set, the resources are written to this gzip compressed tar archive, rather than the OutputPath.
+
+	// If set, the built resources are applied with a server-side dry run, so
+	// that resources rejected by the cluster's admission controllers fail the
+	// build.
+	DryRunApplier dryrun.Applier
+
+	// Warnf reports warnings, if it's nil, they're written with log.Warningf.
+	Warnf func(format string, a ...interface{})
 }
 
+// The ways that the resources of the applications can be packaged for
+// deployment, the default is PackageKustomize.
+const (
+	PackageKustomize = "kustomize"
+	PackageHelm      = "helm"
+)
+
+// Packages is the set of valid packages.
+var Packages = []string{PackageKustomize, PackageHelm}
+
 // BuildResources builds all resources from a pipelines.
 func BuildResources(o *BuildParameters, appFs afero.Fs) error {
-	m, err := config.LoadManifest(appFs, o.PipelinesFolderPath)
+	if err := ioutils.ValidateOutputPath(appFs, o.OutputPath, o.OutputRoot); err != nil {
+		return err
+	}
+	if o.OutputArchive != "" {
+		if err := ioutils.ValidateOutputPath(appFs, filepath.Dir(o.OutputArchive), o.OutputRoot); err != nil {
+			return err
+		}
+	}
+	m, err := config.LoadManifestFile(appFs, o.PipelinesFolderPath, o.ManifestFile)
 	if err != nil {
 		return err
 	}
@@ -26,16 +66,47 @@ func BuildResources(o *BuildParameters, appFs afero.Fs) error {
 	if err != nil {
 		return err
 	}
-	_, err = yaml.WriteResources(appFs, o.OutputPath, resources)
+	if err := writeBuiltResources(appFs, o, resources); err != nil {
+		return err
+	}
+	if o.DryRunApplier == nil {
+		return nil
+	}
+	skipped, err := dryrun.ServerDryRun(resources, o.DryRunApplier)
+	if len(skipped) > 0 {
+		warningf(o.Warnf, "Resources in namespaces that don't exist yet were not checked: %s", strings.Join(skipped, ", "))
+	}
 	return err
 }
 
+// writeBuiltResources writes the resources to the OutputArchive if it's set,
+// otherwise, the changed resources are written to the OutputPath.
+func writeBuiltResources(fs afero.Fs, o *BuildParameters, resources res.Resources) error {
+	if o.OutputArchive != "" {
+		written, err := yaml.WriteArchive(fs, o.OutputArchive, resources)
+		if err != nil {
+			return err
+		}
+		log.Successf("Wrote %d files to the archive %s", len(written), o.OutputArchive)
+		return nil
+	}
+	_, unchanged, err := yaml.WriteChangedResources(fs, o.OutputPath, resources)
+	if err != nil {
+		return err
+	}
+	if len(unchanged) > 0 {
+		log.Infof("Skipped %d unchanged files", len(unchanged))
+	}
+	return nil
+}
+
 func buildResources(fs afero.Fs, o *BuildParameters, m *config.Manifest) (res.Resources, error) {
 	resources := res.Resources{}
 
 	argoCD := m.GetArgoCDConfig()
+	flux := m.GetFluxConfig()
 	appLinks := environments.EnvironmentsToApps
-	if argoCD != nil {
+	if argoCD != nil || flux != nil {
 		appLinks = environments.AppsToEnvironments
 	}
 
@@ -51,10 +122,27 @@ func buildResources(fs afero.Fs, o *BuildParameters, m *config.Manifest) (res.Re
 	}
 
 	resources = res.Merge(elFiles, resources)
-	argoApps, err := argocd.Build(argocd.ArgoCDNamespace, m.GitOpsURL, m)
+	buildApps, appsNS := argocd.Build, argocd.ArgoCDNamespace
+	switch {
+	case o.Package == PackageHelm:
+		if argoCD == nil || o.UseApplicationSet {
+			return nil, errors.New("Helm charts can only be deployed by Argo CD Applications")
+		}
+		charts, err := helm.Build(fs, o.OutputPath, m, resources)
+		if err != nil {
+			return nil, err
+		}
+		resources = res.Merge(charts, resources)
+		buildApps = argocd.BuildHelm
+	case flux != nil:
+		buildApps, appsNS = fluxcd.Build, fluxcd.FluxNamespace
+	case o.UseApplicationSet:
+		buildApps = argocd.BuildApplicationSet
+	}
+	apps, err := buildApps(appsNS, m.GitOpsURL, m)
 	if err != nil {
 		return nil, err
 	}
-	resources = res.Merge(argoApps, resources)
+	resources = res.Merge(apps, resources)
 	return resources, nil
 }
@@ -0,0 +1,192 @@
+package pipelines
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/spf13/afero"
+	yamlv3 "gopkg.in/yaml.v3"
+	"k8s.io/apimachinery/pkg/api/validation"
+
+	"github.com/rhd-gitops-example/gitops-cli/pkg/pipelines/git"
+	"github.com/rhd-gitops-example/gitops-cli/pkg/pipelines/repotemplate"
+)
+
+const (
+	catalogFilename          = "catalog-info.yaml"
+	catalogComponentKind     = "Component"
+	sourceLocationAnnotation = "backstage.io/source-location"
+)
+
+// catalogRepoFiles is used to fetch the files from a catalog repository, it's
+// a var to allow replacement in tests.
+var catalogRepoFiles = repotemplate.Files
+
+// ImportServiceOptions control how services are imported from a Backstage
+// catalog, the service name and Git repository are taken from each Component.
+type ImportServiceOptions struct {
+	AddServiceOptions
+	FromCatalog string // A catalog-info.yaml file, a directory to search, or a Git repository URL.
+}
+
+// catalogEntity is the part of a Backstage catalog entity that's needed to
+// create a service.
+type catalogEntity struct {
+	Kind     string `yaml:"kind"`
+	Metadata struct {
+		Name        string            `yaml:"name"`
+		Annotations map[string]string `yaml:"annotations"`
+	} `yaml:"metadata"`
+}
+
+// ImportServices adds a service to the environment and application for each
+// Component entity in the catalog, other kinds of entity are skipped.
+//
+// All the Components are validated before any services are added, and the
+// names of the added services are returned.
+func ImportServices(o *ImportServiceOptions, appFs afero.Fs) ([]string, error) {
+	files, err := catalogFiles(appFs, o.FromCatalog)
+	if err != nil {
+		return nil, err
+	}
+	services, err := catalogServices(files)
+	if err != nil {
+		return nil, err
+	}
+	if len(services) == 0 {
+		return nil, fmt.Errorf("no Component entities found in %s", o.FromCatalog)
+	}
+	names := []string{}
+	for _, svc := range services {
+		svcOptions := o.AddServiceOptions
+		svcOptions.ServiceName = svc.name
+		svcOptions.GitRepoURL = git.AddGitSuffixIfNecessary(svc.repoURL)
+		if err := AddService(&svcOptions, appFs); err != nil {
+			return names, fmt.Errorf("failed to import service %s from %s: %w", svc.name, svc.filename, err)
+		}
+		names = append(names, svc.name)
+	}
+	return names, nil
+}
+
+type catalogService struct {
+	name     string
+	repoURL  string
+	filename string
+}
+
+// catalogServices parses the catalog files, in filename order, and returns a
+// service for each Component.
+func catalogServices(files map[string][]byte) ([]catalogService, error) {
+	filenames := []string{}
+	for k := range files {
+		filenames = append(filenames, k)
+	}
+	sort.Strings(filenames)
+
+	services := []catalogService{}
+	seen := map[string]string{}
+	for _, filename := range filenames {
+		dec := yamlv3.NewDecoder(bytes.NewReader(files[filename]))
+		for {
+			var entity catalogEntity
+			err := dec.Decode(&entity)
+			if err == io.EOF {
+				break
+			}
+			if err != nil {
+				return nil, fmt.Errorf("failed to parse catalog file %s: %w", filename, err)
+			}
+			if entity.Kind != catalogComponentKind {
+				continue
+			}
+			name := entity.Metadata.Name
+			if errs := validation.NameIsDNS1035Label(name, false); len(errs) > 0 {
+				return nil, fmt.Errorf("invalid service name %q in catalog file %s: %s", name, filename, errs[0])
+			}
+			if other, ok := seen[name]; ok {
+				return nil, fmt.Errorf("duplicate Component %q in catalog files %s and %s", name, other, filename)
+			}
+			seen[name] = filename
+			services = append(services, catalogService{
+				name:     name,
+				repoURL:  sourceLocationRepo(entity.Metadata.Annotations[sourceLocationAnnotation]),
+				filename: filename,
+			})
+		}
+	}
+	return services, nil
+}
+
+// sourceLocationRepo returns the repository URL from a Backstage source
+// location, e.g. "url:https://github.com/org/repo/tree/main/" is
+// "https://github.com/org/repo".
+func sourceLocationRepo(location string) string {
+	location = strings.TrimPrefix(location, "url:")
+	for _, sep := range []string{"/-/tree/", "/-/blob/", "/tree/", "/blob/", "/src/"} {
+		if i := strings.Index(location, sep); i != -1 {
+			location = location[:i]
+			break
+		}
+	}
+	return strings.TrimSuffix(location, "/")
+}
+
+// catalogFiles returns the catalog-info.yaml files from a local file or
+// directory, or if the path doesn't exist locally, from a Git repository.
+func catalogFiles(appFs afero.Fs, from string) (map[string][]byte, error) {
+	info, err := appFs.Stat(from)
+	if errors.Is(err, os.ErrNotExist) {
+		return catalogFilesFromRepo(from)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read catalog %s: %w", from, err)
+	}
+	if !info.IsDir() {
+		b, err := afero.ReadFile(appFs, from)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read catalog %s: %w", from, err)
+		}
+		return map[string][]byte{from: b}, nil
+	}
+	files := map[string][]byte{}
+	err = afero.Walk(appFs, from, func(filename string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if info.IsDir() || info.Name() != catalogFilename {
+			return nil
+		}
+		b, err := afero.ReadFile(appFs, filename)
+		if err != nil {
+			return err
+		}
+		files[filename] = b
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to read catalog %s: %w", from, err)
+	}
+	return files, nil
+}
+
+func catalogFilesFromRepo(repoURL string) (map[string][]byte, error) {
+	repoFiles, err := catalogRepoFiles(repoURL, repotemplate.Options{})
+	if err != nil {
+		return nil, err
+	}
+	files := map[string][]byte{}
+	for k, v := range repoFiles {
+		if path.Base(k) == catalogFilename {
+			files[filepath.FromSlash(k)] = v
+		}
+	}
+	return files, nil
+}
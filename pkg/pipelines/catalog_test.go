@@ -0,0 +1,165 @@
+package pipelines
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"regexp"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/spf13/afero"
+	"sigs.k8s.io/yaml"
+
+	"github.com/rhd-gitops-example/gitops-cli/pkg/pipelines/config"
+	"github.com/rhd-gitops-example/gitops-cli/pkg/pipelines/ioutils"
+	"github.com/rhd-gitops-example/gitops-cli/pkg/pipelines/repotemplate"
+)
+
+func TestImportServicesFromCatalogFile(t *testing.T) {
+	fakeFs, outputPath := manifestFixture(t, buildManifest(false, false))
+	catalog, err := ioutil.ReadFile("testdata/catalog/catalog-info.yaml")
+	assertNoError(t, err)
+	catalogPath := filepath.Join(outputPath, "payments", "catalog-info.yaml")
+	assertNoError(t, afero.WriteFile(fakeFs, catalogPath, catalog, 0644))
+
+	names, err := ImportServices(&ImportServiceOptions{
+		AddServiceOptions: AddServiceOptions{
+			AppName:             "payments",
+			EnvName:             "test-dev",
+			PipelinesFolderPath: outputPath,
+		},
+		FromCatalog: filepath.Join(outputPath, "payments"),
+	}, fakeFs)
+	assertNoError(t, err)
+
+	if diff := cmp.Diff([]string{"payment-api", "payment-worker"}, names); diff != "" {
+		t.Fatalf("ImportServices() names failed:\n%s", diff)
+	}
+	m, err := config.ParsePipelinesFolder(fakeFs, outputPath)
+	assertNoError(t, err)
+	want := []*config.Service{
+		{Name: "payment-api", SourceURL: "https://github.com/org/payment-api.git"},
+		{Name: "payment-worker", SourceURL: "https://github.com/org/payment-worker.git"},
+	}
+	if diff := cmp.Diff(want, m.GetApplication("test-dev", "payments").Services); diff != "" {
+		t.Fatalf("ImportServices() services failed:\n%s", diff)
+	}
+	assertFileExists(t, fakeFs, filepath.Join(outputPath, "environments/test-dev/apps/payments/services/payment-worker/kustomization.yaml"))
+}
+
+func TestImportServicesFromCatalogRepository(t *testing.T) {
+	fakeFs, outputPath := manifestFixture(t, buildManifest(false, false))
+	defer stubCatalogRepoFiles(t, map[string][]byte{
+		"catalog-info.yaml": []byte(`
+apiVersion: backstage.io/v1alpha1
+kind: Component
+metadata:
+  name: inventory
+  annotations:
+    backstage.io/source-location: url:https://github.com/org/inventory
+`),
+		"docs/index.md": []byte("# Inventory\n"),
+	})()
+
+	names, err := ImportServices(&ImportServiceOptions{
+		AddServiceOptions: AddServiceOptions{
+			AppName:             "test-app",
+			EnvName:             "test-dev",
+			PipelinesFolderPath: outputPath,
+		},
+		FromCatalog: "https://github.com/org/inventory.git",
+	}, fakeFs)
+	assertNoError(t, err)
+
+	if diff := cmp.Diff([]string{"inventory"}, names); diff != "" {
+		t.Fatalf("ImportServices() names failed:\n%s", diff)
+	}
+}
+
+func TestImportServicesWithInvalidCatalog(t *testing.T) {
+	catalogTests := []struct {
+		name    string
+		catalog string
+		wantErr string
+	}{
+		{
+			"invalid name",
+			"kind: Component\nmetadata:\n  name: Payment_API\n",
+			`invalid service name "Payment_API" in catalog file .*catalog-info.yaml`,
+		},
+		{
+			"no components",
+			"kind: System\nmetadata:\n  name: payments\n",
+			"no Component entities found in .*catalog-info.yaml",
+		},
+		{
+			"duplicate components",
+			"kind: Component\nmetadata:\n  name: payments\n---\nkind: Component\nmetadata:\n  name: payments\n",
+			`duplicate Component "payments"`,
+		},
+	}
+
+	for _, tt := range catalogTests {
+		t.Run(tt.name, func(rt *testing.T) {
+			fakeFs, outputPath := manifestFixture(rt, buildManifest(false, false))
+			catalogPath := filepath.Join(outputPath, "catalog-info.yaml")
+			assertNoError(rt, afero.WriteFile(fakeFs, catalogPath, []byte(tt.catalog), 0644))
+
+			_, err := ImportServices(&ImportServiceOptions{
+				AddServiceOptions: AddServiceOptions{
+					AppName:             "test-app",
+					EnvName:             "test-dev",
+					PipelinesFolderPath: outputPath,
+				},
+				FromCatalog: catalogPath,
+			}, fakeFs)
+
+			if err == nil || !regexp.MustCompile(tt.wantErr).MatchString(err.Error()) {
+				rt.Fatalf("ImportServices() got error %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestSourceLocationRepo(t *testing.T) {
+	locationTests := []struct {
+		location string
+		want     string
+	}{
+		{"", ""},
+		{"url:https://github.com/org/repo", "https://github.com/org/repo"},
+		{"url:https://github.com/org/repo/", "https://github.com/org/repo"},
+		{"url:https://github.com/org/repo/tree/main/", "https://github.com/org/repo"},
+		{"url:https://github.com/org/repo/blob/main/catalog-info.yaml", "https://github.com/org/repo"},
+		{"url:https://gitlab.com/org/repo/-/tree/main/", "https://gitlab.com/org/repo"},
+		{"url:https://bitbucket.org/org/repo/src/main/", "https://bitbucket.org/org/repo"},
+	}
+
+	for _, tt := range locationTests {
+		if got := sourceLocationRepo(tt.location); got != tt.want {
+			t.Errorf("sourceLocationRepo(%q) got %q, want %q", tt.location, got, tt.want)
+		}
+	}
+}
+
+// manifestFixture returns a filesystem with the manifest, and the path of the
+// GitOps repository that it's written to.
+func manifestFixture(t *testing.T, m *config.Manifest) (afero.Fs, string) {
+	t.Helper()
+	fakeFs := ioutils.NewMemoryFilesystem()
+	outputPath := afero.GetTempDir(fakeFs, "test")
+	b, err := yaml.Marshal(m)
+	assertNoError(t, err)
+	assertNoError(t, afero.WriteFile(fakeFs, filepath.Join(outputPath, pipelinesFile), b, 0644))
+	return fakeFs, outputPath
+}
+
+func stubCatalogRepoFiles(t *testing.T, files map[string][]byte) func() {
+	origFunc := catalogRepoFiles
+	catalogRepoFiles = func(repoURL string, o repotemplate.Options) (map[string][]byte, error) {
+		return files, nil
+	}
+	return func() {
+		catalogRepoFiles = origFunc
+	}
+}
@@ -1,14 +1,115 @@
 package clientconfig
 
 import (
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+
 	"k8s.io/client-go/rest"
 	"k8s.io/client-go/tools/clientcmd"
+
+	"github.com/rhd-gitops-example/gitops-cli/pkg/pipelines/network"
 )
 
+// CAFile is the path to a PEM file of additional certificate authorities to
+// trust for the Kubernetes API, this is set from the --k8s-ca-file flag.
+var CAFile string
+
+// Impersonate is the user and groups to act as for requests to the Kubernetes
+// API, this is set from the --as and --as-group flags.
+var Impersonate rest.ImpersonationConfig
+
+// ImpersonateUID is the UID to act as, this is set from the --as-uid flag.
+var ImpersonateUID string
+
+// Context is the kubeconfig context to use, this is set from the --context
+// flag, if empty, the kubeconfig's current context is used.
+var Context string
+
+// impersonateUIDHeader is the header used to impersonate a UID, this isn't
+// supported by rest.ImpersonationConfig in this version of client-go.
+const impersonateUIDHeader = "Impersonate-Uid"
+
 // GetRESTConfig returns client config to be used to create client
 func GetRESTConfig() (*rest.Config, error) {
 	loadingRules := clientcmd.NewDefaultClientConfigLoadingRules()
-	configOverrides := &clientcmd.ConfigOverrides{}
+	configOverrides := &clientcmd.ConfigOverrides{CurrentContext: Context}
 	kubeconfig := clientcmd.NewNonInteractiveDeferredLoadingClientConfig(loadingRules, configOverrides)
-	return kubeconfig.ClientConfig()
+	cfg, err := kubeconfig.ClientConfig()
+	if err != nil {
+		return nil, err
+	}
+	if CAFile != "" {
+		if err := AddCAFile(cfg, CAFile); err != nil {
+			return nil, err
+		}
+	}
+	if err := AddImpersonation(cfg, Impersonate, ImpersonateUID); err != nil {
+		return nil, err
+	}
+	if network.Restricted() {
+		cfg.Dial = network.Dial
+	}
+	return cfg, nil
+}
+
+// AddImpersonation configures the requests made with the config to act as
+// another user, with the same semantics as kubectl's --as, --as-group and
+// --as-uid flags.
+//
+// If no user is provided, the config is unchanged.
+func AddImpersonation(cfg *rest.Config, impersonate rest.ImpersonationConfig, uid string) error {
+	if impersonate.UserName == "" {
+		if len(impersonate.Groups) > 0 || uid != "" {
+			return errors.New("impersonating a group or UID requires a user to impersonate, please provide --as")
+		}
+		return nil
+	}
+	cfg.Impersonate = impersonate
+	if uid != "" {
+		cfg.Wrap(func(rt http.RoundTripper) http.RoundTripper {
+			return &uidRoundTripper{uid: uid, delegate: rt}
+		})
+	}
+	return nil
+}
+
+type uidRoundTripper struct {
+	uid      string
+	delegate http.RoundTripper
+}
+
+func (rt *uidRoundTripper) RoundTrip(req *http.Request) (*http.Response, error) {
+	req = req.Clone(req.Context())
+	req.Header.Set(impersonateUIDHeader, rt.uid)
+	return rt.delegate.RoundTrip(req)
+}
+
+// AddCAFile adds the certificates in the PEM file to the certificate
+// authorities trusted by the config, alongside any from the kubeconfig.
+func AddCAFile(cfg *rest.Config, filename string) error {
+	extra, err := ioutil.ReadFile(filename)
+	if err != nil {
+		return fmt.Errorf("failed to read the Kubernetes CA file: %w", err)
+	}
+	if !x509.NewCertPool().AppendCertsFromPEM(extra) {
+		return fmt.Errorf("failed to find any PEM certificates in the Kubernetes CA file %s", filename)
+	}
+	caData := cfg.TLSClientConfig.CAData
+	// The CAData takes precedence over the CAFile, so the CAFile certificates
+	// are copied over to keep trusting them.
+	if len(caData) == 0 && cfg.TLSClientConfig.CAFile != "" {
+		caData, err = ioutil.ReadFile(cfg.TLSClientConfig.CAFile)
+		if err != nil {
+			return fmt.Errorf("failed to read the kubeconfig CA file: %w", err)
+		}
+		cfg.TLSClientConfig.CAFile = ""
+	}
+	if len(caData) > 0 && caData[len(caData)-1] != '\n' {
+		caData = append(caData, '\n')
+	}
+	cfg.TLSClientConfig.CAData = append(caData, extra...)
+	return nil
 }
@@ -0,0 +1,188 @@
+package clientconfig
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"io/ioutil"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	"k8s.io/client-go/rest"
+)
+
+func TestAddCAFile(t *testing.T) {
+	dir := tempDir(t)
+	defer os.RemoveAll(dir)
+	kubeCA := makeCertificate(t, "kube-ca")
+	extraCA := makeCertificate(t, "extra-ca")
+	kubeCAFile := writeFile(t, dir, "kube-ca.pem", kubeCA)
+	extraCAFile := writeFile(t, dir, "extra-ca.pem", extraCA)
+
+	caTests := []struct {
+		name string
+		tls  rest.TLSClientConfig
+	}{
+		{"CA data in kubeconfig", rest.TLSClientConfig{CAData: kubeCA}},
+		{"CA file in kubeconfig", rest.TLSClientConfig{CAFile: kubeCAFile}},
+	}
+
+	for _, tt := range caTests {
+		t.Run(tt.name, func(rt *testing.T) {
+			cfg := &rest.Config{Host: "https://api.example.com:6443", TLSClientConfig: tt.tls}
+
+			if err := AddCAFile(cfg, extraCAFile); err != nil {
+				rt.Fatal(err)
+			}
+
+			if cfg.TLSClientConfig.CAFile != "" {
+				rt.Fatalf("CAFile got %q, want it to be cleared", cfg.TLSClientConfig.CAFile)
+			}
+			got := certificateNames(rt, cfg.TLSClientConfig.CAData)
+			want := []string{"kube-ca", "extra-ca"}
+			if len(got) != len(want) || got[0] != want[0] || got[1] != want[1] {
+				rt.Fatalf("CAData got certificates %v, want %v", got, want)
+			}
+			if _, err := rest.TransportFor(cfg); err != nil {
+				rt.Fatalf("failed to create a transport with the CAData: %s", err)
+			}
+		})
+	}
+}
+
+func TestAddCAFileWithNoCertificates(t *testing.T) {
+	dir := tempDir(t)
+	defer os.RemoveAll(dir)
+	filename := writeFile(t, dir, "ca.pem", []byte("not a certificate"))
+
+	err := AddCAFile(&rest.Config{}, filename)
+
+	if err == nil || !regexp.MustCompile("failed to find any PEM certificates").MatchString(err.Error()) {
+		t.Fatalf("got error %v, want no PEM certificates error", err)
+	}
+}
+
+func TestAddImpersonation(t *testing.T) {
+	var got http.Header
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got = r.Header
+	}))
+	defer ts.Close()
+	cfg := &rest.Config{Host: ts.URL}
+
+	err := AddImpersonation(cfg, rest.ImpersonationConfig{
+		UserName: "system:serviceaccount:cicd:pipeline",
+		Groups:   []string{"auditors", "system:authenticated"},
+	}, "b79dbf30-0c6a-11ed-861d-0242ac120002")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if cfg.Impersonate.UserName != "system:serviceaccount:cicd:pipeline" {
+		t.Fatalf("Impersonate.UserName got %q, want %q", cfg.Impersonate.UserName, "system:serviceaccount:cicd:pipeline")
+	}
+	rt, err := rest.TransportFor(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp, err := (&http.Client{Transport: rt}).Get(ts.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	headerTests := []struct {
+		header string
+		want   string
+	}{
+		{"Impersonate-User", "system:serviceaccount:cicd:pipeline"},
+		{"Impersonate-Group", "auditors,system:authenticated"},
+		{"Impersonate-Uid", "b79dbf30-0c6a-11ed-861d-0242ac120002"},
+	}
+	for _, tt := range headerTests {
+		if v := strings.Join(got[tt.header], ","); v != tt.want {
+			t.Errorf("%s header got %q, want %q", tt.header, v, tt.want)
+		}
+	}
+}
+
+func TestAddImpersonationWithNoUser(t *testing.T) {
+	cfg := &rest.Config{}
+	if err := AddImpersonation(cfg, rest.ImpersonationConfig{}, ""); err != nil {
+		t.Fatal(err)
+	}
+	if cfg.Impersonate.UserName != "" || cfg.WrapTransport != nil {
+		t.Fatalf("config was changed with no user to impersonate: %#v", cfg)
+	}
+
+	err := AddImpersonation(cfg, rest.ImpersonationConfig{Groups: []string{"auditors"}}, "")
+	if err == nil || !regexp.MustCompile("requires a user to impersonate").MatchString(err.Error()) {
+		t.Fatalf("got error %v, want a user is required error", err)
+	}
+}
+
+func makeCertificate(t *testing.T, name string) []byte {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: name},
+		NotBefore:             time.Now(),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+		KeyUsage:              x509.KeyUsageCertSign,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})
+}
+
+func certificateNames(t *testing.T, b []byte) []string {
+	t.Helper()
+	names := []string{}
+	for {
+		var block *pem.Block
+		block, b = pem.Decode(b)
+		if block == nil {
+			return names
+		}
+		cert, err := x509.ParseCertificate(block.Bytes)
+		if err != nil {
+			t.Fatal(err)
+		}
+		names = append(names, cert.Subject.CommonName)
+	}
+}
+
+func tempDir(t *testing.T) string {
+	t.Helper()
+	dir, err := ioutil.TempDir("", "clientconfig")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return dir
+}
+
+func writeFile(t *testing.T, dir, name string, b []byte) string {
+	t.Helper()
+	filename := filepath.Join(dir, name)
+	if err := ioutil.WriteFile(filename, b, 0644); err != nil {
+		t.Fatal(err)
+	}
+	return filename
+}
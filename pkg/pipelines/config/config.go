@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"path/filepath"
 	"sort"
+	"strings"
 )
 
 const (
@@ -36,14 +37,33 @@ func PathForArgoCD() string {
 	return filepath.Join("config", "argocd")
 }
 
+// PathForFlux returns the path for recording Flux configuration.
+func PathForFlux() string {
+	return filepath.Join("config", "flux")
+}
+
+// LatestVersion is the newest version of the manifest that this version of the
+// CLI understands.
+const LatestVersion = 1
+
 // Manifest describes a set of environments, apps and services for deployment.
+//
+// The GitOpsPath is the directory within the GitOpsURL repository that the
+// configuration is in, if it's not the root of the repository.
 type Manifest struct {
 	GitOpsURL    string         `json:"gitops_url,omitempty"`
+	GitOpsPath   string         `json:"gitops_path,omitempty"`
 	Environments []*Environment `json:"environments,omitempty"`
 	Config       *Config        `json:"config,omitempty"`
 	Version      int            `json:"version,omitempty"`
 }
 
+// GitOpsRepoPath returns the path within the GitOps repository of a path
+// relative to the configuration.
+func (m *Manifest) GitOpsRepoPath(path string) string {
+	return filepath.Join(m.GitOpsPath, path)
+}
+
 // GetEnvironment returns a named environment if it exists in the configuration.
 func (m *Manifest) GetEnvironment(n string) *Environment {
 	for _, env := range m.Environments {
@@ -101,32 +121,182 @@ func (m *Manifest) GetArgoCDConfig() *ArgoCDConfig {
 	return nil
 }
 
+// GetFluxConfig returns the global Flux configuration, if one exists.
+func (m *Manifest) GetFluxConfig() *FluxConfig {
+	if m.Config != nil {
+		return m.Config.Flux
+	}
+	return nil
+}
+
 // Environment is a slice of Apps, these are the named apps in the namespace.
 //
 type Environment struct {
-	Name      string         `json:"name,omitempty"`
-	Cluster   string         `json:"cluster,omitempty"`
-	Pipelines *Pipelines     `json:"pipelines,omitempty"`
-	Apps      []*Application `json:"apps,omitempty"`
+	Name       string         `json:"name,omitempty"`
+	Cluster    string         `json:"cluster,omitempty"`
+	SyncPolicy string         `json:"sync_policy,omitempty"`
+	NoDeploy   bool           `json:"no_deploy,omitempty"`
+	Pipelines  *Pipelines     `json:"pipelines,omitempty"`
+	Quota      *QuotaConfig   `json:"quota,omitempty"`
+	Apps       []*Application `json:"apps,omitempty"`
+}
+
+// Deploys returns true if the applications in the environment are deployed,
+// environments that are only used for pipeline runs are not.
+func (e *Environment) Deploys() bool {
+	return !e.NoDeploy
+}
+
+// The sync policies for the Argo CD Applications of an Environment, an
+// automated policy can be followed by sub-options e.g. "auto+prune+selfheal".
+const (
+	SyncPolicyAuto     = "auto"
+	SyncPolicyManual   = "manual"
+	SyncOptionPrune    = "prune"
+	SyncOptionSelfHeal = "selfheal"
+)
+
+// SyncPolicy is the parsed form of an Environment's SyncPolicy.
+type SyncPolicy struct {
+	Automated bool
+	Prune     bool
+	SelfHeal  bool
+}
+
+// ParseSyncPolicy parses a sync policy, an empty policy is automated, with
+// pruning and self-healing, which is what's generated by default.
+func ParseSyncPolicy(s string) (*SyncPolicy, error) {
+	if s == "" {
+		return &SyncPolicy{Automated: true, Prune: true, SelfHeal: true}, nil
+	}
+	if s == SyncPolicyManual {
+		return &SyncPolicy{}, nil
+	}
+	tokens := strings.Split(s, "+")
+	if tokens[0] != SyncPolicyAuto {
+		return nil, fmt.Errorf("invalid sync policy %q, must be %s or %s", s, SyncPolicyAuto, SyncPolicyManual)
+	}
+	policy := &SyncPolicy{Automated: true}
+	for _, t := range tokens[1:] {
+		switch t {
+		case SyncOptionPrune:
+			policy.Prune = true
+		case SyncOptionSelfHeal:
+			policy.SelfHeal = true
+		default:
+			return nil, fmt.Errorf("invalid sync policy %q, unknown option %q, must be %s or %s", s, t, SyncOptionPrune, SyncOptionSelfHeal)
+		}
+	}
+	return policy, nil
 }
 
 // Config represents the configuration for non-application environments.
 type Config struct {
-	Pipelines *PipelinesConfig `json:"pipelines,omitempty"`
-	ArgoCD    *ArgoCDConfig    `json:"argocd,omitempty"`
-	Git       *GitConfig       `json:"git,omitempty"`
+	Pipelines         *PipelinesConfig         `json:"pipelines,omitempty"`
+	ArgoCD            *ArgoCDConfig            `json:"argocd,omitempty"`
+	Flux              *FluxConfig              `json:"flux,omitempty"`
+	Git               *GitConfig               `json:"git,omitempty"`
+	NamespaceDefaults *NamespaceDefaultsConfig `json:"namespace_defaults,omitempty"`
 }
 
 // PipelinesConfig provides configuration for the CI/CD pipelines.
+//
+// If Branches are provided, the generated triggers only start pipelines for
+// pushes to these branches. The WebhookSecret is the name of the Secret that
+// hooks from the GitOps repository are authenticated with, if not the default.
 type PipelinesConfig struct {
-	Name string `json:"name,omitempty"`
+	Name          string   `json:"name,omitempty"`
+	Branches      []string `json:"branches,omitempty"`
+	WebhookSecret string   `json:"webhook_secret,omitempty"`
+}
+
+// IsBranchName returns true if s can be used as one of the Branches, these
+// are quoted in the CEL filters of the generated triggers.
+func IsBranchName(s string) bool {
+	return s != "" && !strings.ContainsAny(s, "'\\\" \t\n")
 }
 
 // ArgoCDConfig provides configuration for the ArgoCD application generation.
+//
+// The APIVersion is the apiVersion of the generated Applications and
+// ApplicationSets, for clusters running an Argo CD that doesn't support the
+// DefaultArgoCDAPIVersion.
 type ArgoCDConfig struct {
+	Namespace  string `json:"namespace,omitempty"`
+	APIVersion string `json:"api_version,omitempty"`
+}
+
+// DefaultArgoCDAPIVersion is the apiVersion of the generated Argo CD resources
+// if none is configured.
+const DefaultArgoCDAPIVersion = "argoproj.io/v1alpha1"
+
+// ArgoCDAPIVersions is the set of apiVersions that Argo CD Applications can be
+// generated with.
+var ArgoCDAPIVersions = []string{DefaultArgoCDAPIVersion, "argoproj.io/v1beta1"}
+
+// IsArgoCDAPIVersion returns true if s is one of the ArgoCDAPIVersions.
+func IsArgoCDAPIVersion(s string) bool {
+	for _, v := range ArgoCDAPIVersions {
+		if s == v {
+			return true
+		}
+	}
+	return false
+}
+
+// GetAPIVersion returns the configured APIVersion, or the
+// DefaultArgoCDAPIVersion.
+func (c *ArgoCDConfig) GetAPIVersion() string {
+	if c.APIVersion == "" {
+		return DefaultArgoCDAPIVersion
+	}
+	return c.APIVersion
+}
+
+// FluxConfig provides configuration for the Flux Kustomization generation, it
+// is used instead of the ArgoCDConfig when Flux is the GitOps engine.
+type FluxConfig struct {
 	Namespace string `json:"namespace,omitempty"`
 }
 
+// NamespaceDefaultsConfig provides the defaults for the generated environment
+// Namespaces.
+//
+// The Namespaces are labelled to enforce the PodSecurity level of the Pod
+// Security Standards, and ingress to their pods is denied by NetworkPolicies
+// except from the CI/CD namespace.
+type NamespaceDefaultsConfig struct {
+	PodSecurity string `json:"pod_security,omitempty"`
+}
+
+// The Pod Security Standard levels that the environment Namespaces can
+// enforce, the default is PodSecurityBaseline.
+const (
+	PodSecurityBaseline   = "baseline"
+	PodSecurityRestricted = "restricted"
+)
+
+// PodSecurityLevels is the set of levels that the PodSecurity can be.
+var PodSecurityLevels = []string{PodSecurityBaseline, PodSecurityRestricted}
+
+// IsPodSecurityLevel returns true if s is one of the PodSecurityLevels.
+func IsPodSecurityLevel(s string) bool {
+	for _, v := range PodSecurityLevels {
+		if s == v {
+			return true
+		}
+	}
+	return false
+}
+
+// GetPodSecurity returns the configured PodSecurity, or PodSecurityBaseline.
+func (c *NamespaceDefaultsConfig) GetPodSecurity() string {
+	if c.PodSecurity == "" {
+		return PodSecurityBaseline
+	}
+	return c.PodSecurity
+}
+
 // GitConfig configures the git drivers.
 type GitConfig struct {
 	Drivers map[string]string `json:"drivers,omitempty"`
@@ -146,12 +316,79 @@ type Application struct {
 	ConfigRepo *Repository `json:"config_repo,omitempty"`
 }
 
+// Deployed returns true if the application has configuration to deploy, an
+// application with only build pipeline services has nothing to deploy.
+func (a *Application) Deployed() bool {
+	if a.ConfigRepo != nil || len(a.Services) == 0 {
+		return true
+	}
+	for _, svc := range a.Services {
+		if svc.Deploys() {
+			return true
+		}
+	}
+	return false
+}
+
+// The pipeline types that can be selected for a service, the default is
+// BuildDeployPipeline.
+const (
+	BuildPipeline       = "build"
+	DeployPipeline      = "deploy"
+	BuildDeployPipeline = "build-deploy"
+)
+
+// PipelineTypes is the set of valid service pipeline types.
+var PipelineTypes = []string{BuildPipeline, DeployPipeline, BuildDeployPipeline}
+
+// IsPipelineType returns true if s is one of the PipelineTypes.
+func IsPipelineType(s string) bool {
+	for _, v := range PipelineTypes {
+		if s == v {
+			return true
+		}
+	}
+	return false
+}
+
 // Service has an upstream source.
+//
+// The PipelineType determines whether the service is built by the CI pipeline,
+// deployed, or both.
+//
+// The ContextDir is the directory within the source repository that the
+// service is built from, this allows services in a monorepo to share the
+// SourceURL.
 type Service struct {
-	Name      string     `json:"name,omitempty"`
-	Webhook   *Webhook   `json:"webhook,omitempty"`
-	SourceURL string     `json:"source_url,omitempty"`
-	Pipelines *Pipelines `json:"pipelines,omitempty"`
+	Name         string     `json:"name,omitempty"`
+	Webhook      *Webhook   `json:"webhook,omitempty"`
+	SourceURL    string     `json:"source_url,omitempty"`
+	ContextDir   string     `json:"context_dir,omitempty"`
+	Pipelines    *Pipelines `json:"pipelines,omitempty"`
+	PipelineType string     `json:"pipeline_type,omitempty"`
+	// TriggerBranch is the only branch that pushes to start the CI pipeline
+	// for, rather than the Branches of the PipelinesConfig.
+	TriggerBranch string `json:"trigger_branch,omitempty"`
+}
+
+// IsContextDir returns true if s is a relative path within a repository, the
+// root of the repository is ".".
+func IsContextDir(s string) bool {
+	if s == "" || filepath.IsAbs(s) {
+		return false
+	}
+	clean := filepath.Clean(s)
+	return clean != ".." && !strings.HasPrefix(clean, "../")
+}
+
+// Builds returns true if the service is built by the CI pipeline.
+func (s *Service) Builds() bool {
+	return s.PipelineType != DeployPipeline
+}
+
+// Deploys returns true if the service configuration is deployed.
+func (s *Service) Deploys() bool {
+	return s.PipelineType != BuildPipeline
 }
 
 // Webhook provides Github webhook secret for eventlisteners
@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"path/filepath"
 	"sort"
+	"strings"
 	"testing"
 
 	"github.com/google/go-cmp/cmp"
@@ -202,6 +203,73 @@ func TestGetEnvironment(t *testing.T) {
 		t.Fatalf("found an unknown env: %#v", unknown)
 	}
 }
+
+func TestParseSyncPolicy(t *testing.T) {
+	policyTests := []struct {
+		policy  string
+		want    *SyncPolicy
+		wantErr string
+	}{
+		{"", &SyncPolicy{Automated: true, Prune: true, SelfHeal: true}, ""},
+		{"manual", &SyncPolicy{}, ""},
+		{"auto", &SyncPolicy{Automated: true}, ""},
+		{"auto+prune+selfheal", &SyncPolicy{Automated: true, Prune: true, SelfHeal: true}, ""},
+		{"auto+selfheal", &SyncPolicy{Automated: true, SelfHeal: true}, ""},
+		{"automatic", nil, `invalid sync policy "automatic", must be auto or manual`},
+		{"auto+replace", nil, `invalid sync policy "auto+replace", unknown option "replace", must be prune or selfheal`},
+	}
+
+	for _, tt := range policyTests {
+		t.Run(tt.policy, func(rt *testing.T) {
+			got, err := ParseSyncPolicy(tt.policy)
+			if tt.wantErr != "" {
+				if err == nil || err.Error() != tt.wantErr {
+					rt.Fatalf("got error %v, want %s", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				rt.Fatal(err)
+			}
+			if diff := cmp.Diff(tt.want, got); diff != "" {
+				rt.Fatalf("ParseSyncPolicy() failed:\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestParseQuota(t *testing.T) {
+	quotaTests := []struct {
+		name    string
+		values  map[string]string
+		want    *QuotaConfig
+		wantErr string
+	}{
+		{"cpu and memory", map[string]string{"cpu": "4", "memory": "8Gi"}, &QuotaConfig{CPU: "4", Memory: "8Gi"}, ""},
+		{"container defaults", map[string]string{"default-cpu": "250m", "default-memory": "256Mi"}, &QuotaConfig{DefaultCPU: "250m", DefaultMemory: "256Mi"}, ""},
+		{"unknown quota", map[string]string{"pods": "10"}, nil, `invalid quota "pods", must be one of cpu, default-cpu, default-memory, memory`},
+		{"invalid quantity", map[string]string{"memory": "8GB"}, nil, `invalid quantity "8GB" for the memory quota: `},
+	}
+
+	for _, tt := range quotaTests {
+		t.Run(tt.name, func(rt *testing.T) {
+			got, err := ParseQuota(tt.values)
+			if tt.wantErr != "" {
+				if err == nil || !strings.HasPrefix(err.Error(), tt.wantErr) {
+					rt.Fatalf("got error %v, want %s", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				rt.Fatal(err)
+			}
+			if diff := cmp.Diff(tt.want, got); diff != "" {
+				rt.Fatalf("ParseQuota() failed:\n%s", diff)
+			}
+		})
+	}
+}
+
 func makeEnvs(ns []testEnv) []*Environment {
 	n := make([]*Environment, len(ns))
 	for i, v := range ns {
@@ -11,6 +11,9 @@ import (
 )
 
 // Parse decodes YAML describing an environment manifest.
+//
+// Manifests with a version newer than the LatestVersion are rejected, rather
+// than being partially understood.
 func Parse(in io.Reader) (*Manifest, error) {
 	m := &Manifest{}
 	buf, err := ioutil.ReadAll(in)
@@ -21,6 +24,9 @@ func Parse(in io.Reader) (*Manifest, error) {
 	if err != nil {
 		return nil, err
 	}
+	if m.Version > LatestVersion {
+		return nil, fmt.Errorf("%s version %d requires a newer version of the CLI, this version supports up to version %d", PipelinesFile, m.Version, LatestVersion)
+	}
 	return m, nil
 }
 
@@ -38,6 +44,16 @@ func ParseFile(fs afero.Fs, filename string) (*Manifest, error) {
 // ParsePipelinesFolder will accept the pipelines folder path
 // and appends pipelines file name before parsing it
 func ParsePipelinesFolder(fs afero.Fs, folderPath string) (*Manifest, error) {
+	return ParseManifestFile(fs, folderPath, PipelinesFile)
+}
+
+// ParseManifestFile is ParsePipelinesFolder for a manifest file that's not
+// named pipelines.yaml, the manifestFile is relative to the folder path, if
+// it's empty, the PipelinesFile is parsed.
+func ParseManifestFile(fs afero.Fs, folderPath, manifestFile string) (*Manifest, error) {
+	if manifestFile == "" {
+		manifestFile = PipelinesFile
+	}
 	info, err := fs.Stat(folderPath)
 	if err != nil {
 		return nil, err
@@ -45,5 +61,5 @@ func ParsePipelinesFolder(fs afero.Fs, folderPath string) (*Manifest, error) {
 	if !info.IsDir() {
 		return nil, fmt.Errorf("The path %q is a file path (required directory path)", folderPath)
 	}
-	return ParseFile(fs, filepath.Join(folderPath, PipelinesFile))
+	return ParseFile(fs, filepath.Join(folderPath, manifestFile))
 }
@@ -144,6 +144,15 @@ func TestParse(t *testing.T) {
 	}
 }
 
+func TestParseWithFutureVersion(t *testing.T) {
+	_, err := ParseFile(ioutils.NewFilesystem(), "testdata/future_version.yaml")
+
+	want := fmt.Sprintf("pipelines.yaml version 99 requires a newer version of the CLI, this version supports up to version %d", LatestVersion)
+	if err == nil || err.Error() != want {
+		t.Fatalf("ParseFile() got error %v, want %q", err, want)
+	}
+}
+
 func TestParsePipelinesFolder(t *testing.T) {
 
 	want := &Manifest{
@@ -0,0 +1,84 @@
+package config
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/api/resource"
+)
+
+// QuotaConfig configures the ResourceQuota and LimitRange that are generated
+// for an Environment's Namespace.
+//
+// The CPU and Memory are the hard limits for the total of the containers in
+// the Namespace, the DefaultCPU and DefaultMemory are the limits of the
+// containers that don't set them, as the quota requires them to be set.
+type QuotaConfig struct {
+	CPU           string `json:"cpu,omitempty"`
+	Memory        string `json:"memory,omitempty"`
+	DefaultCPU    string `json:"default_cpu,omitempty"`
+	DefaultMemory string `json:"default_memory,omitempty"`
+}
+
+// The limits of containers that don't set them, if the QuotaConfig doesn't
+// configure them.
+const (
+	DefaultContainerCPU    = "500m"
+	DefaultContainerMemory = "512Mi"
+)
+
+// GetDefaultCPU returns the configured DefaultCPU, or the DefaultContainerCPU.
+func (c *QuotaConfig) GetDefaultCPU() string {
+	if c.DefaultCPU == "" {
+		return DefaultContainerCPU
+	}
+	return c.DefaultCPU
+}
+
+// GetDefaultMemory returns the configured DefaultMemory, or the
+// DefaultContainerMemory.
+func (c *QuotaConfig) GetDefaultMemory() string {
+	if c.DefaultMemory == "" {
+		return DefaultContainerMemory
+	}
+	return c.DefaultMemory
+}
+
+// quantities returns the quantities of the QuotaConfig keyed by the names
+// that ParseQuota accepts.
+func (c *QuotaConfig) quantities() map[string]*string {
+	return map[string]*string{
+		"cpu":            &c.CPU,
+		"memory":         &c.Memory,
+		"default-cpu":    &c.DefaultCPU,
+		"default-memory": &c.DefaultMemory,
+	}
+}
+
+// ParseQuota parses the quota from the quantities keyed by name, one of cpu,
+// memory, default-cpu and default-memory e.g. {"cpu": "4", "memory": "8Gi"}.
+func ParseQuota(values map[string]string) (*QuotaConfig, error) {
+	q := &QuotaConfig{}
+	fields := q.quantities()
+	for k, v := range values {
+		field, ok := fields[k]
+		if !ok {
+			return nil, fmt.Errorf("invalid quota %q, must be one of %s", k, strings.Join(quotaNames(), ", "))
+		}
+		if _, err := resource.ParseQuantity(v); err != nil {
+			return nil, fmt.Errorf("invalid quantity %q for the %s quota: %w", v, k, err)
+		}
+		*field = v
+	}
+	return q, nil
+}
+
+func quotaNames() []string {
+	names := []string{}
+	for k := range (&QuotaConfig{}).quantities() {
+		names = append(names, k)
+	}
+	sort.Strings(names)
+	return names
+}
@@ -0,0 +1,73 @@
+package config
+
+import (
+	"reflect"
+	"strings"
+)
+
+// SchemaURI is the JSON Schema draft that the manifest schema is written to.
+const SchemaURI = "http://json-schema.org/draft-07/schema#"
+
+// Schema returns a JSON Schema for the manifest.
+//
+// The schema is generated from the Manifest struct, rather than maintained
+// separately, so that it always describes the fields that can be parsed,
+// unknown fields are rejected, so that typos are reported by editors.
+func Schema() map[string]interface{} {
+	s := schemaForType(reflect.TypeOf(Manifest{}))
+	s["$schema"] = SchemaURI
+	s["title"] = PipelinesFile
+	return s
+}
+
+func schemaForType(t reflect.Type) map[string]interface{} {
+	switch t.Kind() {
+	case reflect.Ptr:
+		return schemaForType(t.Elem())
+	case reflect.Struct:
+		return schemaForStruct(t)
+	case reflect.Slice:
+		return map[string]interface{}{
+			"type":  "array",
+			"items": schemaForType(t.Elem()),
+		}
+	case reflect.Map:
+		return map[string]interface{}{
+			"type":                 "object",
+			"additionalProperties": schemaForType(t.Elem()),
+		}
+	case reflect.String:
+		return map[string]interface{}{"type": "string"}
+	case reflect.Bool:
+		return map[string]interface{}{"type": "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return map[string]interface{}{"type": "integer"}
+	case reflect.Float32, reflect.Float64:
+		return map[string]interface{}{"type": "number"}
+	}
+	return map[string]interface{}{}
+}
+
+func schemaForStruct(t reflect.Type) map[string]interface{} {
+	properties := map[string]interface{}{}
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if f.PkgPath != "" {
+			continue
+		}
+		name := strings.Split(f.Tag.Get("json"), ",")[0]
+		if name == "-" {
+			continue
+		}
+		if name == "" {
+			name = f.Name
+		}
+		properties[name] = schemaForType(f.Type)
+	}
+	return map[string]interface{}{
+		"type":                 "object",
+		"properties":           properties,
+		"additionalProperties": false,
+	}
+}
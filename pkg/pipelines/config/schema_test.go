@@ -0,0 +1,134 @@
+package config
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/rhd-gitops-example/gitops-cli/pkg/pipelines/helper"
+	"sigs.k8s.io/yaml"
+)
+
+func TestSchema(t *testing.T) {
+	b, err := json.Marshal(Schema())
+	if err != nil {
+		t.Fatal(err)
+	}
+	var schema map[string]interface{}
+	if err := json.Unmarshal(b, &schema); err != nil {
+		t.Fatal(err)
+	}
+
+	schemaTests := []struct {
+		filename string
+		wantErr  string
+	}{
+		{"testdata/example1.yaml", ""},
+		{"testdata/example-with-cluster.yaml", ""},
+		{"testdata/schema_malformed.yaml", `environments\[0\].apps\[0\].services\[0\]: unknown property "sourceURL", version: got string, want integer`},
+	}
+
+	for _, tt := range schemaTests {
+		t.Run(tt.filename, func(rt *testing.T) {
+			doc := readYAMLAsJSON(rt, tt.filename)
+			errs := validateAgainstSchema(schema, doc, "")
+			var err error
+			if len(errs) > 0 {
+				sort.Strings(errs)
+				err = errors.New(strings.Join(errs, ", "))
+			}
+			if !helper.ErrorMatch(rt, tt.wantErr, err) {
+				rt.Fatalf("got error %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func readYAMLAsJSON(t *testing.T, filename string) interface{} {
+	t.Helper()
+	b, err := ioutil.ReadFile(filename)
+	if err != nil {
+		t.Fatal(err)
+	}
+	j, err := yaml.YAMLToJSON(b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var doc interface{}
+	if err := json.Unmarshal(j, &doc); err != nil {
+		t.Fatal(err)
+	}
+	return doc
+}
+
+// validateAgainstSchema validates the document against the subset of JSON
+// Schema that's generated by Schema.
+func validateAgainstSchema(schema map[string]interface{}, doc interface{}, path string) []string {
+	errs := []string{}
+	switch schema["type"] {
+	case "object":
+		obj, ok := doc.(map[string]interface{})
+		if !ok {
+			return append(errs, fmt.Sprintf("%s: got %s, want object", path, jsonType(doc)))
+		}
+		properties, _ := schema["properties"].(map[string]interface{})
+		for k, v := range obj {
+			p := k
+			if path != "" {
+				p = path + "." + k
+			}
+			if s, ok := properties[k].(map[string]interface{}); ok {
+				errs = append(errs, validateAgainstSchema(s, v, p)...)
+				continue
+			}
+			switch additional := schema["additionalProperties"].(type) {
+			case bool:
+				if !additional {
+					errs = append(errs, fmt.Sprintf("%s: unknown property %q", path, k))
+				}
+			case map[string]interface{}:
+				errs = append(errs, validateAgainstSchema(additional, v, p)...)
+			}
+		}
+	case "array":
+		arr, ok := doc.([]interface{})
+		if !ok {
+			return append(errs, fmt.Sprintf("%s: got %s, want array", path, jsonType(doc)))
+		}
+		items := schema["items"].(map[string]interface{})
+		for i, v := range arr {
+			errs = append(errs, validateAgainstSchema(items, v, fmt.Sprintf("%s[%d]", path, i))...)
+		}
+	case "integer":
+		if n, ok := doc.(float64); !ok || n != float64(int64(n)) {
+			errs = append(errs, fmt.Sprintf("%s: got %s, want integer", path, jsonType(doc)))
+		}
+	default:
+		if want := schema["type"]; want != nil && jsonType(doc) != want {
+			errs = append(errs, fmt.Sprintf("%s: got %s, want %s", path, jsonType(doc), want))
+		}
+	}
+	return errs
+}
+
+func jsonType(v interface{}) string {
+	switch v.(type) {
+	case map[string]interface{}:
+		return "object"
+	case []interface{}:
+		return "array"
+	case string:
+		return "string"
+	case bool:
+		return "boolean"
+	case float64:
+		return "number"
+	case nil:
+		return "null"
+	}
+	return fmt.Sprintf("%T", v)
+}
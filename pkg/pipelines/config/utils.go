@@ -10,7 +10,14 @@ import (
 // LoadManifest reads a manifest file, and configures the environment based on
 // the configuration.
 func LoadManifest(fs afero.Fs, path string) (*Manifest, error) {
-	m, err := ParsePipelinesFolder(fs, path)
+	return LoadManifestFile(fs, path, PipelinesFile)
+}
+
+// LoadManifestFile is LoadManifest for a manifest file that's not named
+// pipelines.yaml, the manifestFile is relative to the path, if it's empty, the
+// PipelinesFile is loaded.
+func LoadManifestFile(fs afero.Fs, path, manifestFile string) (*Manifest, error) {
+	m, err := ParseManifestFile(fs, path, manifestFile)
 	if err != nil {
 		return nil, fmt.Errorf("failed to load manifest: %w", err)
 	}
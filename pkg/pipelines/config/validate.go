@@ -7,6 +7,7 @@ import (
 
 	"github.com/mkmik/multierror"
 	"github.com/rhd-gitops-example/gitops-cli/pkg/pipelines/scm"
+	"k8s.io/apimachinery/pkg/api/resource"
 	"k8s.io/apimachinery/pkg/api/validation"
 	"knative.dev/pkg/apis"
 )
@@ -15,12 +16,28 @@ const (
 	LongServiceNameError = "A service name can be no longer than 47 characters"
 )
 
+// builtSource identifies the source that a service is built from, services in
+// a monorepo share the URL but are built from different directories.
+type builtSource struct {
+	url        string
+	contextDir string
+}
+
+func (s builtSource) String() string {
+	if s.contextDir == "" {
+		return s.url
+	}
+	return s.url + " (" + s.contextDir + ")"
+}
+
 type validateVisitor struct {
 	errs         []error
 	envNames     map[string]bool
 	appNames     map[string]bool
 	serviceNames map[string]bool
 	serviceURLs  map[string][]string
+	builtURLs    map[builtSource][]string          // The sources of services that are built, these can't be shared as each gets a trigger.
+	envSources   map[string]map[builtSource]string // The services in each environment by their normalized source.
 	configNames  map[string]bool
 }
 
@@ -31,6 +48,8 @@ func (m *Manifest) Validate() error {
 		appNames:     map[string]bool{},
 		serviceNames: map[string]bool{},
 		serviceURLs:  map[string][]string{},
+		builtURLs:    map[builtSource][]string{},
+		envSources:   map[string]map[builtSource]string{},
 		configNames:  map[string]bool{},
 	}
 
@@ -72,8 +91,10 @@ func (vv *validateVisitor) validateServiceURLs(gitOpsURL string) []error {
 				}
 			}
 		}
+	}
+	for src, paths := range vv.builtURLs {
 		if len(paths) > 1 {
-			errs = append(errs, duplicateSourceError(url, paths))
+			errs = append(errs, duplicateSourceError(src.String(), paths))
 		}
 	}
 	return errs
@@ -93,9 +114,33 @@ func (vv *validateVisitor) Environment(env *Environment) error {
 	if err := validatePipelines(env.Pipelines, envPath); err != nil {
 		vv.errs = append(vv.errs, err...)
 	}
+	if _, err := ParseSyncPolicy(env.SyncPolicy); err != nil {
+		vv.errs = append(vv.errs, apis.ErrInvalidValue(env.SyncPolicy, yamlJoin(envPath, "sync_policy")))
+	}
+	if env.Quota != nil {
+		vv.errs = append(vv.errs, validateQuota(env.Quota, yamlJoin(envPath, "quota"))...)
+	}
 	return nil
 }
 
+func validateQuota(q *QuotaConfig, path string) []error {
+	errs := []error{}
+	fields := map[string]string{
+		"cpu":            q.CPU,
+		"memory":         q.Memory,
+		"default_cpu":    q.DefaultCPU,
+		"default_memory": q.DefaultMemory,
+	}
+	for _, k := range []string{"cpu", "memory", "default_cpu", "default_memory"} {
+		if v := fields[k]; v != "" {
+			if _, err := resource.ParseQuantity(v); err != nil {
+				errs = append(errs, apis.ErrInvalidValue(v, yamlJoin(path, k)))
+			}
+		}
+	}
+	return errs
+}
+
 func (vv *validateVisitor) Application(env *Environment, app *Application) error {
 	appPath := yamlPath(PathForApplication(env, app))
 	if err := checkDuplicate(app.Name, appPath, vv.appNames); err != nil {
@@ -136,6 +181,18 @@ func (vv *validateVisitor) Service(app *Application, env *Environment, svc *Serv
 		}
 		previous = append(previous, svcPath)
 		vv.serviceURLs[svc.SourceURL] = previous
+		if existing := vv.checkEnvironmentSource(env, svc); existing != "" {
+			vv.errs = append(vv.errs, duplicateEnvironmentSourceError(svc.SourceURL, env.Name, existing, []string{svcPath}))
+		} else if svc.Builds() {
+			src := builtSource{url: svc.SourceURL, contextDir: svc.ContextDir}
+			vv.builtURLs[src] = append(vv.builtURLs[src], svcPath)
+		}
+	}
+	if svc.ContextDir != "" && !IsContextDir(svc.ContextDir) {
+		vv.errs = append(vv.errs, invalidContextDirError(svc.ContextDir, []string{yamlJoin(svcPath, "context_dir")}))
+	}
+	if svc.TriggerBranch != "" && !IsBranchName(svc.TriggerBranch) {
+		vv.errs = append(vv.errs, apis.ErrInvalidValue(svc.TriggerBranch, yamlJoin(svcPath, "trigger_branch")))
 	}
 	if err := checkDuplicateService(svc.Name, svcPath, svcRelativePath, vv.serviceNames); err != nil {
 		vv.errs = append(vv.errs, err)
@@ -153,10 +210,30 @@ func (vv *validateVisitor) Service(app *Application, env *Environment, svc *Serv
 	if err := validatePipelines(svc.Pipelines, svcPath); err != nil {
 		vv.errs = append(vv.errs, err...)
 	}
+	if svc.PipelineType != "" && !IsPipelineType(svc.PipelineType) {
+		vv.errs = append(vv.errs, apis.ErrInvalidValue(svc.PipelineType, yamlJoin(svcPath, "pipeline_type")))
+	}
 	vv.serviceNames[svc.Name] = true
 	return nil
 }
 
+// checkEnvironmentSource records the source of the service in its environment,
+// and returns the name of the service that already has the same source if
+// there is one, the same repository can be used in different environments.
+func (vv *validateVisitor) checkEnvironmentSource(env *Environment, svc *Service) string {
+	sources, ok := vv.envSources[env.Name]
+	if !ok {
+		sources = map[builtSource]string{}
+		vv.envSources[env.Name] = sources
+	}
+	src := builtSource{url: scm.NormalizeRepoURL(svc.SourceURL), contextDir: svc.ContextDir}
+	if existing, ok := sources[src]; ok {
+		return existing
+	}
+	sources[src] = svc.Name
+	return ""
+}
+
 func validateConfigRepo(repo *Repository, path string) []error {
 	missingFields := []string{}
 	errs := []error{}
@@ -211,14 +288,41 @@ func (vv *validateVisitor) validateConfig(manifest *Manifest) []error {
 			if err := validateName(manifest.Config.ArgoCD.Namespace, yamlPath(PathForArgoCD())); err != nil {
 				errs = append(errs, err)
 			}
+			if v := manifest.Config.ArgoCD.APIVersion; v != "" && !IsArgoCDAPIVersion(v) {
+				errs = append(errs, apis.ErrInvalidValue(v, yamlJoin(yamlPath(PathForArgoCD()), "api_version")))
+			}
 			vv.configNames[manifest.Config.ArgoCD.Namespace] = true
 		}
+		if manifest.Config.Flux != nil {
+			if manifest.Config.ArgoCD != nil {
+				errs = append(errs, apis.ErrMultipleOneOf(yamlPath(PathForArgoCD()), yamlPath(PathForFlux())))
+			}
+			if err := validateName(manifest.Config.Flux.Namespace, yamlPath(PathForFlux())); err != nil {
+				errs = append(errs, err)
+			}
+			vv.configNames[manifest.Config.Flux.Namespace] = true
+		}
 		if manifest.Config.Pipelines != nil {
 			if err := validateName(manifest.Config.Pipelines.Name, yamlPath(PathForPipelines(manifest.Config.Pipelines))); err != nil {
 				errs = append(errs, err)
 			}
+			for _, branch := range manifest.Config.Pipelines.Branches {
+				if !IsBranchName(branch) {
+					errs = append(errs, apis.ErrInvalidValue(branch, yamlJoin("config", "pipelines", "branches")))
+				}
+			}
+			if v := manifest.Config.Pipelines.WebhookSecret; v != "" {
+				if err := validateName(v, yamlJoin("config", "pipelines", "webhook_secret")); err != nil {
+					errs = append(errs, err)
+				}
+			}
 			vv.configNames[manifest.Config.Pipelines.Name] = true
 		}
+		if manifest.Config.NamespaceDefaults != nil {
+			if v := manifest.Config.NamespaceDefaults.PodSecurity; v != "" && !IsPodSecurityLevel(v) {
+				errs = append(errs, apis.ErrInvalidValue(v, yamlJoin("config", "namespace_defaults", "pod_security")))
+			}
+		}
 	}
 	return errs
 }
@@ -290,6 +394,22 @@ func duplicateSourceError(url string, paths []string) *apis.FieldError {
 	}
 }
 
+func duplicateEnvironmentSourceError(url, envName, existing string, paths []string) *apis.FieldError {
+	return &apis.FieldError{
+		Message: fmt.Sprintf("duplicate source %v in environment %s, it is already used by service %s", url, envName, existing),
+		Details: "The webhooks and pipelines of services with the same source in an environment would conflict",
+		Paths:   paths,
+	}
+}
+
+func invalidContextDirError(dir string, paths []string) *apis.FieldError {
+	return &apis.FieldError{
+		Message: fmt.Sprintf("invalid context directory %q", dir),
+		Details: "The context directory must be a relative path within the source repository",
+		Paths:   paths,
+	}
+}
+
 func inconsistentGitTypeError(gitType, serviceURL string, paths []string) *apis.FieldError {
 	return &apis.FieldError{
 		Message: fmt.Sprintf("service URL must be a %s repository: %v", gitType, serviceURL),
@@ -113,7 +113,72 @@ func TestValidate(t *testing.T) {
 			"testdata/duplicate_source_url.yaml",
 			multierror.Join(
 				[]error{
-					duplicateSourceError("https://github.com/testing/testing.git", []string{"environments.duplicate-source.apps.my-app-1.services.app-1-service-http", "environments.duplicate-source.apps.my-app-2.services.app-2-service-http"}),
+					duplicateEnvironmentSourceError("https://github.com/testing/testing.git", "duplicate-source", "app-1-service-http", []string{"environments.duplicate-source.apps.my-app-2.services.app-2-service-http"}),
+				},
+			),
+		},
+		{
+			"duplicate normalized source in an environment",
+			"testdata/duplicate_normalized_source_url.yaml",
+			multierror.Join(
+				[]error{
+					duplicateEnvironmentSourceError("https://github.com/Testing/testing/", "stage", "app-1-service-http", []string{"environments.stage.apps.my-app-2.services.app-2-service-http"}),
+				},
+			),
+		},
+		{
+			"duplicate source for a service that is only deployed",
+			"testdata/deployed_source_url.yaml",
+			nil,
+		},
+		{
+			"duplicate source for services built from different directories",
+			"testdata/monorepo_source_url.yaml",
+			nil,
+		},
+		{
+			"invalid service context dir",
+			"testdata/invalid_context_dir.yaml",
+			multierror.Join(
+				[]error{
+					invalidContextDirError("../frontend", []string{"environments.monorepo.apps.my-app-1.services.frontend.context_dir"}),
+				},
+			),
+		},
+		{
+			"invalid service pipeline type",
+			"testdata/invalid_pipeline_type.yaml",
+			multierror.Join(
+				[]error{
+					apis.ErrInvalidValue("release", "environments.development.apps.app-1.services.service-1.pipeline_type"),
+				},
+			),
+		},
+		{
+			"invalid argocd api version",
+			"testdata/invalid_argocd_api_version.yaml",
+			multierror.Join(
+				[]error{
+					apis.ErrInvalidValue("argoproj.io/v2", "config.argocd.api_version"),
+				},
+			),
+		},
+		{
+			"invalid environment sync policy",
+			"testdata/invalid_sync_policy.yaml",
+			multierror.Join(
+				[]error{
+					apis.ErrInvalidValue("auto+replace", "environments.development.sync_policy"),
+				},
+			),
+		},
+		{
+			"invalid pipelines branches and webhook secret",
+			"testdata/invalid_pipelines_config.yaml",
+			multierror.Join(
+				[]error{
+					apis.ErrInvalidValue("main' || true", "config.pipelines.branches"),
+					invalidNameError("GitOps_Hooks", DNS1035Error, []string{"config.pipelines.webhook_secret"}),
 				},
 			),
 		},
@@ -0,0 +1,225 @@
+package pipelines
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+
+	"github.com/spf13/afero"
+
+	"github.com/rhd-gitops-example/gitops-cli/pkg/pipelines/config"
+)
+
+// ErrManifestsDiffer is returned by Diff if the manifests have semantic
+// differences.
+var ErrManifestsDiffer = errors.New("the manifests have semantic differences")
+
+// DiffParameters is a struct that provides flags for the Diff command.
+type DiffParameters struct {
+	OldPath string
+	NewPath string
+}
+
+// Diff parses the old and new manifest files, and writes their semantic
+// differences to out, one per line.
+//
+// ErrManifestsDiffer is returned if there are differences, so that the
+// command fails.
+func Diff(o *DiffParameters, appFs afero.Fs, out io.Writer) error {
+	previous, err := config.ParseFile(appFs, o.OldPath)
+	if err != nil {
+		return fmt.Errorf("failed to parse %s: %w", o.OldPath, err)
+	}
+	updated, err := config.ParseFile(appFs, o.NewPath)
+	if err != nil {
+		return fmt.Errorf("failed to parse %s: %w", o.NewPath, err)
+	}
+	diffs, err := ManifestDiff(previous, updated)
+	if err != nil {
+		return err
+	}
+	for _, d := range diffs {
+		if _, err := fmt.Fprintln(out, d); err != nil {
+			return err
+		}
+	}
+	if len(diffs) > 0 {
+		return ErrManifestsDiffer
+	}
+	return nil
+}
+
+// ManifestDiff returns the semantic differences between the previous and
+// updated manifests.
+//
+// Environments, applications and services are matched by name, so their order
+// in the manifests doesn't matter, added ones are prefixed with "+", removed
+// ones with "-", and changes to their fields with "~".
+func ManifestDiff(previous, updated *config.Manifest) ([]string, error) {
+	d := &manifestDiffer{}
+	d.fields("manifest", previous, updated, "environments")
+	d.environments(previous.Environments, updated.Environments)
+	return d.diffs, d.err
+}
+
+type manifestDiffer struct {
+	diffs []string
+	err   error
+}
+
+func (d *manifestDiffer) environments(previous, updated []*config.Environment) {
+	oldEnvs, oldNames := map[string]*config.Environment{}, map[string]bool{}
+	for _, env := range previous {
+		oldEnvs[env.Name] = env
+		oldNames[env.Name] = true
+	}
+	newEnvs, newNames := map[string]*config.Environment{}, map[string]bool{}
+	for _, env := range updated {
+		newEnvs[env.Name] = env
+		newNames[env.Name] = true
+	}
+	for _, name := range d.matchNames("environment", "", oldNames, newNames) {
+		d.fields("environment "+name, oldEnvs[name], newEnvs[name], "apps")
+		d.applications(name, oldEnvs[name].Apps, newEnvs[name].Apps)
+	}
+}
+
+func (d *manifestDiffer) applications(envName string, previous, updated []*config.Application) {
+	oldApps, oldNames := map[string]*config.Application{}, map[string]bool{}
+	for _, app := range previous {
+		oldApps[app.Name] = app
+		oldNames[app.Name] = true
+	}
+	newApps, newNames := map[string]*config.Application{}, map[string]bool{}
+	for _, app := range updated {
+		newApps[app.Name] = app
+		newNames[app.Name] = true
+	}
+	for _, name := range d.matchNames("application", envName+"/", oldNames, newNames) {
+		path := envName + "/" + name
+		d.fields("application "+path, oldApps[name], newApps[name], "services")
+		d.services(path, oldApps[name].Services, newApps[name].Services)
+	}
+}
+
+func (d *manifestDiffer) services(appPath string, previous, updated []*config.Service) {
+	oldSvcs, oldNames := map[string]*config.Service{}, map[string]bool{}
+	for _, svc := range previous {
+		oldSvcs[svc.Name] = svc
+		oldNames[svc.Name] = true
+	}
+	newSvcs, newNames := map[string]*config.Service{}, map[string]bool{}
+	for _, svc := range updated {
+		newSvcs[svc.Name] = svc
+		newNames[svc.Name] = true
+	}
+	for _, name := range d.matchNames("service", appPath+"/", oldNames, newNames) {
+		d.fields("service "+appPath+"/"+name, oldSvcs[name], newSvcs[name])
+	}
+}
+
+// matchNames records the names that were removed or added, and returns the
+// names in both, sorted.
+func (d *manifestDiffer) matchNames(kind, prefix string, previous, updated map[string]bool) []string {
+	for _, name := range sortedNames(previous) {
+		if !updated[name] {
+			d.diffs = append(d.diffs, fmt.Sprintf("- %s %s%s", kind, prefix, name))
+		}
+	}
+	matched := []string{}
+	for _, name := range sortedNames(updated) {
+		if !previous[name] {
+			d.diffs = append(d.diffs, fmt.Sprintf("+ %s %s%s", kind, prefix, name))
+			continue
+		}
+		matched = append(matched, name)
+	}
+	return matched
+}
+
+// fields records the changes to the fields of the previous and updated values,
+// other than the child fields, which are compared separately, nested fields
+// are compared individually e.g. webhook.secret.name.
+func (d *manifestDiffer) fields(desc string, previous, updated interface{}, children ...string) {
+	if d.err != nil {
+		return
+	}
+	oldFields, err := flatFields(previous, children)
+	if err != nil {
+		d.err = err
+		return
+	}
+	newFields, err := flatFields(updated, children)
+	if err != nil {
+		d.err = err
+		return
+	}
+	names := map[string]bool{}
+	for k := range oldFields {
+		names[k] = true
+	}
+	for k := range newFields {
+		names[k] = true
+	}
+	for _, k := range sortedNames(names) {
+		o, inOld := oldFields[k]
+		n, inNew := newFields[k]
+		switch {
+		case !inOld:
+			d.diffs = append(d.diffs, fmt.Sprintf("~ %s: %s set to %s", desc, k, n))
+		case !inNew:
+			d.diffs = append(d.diffs, fmt.Sprintf("~ %s: %s removed, was %s", desc, k, o))
+		case o != n:
+			d.diffs = append(d.diffs, fmt.Sprintf("~ %s: %s changed from %s to %s", desc, k, o, n))
+		}
+	}
+}
+
+// flatFields returns the JSON encoded fields of v, keyed by their dotted path,
+// without the child fields.
+func flatFields(v interface{}, children []string) (map[string]string, error) {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal the manifest: %w", err)
+	}
+	var decoded map[string]interface{}
+	if err := json.Unmarshal(b, &decoded); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal the manifest: %w", err)
+	}
+	for _, c := range children {
+		delete(decoded, c)
+	}
+	flat := map[string]string{}
+	if err := flatten("", decoded, flat); err != nil {
+		return nil, err
+	}
+	return flat, nil
+}
+
+func flatten(prefix string, m map[string]interface{}, flat map[string]string) error {
+	for k, v := range m {
+		if nested, ok := v.(map[string]interface{}); ok {
+			if err := flatten(prefix+k+".", nested, flat); err != nil {
+				return err
+			}
+			continue
+		}
+		b, err := json.Marshal(v)
+		if err != nil {
+			return fmt.Errorf("failed to marshal the manifest: %w", err)
+		}
+		flat[prefix+k] = string(b)
+	}
+	return nil
+}
+
+func sortedNames(names map[string]bool) []string {
+	sorted := []string{}
+	for k := range names {
+		sorted = append(sorted, k)
+	}
+	sort.Strings(sorted)
+	return sorted
+}
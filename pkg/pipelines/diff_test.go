@@ -0,0 +1,53 @@
+package pipelines
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/rhd-gitops-example/gitops-cli/pkg/pipelines/helper"
+	"github.com/rhd-gitops-example/gitops-cli/pkg/pipelines/ioutils"
+)
+
+func TestDiffIgnoresOrderAndFormatting(t *testing.T) {
+	var out bytes.Buffer
+	o := &DiffParameters{OldPath: "testdata/diff/old.yaml", NewPath: "testdata/diff/reordered.yaml"}
+
+	err := Diff(o, ioutils.NewFilesystem(), &out)
+	assertNoError(t, err)
+
+	if out.String() != "" {
+		t.Fatalf("got differences for reordered manifests:\n%s", out.String())
+	}
+}
+
+func TestDiff(t *testing.T) {
+	var out bytes.Buffer
+	o := &DiffParameters{OldPath: "testdata/diff/old.yaml", NewPath: "testdata/diff/new.yaml"}
+
+	err := Diff(o, ioutils.NewFilesystem(), &out)
+	if err != ErrManifestsDiffer {
+		t.Fatalf("got error %v, want %v", err, ErrManifestsDiffer)
+	}
+
+	want := `~ manifest: config.pipelines.name changed from "cicd" to "tst-cicd"
+- environment stage
++ environment qa
+- service dev/taxi/builder
++ service dev/taxi/notifier
+~ service dev/taxi/gateway: webhook.secret.namespace changed from "cicd" to "tst-cicd"
+~ application prod/taxi: config_repo.target_revision set to "main"
+`
+	if diff := cmp.Diff(want, out.String()); diff != "" {
+		t.Fatalf("diff failed:\n%s", diff)
+	}
+}
+
+func TestDiffWithMissingFile(t *testing.T) {
+	o := &DiffParameters{OldPath: "testdata/diff/old.yaml", NewPath: "testdata/diff/missing.yaml"}
+
+	err := Diff(o, ioutils.NewFilesystem(), &bytes.Buffer{})
+
+	helper.AssertErrorMatch(t, "failed to parse testdata/diff/missing.yaml", err)
+}
@@ -7,6 +7,9 @@ import (
 )
 
 const scriptTemplate = `#!/bin/bash
+{{- if .RepoPath }}
+cd "{{ .RepoPath }}" || exit 1
+{{- end }}
 is_argocd=false
 argo_path="config/argocd"
 cicd_path="config/{{ .CICDEnv }}"
@@ -51,14 +54,18 @@ exit $overall_exit
 `
 
 type templateParam struct {
-	Cmd     string
-	CICDEnv string
+	Cmd      string
+	CICDEnv  string
+	RepoPath string
 }
 
 // MakeScript will create a script that can dry-run/apply
 // across all environments/applications
-func MakeScript(command, cicdEnv string) (string, error) {
-	params := templateParam{CICDEnv: cicdEnv, Cmd: command}
+//
+// If the repoPath is not empty, the configuration is applied from that
+// directory of the repository.
+func MakeScript(command, cicdEnv, repoPath string) (string, error) {
+	params := templateParam{CICDEnv: cicdEnv, Cmd: command, RepoPath: repoPath}
 	template, err := template.New("dryrun_script").Parse(scriptTemplate)
 	if err != nil {
 		return "", fmt.Errorf("unable to parse template: %v", err)
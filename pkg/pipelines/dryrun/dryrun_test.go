@@ -37,7 +37,7 @@ func TestMakeScriptWithArgo(t *testing.T) {
 
 	fs := ioutils.NewFilesystem()
 	setupGitOpsTree(t, fs, tempDir, true)
-	s, err := MakeScript("", "cicd")
+	s, err := MakeScript("", "cicd", "")
 	assertNoError(t, err)
 
 	want := logsWithArgoCD
@@ -53,7 +53,7 @@ func TestMakeScriptWithoutArgo(t *testing.T) {
 
 	fs := ioutils.NewFilesystem()
 	setupGitOpsTree(t, fs, tempDir, false)
-	s, err := MakeScript("", "cicd")
+	s, err := MakeScript("", "cicd", "")
 	assertNoError(t, err)
 
 	want := logsWithoutArgoCD
@@ -63,10 +63,26 @@ func TestMakeScriptWithoutArgo(t *testing.T) {
 	}
 }
 
+func TestMakeScriptWithRepoPath(t *testing.T) {
+	tempDir, cleanup := tempDir(t)
+	defer cleanup()
+
+	fs := ioutils.NewFilesystem()
+	setupGitOpsTree(t, fs, filepath.Join(tempDir, "clusters/prod"), true)
+	s, err := MakeScript("", "cicd", "clusters/prod")
+	assertNoError(t, err)
+
+	want := logsWithArgoCD
+	got := executeScript(t, fs, tempDir, s)
+	if got != want {
+		t.Fatalf("makeScript() failed: got \n%s want: \n%s", got, want)
+	}
+}
+
 func setupGitOpsTree(t *testing.T, fs afero.Fs, base string, withArgoCD bool) {
 	t.Helper()
 	// minimal resources to have a valid GitOps tree
-	script, err := MakeScript("", "cicd")
+	script, err := MakeScript("", "cicd", "")
 	assertNoError(t, err)
 	files := res.Resources{
 		"environments/dev/env/overlays/kustomization.yaml":   res.Kustomization{Bases: []string{"../base"}},
@@ -75,7 +91,7 @@ func setupGitOpsTree(t *testing.T, fs afero.Fs, base string, withArgoCD bool) {
 		"environments/stage/apps/go-app/kustomization.yaml":  res.Kustomization{Bases: []string{"../overlays"}},
 		"config/cicd/base/kustomization.yaml":                res.Kustomization{Resources: []string{"task.yaml"}},
 		"config/cicd/overlays/kustomization.yaml":            res.Kustomization{Bases: []string{"../base"}},
-		"config/cicd/base/task.yaml":                         tasks.CreateDeployFromSourceTask("cicd", script),
+		"config/cicd/base/task.yaml":                         tasks.CreateDeployFromSourceTask("cicd", script, ""),
 	}
 	if withArgoCD {
 		argoDir := res.Resources{
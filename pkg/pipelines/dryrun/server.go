@@ -0,0 +1,155 @@
+package dryrun
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+
+	"github.com/mkmik/multierror"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/discovery"
+	"k8s.io/client-go/discovery/cached/memory"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/restmapper"
+
+	res "github.com/rhd-gitops-example/gitops-cli/pkg/pipelines/resources"
+)
+
+// fieldManager is the manager recorded for the fields of the applied
+// resources, nothing is persisted, but the API requires it for applies.
+const fieldManager = "gitops-cli"
+
+// Applier applies resources to a cluster without persisting them.
+type Applier interface {
+	DryRunApply(obj *unstructured.Unstructured) error
+}
+
+// ServerDryRun applies the resources with the Applier, so that the cluster's
+// admission controllers check them, and returns an error for each resource
+// that was rejected.
+//
+// Resources in namespaces that are created by the resources can't be checked
+// until the namespaces exist, their paths are returned as skipped. Files that
+// aren't Kubernetes resources e.g. kustomization.yaml are ignored.
+func ServerDryRun(resources res.Resources, a Applier) ([]string, error) {
+	paths := []string{}
+	objs := map[string]*unstructured.Unstructured{}
+	generatedNamespaces := map[string]bool{}
+	for path, v := range resources {
+		obj, err := toUnstructured(v)
+		if err != nil {
+			return nil, fmt.Errorf("failed to convert %s: %w", path, err)
+		}
+		if obj == nil {
+			continue
+		}
+		if obj.GetKind() == "Namespace" && obj.GetAPIVersion() == "v1" {
+			generatedNamespaces[obj.GetName()] = true
+		}
+		objs[path] = obj
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+
+	skipped := []string{}
+	rejected := []error{}
+	for _, path := range paths {
+		obj := objs[path]
+		err := a.DryRunApply(obj)
+		if err == nil {
+			continue
+		}
+		if errors.IsNotFound(err) && generatedNamespaces[obj.GetNamespace()] {
+			skipped = append(skipped, path)
+			continue
+		}
+		rejected = append(rejected, fmt.Errorf("%s (%s %s) was rejected: %w", path, obj.GetKind(), qualifiedName(obj), err))
+	}
+	if len(rejected) > 0 {
+		return skipped, multierror.Join(rejected)
+	}
+	return skipped, nil
+}
+
+// toUnstructured returns nil if v isn't a Kubernetes resource.
+func toUnstructured(v interface{}) (*unstructured.Unstructured, error) {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	var typeMeta struct {
+		APIVersion string `json:"apiVersion"`
+		Kind       string `json:"kind"`
+	}
+	if err := json.Unmarshal(b, &typeMeta); err != nil || typeMeta.APIVersion == "" || typeMeta.Kind == "" {
+		return nil, nil
+	}
+	obj := &unstructured.Unstructured{}
+	if err := obj.UnmarshalJSON(b); err != nil {
+		return nil, err
+	}
+	return obj, nil
+}
+
+func qualifiedName(obj *unstructured.Unstructured) string {
+	if obj.GetNamespace() == "" {
+		return obj.GetName()
+	}
+	return obj.GetNamespace() + "/" + obj.GetName()
+}
+
+type clusterApplier struct {
+	client dynamic.Interface
+	mapper meta.RESTMapper
+}
+
+// NewClusterApplier creates an Applier that makes server-side applies with
+// dryRun=All to the cluster.
+func NewClusterApplier(cfg *rest.Config) (Applier, error) {
+	client, err := dynamic.NewForConfig(cfg)
+	if err != nil {
+		return nil, err
+	}
+	dc, err := discovery.NewDiscoveryClientForConfig(cfg)
+	if err != nil {
+		return nil, err
+	}
+	return newClusterApplier(client, restmapper.NewDeferredDiscoveryRESTMapper(memory.NewMemCacheClient(dc))), nil
+}
+
+func newClusterApplier(client dynamic.Interface, mapper meta.RESTMapper) *clusterApplier {
+	return &clusterApplier{client: client, mapper: mapper}
+}
+
+// DryRunApply implements the Applier interface.
+func (c *clusterApplier) DryRunApply(obj *unstructured.Unstructured) error {
+	gvk := obj.GroupVersionKind()
+	mapping, err := c.mapper.RESTMapping(gvk.GroupKind(), gvk.Version)
+	if err != nil {
+		return err
+	}
+	data, err := obj.MarshalJSON()
+	if err != nil {
+		return err
+	}
+	var client dynamic.ResourceInterface = c.client.Resource(mapping.Resource)
+	if mapping.Scope.Name() == meta.RESTScopeNameNamespace {
+		ns := obj.GetNamespace()
+		if ns == "" {
+			ns = metav1.NamespaceDefault
+		}
+		client = c.client.Resource(mapping.Resource).Namespace(ns)
+	}
+	force := true
+	_, err = client.Patch(obj.GetName(), types.ApplyPatchType, data, metav1.PatchOptions{
+		DryRun:       []string{metav1.DryRunAll},
+		FieldManager: fieldManager,
+		Force:        &force,
+	})
+	return err
+}
@@ -0,0 +1,102 @@
+package dryrun
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/dynamic/fake"
+	ktesting "k8s.io/client-go/testing"
+
+	"github.com/rhd-gitops-example/gitops-cli/pkg/pipelines/helper"
+	pmeta "github.com/rhd-gitops-example/gitops-cli/pkg/pipelines/meta"
+	res "github.com/rhd-gitops-example/gitops-cli/pkg/pipelines/resources"
+)
+
+func TestServerDryRunReportsRejectedResources(t *testing.T) {
+	client := fake.NewSimpleDynamicClient(runtime.NewScheme())
+	applied := []string{}
+	client.PrependReactor("patch", "*", func(action ktesting.Action) (bool, runtime.Object, error) {
+		patch := action.(ktesting.PatchAction)
+		if patch.GetPatchType() != types.ApplyPatchType {
+			t.Fatalf("got patch type %q, want %q", patch.GetPatchType(), types.ApplyPatchType)
+		}
+		applied = append(applied, patch.GetResource().Resource+" "+patch.GetName())
+		switch {
+		case patch.GetResource().Resource == "deployments":
+			return true, nil, apierrors.NewForbidden(schema.GroupResource{Group: "apps", Resource: "deployments"}, patch.GetName(),
+				errors.New(`admission webhook "validation.gatekeeper.sh" denied the request: you must provide labels: {"owner"}`))
+		case patch.GetNamespace() == "tst-dev":
+			return true, nil, apierrors.NewNotFound(schema.GroupResource{Resource: "namespaces"}, "tst-dev")
+		}
+		return true, nil, nil
+	})
+	applier := newClusterApplier(client, testRESTMapper())
+
+	resources := res.Resources{
+		"01-namespaces/tst-dev.yaml": &corev1.Namespace{
+			TypeMeta:   pmeta.TypeMeta("Namespace", "v1"),
+			ObjectMeta: pmeta.ObjectMeta(pmeta.NamespacedName("", "tst-dev")),
+		},
+		"02-deployments/taxi.yaml": &appsv1.Deployment{
+			TypeMeta:   pmeta.TypeMeta("Deployment", "apps/v1"),
+			ObjectMeta: pmeta.ObjectMeta(pmeta.NamespacedName("tst-stage", "taxi")),
+		},
+		"03-configmaps/settings.yaml": &corev1.ConfigMap{
+			TypeMeta:   pmeta.TypeMeta("ConfigMap", "v1"),
+			ObjectMeta: pmeta.ObjectMeta(pmeta.NamespacedName("tst-stage", "settings")),
+		},
+		"03-configmaps/new.yaml": &corev1.ConfigMap{
+			TypeMeta:   pmeta.TypeMeta("ConfigMap", "v1"),
+			ObjectMeta: pmeta.ObjectMeta(pmeta.NamespacedName("tst-dev", "new")),
+		},
+		"kustomization.yaml": &res.Kustomization{Resources: []string{"01-namespaces/tst-dev.yaml"}},
+	}
+
+	skipped, err := ServerDryRun(resources, applier)
+
+	helper.AssertErrorMatch(t, `02-deployments/taxi.yaml \(Deployment tst-stage/taxi\) was rejected: deployments.apps "taxi" is forbidden: admission webhook "validation.gatekeeper.sh" denied the request`, err)
+	if diff := cmp.Diff([]string{"03-configmaps/new.yaml"}, skipped); diff != "" {
+		t.Fatalf("skipped resources failed:\n%s", diff)
+	}
+	wantApplied := []string{"namespaces tst-dev", "deployments taxi", "configmaps new", "configmaps settings"}
+	if diff := cmp.Diff(wantApplied, applied); diff != "" {
+		t.Fatalf("applied resources failed:\n%s", diff)
+	}
+}
+
+func TestServerDryRunWithAcceptedResources(t *testing.T) {
+	client := fake.NewSimpleDynamicClient(runtime.NewScheme())
+	client.PrependReactor("patch", "*", func(action ktesting.Action) (bool, runtime.Object, error) {
+		return true, nil, nil
+	})
+	resources := res.Resources{
+		"03-configmaps/settings.yaml": &corev1.ConfigMap{
+			TypeMeta:   pmeta.TypeMeta("ConfigMap", "v1"),
+			ObjectMeta: pmeta.ObjectMeta(pmeta.NamespacedName("tst-stage", "settings")),
+		},
+	}
+
+	skipped, err := ServerDryRun(resources, newClusterApplier(client, testRESTMapper()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(skipped) != 0 {
+		t.Fatalf("got skipped resources %v", skipped)
+	}
+}
+
+func testRESTMapper() meta.RESTMapper {
+	mapper := meta.NewDefaultRESTMapper(nil)
+	mapper.Add(schema.GroupVersionKind{Version: "v1", Kind: "Namespace"}, meta.RESTScopeRoot)
+	mapper.Add(schema.GroupVersionKind{Version: "v1", Kind: "ConfigMap"}, meta.RESTScopeNamespace)
+	mapper.Add(schema.GroupVersionKind{Group: "apps", Version: "v1", Kind: "Deployment"}, meta.RESTScopeNamespace)
+	return mapper
+}
@@ -0,0 +1,88 @@
+package pipelines
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+
+	"github.com/spf13/afero"
+
+	"github.com/rhd-gitops-example/gitops-cli/pkg/pipelines/config"
+	"github.com/rhd-gitops-example/gitops-cli/pkg/pipelines/eventlisteners"
+	"github.com/rhd-gitops-example/gitops-cli/pkg/pipelines/yaml"
+)
+
+// defaultSyncPolicy is the sync policy of environments without one, this is
+// what ParseSyncPolicy parses an empty policy as.
+const defaultSyncPolicy = config.SyncPolicyAuto + "+" + config.SyncOptionPrune + "+" + config.SyncOptionSelfHeal
+
+// DumpParameters is a struct that provides flags for the DumpManifest command.
+type DumpParameters struct {
+	PipelinesFolderPath string
+	Output              string // The output format, json or yaml.
+}
+
+// DumpManifest writes the manifest in the pipelines folder to out in a
+// canonical form for programmatic consumers.
+//
+// The environments, applications and services are sorted by name, and the
+// defaults that are applied when generating resources are filled in, so that
+// consumers don't need to know them, pipelines.yaml isn't modified.
+func DumpManifest(o *DumpParameters, appFs afero.Fs, out io.Writer) error {
+	m, err := config.LoadManifest(appFs, o.PipelinesFolderPath)
+	if err != nil {
+		return err
+	}
+	resolveManifest(m)
+	switch o.Output {
+	case "json":
+		b, err := json.MarshalIndent(m, "", "	")
+		if err != nil {
+			return fmt.Errorf("failed to marshal the manifest: %w", err)
+		}
+		_, err = fmt.Fprintf(out, "%s\n", b)
+		return err
+	case "", "yaml":
+		return yaml.MarshalOutput(out, m)
+	}
+	return fmt.Errorf("unsupported output format %q, must be json or yaml", o.Output)
+}
+
+// resolveManifest sorts the manifest, and fills in the defaults that are
+// applied when it's built.
+func resolveManifest(m *config.Manifest) {
+	if m.Version == 0 {
+		m.Version = config.LatestVersion
+	}
+	if m.Config != nil {
+		if m.Config.Pipelines != nil {
+			m.Config.Pipelines.WebhookSecret = eventlisteners.WebhookSecretName(m.Config.Pipelines.WebhookSecret)
+		}
+		if m.Config.ArgoCD != nil {
+			m.Config.ArgoCD.APIVersion = m.Config.ArgoCD.GetAPIVersion()
+		}
+		if m.Config.NamespaceDefaults != nil {
+			m.Config.NamespaceDefaults.PodSecurity = m.Config.NamespaceDefaults.GetPodSecurity()
+		}
+	}
+	sort.Sort(config.ByName(m.Environments))
+	for _, env := range m.Environments {
+		if env.SyncPolicy == "" && env.Deploys() {
+			env.SyncPolicy = defaultSyncPolicy
+		}
+		sort.Slice(env.Apps, func(i, j int) bool {
+			return env.Apps[i].Name < env.Apps[j].Name
+		})
+		for _, app := range env.Apps {
+			sort.Slice(app.Services, func(i, j int) bool {
+				return app.Services[i].Name < app.Services[j].Name
+			})
+			for _, svc := range app.Services {
+				if svc.PipelineType == "" {
+					svc.PipelineType = config.BuildDeployPipeline
+				}
+			}
+		}
+	}
+}
@@ -0,0 +1,72 @@
+package pipelines
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"sigs.k8s.io/yaml"
+
+	"github.com/rhd-gitops-example/gitops-cli/pkg/pipelines/config"
+	"github.com/rhd-gitops-example/gitops-cli/pkg/pipelines/eventlisteners"
+	"github.com/rhd-gitops-example/gitops-cli/pkg/pipelines/helper"
+)
+
+func TestDumpManifestPopulatesDefaults(t *testing.T) {
+	fakeFs, gitopsPath := manifestFileFixture(t, "testdata/list/pipelines.yaml")
+	var out bytes.Buffer
+
+	err := DumpManifest(&DumpParameters{PipelinesFolderPath: gitopsPath, Output: "yaml"}, fakeFs, &out)
+	assertNoError(t, err)
+
+	got := &config.Manifest{}
+	assertNoError(t, yaml.Unmarshal(out.Bytes(), got))
+	if got.Version != config.LatestVersion {
+		t.Errorf("got version %d, want %d", got.Version, config.LatestVersion)
+	}
+	if s := got.Config.Pipelines.WebhookSecret; s != eventlisteners.GitOpsWebhookSecret {
+		t.Errorf("got webhook secret %q, want %q", s, eventlisteners.GitOpsWebhookSecret)
+	}
+	if v := got.Config.ArgoCD.APIVersion; v != config.DefaultArgoCDAPIVersion {
+		t.Errorf("got Argo CD API version %q, want %q", v, config.DefaultArgoCDAPIVersion)
+	}
+	for _, env := range got.Environments {
+		if env.SyncPolicy != "auto+prune+selfheal" {
+			t.Errorf("got sync policy %q for environment %s", env.SyncPolicy, env.Name)
+		}
+	}
+
+	services := got.Environments[0].Apps[0].Services
+	wantServices := []string{"builder:build", "gateway:build-deploy"}
+	gotServices := []string{}
+	for _, svc := range services {
+		gotServices = append(gotServices, svc.Name+":"+svc.PipelineType)
+	}
+	if diff := cmp.Diff(wantServices, gotServices); diff != "" {
+		t.Fatalf("services not sorted with defaults:\n%s", diff)
+	}
+}
+
+func TestDumpManifestJSONMatchesYAML(t *testing.T) {
+	fakeFs, gitopsPath := manifestFileFixture(t, "testdata/list/pipelines.yaml")
+	var yamlOut, jsonOut bytes.Buffer
+
+	assertNoError(t, DumpManifest(&DumpParameters{PipelinesFolderPath: gitopsPath, Output: "yaml"}, fakeFs, &yamlOut))
+	assertNoError(t, DumpManifest(&DumpParameters{PipelinesFolderPath: gitopsPath, Output: "json"}, fakeFs, &jsonOut))
+
+	fromYAML := &config.Manifest{}
+	assertNoError(t, yaml.Unmarshal(yamlOut.Bytes(), fromYAML))
+	fromJSON := &config.Manifest{}
+	assertNoError(t, json.Unmarshal(jsonOut.Bytes(), fromJSON))
+	if diff := cmp.Diff(fromYAML, fromJSON); diff != "" {
+		t.Fatalf("json and yaml dumps differ:\n%s", diff)
+	}
+}
+
+func TestDumpManifestUnknownFormat(t *testing.T) {
+	fakeFs, gitopsPath := manifestFileFixture(t, "testdata/list/pipelines.yaml")
+
+	err := DumpManifest(&DumpParameters{PipelinesFolderPath: gitopsPath, Output: "table"}, fakeFs, &bytes.Buffer{})
+	helper.AssertErrorMatch(t, `unsupported output format "table"`, err)
+}
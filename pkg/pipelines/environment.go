@@ -2,6 +2,7 @@ package pipelines
 
 import (
 	"fmt"
+	"path/filepath"
 
 	"github.com/rhd-gitops-example/gitops-cli/pkg/pipelines/config"
 	res "github.com/rhd-gitops-example/gitops-cli/pkg/pipelines/resources"
@@ -13,13 +14,16 @@ import (
 // EnvParameters encapsulates parameters for add env command.
 type EnvParameters struct {
 	PipelinesFolderPath string
+	ManifestFile        string // Path of the manifest within the PipelinesFolderPath, defaults to pipelines.yaml.
 	EnvName             string
 	Cluster             string
+	SyncPolicy          string
+	NoDeploy            bool // If true, the environment's applications are not deployed.
 }
 
 // AddEnv adds a new environment to the pipelines file.
 func AddEnv(o *EnvParameters, appFs afero.Fs) error {
-	m, err := config.LoadManifest(appFs, o.PipelinesFolderPath)
+	m, err := config.LoadManifestFile(appFs, o.PipelinesFolderPath, o.ManifestFile)
 	if err != nil {
 		return err
 	}
@@ -35,10 +39,12 @@ func AddEnv(o *EnvParameters, appFs afero.Fs) error {
 	if o.Cluster != "" {
 		newEnv.Cluster = o.Cluster
 	}
+	newEnv.SyncPolicy = o.SyncPolicy
+	newEnv.NoDeploy = o.NoDeploy
 	m.Environments = append(m.Environments, newEnv)
-	files[pipelinesFile] = m
 	buildParams := &BuildParameters{
 		PipelinesFolderPath: o.PipelinesFolderPath,
+		ManifestFile:        o.ManifestFile,
 		OutputPath:          o.PipelinesFolderPath,
 	}
 	built, err := buildResources(appFs, buildParams, m)
@@ -46,8 +52,12 @@ func AddEnv(o *EnvParameters, appFs afero.Fs) error {
 		return fmt.Errorf("failed to build resources: %v", err)
 	}
 	files = res.Merge(built, files)
-	_, err = yaml.WriteResources(appFs, o.PipelinesFolderPath, files)
-	return err
+	if _, err = yaml.WriteResources(appFs, o.PipelinesFolderPath, files); err != nil {
+		return err
+	}
+	// The pipelines file is hand-edited, so it's updated in place to keep any
+	// comments, anchors and aliases that users have added.
+	return yaml.MarshalItemToFilePreserving(appFs, filepath.Join(o.PipelinesFolderPath, manifestFileOrDefault(o.ManifestFile)), m)
 }
 
 func newEnvironment(m *config.Manifest, name string) (*config.Environment, error) {
@@ -67,3 +77,87 @@ func newEnvironment(m *config.Manifest, name string) (*config.Environment, error
 		Name: name,
 	}, nil
 }
+
+// PromoteParameters encapsulates parameters for the promote env command.
+type PromoteParameters struct {
+	PipelinesFolderPath string
+	ManifestFile        string // Path of the manifest within the PipelinesFolderPath, defaults to pipelines.yaml.
+	FromEnvName         string
+	ToEnvName           string
+	AppName             string // If empty, the application is found from the service in the FromEnvName.
+	ServiceName         string
+}
+
+// PromoteService updates the service's source_url in the ToEnvName to match
+// the FromEnvName, and regenerates the resources.
+//
+// Returns false if the service in the ToEnvName already matched, in which
+// case nothing is written.
+func PromoteService(o *PromoteParameters, appFs afero.Fs) (bool, error) {
+	m, err := config.LoadManifestFile(appFs, o.PipelinesFolderPath, o.ManifestFile)
+	if err != nil {
+		return false, err
+	}
+	from, err := findEnvService(m, o.FromEnvName, o.AppName, o.ServiceName)
+	if err != nil {
+		return false, err
+	}
+	to, err := findEnvService(m, o.ToEnvName, from.app, o.ServiceName)
+	if err != nil {
+		return false, err
+	}
+	if to.svc.SourceURL == from.svc.SourceURL {
+		return false, nil
+	}
+	to.svc.SourceURL = from.svc.SourceURL
+
+	buildParams := &BuildParameters{
+		PipelinesFolderPath: o.PipelinesFolderPath,
+		ManifestFile:        o.ManifestFile,
+		OutputPath:          o.PipelinesFolderPath,
+	}
+	built, err := buildResources(appFs, buildParams, m)
+	if err != nil {
+		return false, fmt.Errorf("failed to build resources: %v", err)
+	}
+	if _, err = yaml.WriteResources(appFs, o.PipelinesFolderPath, built); err != nil {
+		return false, err
+	}
+	return true, yaml.MarshalItemToFilePreserving(appFs, filepath.Join(o.PipelinesFolderPath, manifestFileOrDefault(o.ManifestFile)), m)
+}
+
+type envService struct {
+	app string
+	svc *config.Service
+}
+
+// findEnvService finds the named service in an environment, if the appName is
+// empty, the service must be in only one of the environment's applications.
+func findEnvService(m *config.Manifest, envName, appName, serviceName string) (*envService, error) {
+	env := m.GetEnvironment(envName)
+	if env == nil {
+		return nil, fmt.Errorf("environment %s does not exist", envName)
+	}
+	var found *envService
+	for _, app := range env.Apps {
+		if appName != "" && app.Name != appName {
+			continue
+		}
+		for _, svc := range app.Services {
+			if svc.Name != serviceName {
+				continue
+			}
+			if found != nil {
+				return nil, fmt.Errorf("service %s is in applications %s and %s in environment %s, the application must be provided", serviceName, found.app, app.Name, envName)
+			}
+			found = &envService{app: app.Name, svc: svc}
+		}
+	}
+	if found == nil {
+		if appName != "" {
+			return nil, fmt.Errorf("service %s does not exist in application %s in environment %s", serviceName, appName, envName)
+		}
+		return nil, fmt.Errorf("service %s does not exist in environment %s", serviceName, envName)
+	}
+	return found, nil
+}
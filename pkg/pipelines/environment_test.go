@@ -3,6 +3,7 @@ package pipelines
 import (
 	"fmt"
 	"path/filepath"
+	"strings"
 	"testing"
 
 	"github.com/google/go-cmp/cmp"
@@ -94,6 +95,85 @@ func TestAddEnvWithClusterProvided(t *testing.T) {
 	}
 }
 
+func TestAddEnvWithNoDeploy(t *testing.T) {
+	fakeFs := ioutils.NewMemoryFilesystem()
+	gitopsPath := afero.GetTempDir(fakeFs, "test")
+	pipelinesFilePath := filepath.Join(gitopsPath, pipelinesFile)
+	envParameters := EnvParameters{
+		PipelinesFolderPath: gitopsPath,
+		EnvName:             "ci",
+		NoDeploy:            true,
+	}
+	_ = afero.WriteFile(fakeFs, pipelinesFilePath, []byte("environments:"), 0644)
+
+	if err := AddEnv(&envParameters, fakeFs); err != nil {
+		t.Fatalf("AddEnv() failed :%s", err)
+	}
+
+	got := mustReadFileAsMap(t, fakeFs, pipelinesFilePath)
+	want := map[string]interface{}{
+		"environments": []interface{}{
+			map[string]interface{}{
+				"name":      "ci",
+				"no_deploy": true,
+			},
+		},
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Fatalf("written environments failed:\n%s", diff)
+	}
+}
+
+func TestAddEnvPreservesAnchors(t *testing.T) {
+	fakeFs := ioutils.NewMemoryFilesystem()
+	gitopsPath := afero.GetTempDir(fakeFs, "test")
+	pipelinesFilePath := filepath.Join(gitopsPath, pipelinesFile)
+	envParameters := EnvParameters{
+		PipelinesFolderPath: gitopsPath,
+		EnvName:             "test",
+	}
+	anchored := `environments:
+- &defaults
+  name: dev
+  cluster: https://dev.example.com
+- <<: *defaults
+  name: stage
+`
+	_ = afero.WriteFile(fakeFs, pipelinesFilePath, []byte(anchored), 0644)
+
+	if err := AddEnv(&envParameters, fakeFs); err != nil {
+		t.Fatalf("AddEnv() failed :%s", err)
+	}
+
+	b, err := afero.ReadFile(fakeFs, pipelinesFilePath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := anchored + "- name: test\n"; string(b) != want {
+		t.Fatalf("AddEnv() did not preserve the anchors, got:\n%s\nwant:\n%s", b, want)
+	}
+
+	got := mustReadFileAsMap(t, fakeFs, pipelinesFilePath)
+	want := map[string]interface{}{
+		"environments": []interface{}{
+			map[string]interface{}{
+				"cluster": "https://dev.example.com",
+				"name":    "dev",
+			},
+			map[string]interface{}{
+				"cluster": "https://dev.example.com",
+				"name":    "stage",
+			},
+			map[string]interface{}{
+				"name": "test",
+			},
+		},
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Fatalf("written environments failed:\n%s", diff)
+	}
+}
+
 func TestAddEnvWithExistingName(t *testing.T) {
 	fakeFs := ioutils.NewMemoryFilesystem()
 	gitopsPath := afero.GetTempDir(fakeFs, "test")
@@ -252,3 +332,90 @@ func mustReadFileAsMap(t *testing.T, fs afero.Fs, filename string) map[string]in
 	return m
 
 }
+
+const promoteManifest = `environments:
+- name: prod
+  apps:
+  - name: payments
+    services:
+    - name: api
+      source_url: https://github.com/org/api.git
+      pipeline_type: deploy
+- name: stage
+  apps:
+  - name: payments
+    services:
+    - name: api
+      source_url: https://github.com/org/api-v2.git
+`
+
+func TestPromoteService(t *testing.T) {
+	fakeFs := ioutils.NewMemoryFilesystem()
+	gitopsPath := afero.GetTempDir(fakeFs, "test")
+	pipelinesFilePath := filepath.Join(gitopsPath, pipelinesFile)
+	_ = afero.WriteFile(fakeFs, pipelinesFilePath, []byte(promoteManifest), 0644)
+
+	promoted, err := PromoteService(&PromoteParameters{
+		PipelinesFolderPath: gitopsPath,
+		FromEnvName:         "stage",
+		ToEnvName:           "prod",
+		ServiceName:         "api",
+	}, fakeFs)
+	if err != nil {
+		t.Fatalf("PromoteService() failed: %s", err)
+	}
+	if !promoted {
+		t.Fatal("PromoteService() did not promote the service")
+	}
+
+	b, err := afero.ReadFile(fakeFs, pipelinesFilePath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := strings.Replace(promoteManifest, "https://github.com/org/api.git", "https://github.com/org/api-v2.git", 1)
+	if diff := cmp.Diff(want, string(b)); diff != "" {
+		t.Fatalf("PromoteService() pipelines file failed:\n%s", diff)
+	}
+	assertFileExists(t, fakeFs, filepath.Join(gitopsPath, "environments/prod/apps/payments/services/api/base/kustomization.yaml"))
+
+	promoted, err = PromoteService(&PromoteParameters{
+		PipelinesFolderPath: gitopsPath,
+		FromEnvName:         "stage",
+		ToEnvName:           "prod",
+		ServiceName:         "api",
+	}, fakeFs)
+	if err != nil {
+		t.Fatalf("PromoteService() failed: %s", err)
+	}
+	if promoted {
+		t.Fatal("PromoteService() promoted a service that was already promoted")
+	}
+}
+
+func TestPromoteServiceErrors(t *testing.T) {
+	promoteTests := []struct {
+		name    string
+		params  PromoteParameters
+		wantErr string
+	}{
+		{"unknown from environment", PromoteParameters{FromEnvName: "dev", ToEnvName: "prod", ServiceName: "api"}, "environment dev does not exist"},
+		{"unknown to environment", PromoteParameters{FromEnvName: "stage", ToEnvName: "test", ServiceName: "api"}, "environment test does not exist"},
+		{"unknown service", PromoteParameters{FromEnvName: "stage", ToEnvName: "prod", ServiceName: "web"}, "service web does not exist in environment stage"},
+		{"unknown application", PromoteParameters{FromEnvName: "stage", ToEnvName: "prod", AppName: "orders", ServiceName: "api"}, "service api does not exist in application orders in environment stage"},
+	}
+
+	for _, tt := range promoteTests {
+		t.Run(tt.name, func(rt *testing.T) {
+			fakeFs := ioutils.NewMemoryFilesystem()
+			gitopsPath := afero.GetTempDir(fakeFs, "test")
+			_ = afero.WriteFile(fakeFs, filepath.Join(gitopsPath, pipelinesFile), []byte(promoteManifest), 0644)
+			tt.params.PipelinesFolderPath = gitopsPath
+
+			_, err := PromoteService(&tt.params, fakeFs)
+
+			if err == nil || err.Error() != tt.wantErr {
+				rt.Fatalf("PromoteService() got error %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
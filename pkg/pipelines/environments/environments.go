@@ -13,7 +13,9 @@ import (
 	res "github.com/rhd-gitops-example/gitops-cli/pkg/pipelines/resources"
 	"github.com/rhd-gitops-example/gitops-cli/pkg/pipelines/roles"
 	"github.com/spf13/afero"
+	corev1 "k8s.io/api/core/v1"
 	v1 "k8s.io/api/rbac/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
 )
 
 // AppLinks represents whether or not apps are linked to environments.
@@ -29,12 +31,13 @@ const (
 const kustomization = "kustomization.yaml"
 
 type envBuilder struct {
-	files           res.Resources
-	pipelinesConfig *config.PipelinesConfig
-	fs              afero.Fs
-	saName          string
-	appLinks        AppLinks
-	gitOpsRepoURL   string
+	files             res.Resources
+	pipelinesConfig   *config.PipelinesConfig
+	namespaceDefaults *config.NamespaceDefaultsConfig
+	fs                afero.Fs
+	saName            string
+	appLinks          AppLinks
+	gitOpsRepoURL     string
 }
 
 // Build generates a set of resources from the manifest, related to the
@@ -50,6 +53,9 @@ func Build(fs afero.Fs, m *config.Manifest, saName string, o AppLinks) (res.Reso
 		appLinks:        o,
 		gitOpsRepoURL:   m.GitOpsURL,
 	}
+	if m.Config != nil {
+		eb.namespaceDefaults = m.Config.NamespaceDefaults
+	}
 	return eb.files, m.Walk(eb)
 }
 
@@ -86,7 +92,10 @@ func (b *envBuilder) Service(app *config.Application, env *config.Environment, s
 func (b *envBuilder) Environment(env *config.Environment) error {
 	envPath := filepath.Join(config.PathForEnvironment(env), "env")
 	basePath := filepath.Join(envPath, "base")
-	envFiles := filesForEnvironment(basePath, env, b.gitOpsRepoURL)
+	envFiles, err := b.filesForEnvironment(basePath, env)
+	if err != nil {
+		return err
+	}
 	kustomizedFilenames, err := ListFiles(b.fs, basePath)
 	if err != nil {
 		return fmt.Errorf("failed to list initial files for %s: %s", basePath, err)
@@ -118,11 +127,68 @@ func (b *envBuilder) Environment(env *config.Environment) error {
 	return nil
 }
 
-func filesForEnvironment(basePath string, env *config.Environment, gitOpsRepoURL string) res.Resources {
+// filesForEnvironment creates the Namespace for the environment.
+//
+// If namespace defaults are configured, the Namespace is labelled for Pod
+// Security admission and a default-deny NetworkPolicy is added, along with a
+// policy allowing ingress from the CI/CD namespace.
+//
+// If the environment has a quota, a ResourceQuota and LimitRange are added.
+func (b *envBuilder) filesForEnvironment(basePath string, env *config.Environment) (res.Resources, error) {
 	envFiles := res.Resources{}
 	filename := filepath.Join(basePath, fmt.Sprintf("%s-environment.yaml", env.Name))
-	envFiles[filename] = namespaces.Create(env.Name, gitOpsRepoURL)
-	return envFiles
+	ns := namespaces.Create(env.Name, b.gitOpsRepoURL)
+	envFiles[filename] = ns
+	if env.Quota != nil {
+		quotaFiles, err := filesForQuota(basePath, env)
+		if err != nil {
+			return nil, err
+		}
+		envFiles = res.Merge(quotaFiles, envFiles)
+	}
+	if b.namespaceDefaults == nil {
+		return envFiles, nil
+	}
+	namespaces.AddPodSecurityLabels(ns, b.namespaceDefaults.GetPodSecurity())
+	envFiles[filepath.Join(basePath, fmt.Sprintf("%s-default-deny-networkpolicy.yaml", env.Name))] = namespaces.DefaultDenyNetworkPolicy(env.Name)
+	if b.pipelinesConfig != nil {
+		envFiles[filepath.Join(basePath, fmt.Sprintf("%s-allow-from-cicd-networkpolicy.yaml", env.Name))] = namespaces.AllowFromNamespaceNetworkPolicy(env.Name, b.pipelinesConfig.Name)
+	}
+	return envFiles, nil
+}
+
+// filesForQuota creates the ResourceQuota and LimitRange for the environment's
+// quota, the CPU and memory that are not configured are not limited.
+func filesForQuota(basePath string, env *config.Environment) (res.Resources, error) {
+	hard := corev1.ResourceList{}
+	for name, v := range map[corev1.ResourceName]string{
+		corev1.ResourceLimitsCPU:    env.Quota.CPU,
+		corev1.ResourceLimitsMemory: env.Quota.Memory,
+	} {
+		if v == "" {
+			continue
+		}
+		q, err := resource.ParseQuantity(v)
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s quota for environment %s: %w", name, env.Name, err)
+		}
+		hard[name] = q
+	}
+	defaults := corev1.ResourceList{}
+	for name, v := range map[corev1.ResourceName]string{
+		corev1.ResourceCPU:    env.Quota.GetDefaultCPU(),
+		corev1.ResourceMemory: env.Quota.GetDefaultMemory(),
+	} {
+		q, err := resource.ParseQuantity(v)
+		if err != nil {
+			return nil, fmt.Errorf("invalid default %s limit for environment %s: %w", name, env.Name, err)
+		}
+		defaults[name] = q
+	}
+	return res.Resources{
+		filepath.Join(basePath, fmt.Sprintf("%s-resourcequota.yaml", env.Name)): namespaces.ResourceQuota(env.Name, hard),
+		filepath.Join(basePath, fmt.Sprintf("%s-limitrange.yaml", env.Name)):    namespaces.LimitRange(env.Name, defaults),
+	}, nil
 }
 
 func filesForApplication(env *config.Environment, gitOpsRepoURL, appPath string, app *config.Application, o AppLinks) (res.Resources, error) {
@@ -139,6 +205,9 @@ func filesForApplication(env *config.Environment, gitOpsRepoURL, appPath string,
 	baseKustomization := filepath.Join(appPath, "base", kustomization)
 	relServices := []string{}
 	for _, v := range app.Services {
+		if !v.Deploys() {
+			continue
+		}
 		svcPath := config.PathForService(app, env, v.Name)
 		relService, err := filepath.Rel(filepath.Dir(baseKustomization), svcPath)
 		if err != nil {
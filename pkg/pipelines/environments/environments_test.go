@@ -11,6 +11,8 @@ import (
 	"github.com/rhd-gitops-example/gitops-cli/pkg/pipelines/namespaces"
 	res "github.com/rhd-gitops-example/gitops-cli/pkg/pipelines/resources"
 	"github.com/spf13/afero"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
 )
 
 const testGitOpsRepoURL = "https://github.com/example/example.git"
@@ -189,6 +191,102 @@ func TestBuildEnvironmentFilesWithNoCICDEnv(t *testing.T) {
 	}
 }
 
+func TestBuildEnvironmentFilesWithNamespaceDefaults(t *testing.T) {
+	var appFs = ioutils.NewMemoryFilesystem()
+	m := buildManifestWithCICD()
+	m.Config.NamespaceDefaults = &config.NamespaceDefaultsConfig{PodSecurity: config.PodSecurityRestricted}
+
+	files, err := Build(appFs, m, "pipelines", AppsToEnvironments)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ns, ok := files["environments/test-dev/env/base/test-dev-environment.yaml"].(*corev1.Namespace)
+	if !ok {
+		t.Fatalf("no Namespace generated for test-dev")
+	}
+	wantLabels := map[string]string{
+		"pod-security.kubernetes.io/enforce": "restricted",
+		"pod-security.kubernetes.io/audit":   "restricted",
+		"pod-security.kubernetes.io/warn":    "restricted",
+	}
+	for k, v := range wantLabels {
+		if ns.Labels[k] != v {
+			t.Errorf("Namespace label %q got %q, want %q", k, ns.Labels[k], v)
+		}
+	}
+
+	wantPolicies := res.Resources{
+		"environments/test-dev/env/base/test-dev-default-deny-networkpolicy.yaml":    namespaces.DefaultDenyNetworkPolicy("test-dev"),
+		"environments/test-dev/env/base/test-dev-allow-from-cicd-networkpolicy.yaml": namespaces.AllowFromNamespaceNetworkPolicy("test-dev", "cicd"),
+	}
+	for k, v := range wantPolicies {
+		if diff := cmp.Diff(v, files[k]); diff != "" {
+			t.Errorf("NetworkPolicy %s didn't match: %s\n", k, diff)
+		}
+	}
+
+	want := &res.Kustomization{Resources: []string{
+		"test-dev-allow-from-cicd-networkpolicy.yaml",
+		"test-dev-default-deny-networkpolicy.yaml",
+		"test-dev-environment.yaml",
+		"test-dev-rolebinding.yaml",
+	}}
+	if diff := cmp.Diff(want, files["environments/test-dev/env/base/kustomization.yaml"]); diff != "" {
+		t.Fatalf("kustomization didn't match: %s\n", diff)
+	}
+}
+
+func TestBuildEnvironmentFilesWithQuota(t *testing.T) {
+	var appFs = ioutils.NewMemoryFilesystem()
+	m := buildManifestWithCICD()
+	m.Environments = append(m.Environments, &config.Environment{Name: "test-stage"})
+	for _, env := range m.Environments {
+		env.Quota = &config.QuotaConfig{CPU: "4", Memory: "8Gi", DefaultMemory: "256Mi"}
+	}
+
+	files, err := Build(appFs, m, "pipelines", AppsToEnvironments)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, env := range []string{"test-dev", "test-stage"} {
+		basePath := "environments/" + env + "/env/base/"
+		wantQuota := namespaces.ResourceQuota(env, corev1.ResourceList{
+			corev1.ResourceLimitsCPU:    resource.MustParse("4"),
+			corev1.ResourceLimitsMemory: resource.MustParse("8Gi"),
+		})
+		if diff := cmp.Diff(wantQuota, files[basePath+env+"-resourcequota.yaml"]); diff != "" {
+			t.Errorf("ResourceQuota for %s didn't match: %s\n", env, diff)
+		}
+		wantLimits := namespaces.LimitRange(env, corev1.ResourceList{
+			corev1.ResourceCPU:    resource.MustParse(config.DefaultContainerCPU),
+			corev1.ResourceMemory: resource.MustParse("256Mi"),
+		})
+		if diff := cmp.Diff(wantLimits, files[basePath+env+"-limitrange.yaml"]); diff != "" {
+			t.Errorf("LimitRange for %s didn't match: %s\n", env, diff)
+		}
+		kustomization, ok := files[basePath+"kustomization.yaml"].(*res.Kustomization)
+		if !ok {
+			t.Fatalf("no kustomization generated for %s", env)
+		}
+		for _, want := range []string{env + "-limitrange.yaml", env + "-resourcequota.yaml"} {
+			if !contains(kustomization.Resources, want) {
+				t.Errorf("%s not in the kustomization resources %v", want, kustomization.Resources)
+			}
+		}
+	}
+}
+
+func contains(s []string, v string) bool {
+	for _, e := range s {
+		if e == v {
+			return true
+		}
+	}
+	return false
+}
+
 func filesFromResources(r res.Resources) []string {
 	names := []string{}
 	for k := range r {
@@ -1,6 +1,9 @@
 package eventlisteners
 
 import (
+	"fmt"
+	"strings"
+
 	"github.com/rhd-gitops-example/gitops-cli/pkg/pipelines/scm"
 	"github.com/tektoncd/triggers/pkg/apis/triggers/v1alpha1"
 	triggersv1 "github.com/tektoncd/triggers/pkg/apis/triggers/v1alpha1"
@@ -21,19 +24,56 @@ var (
 )
 
 // Generate will create the required eventlisteners.
-func Generate(repo scm.Repository, ns, saName, secretName string) triggersv1.EventListener {
+//
+// If branches are provided, the trigger only accepts pushes to these branches.
+func Generate(repo scm.Repository, ns, saName, secretName string, branches ...string) triggersv1.EventListener {
 	return triggersv1.EventListener{
 		TypeMeta:   eventListenerTypeMeta,
 		ObjectMeta: createListenerObjectMeta("cicd-event-listener", ns),
 		Spec: triggersv1.EventListenerSpec{
 			ServiceAccountName: saName,
-			Triggers: []triggersv1.EventListenerTrigger{
+			Triggers: FilterBranches([]triggersv1.EventListenerTrigger{
 				repo.CreatePushTrigger("ci-dryrun-from-push", secretName, ns, "ci-dryrun-from-push-template", []string{"github-push-binding"}),
-			},
+			}, branches),
 		},
 	}
 }
 
+// WebhookSecretName returns the name of the Secret used to authenticate hooks
+// from the GitOps repository, name if it's set, or GitOpsWebhookSecret.
+func WebhookSecretName(name string) string {
+	if name != "" {
+		return name
+	}
+	return GitOpsWebhookSecret
+}
+
+// BranchFilter creates a CEL interceptor that only accepts pushes to the
+// provided branches.
+func BranchFilter(branches []string) *triggersv1.EventInterceptor {
+	refs := make([]string, len(branches))
+	for i, branch := range branches {
+		refs[i] = fmt.Sprintf("'refs/heads/%s'", branch)
+	}
+	return &triggersv1.EventInterceptor{
+		CEL: &triggersv1.CELInterceptor{
+			Filter: fmt.Sprintf("body.ref in [%s]", strings.Join(refs, ", ")),
+		},
+	}
+}
+
+// FilterBranches adds a BranchFilter interceptor to each of the triggers, if
+// any branches are provided.
+func FilterBranches(triggers []triggersv1.EventListenerTrigger, branches []string) []triggersv1.EventListenerTrigger {
+	if len(branches) == 0 {
+		return triggers
+	}
+	for i := range triggers {
+		triggers[i].Interceptors = append(triggers[i].Interceptors, BranchFilter(branches))
+	}
+	return triggers
+}
+
 // CreateELFromTriggers creates an EventListener from a supplied set of
 // trigger, with the provided namespace and name.
 func CreateELFromTriggers(cicdNS, saName string, triggers []triggersv1.EventListenerTrigger) *triggersv1.EventListener {
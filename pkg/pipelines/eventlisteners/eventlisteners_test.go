@@ -62,6 +62,33 @@ func TestGenerateEventListener(t *testing.T) {
 	}
 }
 
+func TestGenerateEventListenerWithBranches(t *testing.T) {
+	repo, err := scm.NewRepository("http://github.com/org/test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	eventListener := Generate(repo, "testing", "pipeline", "test", "main", "release")
+
+	interceptors := eventListener.Spec.Triggers[0].Interceptors
+	want := &triggersv1.EventInterceptor{
+		CEL: &triggersv1.CELInterceptor{
+			Filter: "body.ref in ['refs/heads/main', 'refs/heads/release']",
+		},
+	}
+	if diff := cmp.Diff(want, interceptors[len(interceptors)-1]); diff != "" {
+		t.Fatalf("Generate() branch filter failed:\n%s", diff)
+	}
+}
+
+func TestWebhookSecretName(t *testing.T) {
+	if got := WebhookSecretName(""); got != GitOpsWebhookSecret {
+		t.Errorf("WebhookSecretName(\"\") got %q, want %q", got, GitOpsWebhookSecret)
+	}
+	if got := WebhookSecretName("gitops-hooks"); got != "gitops-hooks" {
+		t.Errorf("WebhookSecretName(\"gitops-hooks\") got %q, want %q", got, "gitops-hooks")
+	}
+}
+
 func TestCreateListenerObjectMeta(t *testing.T) {
 	validObjectMeta := metav1.ObjectMeta{
 		Name:      "sample",
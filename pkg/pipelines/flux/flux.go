@@ -0,0 +1,170 @@
+package flux
+
+import (
+	"fmt"
+	"path/filepath"
+	"sort"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/rhd-gitops-example/gitops-cli/pkg/pipelines/config"
+	"github.com/rhd-gitops-example/gitops-cli/pkg/pipelines/meta"
+	res "github.com/rhd-gitops-example/gitops-cli/pkg/pipelines/resources"
+)
+
+const (
+	// FluxNamespace is the namespace that the Flux controllers are installed
+	// to by default.
+	FluxNamespace = "flux-system"
+
+	gitOpsRepoName  = "gitops-repo"
+	defaultInterval = "1m"
+	defaultServer   = "https://kubernetes.default.svc"
+)
+
+var (
+	kustomizationTypeMeta = meta.TypeMeta("Kustomization", "kustomize.toolkit.fluxcd.io/v1beta1")
+	gitRepositoryTypeMeta = meta.TypeMeta("GitRepository", "source.toolkit.fluxcd.io/v1beta1")
+)
+
+// Kustomization is a Flux Kustomization, which applies the manifests at a
+// path in a source, there are no compatible upstream types, so only the fields
+// we generate are defined here.
+type Kustomization struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata"`
+	Spec              KustomizationSpec `json:"spec"`
+}
+
+// KustomizationSpec is the specification of a Kustomization.
+type KustomizationSpec struct {
+	Interval        string            `json:"interval"`
+	Path            string            `json:"path"`
+	Prune           bool              `json:"prune"`
+	SourceRef       CrossNamespaceRef `json:"sourceRef"`
+	TargetNamespace string            `json:"targetNamespace,omitempty"`
+}
+
+// CrossNamespaceRef refers to the source of a Kustomization.
+type CrossNamespaceRef struct {
+	Kind      string `json:"kind"`
+	Name      string `json:"name"`
+	Namespace string `json:"namespace,omitempty"`
+}
+
+// GitRepository is a Flux source for a git repository.
+type GitRepository struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata"`
+	Spec              GitRepositorySpec `json:"spec"`
+}
+
+// GitRepositorySpec is the specification of a GitRepository.
+type GitRepositorySpec struct {
+	URL      string            `json:"url"`
+	Interval string            `json:"interval"`
+	Ref      *GitRepositoryRef `json:"ref,omitempty"`
+}
+
+// GitRepositoryRef is the git reference to checkout from a GitRepository.
+type GitRepositoryRef struct {
+	Branch string `json:"branch,omitempty"`
+}
+
+// Build generates a Flux Kustomization for each environment and application,
+// the equivalent of the Argo CD Applications generated by argocd.Build.
+func Build(fluxNS, repoURL string, m *config.Manifest) (res.Resources, error) {
+	// Without a RepositoryURL we can't do anything.
+	if repoURL == "" {
+		return res.Resources{}, nil
+	}
+	fluxConfig := m.GetFluxConfig()
+	if fluxConfig == nil {
+		return res.Resources{}, nil
+	}
+
+	fb := &fluxBuilder{fluxNS: fluxNS, gitOpsPath: m.GitOpsPath, files: res.Resources{}}
+	err := m.Walk(fb)
+	if err != nil {
+		return nil, err
+	}
+	fluxConfigResources(m, repoURL, fb.files)
+	return fb.files, nil
+}
+
+type fluxBuilder struct {
+	fluxNS     string
+	gitOpsPath string // The directory within the GitOps repository that the configuration is in.
+	files      res.Resources
+}
+
+func (b *fluxBuilder) Application(env *config.Environment, app *config.Application) error {
+	if !env.Deploys() || !app.Deployed() {
+		return nil
+	}
+	if env.Cluster != "" && env.Cluster != defaultServer {
+		return fmt.Errorf("environment %s is deployed to cluster %s, Flux can only deploy to its own cluster", env.Name, env.Cluster)
+	}
+	basePath := config.PathForFlux()
+	name := env.Name + "-" + app.Name
+	source := gitOpsRepoName
+	path := filepath.Join(b.gitOpsPath, config.PathForApplication(env, app), "base")
+	if app.ConfigRepo != nil {
+		source = name
+		path = app.ConfigRepo.Path
+		b.files[filepath.Join(basePath, name+"-repo.yaml")] = makeGitRepository(name, b.fluxNS, app.ConfigRepo.URL, app.ConfigRepo.TargetRevision)
+	}
+	b.files[filepath.Join(basePath, name+"-app.yaml")] = makeKustomization(name, b.fluxNS, env.Name, source, path)
+	return nil
+}
+
+// fluxConfigResources adds the GitOps repository source, and Kustomizations
+// for the Flux configuration itself, and the CI/CD pipelines.
+func fluxConfigResources(m *config.Manifest, repoURL string, files res.Resources) {
+	cfg := m.Config
+	if cfg.Flux.Namespace == "" {
+		return
+	}
+	basePath := config.PathForFlux()
+	files[filepath.Join(basePath, gitOpsRepoName+".yaml")] = makeGitRepository(gitOpsRepoName, cfg.Flux.Namespace, repoURL, "")
+	files[filepath.Join(basePath, "flux-app.yaml")] = makeKustomization("flux-app", cfg.Flux.Namespace, "", gitOpsRepoName, m.GitOpsRepoPath(basePath))
+	if cfg.Pipelines != nil {
+		files[filepath.Join(basePath, "cicd-app.yaml")] = makeKustomization("cicd-app", cfg.Flux.Namespace, cfg.Pipelines.Name, gitOpsRepoName,
+			m.GitOpsRepoPath(filepath.Join(config.PathForPipelines(cfg.Pipelines), "overlays")))
+	}
+	resourceNames := []string{}
+	for k := range files {
+		resourceNames = append(resourceNames, filepath.Base(k))
+	}
+	sort.Strings(resourceNames)
+	files[filepath.Join(basePath, "kustomization.yaml")] = &res.Kustomization{Resources: resourceNames}
+}
+
+func makeKustomization(name, fluxNS, targetNS, source, path string) *Kustomization {
+	return &Kustomization{
+		TypeMeta:   kustomizationTypeMeta,
+		ObjectMeta: meta.ObjectMeta(meta.NamespacedName(fluxNS, name)),
+		Spec: KustomizationSpec{
+			Interval:        defaultInterval,
+			Path:            "./" + path,
+			Prune:           true,
+			SourceRef:       CrossNamespaceRef{Kind: "GitRepository", Name: source},
+			TargetNamespace: targetNS,
+		},
+	}
+}
+
+func makeGitRepository(name, fluxNS, url, branch string) *GitRepository {
+	repo := &GitRepository{
+		TypeMeta:   gitRepositoryTypeMeta,
+		ObjectMeta: meta.ObjectMeta(meta.NamespacedName(fluxNS, name)),
+		Spec: GitRepositorySpec{
+			URL:      url,
+			Interval: defaultInterval,
+		},
+	}
+	if branch != "" {
+		repo.Spec.Ref = &GitRepositoryRef{Branch: branch}
+	}
+	return repo
+}
@@ -0,0 +1,136 @@
+package flux
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/rhd-gitops-example/gitops-cli/pkg/pipelines/config"
+	"github.com/rhd-gitops-example/gitops-cli/pkg/pipelines/helper"
+	res "github.com/rhd-gitops-example/gitops-cli/pkg/pipelines/resources"
+)
+
+const testRepoURL = "https://github.com/rhd-example-gitops/example"
+
+var (
+	testApp = &config.Application{
+		Name: "http-api",
+	}
+	configRepoApp = &config.Application{
+		Name: "prod-api",
+		ConfigRepo: &config.Repository{
+			URL:            "https://github.com/rhd-example-gitops/other-repo",
+			Path:           "deploys",
+			TargetRevision: "release",
+		},
+	}
+
+	testEnv = &config.Environment{
+		Name: "test-dev",
+		Apps: []*config.Application{
+			testApp,
+		},
+	}
+)
+
+func TestBuildCreatesKustomizations(t *testing.T) {
+	m := &config.Manifest{
+		Environments: []*config.Environment{testEnv},
+		Config: &config.Config{
+			Pipelines: &config.PipelinesConfig{Name: "test-cicd"},
+			Flux:      &config.FluxConfig{Namespace: FluxNamespace},
+		},
+	}
+
+	files, err := Build(FluxNamespace, testRepoURL, m)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := res.Resources{
+		"config/flux/test-dev-http-api-app.yaml": makeKustomization("test-dev-http-api", FluxNamespace, "test-dev", "gitops-repo", "environments/test-dev/apps/http-api/base"),
+		"config/flux/gitops-repo.yaml":           makeGitRepository("gitops-repo", FluxNamespace, testRepoURL, ""),
+		"config/flux/flux-app.yaml":              makeKustomization("flux-app", FluxNamespace, "", "gitops-repo", "config/flux"),
+		"config/flux/cicd-app.yaml":              makeKustomization("cicd-app", FluxNamespace, "test-cicd", "gitops-repo", "config/test-cicd/overlays"),
+		"config/flux/kustomization.yaml": &res.Kustomization{
+			Resources: []string{"cicd-app.yaml", "flux-app.yaml", "gitops-repo.yaml", "test-dev-http-api-app.yaml"},
+		},
+	}
+	if diff := cmp.Diff(want, files); diff != "" {
+		t.Fatalf("files didn't match: %s\n", diff)
+	}
+}
+
+func TestBuildKustomizationPathAndSource(t *testing.T) {
+	prodEnv := &config.Environment{
+		Name: "test-production",
+		Apps: []*config.Application{configRepoApp},
+	}
+	m := &config.Manifest{
+		Environments: []*config.Environment{testEnv, prodEnv},
+		Config: &config.Config{
+			Flux: &config.FluxConfig{Namespace: FluxNamespace},
+		},
+	}
+
+	files, err := Build(FluxNamespace, testRepoURL, m)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	sourceTests := []struct {
+		filename   string
+		wantPath   string
+		wantSource CrossNamespaceRef
+	}{
+		{"config/flux/test-dev-http-api-app.yaml", "./environments/test-dev/apps/http-api/base", CrossNamespaceRef{Kind: "GitRepository", Name: "gitops-repo"}},
+		{"config/flux/test-production-prod-api-app.yaml", "./deploys", CrossNamespaceRef{Kind: "GitRepository", Name: "test-production-prod-api"}},
+	}
+	for _, tt := range sourceTests {
+		t.Run(tt.filename, func(rt *testing.T) {
+			k := files[tt.filename].(*Kustomization)
+			if k.Spec.Path != tt.wantPath {
+				rt.Errorf("got path %q, want %q", k.Spec.Path, tt.wantPath)
+			}
+			if diff := cmp.Diff(tt.wantSource, k.Spec.SourceRef); diff != "" {
+				rt.Errorf("sourceRef didn't match: %s\n", diff)
+			}
+		})
+	}
+
+	want := makeGitRepository("test-production-prod-api", FluxNamespace, "https://github.com/rhd-example-gitops/other-repo", "release")
+	if diff := cmp.Diff(want, files["config/flux/test-production-prod-api-repo.yaml"]); diff != "" {
+		t.Fatalf("config repo source didn't match: %s\n", diff)
+	}
+}
+
+func TestBuildWithRemoteCluster(t *testing.T) {
+	m := &config.Manifest{
+		Environments: []*config.Environment{
+			{Name: "test-production", Cluster: "https://prod.example.com", Apps: []*config.Application{testApp}},
+		},
+		Config: &config.Config{
+			Flux: &config.FluxConfig{Namespace: FluxNamespace},
+		},
+	}
+
+	_, err := Build(FluxNamespace, testRepoURL, m)
+	helper.AssertErrorMatch(t, "environment test-production is deployed to cluster https://prod.example.com", err)
+}
+
+func TestBuildWithNoFluxConfig(t *testing.T) {
+	m := &config.Manifest{
+		Environments: []*config.Environment{testEnv},
+		Config: &config.Config{
+			ArgoCD: &config.ArgoCDConfig{Namespace: "argocd"},
+		},
+	}
+
+	files, err := Build(FluxNamespace, testRepoURL, m)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff(res.Resources{}, files); diff != "" {
+		t.Fatalf("files didn't match: %s\n", diff)
+	}
+}
@@ -0,0 +1,76 @@
+package pipelines
+
+import (
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/rhd-gitops-example/gitops-cli/pkg/pipelines/config"
+	"github.com/rhd-gitops-example/gitops-cli/pkg/pipelines/ioutils"
+	"github.com/rhd-gitops-example/gitops-cli/pkg/pipelines/yaml"
+	"github.com/spf13/afero"
+)
+
+// GenerateParameters is a struct that provides flags for the Generate command.
+type GenerateParameters struct {
+	PipelinesFolderPath string
+	OutputPath          string
+	OutputRoot          string // If set, the OutputPath must be within this directory.
+	UseApplicationSet   bool   // Generate an ApplicationSet rather than individual Applications.
+	DryRun              bool   // If true, the files are not written.
+	Overwrite           bool   // If true, existing files in the OutputPath are replaced.
+}
+
+// Generate regenerates the manifest files from an existing pipelines.yaml.
+//
+// It returns the sorted list of files that were written, or would be written
+// if DryRun is set, and the sorted list of files that already had the generated
+// content, these are not rewritten.
+func Generate(o *GenerateParameters, appFs afero.Fs) ([]string, []string, error) {
+	if err := ioutils.ValidateOutputPath(appFs, o.OutputPath, o.OutputRoot); err != nil {
+		return nil, nil, err
+	}
+	m, err := config.LoadManifest(appFs, o.PipelinesFolderPath)
+	if err != nil {
+		return nil, nil, err
+	}
+	buildParams := &BuildParameters{
+		PipelinesFolderPath: o.PipelinesFolderPath,
+		OutputPath:          o.OutputPath,
+		UseApplicationSet:   o.UseApplicationSet,
+	}
+	resources, err := buildResources(appFs, buildParams, m)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to build resources: %v", err)
+	}
+	filenames := getResourceFiles(resources)
+	if !o.Overwrite {
+		if err := checkGeneratedFilesExist(appFs, o.OutputPath, filenames); err != nil {
+			return nil, nil, err
+		}
+	}
+	if o.DryRun {
+		return filenames, nil, nil
+	}
+	written, unchanged, err := yaml.WriteChangedResources(appFs, o.OutputPath, resources)
+	if err != nil {
+		return nil, nil, err
+	}
+	sort.Strings(written)
+	sort.Strings(unchanged)
+	return written, unchanged, nil
+}
+
+func checkGeneratedFilesExist(appFs afero.Fs, outputPath string, filenames []string) error {
+	existing := []string{}
+	for _, f := range filenames {
+		if exists, _ := ioutils.IsExisting(appFs, filepath.Join(outputPath, f)); exists {
+			existing = append(existing, f)
+		}
+	}
+	if len(existing) > 0 {
+		return fmt.Errorf("%s in output path already exist. If you want to replace your existing files, please rerun with --overwrite", strings.Join(existing, ", "))
+	}
+	return nil
+}
@@ -0,0 +1,108 @@
+package pipelines
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/spf13/afero"
+
+	"github.com/rhd-gitops-example/gitops-cli/pkg/pipelines/helper"
+	"github.com/rhd-gitops-example/gitops-cli/pkg/pipelines/ioutils"
+)
+
+var generatedTree = []string{
+	"environments/dev/apps/taxi/base/kustomization.yaml",
+	"environments/dev/apps/taxi/kustomization.yaml",
+	"environments/dev/apps/taxi/overlays/kustomization.yaml",
+	"environments/dev/apps/taxi/services/gateway/base/kustomization.yaml",
+	"environments/dev/apps/taxi/services/gateway/kustomization.yaml",
+	"environments/dev/apps/taxi/services/gateway/overlays/kustomization.yaml",
+	"environments/dev/env/base/dev-environment.yaml",
+	"environments/dev/env/base/kustomization.yaml",
+	"environments/dev/env/overlays/kustomization.yaml",
+	"environments/stage/env/base/kustomization.yaml",
+	"environments/stage/env/base/stage-environment.yaml",
+	"environments/stage/env/overlays/kustomization.yaml",
+}
+
+func TestGenerate(t *testing.T) {
+	fakeFs, gitopsPath := manifestFileFixture(t, "testdata/generate/pipelines.yaml")
+
+	files, _, err := Generate(&GenerateParameters{
+		PipelinesFolderPath: gitopsPath,
+		OutputPath:          gitopsPath,
+	}, fakeFs)
+	assertNoError(t, err)
+
+	if diff := cmp.Diff(generatedTree, files); diff != "" {
+		t.Fatalf("generated files failed:\n%s", diff)
+	}
+	for _, f := range generatedTree {
+		assertFileExists(t, fakeFs, filepath.Join(gitopsPath, f))
+	}
+}
+
+func TestGenerateWithDryRun(t *testing.T) {
+	fakeFs, gitopsPath := manifestFileFixture(t, "testdata/generate/pipelines.yaml")
+
+	files, _, err := Generate(&GenerateParameters{
+		PipelinesFolderPath: gitopsPath,
+		OutputPath:          gitopsPath,
+		DryRun:              true,
+	}, fakeFs)
+	assertNoError(t, err)
+
+	if diff := cmp.Diff(generatedTree, files); diff != "" {
+		t.Fatalf("generated files failed:\n%s", diff)
+	}
+	for _, f := range generatedTree {
+		if exists, _ := ioutils.IsExisting(fakeFs, filepath.Join(gitopsPath, f)); exists {
+			t.Errorf("dry-run wrote %s", f)
+		}
+	}
+}
+
+func TestGenerateWithExistingFiles(t *testing.T) {
+	fakeFs, gitopsPath := manifestFileFixture(t, "testdata/generate/pipelines.yaml")
+	existing := filepath.Join(gitopsPath, "environments/dev/env/base/dev-environment.yaml")
+	assertNoError(t, afero.WriteFile(fakeFs, existing, []byte("stale: true\n"), 0644))
+	params := &GenerateParameters{
+		PipelinesFolderPath: gitopsPath,
+		OutputPath:          gitopsPath,
+	}
+
+	_, _, err := Generate(params, fakeFs)
+	helper.AssertErrorMatch(t, "environments/dev/env/base/dev-environment.yaml in output path already exist.*--overwrite", err)
+
+	params.Overwrite = true
+	_, _, err = Generate(params, fakeFs)
+	assertNoError(t, err)
+	b, err := afero.ReadFile(fakeFs, existing)
+	assertNoError(t, err)
+	if string(b) == "stale: true\n" {
+		t.Fatal("Generate() with overwrite did not replace the existing file")
+	}
+}
+
+func TestGenerateSkipsUnchangedFiles(t *testing.T) {
+	fakeFs, gitopsPath := manifestFileFixture(t, "testdata/generate/pipelines.yaml")
+	params := &GenerateParameters{
+		PipelinesFolderPath: gitopsPath,
+		OutputPath:          gitopsPath,
+		Overwrite:           true,
+	}
+	_, _, err := Generate(params, fakeFs)
+	assertNoError(t, err)
+
+	// Any attempt to rewrite a file fails on the read-only filesystem.
+	files, unchanged, err := Generate(params, afero.NewReadOnlyFs(fakeFs))
+	assertNoError(t, err)
+
+	if len(files) != 0 {
+		t.Fatalf("regenerating an unchanged tree rewrote %v", files)
+	}
+	if diff := cmp.Diff(generatedTree, unchanged); diff != "" {
+		t.Fatalf("unchanged files failed:\n%s", diff)
+	}
+}
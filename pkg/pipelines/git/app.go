@@ -0,0 +1,153 @@
+package git
+
+import (
+	"context"
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/jenkins-x/go-scm/scm"
+)
+
+// GitHubApp is a GitHub App whose webhook is configured for the app, rather
+// than for each repository, the app delivers the events for all the
+// repositories that it's installed on.
+type GitHubApp struct {
+	ID         string
+	PrivateKey *rsa.PrivateKey
+}
+
+// NewGitHubApp creates a GitHubApp from its ID and the PEM encoded private key
+// that's generated for it by GitHub.
+func NewGitHubApp(id string, pemKey []byte) (*GitHubApp, error) {
+	block, _ := pem.Decode(pemKey)
+	if block == nil {
+		return nil, fmt.Errorf("failed to parse the private key for GitHub App %s: no PEM data was found", id)
+	}
+	if key, err := x509.ParsePKCS1PrivateKey(block.Bytes); err == nil {
+		return &GitHubApp{ID: id, PrivateKey: key}, nil
+	}
+	parsed, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse the private key for GitHub App %s: %w", id, err)
+	}
+	key, ok := parsed.(*rsa.PrivateKey)
+	if !ok {
+		return nil, fmt.Errorf("failed to parse the private key for GitHub App %s: not an RSA key", id)
+	}
+	return &GitHubApp{ID: id, PrivateKey: key}, nil
+}
+
+// appTokenLifetime is how long the JWTs that authenticate as the app are valid
+// for, GitHub allows at most 10 minutes.
+const appTokenLifetime = 9 * time.Minute
+
+// jwt returns a JWT signed with the app's private key, that authenticates
+// requests as the app, the issued time is backdated to allow for clock drift.
+func (a *GitHubApp) jwt(now time.Time) (string, error) {
+	header, err := json.Marshal(map[string]string{"alg": "RS256", "typ": "JWT"})
+	if err != nil {
+		return "", err
+	}
+	claims, err := json.Marshal(map[string]interface{}{
+		"iat": now.Add(-time.Minute).Unix(),
+		"exp": now.Add(appTokenLifetime).Unix(),
+		"iss": a.ID,
+	})
+	if err != nil {
+		return "", err
+	}
+	unsigned := base64.RawURLEncoding.EncodeToString(header) + "." + base64.RawURLEncoding.EncodeToString(claims)
+	hashed := sha256.Sum256([]byte(unsigned))
+	sig, err := rsa.SignPKCS1v15(rand.Reader, a.PrivateKey, crypto.SHA256, hashed[:])
+	if err != nil {
+		return "", fmt.Errorf("failed to sign the token for GitHub App %s: %w", a.ID, err)
+	}
+	return unsigned + "." + base64.RawURLEncoding.EncodeToString(sig), nil
+}
+
+// ValidateAppInstallation returns an error if the GitHub App isn't installed
+// with access to the repository.
+//
+// The app's installation for the repository is found with a JWT for the app,
+// and an installation token is created, which must be able to read the
+// repository, so the repository must be created without an access token,
+// which would replace the authorization of the requests.
+func (r *Repository) ValidateAppInstallation(app *GitHubApp) error {
+	if r.Client.Driver != scm.DriverGithub {
+		return fmt.Errorf("GitHub Apps are only supported for GitHub repositories, not %s", r.Client.Driver)
+	}
+	jwt, err := app.jwt(time.Now())
+	if err != nil {
+		return err
+	}
+	var installation struct {
+		ID int64 `json:"id"`
+	}
+	status, err := r.doJSON(http.MethodGet, fmt.Sprintf("repos/%s/installation", r.name), "Bearer "+jwt, &installation)
+	if err != nil {
+		return fmt.Errorf("failed to get the installation of GitHub App %s for %s: %w", app.ID, r.name, err)
+	}
+	if status == http.StatusNotFound {
+		return fmt.Errorf("GitHub App %s is not installed for %s", app.ID, r.name)
+	}
+	if status > 299 {
+		return fmt.Errorf("failed to get the installation of GitHub App %s for %s: unexpected status %d", app.ID, r.name, status)
+	}
+
+	var token struct {
+		Token string `json:"token"`
+	}
+	status, err = r.doJSON(http.MethodPost, fmt.Sprintf("app/installations/%d/access_tokens", installation.ID), "Bearer "+jwt, &token)
+	if err != nil {
+		return fmt.Errorf("failed to create an installation token for GitHub App %s: %w", app.ID, err)
+	}
+	if status > 299 {
+		return fmt.Errorf("failed to create an installation token for GitHub App %s: unexpected status %d", app.ID, status)
+	}
+
+	status, err = r.doJSON(http.MethodGet, fmt.Sprintf("repos/%s", r.name), "token "+token.Token, nil)
+	if err != nil {
+		return fmt.Errorf("failed to get %s with the installation token for GitHub App %s: %w", r.name, app.ID, err)
+	}
+	if status == http.StatusNotFound {
+		return fmt.Errorf("the installation of GitHub App %s does not have access to %s", app.ID, r.name)
+	}
+	if status > 299 {
+		return fmt.Errorf("failed to get %s with the installation token for GitHub App %s: unexpected status %d", r.name, app.ID, status)
+	}
+	return nil
+}
+
+// doJSON makes a request with the authorization, and decodes a successful
+// response into v, if it's not nil, the status is returned to be checked.
+func (r *Repository) doJSON(method, path, authorization string, v interface{}) (int, error) {
+	req := &scm.Request{
+		Method: method,
+		Path:   path,
+		Header: http.Header{
+			"Accept":        []string{"application/vnd.github.v3+json"},
+			"Authorization": []string{authorization},
+		},
+	}
+	res, err := r.Client.Do(context.Background(), req)
+	if err != nil {
+		return 0, err
+	}
+	defer res.Body.Close()
+	if res.Status > 299 || v == nil {
+		return res.Status, nil
+	}
+	if err := json.NewDecoder(res.Body).Decode(v); err != nil {
+		return res.Status, fmt.Errorf("failed to decode the response: %w", err)
+	}
+	return res.Status, nil
+}
@@ -0,0 +1,171 @@
+package git
+
+import (
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/h2non/gock"
+)
+
+func TestNewGitHubApp(t *testing.T) {
+	key := generateAppKey(t)
+	pkcs8, err := x509.MarshalPKCS8PrivateKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for name, block := range map[string]*pem.Block{
+		"PKCS1": {Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(key)},
+		"PKCS8": {Type: "PRIVATE KEY", Bytes: pkcs8},
+	} {
+		t.Run(name, func(rt *testing.T) {
+			app, err := NewGitHubApp("1234", pem.EncodeToMemory(block))
+			if err != nil {
+				rt.Fatal(err)
+			}
+			if app.ID != "1234" || app.PrivateKey.N.Cmp(key.N) != 0 {
+				rt.Fatalf("got app %s with a different key, want 1234", app.ID)
+			}
+		})
+	}
+}
+
+func TestNewGitHubAppWithInvalidKey(t *testing.T) {
+	_, err := NewGitHubApp("1234", []byte("not a key"))
+	want := "failed to parse the private key for GitHub App 1234: no PEM data was found"
+	if err == nil || err.Error() != want {
+		t.Fatalf("got error %v, want %s", err, want)
+	}
+}
+
+func TestGitHubAppJWT(t *testing.T) {
+	key := generateAppKey(t)
+	app := &GitHubApp{ID: "1234", PrivateKey: key}
+	now := time.Date(2020, time.July, 1, 12, 0, 0, 0, time.UTC)
+
+	token, err := app.jwt(now)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		t.Fatalf("got %d parts in the token, want 3", len(parts))
+	}
+	sig, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		t.Fatal(err)
+	}
+	hashed := sha256.Sum256([]byte(parts[0] + "." + parts[1]))
+	if err := rsa.VerifyPKCS1v15(&key.PublicKey, crypto.SHA256, hashed[:], sig); err != nil {
+		t.Fatalf("failed to verify the token signature: %v", err)
+	}
+	b, err := base64.RawURLEncoding.DecodeString(parts[1])
+	if err != nil {
+		t.Fatal(err)
+	}
+	var claims map[string]interface{}
+	if err := json.Unmarshal(b, &claims); err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]interface{}{
+		"iat": float64(now.Add(-time.Minute).Unix()),
+		"exp": float64(now.Add(9 * time.Minute).Unix()),
+		"iss": "1234",
+	}
+	if diff := cmp.Diff(want, claims); diff != "" {
+		t.Fatalf("claims failed:\n%s", diff)
+	}
+}
+
+func TestValidateAppInstallation(t *testing.T) {
+	defer gock.Off()
+
+	gock.New("https://api.github.com").
+		Get("/repos/foo/bar/installation").
+		MatchHeader("Authorization", "^Bearer ").
+		Reply(200).
+		Type("application/json").
+		SetHeaders(mockHeaders).
+		BodyString(`{"id": 42, "app_id": 1234}`)
+	gock.New("https://api.github.com").
+		Post("/app/installations/42/access_tokens").
+		MatchHeader("Authorization", "^Bearer ").
+		Reply(201).
+		Type("application/json").
+		SetHeaders(mockHeaders).
+		BodyString(`{"token": "ghs_installation", "repository_selection": "selected"}`)
+	gock.New("https://api.github.com").
+		Get("/repos/foo/bar").
+		MatchHeader("Authorization", "^token ghs_installation$").
+		Reply(200).
+		Type("application/json").
+		SetHeaders(mockHeaders).
+		BodyString(`{"id": 1, "full_name": "foo/bar"}`)
+
+	repo, err := NewRepository("https://github.com/foo/bar.git", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := repo.ValidateAppInstallation(&GitHubApp{ID: "1234", PrivateKey: generateAppKey(t)}); err != nil {
+		t.Fatal(err)
+	}
+	if !gock.IsDone() {
+		t.Fatal("the installation token flow was not completed")
+	}
+}
+
+func TestValidateAppInstallationNotInstalled(t *testing.T) {
+	defer gock.Off()
+
+	gock.New("https://api.github.com").
+		Get("/repos/foo/bar/installation").
+		Reply(404).
+		Type("application/json").
+		SetHeaders(mockHeaders).
+		BodyString(`{"message": "Not Found"}`)
+
+	repo, err := NewRepository("https://github.com/foo/bar.git", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = repo.ValidateAppInstallation(&GitHubApp{ID: "1234", PrivateKey: generateAppKey(t)})
+	want := "GitHub App 1234 is not installed for foo/bar"
+	if err == nil || err.Error() != want {
+		t.Fatalf("got error %v, want %s", err, want)
+	}
+}
+
+func TestValidateAppInstallationUnsupportedDriver(t *testing.T) {
+	repo, err := NewRepository("https://gitlab.com/foo/bar.git", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = repo.ValidateAppInstallation(&GitHubApp{ID: "1234"})
+	want := "GitHub Apps are only supported for GitHub repositories, not gitlab"
+	if err == nil || err.Error() != want {
+		t.Fatalf("got error %v, want %s", err, want)
+	}
+}
+
+func generateAppKey(t *testing.T) *rsa.PrivateKey {
+	t.Helper()
+	key, err := rsa.GenerateKey(rand.Reader, 1024)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return key
+}
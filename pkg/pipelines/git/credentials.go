@@ -0,0 +1,57 @@
+package git
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+// Credentials maps Git hosts e.g. github.com to the access token to use for
+// repositories on that host.
+type Credentials map[string]string
+
+// ParseCredentials parses a set of credentials in the form host=token.
+func ParseCredentials(values []string) (Credentials, error) {
+	creds := Credentials{}
+	for _, v := range values {
+		parts := strings.SplitN(v, "=", 2)
+		if len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+			return nil, fmt.Errorf("failed to parse credential %q, expected host=token", redactCredential(v))
+		}
+		host := strings.ToLower(parts[0])
+		if _, ok := creds[host]; ok {
+			return nil, fmt.Errorf("duplicate credential for host %q", host)
+		}
+		creds[host] = parts[1]
+	}
+	return creds, nil
+}
+
+// Token returns the token for the host of the repository URL, if there's no
+// credential for the host, the defaultToken is returned.
+func (c Credentials) Token(rawURL, defaultToken string) string {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return defaultToken
+	}
+	if token, ok := c[strings.ToLower(u.Host)]; ok {
+		return token
+	}
+	if token, ok := c[strings.ToLower(u.Hostname())]; ok {
+		return token
+	}
+	return defaultToken
+}
+
+// NewRepository creates a new Git repository object, authenticated with the
+// credential for the repository's host.
+func (c Credentials) NewRepository(rawURL, defaultToken string) (*Repository, error) {
+	return NewRepository(rawURL, c.Token(rawURL, defaultToken))
+}
+
+func redactCredential(s string) string {
+	if i := strings.Index(s, "="); i >= 0 {
+		return s[:i+1] + "****"
+	}
+	return s
+}
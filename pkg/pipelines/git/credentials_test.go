@@ -0,0 +1,86 @@
+package git
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/h2non/gock"
+
+	"github.com/rhd-gitops-example/gitops-cli/pkg/pipelines/helper"
+)
+
+func TestParseCredentials(t *testing.T) {
+	tests := []struct {
+		values  []string
+		want    Credentials
+		wantErr string
+	}{
+		{[]string{}, Credentials{}, ""},
+		{
+			[]string{"github.com=gh-token", "GitLab.com=gl-token"},
+			Credentials{"github.com": "gh-token", "gitlab.com": "gl-token"}, "",
+		},
+		{[]string{"github.com=abc=def"}, Credentials{"github.com": "abc=def"}, ""},
+		{[]string{"github.com"}, nil, `failed to parse credential "github.com", expected host=token`},
+		{[]string{"github.com="}, nil, `failed to parse credential "github.com=\*\*\*\*", expected host=token`},
+		{[]string{"=secret"}, nil, `failed to parse credential "=\*\*\*\*", expected host=token`},
+		{[]string{"github.com=a", "github.com=b"}, nil, `duplicate credential for host "github.com"`},
+	}
+
+	for _, tt := range tests {
+		got, err := ParseCredentials(tt.values)
+		if !helper.ErrorMatch(t, tt.wantErr, err) {
+			t.Errorf("ParseCredentials(%#v) failed: got %v, want %s", tt.values, err, tt.wantErr)
+			continue
+		}
+		if diff := cmp.Diff(tt.want, got); diff != "" {
+			t.Errorf("ParseCredentials(%#v) failed:\n%s", tt.values, diff)
+		}
+	}
+}
+
+func TestCredentialsToken(t *testing.T) {
+	creds := Credentials{"github.com": "gh-token", "gitlab.example.com": "gl-token"}
+
+	tests := []struct {
+		repoURL string
+		want    string
+	}{
+		{"https://github.com/example/gitops.git", "gh-token"},
+		{"https://gitlab.example.com/example/service.git", "gl-token"},
+		{"https://gitlab.example.com:8443/example/service.git", "gl-token"},
+		{"https://bitbucket.org/example/service.git", "default-token"},
+	}
+
+	for _, tt := range tests {
+		if got := creds.Token(tt.repoURL, "default-token"); got != tt.want {
+			t.Errorf("Token(%q) got %q, want %q", tt.repoURL, got, tt.want)
+		}
+	}
+}
+
+func TestCredentialsNewRepositoryUsesHostToken(t *testing.T) {
+	defer gock.Off()
+
+	gock.New("https://api.github.com").
+		Get("/repos/foo/bar/hooks").
+		MatchHeader("Authorization", "gh-token").
+		Reply(200).
+		Type("application/json").
+		SetHeaders(mockHeaders).
+		File("testdata/hooks.json")
+
+	creds := Credentials{"github.com": "gh-token", "gitlab.com": "gl-token"}
+	repo, err := creds.NewRepository("https://github.com/foo/bar.git", "default-token")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ids, err := repo.ListWebhooks("http://example.com/webhook")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff([]string{"1"}, ids); diff != "" {
+		t.Errorf("webhooks mismatch got\n%s", diff)
+	}
+}
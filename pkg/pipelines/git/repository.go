@@ -1,14 +1,21 @@
 package git
 
 import (
+	"bytes"
 	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
+	"net/http"
 	"net/url"
 	"strings"
+	"time"
 
 	"github.com/jenkins-x/go-scm/scm"
 	"github.com/jenkins-x/go-scm/scm/factory"
+	"github.com/openshift/odo/pkg/log"
+
+	"github.com/rhd-gitops-example/gitops-cli/pkg/pipelines/network"
 )
 
 // Repository represent a Git repository ofa specific Git repository URL
@@ -30,6 +37,9 @@ func NewRepository(rawURL, token string) (*Repository, error) {
 	if err != nil {
 		return nil, err
 	}
+	if network.Restricted() {
+		client.Client = network.HTTPClient()
+	}
 
 	repoName, err := GetRepoName(parsed)
 	if err != nil {
@@ -55,6 +65,18 @@ func (r *Repository) ListWebhooks(listenerURL string) ([]string, error) {
 	return ids, nil
 }
 
+// DefaultBranch returns the name of the default branch of the repository.
+func (r *Repository) DefaultBranch() (string, error) {
+	repo, _, err := r.Client.Repositories.Find(context.Background(), r.name)
+	if err != nil {
+		return "", fmt.Errorf("failed to find repository %s: %w", r.name, err)
+	}
+	if repo.Branch == "" {
+		return "", fmt.Errorf("repository %s has no default branch", r.name)
+	}
+	return repo.Branch, nil
+}
+
 // DeleteWebhooks deletes all webhooks that associate with the given listener in this repository
 func (r *Repository) DeleteWebhooks(ids []string) ([]string, error) {
 	deleted := []string{}
@@ -69,9 +91,49 @@ func (r *Repository) DeleteWebhooks(ids []string) ([]string, error) {
 	return deleted, nil
 }
 
-// CreateWebhook creates a new webhook in the repository
+// The content types of the payloads that webhooks deliver, the EventListener
+// expects JSONContentType.
+const (
+	JSONContentType = "json"
+	FormContentType = "form"
+)
+
+// ContentTypes is the set of webhook content types that can be configured.
+var ContentTypes = []string{JSONContentType, FormContentType}
+
+// IsContentType returns true if s is one of the ContentTypes.
+func IsContentType(s string) bool {
+	for _, v := range ContentTypes {
+		if s == v {
+			return true
+		}
+	}
+	return false
+}
+
+// ValidateContentType returns an error if webhooks for the repository can't
+// deliver payloads with the content type, only GitHub supports form payloads,
+// an empty content type is JSONContentType.
+func (r *Repository) ValidateContentType(contentType string) error {
+	if contentType != "" && !IsContentType(contentType) {
+		return fmt.Errorf("invalid webhook content type %q, must be one of %s", contentType, strings.Join(ContentTypes, ", "))
+	}
+	if contentType == FormContentType && r.Client.Driver != scm.DriverGithub {
+		return fmt.Errorf("the webhook content type %q is only supported for GitHub repositories, not %s", contentType, r.Client.Driver)
+	}
+	return nil
+}
+
+// CreateWebhook creates a new webhook in the repository, that delivers
+// payloads with the content type, by default JSONContentType.
 // It returns ID of the created webhook
-func (r *Repository) CreateWebhook(listenerURL, secret string) (string, error) {
+func (r *Repository) CreateWebhook(listenerURL, secret, contentType string) (string, error) {
+	if err := r.ValidateContentType(contentType); err != nil {
+		return "", err
+	}
+	if r.Client.Driver == scm.DriverGithub {
+		return r.createGitHubWebhook(listenerURL, secret, contentType)
+	}
 	in := &scm.HookInput{
 		Target: listenerURL,
 		Secret: secret,
@@ -85,6 +147,222 @@ func (r *Repository) CreateWebhook(listenerURL, secret string) (string, error) {
 	return created.ID, err
 }
 
+// githubHookInput is the body for creating a GitHub repository hook, this is
+// used instead of scm.HookInput which has no field for the content type.
+type githubHookInput struct {
+	Name   string           `json:"name"`
+	Active bool             `json:"active"`
+	Events []string         `json:"events"`
+	Config githubHookConfig `json:"config"`
+}
+
+type githubHookConfig struct {
+	URL         string `json:"url"`
+	ContentType string `json:"content_type"`
+	Secret      string `json:"secret"`
+	InsecureSSL string `json:"insecure_ssl"`
+}
+
+func (r *Repository) createGitHubWebhook(listenerURL, secret, contentType string) (string, error) {
+	if contentType == "" {
+		contentType = JSONContentType
+	}
+	b, err := json.Marshal(githubHookInput{
+		Name:   "web",
+		Active: true,
+		Events: []string{"pull_request", "push"},
+		Config: githubHookConfig{
+			URL:         listenerURL,
+			ContentType: contentType,
+			Secret:      secret,
+			InsecureSSL: "0",
+		},
+	})
+	if err != nil {
+		return "", err
+	}
+	req := &scm.Request{
+		Method: http.MethodPost,
+		Path:   fmt.Sprintf("repos/%s/hooks", r.name),
+		Header: http.Header{"Content-Type": []string{"application/json"}},
+		Body:   bytes.NewReader(b),
+	}
+	return r.doCreateWebhook(req)
+}
+
+// ValidateBranchFilter returns an error if webhooks for the repository can't
+// filter push events by branch, only GitLab supports this.
+func (r *Repository) ValidateBranchFilter() error {
+	if r.Client.Driver != scm.DriverGitlab {
+		return fmt.Errorf("webhook branch filters are only supported for GitLab repositories, not %s", r.Client.Driver)
+	}
+	return nil
+}
+
+// gitlabHookInput is the body for creating a GitLab project hook, this is used
+// instead of scm.HookInput which has no field for the branch filter.
+type gitlabHookInput struct {
+	URL                    string `json:"url"`
+	Token                  string `json:"token"`
+	PushEvents             bool   `json:"push_events"`
+	MergeRequestsEvents    bool   `json:"merge_requests_events"`
+	PushEventsBranchFilter string `json:"push_events_branch_filter"`
+}
+
+// CreateWebhookWithBranchFilter creates a new webhook in a GitLab repository,
+// which is only sent push events for branches that match the filter, wildcards
+// are supported e.g. release/*.
+// It returns ID of the created webhook
+func (r *Repository) CreateWebhookWithBranchFilter(listenerURL, secret, filter string) (string, error) {
+	if err := r.ValidateBranchFilter(); err != nil {
+		return "", err
+	}
+	b, err := json.Marshal(gitlabHookInput{
+		URL:                    listenerURL,
+		Token:                  secret,
+		PushEvents:             true,
+		MergeRequestsEvents:    true,
+		PushEventsBranchFilter: filter,
+	})
+	if err != nil {
+		return "", err
+	}
+	req := &scm.Request{
+		Method: http.MethodPost,
+		Path:   fmt.Sprintf("api/v4/projects/%s/hooks", url.QueryEscape(r.name)),
+		Header: http.Header{"Content-Type": []string{"application/json"}},
+		Body:   bytes.NewReader(b),
+	}
+	return r.doCreateWebhook(req)
+}
+
+// doCreateWebhook sends the request to create a webhook, and returns the ID of
+// the created webhook.
+func (r *Repository) doCreateWebhook(req *scm.Request) (string, error) {
+	res, err := r.Client.Do(context.Background(), req)
+	if err != nil {
+		return "", err
+	}
+	defer res.Body.Close()
+	if res.Status > 299 {
+		return "", fmt.Errorf("failed to create webhook: unexpected status %d", res.Status)
+	}
+	var created struct {
+		ID int `json:"id"`
+	}
+	if err := json.NewDecoder(res.Body).Decode(&created); err != nil {
+		return "", fmt.Errorf("failed to decode the created webhook: %w", err)
+	}
+	return fmt.Sprint(created.ID), nil
+}
+
+// deliveryPollInterval is the time between checks for the delivery of a
+// webhook's ping, it's a var so that it can be reduced in tests.
+var deliveryPollInterval = 2 * time.Second
+
+// githubHookResponse is the response from the most recent delivery to a GitHub
+// webhook, the Code is nil until the first delivery.
+type githubHookResponse struct {
+	Code    *int   `json:"code"`
+	Status  string `json:"status"`
+	Message string `json:"message"`
+}
+
+// VerifyWebhook waits for the delivery of the ping that's sent when a webhook
+// is created, and returns an error if the delivery failed, e.g. because the
+// EventListener's route is misconfigured.
+//
+// Only GitHub records the responses to webhook deliveries.
+func (r *Repository) VerifyWebhook(id string, timeout time.Duration) error {
+	if r.Client.Driver != scm.DriverGithub {
+		return fmt.Errorf("verifying webhooks is only supported for GitHub repositories, not %s", r.Client.Driver)
+	}
+	deadline := time.Now().Add(timeout)
+	for {
+		last, err := r.lastHookResponse(id)
+		if err != nil {
+			return err
+		}
+		switch {
+		case last.Code != nil && *last.Code >= 200 && *last.Code < 300:
+			return nil
+		case last.Code != nil:
+			return fmt.Errorf("the last delivery to webhook %s failed with status %d: %s", id, *last.Code, last.Message)
+		case last.Status != "" && last.Status != "unused":
+			return fmt.Errorf("the last delivery to webhook %s failed: %s", id, last.Status)
+		}
+		if time.Now().After(deadline) {
+			return fmt.Errorf("timed out after %s waiting for a delivery to webhook %s", timeout, id)
+		}
+		time.Sleep(deliveryPollInterval)
+	}
+}
+
+func (r *Repository) lastHookResponse(id string) (*githubHookResponse, error) {
+	req := &scm.Request{
+		Method: http.MethodGet,
+		Path:   fmt.Sprintf("repos/%s/hooks/%s", r.name, id),
+	}
+	res, err := r.Client.Do(context.Background(), req)
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+	if res.Status > 299 {
+		return nil, fmt.Errorf("failed to get webhook %s: unexpected status %d", id, res.Status)
+	}
+	var hook struct {
+		LastResponse githubHookResponse `json:"last_response"`
+	}
+	if err := json.NewDecoder(res.Body).Decode(&hook); err != nil {
+		return nil, fmt.Errorf("failed to decode webhook %s: %w", id, err)
+	}
+	return &hook.LastResponse, nil
+}
+
+// HookDelivery is a delivery of an event to a webhook.
+type HookDelivery struct {
+	DeliveredAt time.Time `json:"delivered_at"`
+	Status      string    `json:"status"`
+	StatusCode  int       `json:"status_code"`
+}
+
+// Failed returns true if the delivery didn't get a successful response, the
+// StatusCode is 0 if no response was received.
+func (d *HookDelivery) Failed() bool {
+	return d.StatusCode < 200 || d.StatusCode > 299
+}
+
+// LastDelivery returns the most recent delivery to the webhook, or nil if
+// nothing has been delivered to it.
+//
+// Only GitHub records the deliveries to webhooks.
+func (r *Repository) LastDelivery(id string) (*HookDelivery, error) {
+	if r.Client.Driver != scm.DriverGithub {
+		return nil, fmt.Errorf("webhook deliveries are only recorded for GitHub repositories, not %s", r.Client.Driver)
+	}
+	req := &scm.Request{
+		Method: http.MethodGet,
+		Path:   fmt.Sprintf("repos/%s/hooks/%s/deliveries?per_page=1", r.name, id),
+	}
+	res, err := r.Client.Do(context.Background(), req)
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+	if res.Status > 299 {
+		return nil, fmt.Errorf("failed to get the deliveries to webhook %s: unexpected status %d", id, res.Status)
+	}
+	deliveries := []*HookDelivery{}
+	if err := json.NewDecoder(res.Body).Decode(&deliveries); err != nil {
+		return nil, fmt.Errorf("failed to decode the deliveries to webhook %s: %w", id, err)
+	}
+	if len(deliveries) == 0 {
+		return nil, nil
+	}
+	return deliveries[0], nil
+}
+
 // TODO: this likely won't work for GitLab projects because it assumes that the
 // path is always composed of two elements.
 func GetRepoName(u *url.URL) (string, error) {
@@ -105,3 +383,12 @@ func GetRepoName(u *url.URL) (string, error) {
 	}
 	return components[0] + "/" + components[1], nil
 }
+
+// AddGitSuffixIfNecessary will append .git to URL if necessary
+func AddGitSuffixIfNecessary(url string) string {
+	if url == "" || strings.HasSuffix(strings.ToLower(url), ".git") {
+		return url
+	}
+	log.Infof("Adding .git to %s", url)
+	return url + ".git"
+}
@@ -1,7 +1,10 @@
 package git
 
 import (
+	"encoding/json"
+	"net/http"
 	"testing"
+	"time"
 
 	"github.com/google/go-cmp/cmp"
 	"github.com/h2non/gock"
@@ -35,7 +38,7 @@ func TestWebhookWithFakeClient(t *testing.T) {
 	}
 
 	// create a webhook
-	id, err := repo.CreateWebhook(listenerURL, "secret")
+	id, err := repo.CreateWebhook(listenerURL, "secret", "")
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -142,7 +145,7 @@ func TestCreateWebHook(t *testing.T) {
 		t.Fatal(err)
 	}
 
-	created, err := repo.CreateWebhook("http://example.com/webhook", "mysecret")
+	created, err := repo.CreateWebhook("http://example.com/webhook", "mysecret", "")
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -151,3 +154,249 @@ func TestCreateWebHook(t *testing.T) {
 		t.Errorf("deleted mismatch got\n%s", diff)
 	}
 }
+
+func TestCreateWebHookWithContentType(t *testing.T) {
+	for _, contentType := range []string{"", FormContentType} {
+		t.Run(contentType, func(t *testing.T) {
+			defer gock.Off()
+
+			var got map[string]interface{}
+			gock.New("https://api.github.com").
+				Post("/repos/foo/bar/hooks").
+				SetMatcher(gock.NewMatcher()).
+				AddMatcher(func(req *http.Request, _ *gock.Request) (bool, error) {
+					return true, json.NewDecoder(req.Body).Decode(&got)
+				}).
+				Reply(201).
+				Type("application/json").
+				SetHeaders(mockHeaders).
+				File("testdata/hook.json")
+
+			repo, err := NewRepository("https://github.com/foo/bar.git", "token")
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			created, err := repo.CreateWebhook("http://example.com/webhook", "mysecret", contentType)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if diff := cmp.Diff("1", created); diff != "" {
+				t.Errorf("created mismatch got\n%s", diff)
+			}
+			wantContentType := contentType
+			if wantContentType == "" {
+				wantContentType = JSONContentType
+			}
+			want := map[string]interface{}{
+				"name":   "web",
+				"active": true,
+				"events": []interface{}{"pull_request", "push"},
+				"config": map[string]interface{}{
+					"url":          "http://example.com/webhook",
+					"content_type": wantContentType,
+					"secret":       "mysecret",
+					"insecure_ssl": "0",
+				},
+			}
+			if diff := cmp.Diff(want, got); diff != "" {
+				t.Errorf("created hook mismatch got\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestCreateWebHookWithContentTypeUnsupportedDriver(t *testing.T) {
+	repo, err := NewRepository("https://gitlab.com/foo/bar.git", "token")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = repo.CreateWebhook("http://example.com/webhook", "mysecret", FormContentType)
+	want := `the webhook content type "form" is only supported for GitHub repositories, not gitlab`
+	if err == nil || err.Error() != want {
+		t.Fatalf("got error %v, want %s", err, want)
+	}
+}
+
+func TestCreateWebHookWithBranchFilter(t *testing.T) {
+	defer gock.Off()
+
+	var got map[string]interface{}
+	gock.New("https://gitlab.com").
+		Post("/api/v4/projects/foo.*bar/hooks").
+		SetMatcher(gock.NewMatcher()).
+		AddMatcher(func(req *http.Request, _ *gock.Request) (bool, error) {
+			return true, json.NewDecoder(req.Body).Decode(&got)
+		}).
+		Reply(201).
+		Type("application/json").
+		BodyString(`{"id": 1, "url": "http://example.com/webhook"}`)
+
+	repo, err := NewRepository("https://gitlab.com/foo/bar.git", "token")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	created, err := repo.CreateWebhookWithBranchFilter("http://example.com/webhook", "mysecret", "release/*")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if diff := cmp.Diff("1", created); diff != "" {
+		t.Errorf("created mismatch got\n%s", diff)
+	}
+	want := map[string]interface{}{
+		"url":                       "http://example.com/webhook",
+		"token":                     "mysecret",
+		"push_events":               true,
+		"merge_requests_events":     true,
+		"push_events_branch_filter": "release/*",
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("created hook mismatch got\n%s", diff)
+	}
+}
+
+func TestCreateWebHookWithBranchFilterUnsupportedDriver(t *testing.T) {
+	repo, err := NewRepository("https://github.com/foo/bar.git", "token")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = repo.CreateWebhookWithBranchFilter("http://example.com/webhook", "mysecret", "release/*")
+	want := "webhook branch filters are only supported for GitLab repositories, not github"
+	if err == nil || err.Error() != want {
+		t.Fatalf("got error %v, want %s", err, want)
+	}
+}
+
+func TestVerifyWebhook(t *testing.T) {
+	defer stubDeliveryPollInterval(time.Millisecond)()
+	defer gock.Off()
+
+	gock.New("https://api.github.com").
+		Get("/repos/foo/bar/hooks/1").
+		Reply(200).
+		Type("application/json").
+		SetHeaders(mockHeaders).
+		BodyString(`{"id": 1, "last_response": {"code": null, "status": "unused", "message": null}}`)
+	gock.New("https://api.github.com").
+		Get("/repos/foo/bar/hooks/1").
+		Reply(200).
+		Type("application/json").
+		SetHeaders(mockHeaders).
+		BodyString(`{"id": 1, "last_response": {"code": 200, "status": "active", "message": "OK"}}`)
+
+	repo, err := NewRepository("https://github.com/foo/bar.git", "token")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := repo.VerifyWebhook("1", time.Minute); err != nil {
+		t.Fatal(err)
+	}
+	if !gock.IsDone() {
+		t.Fatal("webhook wasn't fetched until the delivery was recorded")
+	}
+}
+
+func TestVerifyWebhookWithFailedDelivery(t *testing.T) {
+	defer gock.Off()
+
+	gock.New("https://api.github.com").
+		Get("/repos/foo/bar/hooks/1").
+		Reply(200).
+		Type("application/json").
+		SetHeaders(mockHeaders).
+		BodyString(`{"id": 1, "last_response": {"code": 503, "status": "active", "message": "Application is not available"}}`)
+
+	repo, err := NewRepository("https://github.com/foo/bar.git", "token")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = repo.VerifyWebhook("1", time.Minute)
+	want := "the last delivery to webhook 1 failed with status 503: Application is not available"
+	if err == nil || err.Error() != want {
+		t.Fatalf("got error %v, want %s", err, want)
+	}
+}
+
+func TestLastDelivery(t *testing.T) {
+	defer gock.Off()
+
+	gock.New("https://api.github.com").
+		Get("/repos/foo/bar/hooks/1/deliveries").
+		MatchParam("per_page", "1").
+		Reply(200).
+		Type("application/json").
+		SetHeaders(mockHeaders).
+		BodyString(`[{"id": 12, "delivered_at": "2020-08-10T12:30:00Z", "status": "Service Unavailable", "status_code": 503, "event": "push"}]`)
+	gock.New("https://api.github.com").
+		Get("/repos/foo/bar/hooks/2/deliveries").
+		Reply(200).
+		Type("application/json").
+		SetHeaders(mockHeaders).
+		BodyString(`[]`)
+
+	repo, err := NewRepository("https://github.com/foo/bar.git", "token")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	last, err := repo.LastDelivery("1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := &HookDelivery{
+		DeliveredAt: time.Date(2020, time.August, 10, 12, 30, 0, 0, time.UTC),
+		Status:      "Service Unavailable",
+		StatusCode:  503,
+	}
+	if diff := cmp.Diff(want, last); diff != "" {
+		t.Fatalf("LastDelivery() failed:\n%s", diff)
+	}
+	if !last.Failed() {
+		t.Fatal("delivery with status 503 didn't fail")
+	}
+
+	last, err = repo.LastDelivery("2")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if last != nil {
+		t.Fatalf("LastDelivery() got %#v for a webhook with no deliveries", last)
+	}
+}
+
+func stubDeliveryPollInterval(d time.Duration) func() {
+	orig := deliveryPollInterval
+	deliveryPollInterval = d
+	return func() {
+		deliveryPollInterval = orig
+	}
+}
+
+func TestAddGitSuffix(t *testing.T) {
+	addSuffixTests := []struct {
+		name string
+		url  string
+		want string
+	}{
+		{"missing git suffix", "https://github.com/test/org", "https://github.com/test/org.git"},
+		{"suffix for empty string", "", ""},
+		{"suffix already present", "https://github.com/test/org.git", "https://github.com/test/org.git"},
+		{"suffix with a different case", "https://github.com/test/org.GIT", "https://github.com/test/org.GIT"},
+	}
+
+	for _, tt := range addSuffixTests {
+		t.Run(tt.name, func(rt *testing.T) {
+			got := AddGitSuffixIfNecessary(tt.url)
+			if tt.want != got {
+				rt.Fatalf("URL mismatch: got %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
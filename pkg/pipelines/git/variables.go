@@ -0,0 +1,89 @@
+package git
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"regexp"
+
+	"github.com/jenkins-x/go-scm/scm"
+)
+
+var (
+	// GitLab variable keys are limited to 255 letters, digits and underscores.
+	ciVariableKeyRE = regexp.MustCompile(`^[a-zA-Z0-9_]{1,255}$`)
+
+	// GitLab can only mask values of at least 8 characters from the Base64
+	// alphabet, and @, :, . and ~.
+	maskableValueRE = regexp.MustCompile(`^[a-zA-Z0-9+/=@:.~_-]{8,}$`)
+)
+
+// ValidateCIVariable returns an error if the key isn't a valid GitLab CI/CD
+// variable key, or the value can't be masked.
+func ValidateCIVariable(key, value string) error {
+	if !ciVariableKeyRE.MatchString(key) {
+		return fmt.Errorf("invalid CI/CD variable key %q, keys can only contain letters, digits and '_', and be no longer than 255 characters", key)
+	}
+	if !maskableValueRE.MatchString(value) {
+		return fmt.Errorf("invalid value for CI/CD variable %s, masked values must be at least 8 characters from the Base64 alphabet, '@', ':', '.' or '~'", key)
+	}
+	return nil
+}
+
+// gitlabVariableInput is the body for creating or updating a GitLab project
+// CI/CD variable, go-scm has no support for variables.
+type gitlabVariableInput struct {
+	Key       string `json:"key"`
+	Value     string `json:"value"`
+	Protected bool   `json:"protected"`
+	Masked    bool   `json:"masked"`
+}
+
+// SetCIVariable creates a CI/CD variable in a GitLab repository, or updates
+// it if it exists.
+//
+// The variable is protected and masked, so it's only passed to pipelines for
+// protected branches and tags, and is hidden in job logs.
+func (r *Repository) SetCIVariable(key, value string) error {
+	if r.Client.Driver != scm.DriverGitlab {
+		return fmt.Errorf("CI/CD variables are only supported for GitLab repositories, not %s", r.Client.Driver)
+	}
+	in := gitlabVariableInput{Key: key, Value: value, Protected: true, Masked: true}
+	path := fmt.Sprintf("api/v4/projects/%s/variables", url.QueryEscape(r.name))
+	status, err := r.sendVariable(http.MethodPut, path+"/"+url.PathEscape(key), in)
+	if err != nil {
+		return err
+	}
+	if status == http.StatusNotFound {
+		status, err = r.sendVariable(http.MethodPost, path, in)
+		if err != nil {
+			return err
+		}
+	}
+	if status > 299 {
+		return fmt.Errorf("failed to set CI/CD variable %s: unexpected status %d", key, status)
+	}
+	return nil
+}
+
+func (r *Repository) sendVariable(method, path string, in gitlabVariableInput) (int, error) {
+	b, err := json.Marshal(in)
+	if err != nil {
+		return 0, err
+	}
+	req := &scm.Request{
+		Method: method,
+		Path:   path,
+		Header: http.Header{"Content-Type": []string{"application/json"}},
+		Body:   bytes.NewReader(b),
+	}
+	res, err := r.Client.Do(context.Background(), req)
+	if err != nil {
+		return 0, err
+	}
+	defer res.Body.Close()
+	return res.Status, nil
+}
@@ -0,0 +1,109 @@
+package git
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/h2non/gock"
+)
+
+func TestValidateCIVariable(t *testing.T) {
+	tests := []struct {
+		key     string
+		value   string
+		wantErr string
+	}{
+		{"CLUSTER_ENDPOINT", "https://api.example.com:6443", ""},
+		{"cluster_token_2", "c2VjcmV0LXRva2Vu", ""},
+		{"CLUSTER-ENDPOINT", "https://api.example.com:6443", `invalid CI/CD variable key "CLUSTER-ENDPOINT", keys can only contain letters, digits and '_', and be no longer than 255 characters`},
+		{"", "https://api.example.com:6443", `invalid CI/CD variable key "", keys can only contain letters, digits and '_', and be no longer than 255 characters`},
+		{"TOKEN", "short", "invalid value for CI/CD variable TOKEN, masked values must be at least 8 characters from the Base64 alphabet, '@', ':', '.' or '~'"},
+		{"TOKEN", "has a space", "invalid value for CI/CD variable TOKEN, masked values must be at least 8 characters from the Base64 alphabet, '@', ':', '.' or '~'"},
+	}
+
+	for _, tt := range tests {
+		err := ValidateCIVariable(tt.key, tt.value)
+		if tt.wantErr == "" && err != nil {
+			t.Errorf("ValidateCIVariable(%q, %q) failed: %s", tt.key, tt.value, err)
+		}
+		if tt.wantErr != "" && (err == nil || err.Error() != tt.wantErr) {
+			t.Errorf("ValidateCIVariable(%q, %q) got error %v, want %s", tt.key, tt.value, err, tt.wantErr)
+		}
+	}
+}
+
+func TestSetCIVariableUpdatesExistingVariable(t *testing.T) {
+	defer gock.Off()
+
+	var got map[string]interface{}
+	gock.New("https://gitlab.com").
+		Put("/api/v4/projects/foo.*bar/variables/CLUSTER_ENDPOINT").
+		SetMatcher(gock.NewMatcher()).
+		AddMatcher(func(req *http.Request, _ *gock.Request) (bool, error) {
+			return true, json.NewDecoder(req.Body).Decode(&got)
+		}).
+		Reply(200).
+		Type("application/json").
+		BodyString(`{"key": "CLUSTER_ENDPOINT"}`)
+
+	repo, err := NewRepository("https://gitlab.com/foo/bar.git", "token")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := repo.SetCIVariable("CLUSTER_ENDPOINT", "https://api.example.com:6443"); err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]interface{}{
+		"key":       "CLUSTER_ENDPOINT",
+		"value":     "https://api.example.com:6443",
+		"protected": true,
+		"masked":    true,
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("updated variable mismatch got\n%s", diff)
+	}
+	if !gock.IsDone() {
+		t.Fatal("the variable was not updated")
+	}
+}
+
+func TestSetCIVariableCreatesMissingVariable(t *testing.T) {
+	defer gock.Off()
+
+	gock.New("https://gitlab.com").
+		Put("/api/v4/projects/foo.*bar/variables/CLUSTER_ENDPOINT").
+		Reply(404)
+	gock.New("https://gitlab.com").
+		Post("/api/v4/projects/foo.*bar/variables").
+		Reply(201).
+		Type("application/json").
+		BodyString(`{"key": "CLUSTER_ENDPOINT"}`)
+
+	repo, err := NewRepository("https://gitlab.com/foo/bar.git", "token")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := repo.SetCIVariable("CLUSTER_ENDPOINT", "https://api.example.com:6443"); err != nil {
+		t.Fatal(err)
+	}
+	if !gock.IsDone() {
+		t.Fatal("the variable was not created")
+	}
+}
+
+func TestSetCIVariableUnsupportedDriver(t *testing.T) {
+	repo, err := NewRepository("https://github.com/foo/bar.git", "token")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = repo.SetCIVariable("CLUSTER_ENDPOINT", "https://api.example.com:6443")
+	want := "CI/CD variables are only supported for GitLab repositories, not github"
+	if err == nil || err.Error() != want {
+		t.Fatalf("got error %v, want %s", err, want)
+	}
+}
@@ -0,0 +1,64 @@
+package pipelines
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/afero"
+)
+
+const gitignoreFile = ".gitignore"
+
+// gitignoreEntries are the patterns for files that shouldn't be committed to a
+// GitOps repository.
+var gitignoreEntries = []string{
+	// Decrypted secrets
+	"*.dec.yaml",
+	"*.dec.yml",
+	// Editor and OS files
+	".DS_Store",
+	"*.swp",
+	"*~",
+	".idea/",
+	".vscode/",
+	// Progress of an interrupted bootstrap
+	stateFile,
+}
+
+// writeGitIgnore writes a .gitignore file to the path, if one already exists,
+// any missing entries are appended and the existing entries are preserved.
+func writeGitIgnore(fs afero.Fs, path string) error {
+	filename := filepath.Join(path, gitignoreFile)
+	existing, err := afero.ReadFile(fs, filename)
+	if err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to read %s: %w", filename, err)
+	}
+	body := mergeGitIgnore(string(existing), gitignoreEntries)
+	if body == string(existing) {
+		return nil
+	}
+	if err := fs.MkdirAll(path, 0755); err != nil {
+		return fmt.Errorf("failed to create the directory for %s: %w", filename, err)
+	}
+	return afero.WriteFile(fs, filename, []byte(body), 0644)
+}
+
+func mergeGitIgnore(existing string, entries []string) string {
+	seen := map[string]bool{}
+	for _, line := range strings.Split(existing, "\n") {
+		seen[strings.TrimSpace(line)] = true
+	}
+	var b strings.Builder
+	b.WriteString(existing)
+	if existing != "" && !strings.HasSuffix(existing, "\n") {
+		b.WriteString("\n")
+	}
+	for _, entry := range entries {
+		if !seen[entry] {
+			b.WriteString(entry + "\n")
+		}
+	}
+	return b.String()
+}
@@ -0,0 +1,65 @@
+package pipelines
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/spf13/afero"
+
+	"github.com/rhd-gitops-example/gitops-cli/pkg/pipelines/ioutils"
+)
+
+func TestWriteGitIgnore(t *testing.T) {
+	fakeFs := ioutils.NewMemoryFilesystem()
+	gitopsPath := afero.GetTempDir(fakeFs, "test")
+
+	assertNoError(t, writeGitIgnore(fakeFs, gitopsPath))
+
+	got, err := afero.ReadFile(fakeFs, filepath.Join(gitopsPath, gitignoreFile))
+	assertNoError(t, err)
+	want := strings.Join(gitignoreEntries, "\n") + "\n"
+	if diff := cmp.Diff(want, string(got)); diff != "" {
+		t.Fatalf("writeGitIgnore() failed:\n%s", diff)
+	}
+}
+
+func TestWriteGitIgnoreMergesExistingEntries(t *testing.T) {
+	fakeFs := ioutils.NewMemoryFilesystem()
+	gitopsPath := afero.GetTempDir(fakeFs, "test")
+	filename := filepath.Join(gitopsPath, gitignoreFile)
+	assertNoError(t, afero.WriteFile(fakeFs, filename, []byte("bin/\n.DS_Store"), 0644))
+
+	assertNoError(t, writeGitIgnore(fakeFs, gitopsPath))
+	// A re-run shouldn't duplicate any entries.
+	assertNoError(t, writeGitIgnore(fakeFs, gitopsPath))
+
+	got, err := afero.ReadFile(fakeFs, filename)
+	assertNoError(t, err)
+	want := "bin/\n.DS_Store\n*.dec.yaml\n*.dec.yml\n*.swp\n*~\n.idea/\n.vscode/\n.gitops-cli-state\n"
+	if diff := cmp.Diff(want, string(got)); diff != "" {
+		t.Fatalf("writeGitIgnore() failed:\n%s", diff)
+	}
+}
+
+func TestBootstrapGitIgnore(t *testing.T) {
+	defer stubDefaultPublicKeyFunc(t)()
+	for _, noGitIgnore := range []bool{false, true} {
+		fakeFs := ioutils.NewMemoryFilesystem()
+		params := &BootstrapOptions{
+			Prefix:         "tst-",
+			GitOpsRepoURL:  testGitOpsRepo,
+			ImageRepo:      "image/repo",
+			ServiceRepoURL: testSvcRepo,
+			OutputPath:     "/tmp/gitops",
+			NoGitIgnore:    noGitIgnore,
+		}
+		assertNoError(t, Bootstrap(params, fakeFs))
+
+		exists, _ := afero.Exists(fakeFs, filepath.Join("/tmp/gitops", gitignoreFile))
+		if exists == noGitIgnore {
+			t.Errorf("Bootstrap() with NoGitIgnore %v wrote .gitignore: %v", noGitIgnore, exists)
+		}
+	}
+}
@@ -0,0 +1,174 @@
+package helm
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/spf13/afero"
+	"sigs.k8s.io/yaml"
+
+	"github.com/rhd-gitops-example/gitops-cli/pkg/pipelines/config"
+	res "github.com/rhd-gitops-example/gitops-cli/pkg/pipelines/resources"
+)
+
+const (
+	// ChartAPIVersion is the version of Chart.yaml that's generated, this
+	// requires Helm 3.
+	ChartAPIVersion = "v2"
+	chartVersion    = "0.1.0"
+	chartFile       = "Chart.yaml"
+	templatesDir    = "templates"
+	kustomization   = "kustomization.yaml"
+)
+
+// chartNameRegexp matches the chart names that Helm recommends, lower case
+// letters and numbers, separated by dashes.
+var chartNameRegexp = regexp.MustCompile(`^[a-z0-9]+(-[a-z0-9]+)*$`)
+
+// documentSeparator splits a YAML file into its documents.
+var documentSeparator = regexp.MustCompile(`(?m)^---\s*$`)
+
+// Chart is the Chart.yaml of a Helm chart.
+type Chart struct {
+	APIVersion  string `json:"apiVersion"`
+	Name        string `json:"name"`
+	Description string `json:"description,omitempty"`
+	Type        string `json:"type"`
+	Version     string `json:"version"`
+}
+
+// ValidateChartName returns an error if the name isn't a valid Helm chart
+// name.
+func ValidateChartName(name string) error {
+	if !chartNameRegexp.MatchString(name) {
+		return fmt.Errorf("invalid Helm chart name %q, chart names must be lower case letters and numbers, separated by dashes", name)
+	}
+	return nil
+}
+
+// ChartName returns the name of the chart for an application in an
+// environment, this is the name of the Argo CD Application that deploys it.
+func ChartName(env *config.Environment, app *config.Application) string {
+	return env.Name + "-" + app.Name
+}
+
+// PathForChart returns the repo-rooted path of the chart for an application
+// in an environment.
+func PathForChart(env *config.Environment, app *config.Application) string {
+	return filepath.Join(config.PathForApplication(env, app), "chart")
+}
+
+// Build generates a minimal Helm chart for each of the deployed applications in
+// the manifest, that are not deployed from a config repository.
+//
+// The templates of the chart are the resources of the application's
+// environment from the built resources, and the configuration of the
+// application's services, which are read from the fs relative to the root.
+func Build(fs afero.Fs, root string, m *config.Manifest, built res.Resources) (res.Resources, error) {
+	b := &chartBuilder{fs: fs, root: root, built: built, files: res.Resources{}}
+	return b.files, m.Walk(b)
+}
+
+type chartBuilder struct {
+	fs    afero.Fs
+	root  string
+	built res.Resources
+	files res.Resources
+}
+
+func (b *chartBuilder) Application(env *config.Environment, app *config.Application) error {
+	if !env.Deploys() || !app.Deployed() || app.ConfigRepo != nil {
+		return nil
+	}
+	name := ChartName(env, app)
+	if err := ValidateChartName(name); err != nil {
+		return err
+	}
+	chartPath := PathForChart(env, app)
+	b.files[filepath.Join(chartPath, chartFile)] = &Chart{
+		APIVersion:  ChartAPIVersion,
+		Name:        name,
+		Description: fmt.Sprintf("The %s application in the %s environment", app.Name, env.Name),
+		Type:        "application",
+		Version:     chartVersion,
+	}
+	templatesPath := filepath.Join(chartPath, templatesDir)
+	envBasePath := filepath.Join(config.PathForEnvironment(env), "env", "base")
+	for k, v := range b.built {
+		if filepath.Dir(k) == envBasePath && filepath.Base(k) != kustomization {
+			b.files[filepath.Join(templatesPath, filepath.Base(k))] = v
+		}
+	}
+	for _, svc := range app.Services {
+		if !svc.Deploys() {
+			continue
+		}
+		templates, err := b.serviceTemplates(config.PathForService(app, env, svc.Name), svc.Name)
+		if err != nil {
+			return err
+		}
+		for k, v := range templates {
+			b.files[filepath.Join(templatesPath, k)] = v
+		}
+	}
+	return nil
+}
+
+// serviceTemplates reads the documents in the configuration of the service,
+// each document is a template, named for the service and the file.
+func (b *chartBuilder) serviceTemplates(svcPath, svcName string) (map[string]interface{}, error) {
+	configPath := filepath.Join(b.root, svcPath, "base", "config")
+	filenames := []string{}
+	err := afero.Walk(b.fs, configPath, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			if os.IsNotExist(err) {
+				return nil
+			}
+			return err
+		}
+		ext := filepath.Ext(path)
+		if info.IsDir() || info.Name() == kustomization || (ext != ".yaml" && ext != ".yml") {
+			return nil
+		}
+		filenames = append(filenames, path)
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list the configuration of service %s: %w", svcName, err)
+	}
+	sort.Strings(filenames)
+
+	templates := map[string]interface{}{}
+	for _, filename := range filenames {
+		data, err := afero.ReadFile(b.fs, filename)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %s: %w", filename, err)
+		}
+		base := strings.TrimSuffix(filepath.Base(filename), filepath.Ext(filename))
+		docs := documentSeparator.Split(string(data), -1)
+		n := 0
+		for _, doc := range docs {
+			if strings.TrimSpace(doc) == "" {
+				continue
+			}
+			item := map[string]interface{}{}
+			if err := yaml.Unmarshal([]byte(doc), &item); err != nil {
+				return nil, fmt.Errorf("failed to parse %s: %w", filename, err)
+			}
+			if len(item) == 0 {
+				continue
+			}
+			name := fmt.Sprintf("%s-%s.yaml", svcName, base)
+			if n > 0 {
+				name = fmt.Sprintf("%s-%s-%d.yaml", svcName, base, n)
+			}
+			templates[name] = item
+			n++
+		}
+	}
+	return templates, nil
+}
@@ -0,0 +1,103 @@
+package helm
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/spf13/afero"
+
+	"github.com/rhd-gitops-example/gitops-cli/pkg/pipelines/config"
+	"github.com/rhd-gitops-example/gitops-cli/pkg/pipelines/ioutils"
+	res "github.com/rhd-gitops-example/gitops-cli/pkg/pipelines/resources"
+)
+
+const testDeployment = `apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: taxi
+---
+apiVersion: v1
+kind: Service
+metadata:
+  name: taxi
+`
+
+func TestBuild(t *testing.T) {
+	fs := ioutils.NewMemoryFilesystem()
+	configPath := "/gitops/environments/dev/apps/taxi/services/taxi-svc/base/config"
+	assertNoError(t, afero.WriteFile(fs, configPath+"/100-deployment.yaml", []byte(testDeployment), 0644))
+	assertNoError(t, afero.WriteFile(fs, configPath+"/kustomization.yaml", []byte("resources: []\n"), 0644))
+	m := &config.Manifest{
+		Environments: []*config.Environment{
+			{
+				Name: "dev",
+				Apps: []*config.Application{
+					{Name: "taxi", Services: []*config.Service{{Name: "taxi-svc"}}},
+					{Name: "other", ConfigRepo: &config.Repository{URL: "https://github.com/org/config.git", Path: "deploy"}},
+				},
+			},
+		},
+	}
+	ns := map[string]interface{}{"apiVersion": "v1", "kind": "Namespace"}
+	built := res.Resources{
+		"environments/dev/env/base/dev-environment.yaml": ns,
+		"environments/dev/env/base/kustomization.yaml":   &res.Kustomization{},
+	}
+
+	files, err := Build(fs, "/gitops", m, built)
+	assertNoError(t, err)
+
+	want := res.Resources{
+		"environments/dev/apps/taxi/chart/Chart.yaml": &Chart{
+			APIVersion:  ChartAPIVersion,
+			Name:        "dev-taxi",
+			Description: "The taxi application in the dev environment",
+			Type:        "application",
+			Version:     "0.1.0",
+		},
+		"environments/dev/apps/taxi/chart/templates/dev-environment.yaml": ns,
+		"environments/dev/apps/taxi/chart/templates/taxi-svc-100-deployment.yaml": map[string]interface{}{
+			"apiVersion": "apps/v1",
+			"kind":       "Deployment",
+			"metadata":   map[string]interface{}{"name": "taxi"},
+		},
+		"environments/dev/apps/taxi/chart/templates/taxi-svc-100-deployment-1.yaml": map[string]interface{}{
+			"apiVersion": "v1",
+			"kind":       "Service",
+			"metadata":   map[string]interface{}{"name": "taxi"},
+		},
+	}
+	if diff := cmp.Diff(want, files); diff != "" {
+		t.Fatalf("chart files didn't match:\n%s", diff)
+	}
+}
+
+func TestValidateChartName(t *testing.T) {
+	nameTests := []struct {
+		name    string
+		wantErr bool
+	}{
+		{"dev-taxi", false},
+		{"dev2-taxi", false},
+		{"Dev-taxi", true},
+		{"dev_taxi", true},
+		{"dev.taxi", true},
+		{"-dev", true},
+		{"dev-", true},
+		{"", true},
+	}
+
+	for _, tt := range nameTests {
+		err := ValidateChartName(tt.name)
+		if tt.wantErr != (err != nil) {
+			t.Errorf("ValidateChartName(%q) got error %v", tt.name, err)
+		}
+	}
+}
+
+func assertNoError(t *testing.T, err error) {
+	t.Helper()
+	if err != nil {
+		t.Fatal(err)
+	}
+}
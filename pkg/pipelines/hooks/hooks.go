@@ -0,0 +1,56 @@
+package hooks
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/spf13/afero"
+)
+
+const (
+	// PreCommitHookPath is the path of the pre-commit hook relative to the
+	// root of the repository.
+	PreCommitHookPath = ".git/hooks/pre-commit"
+
+	managedMarker = "# Managed by gitops"
+	preCommitHook = `#!/bin/sh
+` + managedMarker + `, reinstall with "gitops hooks install --force".
+exec gitops lint --pipelines-folder "$(git rev-parse --show-toplevel)"
+`
+)
+
+// InstallPreCommit writes a pre-commit hook into the git repository at
+// repoPath, which lints the manifest before each commit.
+//
+// An existing hook is only replaced if it was installed by this tool, or if
+// force is true.
+func InstallPreCommit(fs afero.Fs, repoPath string, force bool) (string, error) {
+	gitDir := filepath.Join(repoPath, ".git")
+	info, err := fs.Stat(gitDir)
+	if err != nil || !info.IsDir() {
+		return "", fmt.Errorf("%q is not the root of a git repository", repoPath)
+	}
+
+	hookPath := filepath.Join(repoPath, PreCommitHookPath)
+	existing, err := afero.ReadFile(fs, hookPath)
+	if err != nil && !os.IsNotExist(err) {
+		return "", fmt.Errorf("failed to read existing hook %q: %w", hookPath, err)
+	}
+	if err == nil && !force && !bytes.Contains(existing, []byte(managedMarker)) {
+		return "", fmt.Errorf("a pre-commit hook not managed by gitops already exists at %q, use --force to overwrite it", hookPath)
+	}
+
+	if err := fs.MkdirAll(filepath.Dir(hookPath), 0755); err != nil {
+		return "", fmt.Errorf("failed to create hooks directory: %w", err)
+	}
+	if err := afero.WriteFile(fs, hookPath, []byte(preCommitHook), 0755); err != nil {
+		return "", fmt.Errorf("failed to write hook %q: %w", hookPath, err)
+	}
+	// WriteFile doesn't change the permissions of an existing file.
+	if err := fs.Chmod(hookPath, 0755); err != nil {
+		return "", fmt.Errorf("failed to make hook %q executable: %w", hookPath, err)
+	}
+	return hookPath, nil
+}
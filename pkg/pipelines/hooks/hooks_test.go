@@ -0,0 +1,100 @@
+package hooks
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/spf13/afero"
+
+	"github.com/rhd-gitops-example/gitops-cli/pkg/pipelines/helper"
+	"github.com/rhd-gitops-example/gitops-cli/pkg/pipelines/ioutils"
+)
+
+func TestInstallPreCommit(t *testing.T) {
+	fs := ioutils.NewMemoryFilesystem()
+	assertNoError(t, fs.MkdirAll("/repo/.git", 0755))
+
+	hookPath, err := InstallPreCommit(fs, "/repo", false)
+	assertNoError(t, err)
+
+	if hookPath != filepath.Join("/repo", PreCommitHookPath) {
+		t.Fatalf("got hook path %q", hookPath)
+	}
+	assertExecutableHook(t, fs, hookPath)
+}
+
+func TestInstallPreCommitOnOsFilesystem(t *testing.T) {
+	dir, cleanup := makeTempDir(t)
+	defer cleanup()
+	fs := ioutils.NewFilesystem()
+	assertNoError(t, fs.MkdirAll(filepath.Join(dir, ".git"), 0755))
+
+	hookPath, err := InstallPreCommit(fs, dir, false)
+	assertNoError(t, err)
+
+	assertExecutableHook(t, fs, hookPath)
+}
+
+func TestInstallPreCommitReplacesManagedHook(t *testing.T) {
+	fs := ioutils.NewMemoryFilesystem()
+	hookPath := filepath.Join("/repo", PreCommitHookPath)
+	assertNoError(t, afero.WriteFile(fs, hookPath, []byte("#!/bin/sh\n"+managedMarker+"\nexit 1\n"), 0644))
+
+	_, err := InstallPreCommit(fs, "/repo", false)
+	assertNoError(t, err)
+
+	assertExecutableHook(t, fs, hookPath)
+}
+
+func TestInstallPreCommitRefusesUnmanagedHook(t *testing.T) {
+	fs := ioutils.NewMemoryFilesystem()
+	hookPath := filepath.Join("/repo", PreCommitHookPath)
+	assertNoError(t, afero.WriteFile(fs, hookPath, []byte("#!/bin/sh\nmake test\n"), 0755))
+
+	_, err := InstallPreCommit(fs, "/repo", false)
+	helper.AssertErrorMatch(t, "not managed by gitops already exists", err)
+
+	_, err = InstallPreCommit(fs, "/repo", true)
+	assertNoError(t, err)
+	assertExecutableHook(t, fs, hookPath)
+}
+
+func TestInstallPreCommitWithNoGitRepository(t *testing.T) {
+	fs := ioutils.NewMemoryFilesystem()
+	assertNoError(t, fs.MkdirAll("/repo", 0755))
+
+	_, err := InstallPreCommit(fs, "/repo", false)
+	helper.AssertErrorMatch(t, `"/repo" is not the root of a git repository`, err)
+}
+
+func assertExecutableHook(t *testing.T, fs afero.Fs, hookPath string) {
+	t.Helper()
+	b, err := afero.ReadFile(fs, hookPath)
+	assertNoError(t, err)
+	if diff := cmp.Diff(preCommitHook, string(b)); diff != "" {
+		t.Fatalf("hook content failed:\n%s", diff)
+	}
+	info, err := fs.Stat(hookPath)
+	assertNoError(t, err)
+	if info.Mode().Perm()&0111 == 0 {
+		t.Fatalf("hook %q is not executable: %v", hookPath, info.Mode())
+	}
+}
+
+func makeTempDir(t *testing.T) (string, func()) {
+	t.Helper()
+	dir, err := afero.TempDir(ioutils.NewFilesystem(), "", "hooks")
+	assertNoError(t, err)
+	return dir, func() {
+		err := ioutils.NewFilesystem().RemoveAll(dir)
+		assertNoError(t, err)
+	}
+}
+
+func assertNoError(t *testing.T, err error) {
+	t.Helper()
+	if err != nil {
+		t.Fatal(err)
+	}
+}
@@ -3,6 +3,7 @@ package imagerepo
 import (
 	"fmt"
 	"path/filepath"
+	"regexp"
 	"strings"
 
 	"github.com/rhd-gitops-example/gitops-cli/pkg/pipelines/meta"
@@ -81,3 +82,43 @@ func createInternalRegistryRoleBinding(cfg *config.PipelinesConfig, ns string, s
 	roleBindingPath := filepath.Join(config.PathForPipelines(cfg), "base", roleBindingFilname)
 	return roleBindingFilname, res.Resources{roleBindingPath: roles.CreateRoleBinding(meta.NamespacedName(ns, roleBindingName), sa, "ClusterRole", "edit")}
 }
+
+var registryRE = regexp.MustCompile(`^[a-zA-Z0-9.-]+(:[0-9]+)?(/[a-z0-9]+([._-][a-z0-9]+)*)*$`)
+
+// ValidateRegistry validates that the registry is a plausible image reference
+// prefix, a registry host with an optional path e.g.
+// registry.example.com:5000/mirror.
+func ValidateRegistry(registry string) error {
+	if !registryRE.MatchString(registry) {
+		return registryValidationError(registry)
+	}
+	host := strings.Split(registry, "/")[0]
+	if !isRegistryHost(host) {
+		return registryValidationError(registry)
+	}
+	return nil
+}
+
+// WithRegistry replaces the registry of the image with the provided registry.
+//
+// Images without an explicit registry host are assumed to be from Docker Hub,
+// and are prefixed with the registry.  If the registry is empty, the image is
+// returned unchanged.
+func WithRegistry(image, registry string) string {
+	if registry == "" {
+		return image
+	}
+	components := strings.SplitN(image, "/", 2)
+	if len(components) == 2 && isRegistryHost(components[0]) {
+		image = components[1]
+	}
+	return strings.TrimSuffix(registry, "/") + "/" + image
+}
+
+func isRegistryHost(s string) bool {
+	return strings.ContainsAny(s, ".:") || s == "localhost"
+}
+
+func registryValidationError(registry string) error {
+	return fmt.Errorf("failed to parse image registry:%s, expected a registry host with an optional path e.g. registry.example.com/mirror", registry)
+}
@@ -184,3 +184,51 @@ func TestValidateImageRepo(t *testing.T) {
 		})
 	}
 }
+
+func TestValidateRegistry(t *testing.T) {
+	errorMsg := "failed to parse image registry:%s, expected a registry host with an optional path e.g. registry.example.com/mirror"
+	tests := []struct {
+		registry string
+		wantErr  string
+	}{
+		{"registry.example.com", ""},
+		{"registry.example.com:5000", ""},
+		{"localhost/mirror", ""},
+		{"registry.example.com/team/mirror", ""},
+		{"mirror", fmt.Sprintf(errorMsg, "mirror")},
+		{"https://registry.example.com", fmt.Sprintf(errorMsg, "https://registry.example.com")},
+		{"registry.example.com/mirror:latest", fmt.Sprintf(errorMsg, "registry.example.com/mirror:latest")},
+		{"registry.example.com/ mirror", fmt.Sprintf(errorMsg, "registry.example.com/ mirror")},
+	}
+	for _, tt := range tests {
+		t.Run(tt.registry, func(t *testing.T) {
+			errorString := ""
+			if err := ValidateRegistry(tt.registry); err != nil {
+				errorString = err.Error()
+			}
+			if diff := cmp.Diff(tt.wantErr, errorString); diff != "" {
+				t.Errorf("ValidateRegistry() failed:\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestWithRegistry(t *testing.T) {
+	tests := []struct {
+		image    string
+		registry string
+		want     string
+	}{
+		{"quay.io/redhat-developer/k8s-kubectl", "", "quay.io/redhat-developer/k8s-kubectl"},
+		{"quay.io/redhat-developer/k8s-kubectl", "registry.example.com", "registry.example.com/redhat-developer/k8s-kubectl"},
+		{"nginxinc/nginx-unprivileged:latest", "registry.example.com/mirror/", "registry.example.com/mirror/nginxinc/nginx-unprivileged:latest"},
+		{"localhost:5000/team/app", "registry.example.com", "registry.example.com/team/app"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.image, func(t *testing.T) {
+			if diff := cmp.Diff(tt.want, WithRegistry(tt.image, tt.registry)); diff != "" {
+				t.Errorf("WithRegistry() failed:\n%s", diff)
+			}
+		})
+	}
+}
@@ -3,6 +3,7 @@ package ioutils
 import (
 	"fmt"
 	"path/filepath"
+	"strings"
 
 	"github.com/spf13/afero"
 )
@@ -28,3 +29,84 @@ func IsExisting(fs afero.Fs, path string) (bool, error) {
 	}
 	return true, fmt.Errorf("%q: File already exists at %s", filepath.Base(path), path)
 }
+
+// ValidateOutputPath checks that the path, if it exists, is a directory.
+//
+// If root is not empty, the path must also be within the root directory, this
+// prevents accidentally writing files elsewhere.
+func ValidateOutputPath(fs afero.Fs, path, root string) error {
+	fileInfo, err := fs.Stat(path)
+	if err == nil && !fileInfo.IsDir() {
+		return fmt.Errorf("output path %q is a file, expected a directory", path)
+	}
+	if root == "" {
+		return nil
+	}
+	absRoot, err := filepath.Abs(root)
+	if err != nil {
+		return fmt.Errorf("failed to resolve output root %q: %w", root, err)
+	}
+	absPath, err := filepath.Abs(path)
+	if err != nil {
+		return fmt.Errorf("failed to resolve output path %q: %w", path, err)
+	}
+	rel, err := filepath.Rel(absRoot, absPath)
+	if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return fmt.Errorf("output path %q is outside of the output root %q", path, root)
+	}
+	return nil
+}
+
+// RepositorySubpath returns the path of the directory within the enclosing Git
+// checkout, this is empty if the path is the root of the checkout, or isn't
+// within one.
+//
+// The path doesn't need to exist, the checkout is found by looking for a .git
+// directory, or file for worktrees, in its closest existing ancestors.
+func RepositorySubpath(fs afero.Fs, path string) (string, error) {
+	absPath, err := filepath.Abs(path)
+	if err != nil {
+		return "", fmt.Errorf("failed to resolve path %q: %w", path, err)
+	}
+	for dir := absPath; ; dir = filepath.Dir(dir) {
+		if _, err := fs.Stat(filepath.Join(dir, ".git")); err == nil {
+			rel, err := filepath.Rel(dir, absPath)
+			if err != nil || rel == "." {
+				return "", err
+			}
+			return filepath.ToSlash(rel), nil
+		}
+		if dir == filepath.Dir(dir) {
+			return "", nil
+		}
+	}
+}
+
+// FindRepositoryDir walks up from the dir to the root of the enclosing Git
+// checkout, and returns the closest directory that has the named file, or the
+// root of the checkout if none of the directories has it.
+//
+// An error is returned if the dir isn't within a Git checkout.
+func FindRepositoryDir(fs afero.Fs, dir, name string) (string, error) {
+	absDir, err := filepath.Abs(dir)
+	if err != nil {
+		return "", fmt.Errorf("failed to resolve path %q: %w", dir, err)
+	}
+	found := ""
+	for d := absDir; ; d = filepath.Dir(d) {
+		if found == "" {
+			if _, err := fs.Stat(filepath.Join(d, name)); err == nil {
+				found = d
+			}
+		}
+		if _, err := fs.Stat(filepath.Join(d, ".git")); err == nil {
+			if found == "" {
+				return d, nil
+			}
+			return found, nil
+		}
+		if d == filepath.Dir(d) {
+			return "", fmt.Errorf("%s is not within a Git repository", absDir)
+		}
+	}
+}
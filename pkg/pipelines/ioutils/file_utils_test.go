@@ -0,0 +1,77 @@
+package ioutils
+
+import (
+	"testing"
+
+	"github.com/spf13/afero"
+
+	"github.com/rhd-gitops-example/gitops-cli/pkg/pipelines/helper"
+)
+
+func TestValidateOutputPath(t *testing.T) {
+	fs := NewMemoryFilesystem()
+	if err := afero.WriteFile(fs, "/tmp/gitops/pipelines.yaml", []byte("environments:"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	pathTests := []struct {
+		name    string
+		path    string
+		root    string
+		wantErr string
+	}{
+		{"missing path", "/tmp/new", "", ""},
+		{"existing directory", "/tmp/gitops", "", ""},
+		{"file as output path", "/tmp/gitops/pipelines.yaml", "", `output path "/tmp/gitops/pipelines.yaml" is a file, expected a directory`},
+		{"path within root", "/tmp/gitops/output", "/tmp/gitops", ""},
+		{"path is the root", "/tmp/gitops", "/tmp/gitops", ""},
+		{"path escapes root", "/tmp/gitops/../other", "/tmp/gitops", `output path "/tmp/gitops/../other" is outside of the output root "/tmp/gitops"`},
+		{"sibling with common prefix", "/tmp/gitops-other", "/tmp/gitops", `output path "/tmp/gitops-other" is outside of the output root "/tmp/gitops"`},
+	}
+
+	for _, tt := range pathTests {
+		t.Run(tt.name, func(rt *testing.T) {
+			err := ValidateOutputPath(fs, tt.path, tt.root)
+			if !helper.ErrorMatch(rt, tt.wantErr, err) {
+				rt.Errorf("ValidateOutputPath() got %v, want %s", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestFindRepositoryDir(t *testing.T) {
+	fs := NewMemoryFilesystem()
+	for _, d := range []string{"/repo/.git", "/repo/clusters/prod/environments/dev", "/other/nested"} {
+		if err := fs.MkdirAll(d, 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := afero.WriteFile(fs, "/repo/clusters/prod/pipelines.yaml", []byte("environments:"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	findTests := []struct {
+		name    string
+		dir     string
+		want    string
+		wantErr string
+	}{
+		{"nested directory", "/repo/clusters/prod/environments/dev", "/repo/clusters/prod", ""},
+		{"directory with the file", "/repo/clusters/prod", "/repo/clusters/prod", ""},
+		{"above the file", "/repo/clusters", "/repo", ""},
+		{"root of the checkout", "/repo", "/repo", ""},
+		{"not in a checkout", "/other/nested", "", "/other/nested is not within a Git repository"},
+	}
+
+	for _, tt := range findTests {
+		t.Run(tt.name, func(rt *testing.T) {
+			got, err := FindRepositoryDir(fs, tt.dir, "pipelines.yaml")
+			if !helper.ErrorMatch(rt, tt.wantErr, err) {
+				rt.Fatalf("FindRepositoryDir() got error %v, want %s", err, tt.wantErr)
+			}
+			if got != tt.want {
+				rt.Errorf("FindRepositoryDir() got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,68 @@
+package pipelines
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/mkmik/multierror"
+	"github.com/spf13/afero"
+	"knative.dev/pkg/apis"
+
+	"github.com/rhd-gitops-example/gitops-cli/pkg/pipelines/config"
+	"github.com/rhd-gitops-example/gitops-cli/pkg/pipelines/ioutils"
+)
+
+// LintParameters is a struct that provides flags for the Lint command.
+type LintParameters struct {
+	PipelinesFolderPath string
+}
+
+// Lint loads the manifest from the pipelines folder, and reports any
+// validation errors.
+//
+// The secrets referenced by the manifest must have sealed secret files in the
+// pipelines folder.
+func Lint(o *LintParameters, appFs afero.Fs) error {
+	m, err := config.LoadManifest(appFs, o.PipelinesFolderPath)
+	if err != nil {
+		return err
+	}
+	cfg := m.GetPipelinesConfig()
+	if cfg == nil {
+		return nil
+	}
+	sv := &secretsVisitor{fs: appFs, basePath: o.PipelinesFolderPath, cfg: cfg}
+	if err := m.Walk(sv); err != nil {
+		return err
+	}
+	if len(sv.errs) == 0 {
+		return nil
+	}
+	return multierror.Join(sv.errs)
+}
+
+// secretsVisitor checks that the webhook secrets of the services have sealed
+// secret files in the CI/CD configuration.
+type secretsVisitor struct {
+	fs       afero.Fs
+	basePath string
+	cfg      *config.PipelinesConfig
+	errs     []error
+}
+
+func (sv *secretsVisitor) Service(app *config.Application, env *config.Environment, svc *config.Service) error {
+	if svc.Webhook == nil || svc.Webhook.Secret == nil {
+		return nil
+	}
+	filename := filepath.Join(config.PathForPipelines(sv.cfg), "base", "03-secrets", svc.Webhook.Secret.Name+".yaml")
+	if exists, _ := ioutils.IsExisting(sv.fs, filepath.Join(sv.basePath, filename)); !exists {
+		svcPath := strings.ReplaceAll(config.PathForService(app, env, svc.Name), "/", ".")
+		sv.errs = append(sv.errs, &apis.FieldError{
+			Message: fmt.Sprintf("missing sealed secret file %s", filename),
+			Details: fmt.Sprintf("The webhook secret %s/%s has no sealed secret file", svc.Webhook.Secret.Namespace, svc.Webhook.Secret.Name),
+			Paths:   []string{svcPath + ".webhook.secret"},
+		})
+	}
+	return nil
+}
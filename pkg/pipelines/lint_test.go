@@ -0,0 +1,78 @@
+package pipelines
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/spf13/afero"
+
+	"github.com/rhd-gitops-example/gitops-cli/pkg/pipelines/helper"
+	"github.com/rhd-gitops-example/gitops-cli/pkg/pipelines/ioutils"
+)
+
+func TestLint(t *testing.T) {
+	lintTests := []struct {
+		name     string
+		manifest string
+		wantErr  string
+	}{
+		{"valid manifest", "environments:\n- name: dev\n", ""},
+		{"duplicate environments", "environments:\n- name: dev\n- name: dev\n", `duplicate field\(s\) "dev"`},
+	}
+
+	for _, tt := range lintTests {
+		t.Run(tt.name, func(rt *testing.T) {
+			fakeFs := ioutils.NewMemoryFilesystem()
+			gitopsPath := afero.GetTempDir(fakeFs, "test")
+			err := afero.WriteFile(fakeFs, filepath.Join(gitopsPath, pipelinesFile), []byte(tt.manifest), 0644)
+			assertNoError(rt, err)
+
+			err = Lint(&LintParameters{PipelinesFolderPath: gitopsPath}, fakeFs)
+			if !helper.ErrorMatch(rt, tt.wantErr, err) {
+				rt.Fatalf("Lint() failed: got %v, want %s", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestLintSecretReferences(t *testing.T) {
+	manifest := `config:
+  pipelines:
+    name: cicd
+environments:
+- name: dev
+  apps:
+  - name: app-http-api
+    services:
+    - name: http-api
+      source_url: https://github.com/my-org/http-api.git
+      webhook:
+        secret:
+          name: webhook-secret-dev-http-api
+          namespace: cicd
+`
+	secretTests := []struct {
+		name    string
+		files   []string
+		wantErr string
+	}{
+		{"sealed secret exists", []string{"config/cicd/base/03-secrets/webhook-secret-dev-http-api.yaml"}, ""},
+		{"sealed secret is missing", nil, `missing sealed secret file config/cicd/base/03-secrets/webhook-secret-dev-http-api.yaml: environments.dev.apps.app-http-api.services.http-api.webhook.secret`},
+	}
+
+	for _, tt := range secretTests {
+		t.Run(tt.name, func(rt *testing.T) {
+			fakeFs := ioutils.NewMemoryFilesystem()
+			gitopsPath := afero.GetTempDir(fakeFs, "test")
+			assertNoError(rt, afero.WriteFile(fakeFs, filepath.Join(gitopsPath, pipelinesFile), []byte(manifest), 0644))
+			for _, f := range tt.files {
+				assertNoError(rt, afero.WriteFile(fakeFs, filepath.Join(gitopsPath, f), []byte("kind: SealedSecret\n"), 0644))
+			}
+
+			err := Lint(&LintParameters{PipelinesFolderPath: gitopsPath}, fakeFs)
+			if !helper.ErrorMatch(rt, tt.wantErr, err) {
+				rt.Fatalf("Lint() failed: got %v, want %s", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,118 @@
+package pipelines
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/spf13/afero"
+
+	"github.com/rhd-gitops-example/gitops-cli/pkg/pipelines/config"
+	"github.com/rhd-gitops-example/gitops-cli/pkg/pipelines/yaml"
+)
+
+// ListParameters is a struct that provides flags for the List command.
+type ListParameters struct {
+	PipelinesFolderPath string
+	Output              string // The output format, empty for a tree, json or yaml.
+}
+
+// List writes the environments, applications and services that are managed by
+// the manifest in the pipelines folder to out, nothing is changed.
+func List(o *ListParameters, appFs afero.Fs, out io.Writer) error {
+	m, err := config.LoadManifest(appFs, o.PipelinesFolderPath)
+	if err != nil {
+		return err
+	}
+	switch o.Output {
+	case "":
+		return writeTree(out, m)
+	case "json":
+		b, err := json.MarshalIndent(m, "", "	")
+		if err != nil {
+			return fmt.Errorf("failed to marshal the manifest: %w", err)
+		}
+		_, err = fmt.Fprintf(out, "%s\n", b)
+		return err
+	case "yaml":
+		return yaml.MarshalOutput(out, m)
+	}
+	return fmt.Errorf("unsupported output format %q, must be json or yaml", o.Output)
+}
+
+// treeWriter writes the manifest as an indented tree, one item per line.
+type treeWriter struct {
+	out   io.Writer
+	depth int
+	err   error
+}
+
+func (t *treeWriter) printf(format string, a ...interface{}) {
+	if t.err != nil {
+		return
+	}
+	_, t.err = fmt.Fprintf(t.out, strings.Repeat("  ", t.depth)+format+"\n", a...)
+}
+
+func writeTree(out io.Writer, m *config.Manifest) error {
+	t := &treeWriter{out: out}
+	if m.GitOpsURL != "" {
+		t.printf("GitOps repository: %s", m.GitOpsURL)
+	}
+	if cfg := m.GetPipelinesConfig(); cfg != nil {
+		t.printf("CI/CD namespace: %s", cfg.Name)
+	}
+	if cfg := m.GetArgoCDConfig(); cfg != nil {
+		t.printf("Argo CD namespace: %s", cfg.Namespace)
+	}
+	if cfg := m.GetFluxConfig(); cfg != nil {
+		t.printf("Flux namespace: %s", cfg.Namespace)
+	}
+	for _, env := range m.Environments {
+		t.printf("Environment %s", env.Name)
+		t.depth++
+		if env.Cluster != "" {
+			t.printf("Cluster: %s", env.Cluster)
+		}
+		for _, app := range env.Apps {
+			writeApplication(t, app)
+		}
+		t.depth--
+	}
+	return t.err
+}
+
+func writeApplication(t *treeWriter, app *config.Application) {
+	t.printf("Application %s", app.Name)
+	t.depth++
+	defer func() { t.depth-- }()
+	if repo := app.ConfigRepo; repo != nil {
+		t.printf("Config repository: %s", repoDescription(repo))
+	}
+	for _, svc := range app.Services {
+		t.printf("Service %s", svc.Name)
+		t.depth++
+		if svc.SourceURL != "" {
+			t.printf("Source repository: %s", svc.SourceURL)
+		}
+		if svc.PipelineType != "" {
+			t.printf("Pipeline: %s", svc.PipelineType)
+		}
+		if svc.Webhook != nil && svc.Webhook.Secret != nil {
+			t.printf("Webhook secret: %s/%s", svc.Webhook.Secret.Namespace, svc.Webhook.Secret.Name)
+		}
+		t.depth--
+	}
+}
+
+func repoDescription(repo *config.Repository) string {
+	desc := repo.URL
+	if repo.Path != "" {
+		desc += " path " + repo.Path
+	}
+	if repo.TargetRevision != "" {
+		desc += " at " + repo.TargetRevision
+	}
+	return desc
+}
@@ -0,0 +1,79 @@
+package pipelines
+
+import (
+	"bytes"
+	"encoding/json"
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/spf13/afero"
+
+	"github.com/rhd-gitops-example/gitops-cli/pkg/pipelines/config"
+	"github.com/rhd-gitops-example/gitops-cli/pkg/pipelines/helper"
+	"github.com/rhd-gitops-example/gitops-cli/pkg/pipelines/ioutils"
+)
+
+func TestListTree(t *testing.T) {
+	fakeFs, gitopsPath := manifestFileFixture(t, "testdata/list/pipelines.yaml")
+	var out bytes.Buffer
+
+	err := List(&ListParameters{PipelinesFolderPath: gitopsPath}, fakeFs, &out)
+	assertNoError(t, err)
+
+	want := `GitOps repository: https://github.com/example/gitops.git
+CI/CD namespace: cicd
+Argo CD namespace: argocd
+Environment dev
+  Application taxi
+    Service gateway
+      Source repository: https://github.com/example/gateway.git
+      Webhook secret: cicd/webhook-secret-dev-gateway
+    Service builder
+      Source repository: https://github.com/example/builder.git
+      Pipeline: build
+Environment prod
+  Cluster: https://prod.example.com
+  Application taxi
+    Config repository: https://github.com/example/taxi-config.git path deploy at main
+`
+	if diff := cmp.Diff(want, out.String()); diff != "" {
+		t.Fatalf("list output failed:\n%s", diff)
+	}
+}
+
+func TestListJSON(t *testing.T) {
+	fakeFs, gitopsPath := manifestFileFixture(t, "testdata/list/pipelines.yaml")
+	var out bytes.Buffer
+
+	err := List(&ListParameters{PipelinesFolderPath: gitopsPath, Output: "json"}, fakeFs, &out)
+	assertNoError(t, err)
+
+	got := &config.Manifest{}
+	assertNoError(t, json.Unmarshal(out.Bytes(), got))
+	want, err := config.LoadManifest(fakeFs, gitopsPath)
+	assertNoError(t, err)
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Fatalf("list json output failed:\n%s", diff)
+	}
+}
+
+func TestListUnknownFormat(t *testing.T) {
+	fakeFs, gitopsPath := manifestFileFixture(t, "testdata/list/pipelines.yaml")
+
+	err := List(&ListParameters{PipelinesFolderPath: gitopsPath, Output: "table"}, fakeFs, &bytes.Buffer{})
+	helper.AssertErrorMatch(t, `unsupported output format "table"`, err)
+}
+
+// manifestFileFixture returns a filesystem with the manifest from the testdata
+// file, and the path of the GitOps repository that it's written to.
+func manifestFileFixture(t *testing.T, filename string) (afero.Fs, string) {
+	t.Helper()
+	b, err := ioutil.ReadFile(filename)
+	assertNoError(t, err)
+	fakeFs := ioutils.NewMemoryFilesystem()
+	gitopsPath := afero.GetTempDir(fakeFs, "test")
+	assertNoError(t, afero.WriteFile(fakeFs, filepath.Join(gitopsPath, pipelinesFile), b, 0644))
+	return fakeFs, gitopsPath
+}
@@ -0,0 +1,90 @@
+package pipelines
+
+import (
+	"bytes"
+	"fmt"
+	"path/filepath"
+	"text/template"
+
+	"github.com/spf13/afero"
+
+	"github.com/rhd-gitops-example/gitops-cli/pkg/pipelines/config"
+)
+
+const makefileFile = "Makefile"
+
+// makefileTemplate has targets that run the CLI against the GitOps repository,
+// so that users of a bootstrapped repository can find the common operations.
+const makefileTemplate = `# Common operations for this GitOps repository, run "make <target>".
+GITOPS ?= gitops
+KUBECTL ?= kubectl
+MANIFEST ?= {{ .Manifest }}
+PIPELINES_FOLDER ?= {{ .PipelinesFolder }}
+
+.PHONY: validate
+validate:
+	$(GITOPS) lint --pipelines-folder $(PIPELINES_FOLDER)
+
+.PHONY: build
+build: validate
+	$(GITOPS) build --pipelines-folder $(PIPELINES_FOLDER) --output .{{ if .UseApplicationSet }} --use-applicationset{{ end }}
+
+.PHONY: diff
+diff:
+	git show HEAD:./$(MANIFEST) > .$(notdir $(MANIFEST)).head
+	$(GITOPS) diff .$(notdir $(MANIFEST)).head $(MANIFEST); status=$$?; rm -f .$(notdir $(MANIFEST)).head; exit $$status
+
+.PHONY: dry-run
+dry-run: validate
+	$(GITOPS) build --pipelines-folder $(PIPELINES_FOLDER) --output .{{ if .UseApplicationSet }} --use-applicationset{{ end }} --server-dry-run
+
+.PHONY: apply
+apply: build
+	$(KUBECTL) apply -k {{ .ApplyPath }}
+`
+
+type makefileParams struct {
+	Manifest          string
+	PipelinesFolder   string
+	UseApplicationSet bool
+	ApplyPath         string
+}
+
+// writeMakefile writes a Makefile with targets for the common operations to
+// the OutputPath.
+func writeMakefile(fs afero.Fs, o *BootstrapOptions) error {
+	params := makefileParams{
+		Manifest:          filepath.ToSlash(o.manifestFile()),
+		PipelinesFolder:   filepath.ToSlash(filepath.Dir(o.manifestFile())),
+		UseApplicationSet: o.UseApplicationSet,
+		ApplyPath:         config.PathForArgoCD(),
+	}
+	if o.GitOpsEngine == FluxEngine {
+		params.ApplyPath = config.PathForFlux()
+	}
+	tmpl, err := template.New("makefile").Parse(makefileTemplate)
+	if err != nil {
+		return fmt.Errorf("unable to parse template: %w", err)
+	}
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, params); err != nil {
+		return fmt.Errorf("unable to execute template: %w", err)
+	}
+	return afero.WriteFile(fs, filepath.Join(o.OutputPath, makefileFile), buf.Bytes(), 0644)
+}
+
+// checkMakefileExists returns an error if a Makefile would be written to the
+// OutputPath and would replace an existing one, unless it's forced.
+func checkMakefileExists(fs afero.Fs, o *BootstrapOptions) error {
+	if !o.WithMakefile || o.ForceMakefile {
+		return nil
+	}
+	exists, err := afero.Exists(fs, filepath.Join(o.OutputPath, makefileFile))
+	if err != nil {
+		return err
+	}
+	if exists {
+		return fmt.Errorf("%s in output path already exists. If you want to replace it, please rerun with --force", makefileFile)
+	}
+	return nil
+}
@@ -0,0 +1,73 @@
+package pipelines
+
+import (
+	"path/filepath"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/spf13/afero"
+
+	"github.com/rhd-gitops-example/gitops-cli/pkg/pipelines/ioutils"
+)
+
+func TestBootstrapWithMakefile(t *testing.T) {
+	defer stubDefaultPublicKeyFunc(t)()
+	fakeFs := ioutils.NewMemoryFilesystem()
+	params := &BootstrapOptions{
+		Prefix:         "tst-",
+		GitOpsRepoURL:  testGitOpsRepo,
+		ImageRepo:      "image/repo",
+		ServiceRepoURL: testSvcRepo,
+		OutputPath:     "/tmp/gitops",
+		WithMakefile:   true,
+	}
+	assertNoError(t, Bootstrap(params, fakeFs))
+
+	b, err := afero.ReadFile(fakeFs, filepath.Join("/tmp/gitops", makefileFile))
+	assertNoError(t, err)
+	got := string(b)
+	for _, target := range []string{"validate", "build", "diff", "dry-run", "apply"} {
+		if !regexp.MustCompile("(?m)^" + target + ":").MatchString(got) {
+			t.Errorf("Makefile has no %s target:\n%s", target, got)
+		}
+	}
+	for _, cmd := range []string{
+		"$(GITOPS) lint --pipelines-folder $(PIPELINES_FOLDER)",
+		"$(GITOPS) diff .$(notdir $(MANIFEST)).head $(MANIFEST)",
+		"$(KUBECTL) apply -k config/argocd",
+	} {
+		if !strings.Contains(got, cmd) {
+			t.Errorf("Makefile doesn't run %q:\n%s", cmd, got)
+		}
+	}
+}
+
+func TestBootstrapWithExistingMakefile(t *testing.T) {
+	defer stubDefaultPublicKeyFunc(t)()
+	fakeFs := ioutils.NewMemoryFilesystem()
+	filename := filepath.Join("/tmp/gitops", makefileFile)
+	assertNoError(t, afero.WriteFile(fakeFs, filename, []byte("all:\n"), 0644))
+	params := &BootstrapOptions{
+		Prefix:         "tst-",
+		GitOpsRepoURL:  testGitOpsRepo,
+		ImageRepo:      "image/repo",
+		ServiceRepoURL: testSvcRepo,
+		OutputPath:     "/tmp/gitops",
+		WithMakefile:   true,
+	}
+
+	err := Bootstrap(params, fakeFs)
+	want := "Makefile in output path already exists. If you want to replace it, please rerun with --force"
+	if err == nil || err.Error() != want {
+		t.Fatalf("got error %v, want %s", err, want)
+	}
+
+	params.ForceMakefile = true
+	assertNoError(t, Bootstrap(params, fakeFs))
+	b, err := afero.ReadFile(fakeFs, filename)
+	assertNoError(t, err)
+	if !strings.Contains(string(b), "apply: build") {
+		t.Fatalf("Makefile wasn't replaced:\n%s", b)
+	}
+}
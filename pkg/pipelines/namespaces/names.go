@@ -0,0 +1,69 @@
+package namespaces
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"text/template"
+)
+
+// MaybeCompletePrefix adds a hyphen on the end of the prefix if it doesn't have
+// one to make prefix-generated names look a bit nicer.
+func MaybeCompletePrefix(s string) string {
+	if s != "" && !strings.HasSuffix(s, "-") {
+		return s + "-"
+	}
+	return s
+}
+
+// EnvironmentNamespace returns the namespace name for an environment, this is
+// the environment name with the completed prefix, truncated to the maxLength,
+// the length limit for names, usually the 63 character limit for a DNS-1123
+// label.
+func EnvironmentNamespace(prefix, envName string, maxLength int) string {
+	ns := MaybeCompletePrefix(prefix) + envName
+	if len(ns) > maxLength {
+		return ns[:maxLength]
+	}
+	return ns
+}
+
+var invalidNameChars = regexp.MustCompile("[^a-z0-9]+")
+
+// SuggestName returns a valid name derived from the name, it's lowercased, and
+// runs of characters that aren't allowed in a DNS-1123 label, like underscores
+// and dots, are replaced with a hyphen, it's truncated to the maxLength.
+func SuggestName(name string, maxLength int) string {
+	s := strings.Trim(invalidNameChars.ReplaceAllString(strings.ToLower(name), "-"), "-")
+	if len(s) > maxLength {
+		s = strings.TrimRight(s[:maxLength], "-")
+	}
+	return s
+}
+
+// DefaultNamespacePattern is the pattern for environment namespaces that
+// produces the EnvironmentNamespace.
+const DefaultNamespacePattern = "{{if .Prefix}}{{.Prefix}}-{{end}}{{.Env}}"
+
+// NamespaceFromPattern renders the namespace for an environment from the
+// pattern, a template with the .Prefix, without the hyphen that completes it,
+// and the .Env name e.g. "{{.Env}}-{{.Prefix}}".
+//
+// The empty and default patterns return the EnvironmentNamespace, truncated to
+// the maxLength, other patterns are not truncated, so the namespace must be
+// validated.
+func NamespaceFromPattern(pattern, prefix, envName string, maxLength int) (string, error) {
+	if pattern == "" || pattern == DefaultNamespacePattern {
+		return EnvironmentNamespace(prefix, envName, maxLength), nil
+	}
+	tmpl, err := template.New("namespace").Parse(pattern)
+	if err != nil {
+		return "", fmt.Errorf("failed to parse namespace pattern %q: %w", pattern, err)
+	}
+	var b strings.Builder
+	params := struct{ Prefix, Env string }{Prefix: strings.TrimSuffix(prefix, "-"), Env: envName}
+	if err := tmpl.Execute(&b, params); err != nil {
+		return "", fmt.Errorf("failed to render namespace pattern %q: %w", pattern, err)
+	}
+	return b.String(), nil
+}
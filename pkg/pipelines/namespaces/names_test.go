@@ -0,0 +1,117 @@
+package namespaces
+
+import (
+	"strings"
+	"testing"
+
+	"k8s.io/apimachinery/pkg/util/validation"
+)
+
+func TestSuggestName(t *testing.T) {
+	nameTests := []struct {
+		name  string
+		input string
+		want  string
+	}{
+		{"valid name", "http-api", "http-api"},
+		{"uppercase and underscores", "My_Repo", "my-repo"},
+		{"dots", "my.repo.name", "my-repo-name"},
+		{"leading and trailing invalid characters", "_repo_", "repo"},
+		{"too long", strings.Repeat("a", 62) + "_b", strings.Repeat("a", 62)},
+	}
+
+	for _, tt := range nameTests {
+		t.Run(tt.name, func(rt *testing.T) {
+			if got := SuggestName(tt.input, validation.DNS1123LabelMaxLength); got != tt.want {
+				rt.Fatalf("SuggestName(%q) got %q, want %q", tt.input, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMaybeCompletePrefix(t *testing.T) {
+	stringsTests := []struct {
+		name   string
+		prefix string
+		want   string
+	}{
+		{"with dash on end", "testing-", "testing-"},
+		{"with no dash on end", "testing", "testing-"},
+	}
+
+	for _, tt := range stringsTests {
+		t.Run(tt.name, func(rt *testing.T) {
+			got := MaybeCompletePrefix(tt.prefix)
+			if tt.want != got {
+				rt.Fatalf("prefixing failed, got %#v, want %#v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestEnvironmentNamespace(t *testing.T) {
+	longEnv := strings.Repeat("a", 59)
+	tests := []struct {
+		prefix  string
+		envName string
+		want    string
+	}{
+		{"", "dev", "dev"},
+		{"", strings.Repeat("a", 64), strings.Repeat("a", 63)},
+		{"tst", "dev", "tst-dev"},
+		{"tst-", "dev", "tst-dev"},
+		{"tst", longEnv, "tst-" + longEnv},
+		{"test", longEnv, "test-" + longEnv[:58]},
+	}
+
+	for _, tt := range tests {
+		got := EnvironmentNamespace(tt.prefix, tt.envName, validation.DNS1123LabelMaxLength)
+		if got != tt.want {
+			t.Errorf("EnvironmentNamespace(%q, %q) got %q, want %q", tt.prefix, tt.envName, got, tt.want)
+		}
+	}
+}
+
+func TestEnvironmentNamespaceWithIncreasedLimit(t *testing.T) {
+	longEnv := strings.Repeat("a", 80)
+
+	if got := EnvironmentNamespace("test", longEnv, 80); got != "test-"+longEnv[:75] {
+		t.Errorf("EnvironmentNamespace(%q, %q) got %q, want %q", "test", longEnv, got, "test-"+longEnv[:75])
+	}
+}
+
+func TestNamespaceFromPattern(t *testing.T) {
+	tests := []struct {
+		pattern string
+		prefix  string
+		envName string
+		want    string
+		wantErr string
+	}{
+		{"", "tst-", "dev", "tst-dev", ""},
+		{DefaultNamespacePattern, "tst-", "dev", "tst-dev", ""},
+		{DefaultNamespacePattern, "", "dev", "dev", ""},
+		{"{{.Env}}-{{.Prefix}}", "tst-", "dev", "dev-tst", ""},
+		{"{{.Prefix}}_{{.Env}}", "tst", "dev", "tst_dev", ""},
+		{"{{.Env", "tst", "dev", "", `failed to parse namespace pattern "{{.Env"`},
+		{"{{.Team}}-{{.Env}}", "tst", "dev", "", `failed to render namespace pattern "{{.Team}}-{{.Env}}"`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.pattern, func(rt *testing.T) {
+			got, err := NamespaceFromPattern(tt.pattern, tt.prefix, tt.envName, validation.DNS1123LabelMaxLength)
+			if tt.wantErr != "" {
+				if err == nil || !strings.HasPrefix(err.Error(), tt.wantErr) {
+					rt.Fatalf("got error %v, want %s", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				rt.Fatal(err)
+			}
+			if got != tt.want {
+				rt.Errorf("NamespaceFromPattern(%q, %q, %q) got %q, want %q", tt.pattern, tt.prefix, tt.envName, got, tt.want)
+			}
+		})
+	}
+}
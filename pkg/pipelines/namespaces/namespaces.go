@@ -6,12 +6,17 @@ import (
 	"github.com/rhd-gitops-example/gitops-cli/pkg/pipelines/clientconfig"
 	"github.com/rhd-gitops-example/gitops-cli/pkg/pipelines/meta"
 	corev1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/client-go/kubernetes"
 )
 
 const (
 	vcsURIAnnotation = "app.openshift.io/vcs-uri"
+
+	podSecurityLabelPrefix = "pod-security.kubernetes.io/"
+	// namespaceNameLabel is set on all namespaces by Kubernetes.
+	namespaceNameLabel = "kubernetes.io/metadata.name"
 )
 
 var (
@@ -21,7 +26,10 @@ var (
 		"cicd":  "cicd",
 	}
 
-	namespaceTypeMeta = meta.TypeMeta("Namespace", "v1")
+	namespaceTypeMeta     = meta.TypeMeta("Namespace", "v1")
+	networkPolicyTypeMeta = meta.TypeMeta("NetworkPolicy", "networking.k8s.io/v1")
+	resourceQuotaTypeMeta = meta.TypeMeta("ResourceQuota", "v1")
+	limitRangeTypeMeta    = meta.TypeMeta("LimitRange", "v1")
 )
 
 // Namespaces create namespaces for the given names.
@@ -34,15 +42,30 @@ func Namespaces(names []string, gitOpsRepoURL string) []*corev1.Namespace {
 }
 
 // NamesWithPrefix returns namespaces of all environments based on the prefix,
-// and using the set of predefined names: dev, stage, cicd.
-func NamesWithPrefix(prefix string) map[string]string {
+// and using the set of predefined names: dev, stage, cicd, these are truncated
+// to the maxLength.
+func NamesWithPrefix(prefix string, maxLength int) map[string]string {
 	prefixedNames := make(map[string]string)
 	for k, v := range namespaceBaseNames {
-		prefixedNames[k] = fmt.Sprintf("%s%s", prefix, v)
+		prefixedNames[k] = EnvironmentNamespace(prefix, v, maxLength)
 	}
 	return prefixedNames
 }
 
+// NamesWithPattern is like NamesWithPrefix, but the namespaces are rendered
+// from the pattern with the prefix, see NamespaceFromPattern.
+func NamesWithPattern(pattern, prefix string, maxLength int) (map[string]string, error) {
+	names := make(map[string]string)
+	for k, v := range namespaceBaseNames {
+		ns, err := NamespaceFromPattern(pattern, prefix, v, maxLength)
+		if err != nil {
+			return nil, err
+		}
+		names[k] = ns
+	}
+	return names, nil
+}
+
 // Create creates a Namespace value from a string.
 func Create(name, gitOpsRepoURL string) *corev1.Namespace {
 	ns := &corev1.Namespace{
@@ -57,6 +80,82 @@ func Create(name, gitOpsRepoURL string) *corev1.Namespace {
 	return ns
 }
 
+// AddPodSecurityLabels labels the namespace to enforce the Pod Security
+// Standard level, violations are also audited and warned about.
+func AddPodSecurityLabels(ns *corev1.Namespace, level string) *corev1.Namespace {
+	if ns.Labels == nil {
+		ns.Labels = map[string]string{}
+	}
+	for _, mode := range []string{"enforce", "audit", "warn"} {
+		ns.Labels[podSecurityLabelPrefix+mode] = level
+	}
+	return ns
+}
+
+// DefaultDenyNetworkPolicy creates a NetworkPolicy that denies ingress to all
+// the pods in the namespace, other NetworkPolicies must allow it.
+func DefaultDenyNetworkPolicy(ns string) *networkingv1.NetworkPolicy {
+	return &networkingv1.NetworkPolicy{
+		TypeMeta:   networkPolicyTypeMeta,
+		ObjectMeta: meta.ObjectMeta(meta.NamespacedName(ns, "default-deny")),
+		Spec: networkingv1.NetworkPolicySpec{
+			PolicyTypes: []networkingv1.PolicyType{networkingv1.PolicyTypeIngress},
+		},
+	}
+}
+
+// AllowFromNamespaceNetworkPolicy creates a NetworkPolicy that allows ingress
+// to all the pods in the namespace from the pods in the other namespace.
+func AllowFromNamespaceNetworkPolicy(ns, from string) *networkingv1.NetworkPolicy {
+	return &networkingv1.NetworkPolicy{
+		TypeMeta:   networkPolicyTypeMeta,
+		ObjectMeta: meta.ObjectMeta(meta.NamespacedName(ns, "allow-from-"+from)),
+		Spec: networkingv1.NetworkPolicySpec{
+			Ingress: []networkingv1.NetworkPolicyIngressRule{
+				{
+					From: []networkingv1.NetworkPolicyPeer{
+						{
+							NamespaceSelector: &metav1.LabelSelector{
+								MatchLabels: map[string]string{namespaceNameLabel: from},
+							},
+						},
+					},
+				},
+			},
+			PolicyTypes: []networkingv1.PolicyType{networkingv1.PolicyTypeIngress},
+		},
+	}
+}
+
+// ResourceQuota creates a ResourceQuota that limits the total CPU and memory
+// limits of the containers in the namespace to the hard limits.
+func ResourceQuota(ns string, hard corev1.ResourceList) *corev1.ResourceQuota {
+	return &corev1.ResourceQuota{
+		TypeMeta:   resourceQuotaTypeMeta,
+		ObjectMeta: meta.ObjectMeta(meta.NamespacedName(ns, "quota")),
+		Spec: corev1.ResourceQuotaSpec{
+			Hard: hard,
+		},
+	}
+}
+
+// LimitRange creates a LimitRange that sets the default limits of the
+// containers in the namespace that don't set them.
+func LimitRange(ns string, defaults corev1.ResourceList) *corev1.LimitRange {
+	return &corev1.LimitRange{
+		TypeMeta:   limitRangeTypeMeta,
+		ObjectMeta: meta.ObjectMeta(meta.NamespacedName(ns, "limits")),
+		Spec: corev1.LimitRangeSpec{
+			Limits: []corev1.LimitRangeItem{
+				{
+					Type:    corev1.LimitTypeContainer,
+					Default: defaults,
+				},
+			},
+		},
+	}
+}
+
 // GetClientSet creates and returns a new Kubernetes clientset.
 func GetClientSet() (*kubernetes.Clientset, error) {
 	clientConfig, err := clientconfig.GetRESTConfig()
@@ -6,6 +6,7 @@ import (
 	"github.com/google/go-cmp/cmp"
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/validation"
 	testclient "k8s.io/client-go/kubernetes/fake"
 )
 
@@ -27,7 +28,7 @@ func TestCreate(t *testing.T) {
 }
 
 func TestNamesWithPrefix(t *testing.T) {
-	ns := NamesWithPrefix("test-")
+	ns := NamesWithPrefix("test-", validation.DNS1123LabelMaxLength)
 	want := map[string]string{
 		"dev":   "test-dev",
 		"stage": "test-stage",
@@ -0,0 +1,98 @@
+package network
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// ErrNetworkDisabled is returned for connections attempted while the network
+// is disabled.
+var ErrNetworkDisabled = errors.New("network disabled")
+
+// ErrHostNotAllowed is returned for connections to hosts that are not in the
+// AllowedHosts.
+var ErrHostNotAllowed = errors.New("not in allowlist")
+
+// Disabled is set from the --no-network flag, when true, the Git and
+// Kubernetes clients fail any attempt to connect rather than making the
+// connection.
+var Disabled bool
+
+// AllowedHosts is set from the --allowed-hosts flag, when it's not empty, the
+// Git and Kubernetes clients fail any attempt to connect to a host that isn't
+// in the list.
+//
+// Hosts are matched exactly, or with a "*." prefix, any subdomain is matched.
+var AllowedHosts []string
+
+// Restricted returns true if connections must be made through Dial, either
+// because the network is disabled, or only some hosts are allowed.
+func Restricted() bool {
+	return Disabled || len(AllowedHosts) > 0
+}
+
+// HostAllowed returns true if connections can be made to the host.
+func HostAllowed(host string) bool {
+	if Disabled {
+		return false
+	}
+	if len(AllowedHosts) == 0 {
+		return true
+	}
+	host = strings.ToLower(host)
+	for _, allowed := range AllowedHosts {
+		allowed = strings.ToLower(allowed)
+		if host == allowed {
+			return true
+		}
+		if strings.HasPrefix(allowed, "*.") && strings.HasSuffix(host, allowed[1:]) {
+			return true
+		}
+	}
+	return false
+}
+
+// CheckHost returns an error if connections can't be made to the host.
+func CheckHost(host string) error {
+	if Disabled {
+		return fmt.Errorf("%w: attempted to connect to %s", ErrNetworkDisabled, host)
+	}
+	if !HostAllowed(host) {
+		return fmt.Errorf("host %s %w", host, ErrHostNotAllowed)
+	}
+	return nil
+}
+
+// Dial fails connections with ErrNetworkDisabled if the network is disabled,
+// or with ErrHostNotAllowed to hosts that aren't allowed, otherwise it makes
+// the connection, it has the signature of net.Dialer.DialContext.
+func Dial(ctx context.Context, network, address string) (net.Conn, error) {
+	if Disabled {
+		return nil, fmt.Errorf("%w: attempted to connect to %s", ErrNetworkDisabled, address)
+	}
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		host = address
+	}
+	if err := CheckHost(host); err != nil {
+		return nil, err
+	}
+	dialer := &net.Dialer{Timeout: 30 * time.Second, KeepAlive: 30 * time.Second}
+	return dialer.DialContext(ctx, network, address)
+}
+
+// HTTPClient returns an HTTP client that makes its connections with Dial.
+//
+// Proxies are not used, as the proxy would make the connection to the host.
+func HTTPClient() *http.Client {
+	return &http.Client{
+		Transport: &http.Transport{
+			DialContext: Dial,
+		},
+	}
+}
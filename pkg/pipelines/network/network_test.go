@@ -0,0 +1,81 @@
+package network
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestHTTPClientWithAllowedHosts(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer ts.Close()
+	defer stubAllowedHosts([]string{"127.0.0.1"})()
+
+	resp, err := HTTPClient().Get(ts.URL)
+	if err != nil {
+		t.Fatalf("failed to connect to an allowed host: %s", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusNoContent {
+		t.Fatalf("got status %d, want %d", resp.StatusCode, http.StatusNoContent)
+	}
+
+	AllowedHosts = []string{"github.com"}
+	_, err = HTTPClient().Get(ts.URL)
+	if !errors.Is(err, ErrHostNotAllowed) {
+		t.Fatalf("got error %v, want %s", err, ErrHostNotAllowed)
+	}
+	if !strings.Contains(err.Error(), "host 127.0.0.1 not in allowlist") {
+		t.Fatalf("got error %s, want it to name the host", err)
+	}
+}
+
+func TestHTTPClientWithNoNetwork(t *testing.T) {
+	defer func(d bool) {
+		Disabled = d
+	}(Disabled)
+	Disabled = true
+	defer stubAllowedHosts([]string{"127.0.0.1"})()
+
+	_, err := HTTPClient().Get("http://127.0.0.1")
+
+	if !errors.Is(err, ErrNetworkDisabled) {
+		t.Fatalf("got error %v, want %s", err, ErrNetworkDisabled)
+	}
+}
+
+func TestHostAllowed(t *testing.T) {
+	hostTests := []struct {
+		allowed []string
+		host    string
+		want    bool
+	}{
+		{nil, "github.com", true},
+		{[]string{"github.com"}, "github.com", true},
+		{[]string{"GitHub.com"}, "github.com", true},
+		{[]string{"github.com"}, "api.github.com", false},
+		{[]string{"gitlab.com", "*.github.com"}, "api.github.com", true},
+		{[]string{"*.github.com"}, "github.com", false},
+		{[]string{"*.github.com"}, "notgithub.com", false},
+	}
+
+	for _, tt := range hostTests {
+		restore := stubAllowedHosts(tt.allowed)
+		if got := HostAllowed(tt.host); got != tt.want {
+			t.Errorf("HostAllowed(%q) with %v got %v, want %v", tt.host, tt.allowed, got, tt.want)
+		}
+		restore()
+	}
+}
+
+func stubAllowedHosts(hosts []string) func() {
+	orig := AllowedHosts
+	AllowedHosts = hosts
+	return func() {
+		AllowedHosts = orig
+	}
+}
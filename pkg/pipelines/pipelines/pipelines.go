@@ -16,8 +16,9 @@ var (
 	pipelineTypeMeta = meta.TypeMeta("Pipeline", "tekton.dev/v1beta1")
 )
 
-// CreateAppCIPipeline creates AppCIPipeline
-func CreateAppCIPipeline(name types.NamespacedName) *pipelinev1.Pipeline {
+// CreateAppCIPipeline creates AppCIPipeline, the image is built with the
+// buildTask ClusterTask.
+func CreateAppCIPipeline(name types.NamespacedName, buildTask string) *pipelinev1.Pipeline {
 	return &pipelinev1.Pipeline{
 		TypeMeta:   pipelineTypeMeta,
 		ObjectMeta: meta.ObjectMeta(name),
@@ -32,6 +33,7 @@ func CreateAppCIPipeline(name types.NamespacedName) *pipelinev1.Pipeline {
 				createParamSpec("COMMIT_AUTHOR", "string"),
 				createParamSpec("COMMIT_MESSAGE", "string"),
 				createParamSpec("GIT_REPO", "string"),
+				createParamSpecDefault("CONTEXT", "string", "."),
 			},
 			Resources: []pipelinev1.PipelineDeclaredResource{
 				createPipelineDeclaredResource("source-repo", "git"),
@@ -39,7 +41,7 @@ func CreateAppCIPipeline(name types.NamespacedName) *pipelinev1.Pipeline {
 			},
 
 			Tasks: []pipelinev1.PipelineTask{
-				createBuildImageTask("build-image"),
+				createBuildImageTask("build-image", buildTask),
 			},
 		},
 	}
@@ -49,7 +51,12 @@ func createParamSpec(name string, paramType pipelinev1.ParamType) pipelinev1.Par
 	return pipelinev1.ParamSpec{Name: name, Type: paramType}
 }
 
-func createBuildImageTask(name string) pipelinev1.PipelineTask {
+func createParamSpecDefault(name string, paramType pipelinev1.ParamType, value string) pipelinev1.ParamSpec {
+	defaultValue := pipelinev1.NewArrayOrString(value)
+	return pipelinev1.ParamSpec{Name: name, Type: paramType, Default: &defaultValue}
+}
+
+func createBuildImageTask(name, buildTask string) pipelinev1.PipelineTask {
 	labels := map[string]string{
 		triggers.GitCommitID:      "$(params.COMMIT_SHA)",
 		triggers.GitRef:           "$(params.GIT_REF)",
@@ -65,7 +72,7 @@ func createBuildImageTask(name string) pipelinev1.PipelineTask {
 
 	return pipelinev1.PipelineTask{
 		Name:    name,
-		TaskRef: createTaskRef("buildah", pipelinev1.ClusterTaskKind),
+		TaskRef: createTaskRef(buildTask, pipelinev1.ClusterTaskKind),
 		Resources: &pipelinev1.PipelineTaskResources{
 			Inputs:  []pipelinev1.PipelineTaskInputResource{createInputTaskResource("source", "source-repo")},
 			Outputs: []pipelinev1.PipelineTaskOutputResource{createOutputTaskResource("image", "runtime-image")},
@@ -73,6 +80,7 @@ func createBuildImageTask(name string) pipelinev1.PipelineTask {
 		Params: []pipelinev1.Param{
 			createTaskParam("TLSVERIFY", "$(params.TLSVERIFY)"),
 			createTaskParam("BUILD_EXTRA_ARGS", strings.Join(labelArgs, " ")),
+			createTaskParam("CONTEXT", "$(params.CONTEXT)"),
 		},
 	}
 
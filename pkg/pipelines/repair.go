@@ -0,0 +1,187 @@
+package pipelines
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/spf13/afero"
+
+	"github.com/rhd-gitops-example/gitops-cli/pkg/pipelines/config"
+	"github.com/rhd-gitops-example/gitops-cli/pkg/pipelines/ioutils"
+	res "github.com/rhd-gitops-example/gitops-cli/pkg/pipelines/resources"
+	"github.com/rhd-gitops-example/gitops-cli/pkg/pipelines/yaml"
+)
+
+// RepairParameters is a struct that provides flags for the Repair command.
+type RepairParameters struct {
+	PipelinesFolderPath string
+	OutputPath          string
+	UseApplicationSet   bool // Generate an ApplicationSet rather than individual Applications.
+}
+
+// RepairReport describes how the files in the OutputPath differ from the files
+// generated from the pipelines.yaml, the paths are relative to the OutputPath.
+type RepairReport struct {
+	Missing  []string // Generated files that don't exist.
+	Drifted  []string // Generated files that exist with different content.
+	Orphaned []string // Files for environments, apps and services that are not in the pipelines.yaml.
+}
+
+// Clean returns true if the files on disk match the pipelines.yaml.
+func (r *RepairReport) Clean() bool {
+	return len(r.Missing) == 0 && len(r.Drifted) == 0 && len(r.Orphaned) == 0
+}
+
+// Diagnose compares the files that would be generated from the pipelines.yaml
+// with the files in the OutputPath.
+func Diagnose(o *RepairParameters, appFs afero.Fs) (*RepairReport, error) {
+	report, _, err := diagnose(o, appFs)
+	return report, err
+}
+
+// Repair regenerates the missing and drifted files, and if removeOrphans is
+// true, removes the orphaned files.
+//
+// It returns the report of the differences that were repaired.
+func Repair(o *RepairParameters, appFs afero.Fs, removeOrphans bool) (*RepairReport, error) {
+	report, resources, err := diagnose(o, appFs)
+	if err != nil {
+		return nil, err
+	}
+	repairs := res.Resources{}
+	for _, f := range append(report.Missing, report.Drifted...) {
+		repairs[f] = resources[f]
+	}
+	if _, err := yaml.WriteResources(appFs, o.OutputPath, repairs); err != nil {
+		return nil, err
+	}
+	if !removeOrphans {
+		return report, nil
+	}
+	for _, f := range report.Orphaned {
+		if err := appFs.Remove(filepath.Join(o.OutputPath, f)); err != nil {
+			return nil, fmt.Errorf("failed to remove orphaned file %s: %w", f, err)
+		}
+	}
+	return report, nil
+}
+
+func diagnose(o *RepairParameters, appFs afero.Fs) (*RepairReport, res.Resources, error) {
+	if err := ioutils.ValidateOutputPath(appFs, o.OutputPath, ""); err != nil {
+		return nil, nil, err
+	}
+	m, err := config.LoadManifest(appFs, o.PipelinesFolderPath)
+	if err != nil {
+		return nil, nil, err
+	}
+	buildParams := &BuildParameters{
+		PipelinesFolderPath: o.PipelinesFolderPath,
+		OutputPath:          o.OutputPath,
+		UseApplicationSet:   o.UseApplicationSet,
+	}
+	resources, err := buildResources(appFs, buildParams, m)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to build resources: %v", err)
+	}
+
+	report := &RepairReport{Missing: []string{}, Drifted: []string{}}
+	for _, f := range getResourceFiles(resources) {
+		existing, err := afero.ReadFile(appFs, filepath.Join(o.OutputPath, f))
+		if os.IsNotExist(err) {
+			report.Missing = append(report.Missing, f)
+			continue
+		}
+		if err != nil {
+			return nil, nil, err
+		}
+		var generated bytes.Buffer
+		if err := yaml.MarshalOutput(&generated, resources[f]); err != nil {
+			return nil, nil, err
+		}
+		if !bytes.Equal(existing, generated.Bytes()) {
+			report.Drifted = append(report.Drifted, f)
+		}
+	}
+	report.Orphaned, err = orphanedFiles(appFs, o.OutputPath, m, resources)
+	if err != nil {
+		return nil, nil, err
+	}
+	return report, resources, nil
+}
+
+// orphanedFiles returns the files in the environments folder that belong to an
+// environment, app or service that is not in the manifest, and the files in
+// the GitOps engine's config folder that are no longer generated.
+func orphanedFiles(appFs afero.Fs, outputPath string, m *config.Manifest, generated res.Resources) ([]string, error) {
+	known := map[string]bool{}
+	for _, env := range m.Environments {
+		known[config.PathForEnvironment(env)] = true
+		for _, app := range env.Apps {
+			known[config.PathForApplication(env, app)] = true
+			for _, svc := range app.Services {
+				known[config.PathForService(app, env, svc.Name)] = true
+			}
+		}
+	}
+
+	orphaned := []string{}
+	for _, dir := range []string{"environments", config.PathForArgoCD(), config.PathForFlux()} {
+		root := filepath.Join(outputPath, dir)
+		exists, err := afero.DirExists(appFs, root)
+		if err != nil {
+			return nil, err
+		}
+		if !exists {
+			continue
+		}
+		err = afero.Walk(appFs, root, func(path string, info os.FileInfo, err error) error {
+			if err != nil || info.IsDir() {
+				return err
+			}
+			rel, err := filepath.Rel(outputPath, path)
+			if err != nil {
+				return err
+			}
+			if dir == "environments" && ownerKnown(rel, known) {
+				return nil
+			}
+			if _, ok := generated[rel]; ok {
+				return nil
+			}
+			orphaned = append(orphaned, rel)
+			return nil
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to list the files in %s: %w", root, err)
+		}
+	}
+	sort.Strings(orphaned)
+	return orphaned, nil
+}
+
+// ownerKnown returns true if the environment, app and service in the path
+// are all known.
+//
+// e.g. for environments/dev/apps/taxi/services/gateway/base/config/100-deployment.yaml
+// environments/dev, environments/dev/apps/taxi and
+// environments/dev/apps/taxi/services/gateway must be known.
+func ownerKnown(path string, known map[string]bool) bool {
+	parts := strings.Split(filepath.ToSlash(path), "/")
+	// The owners are at environments/<env>, apps/<app> and services/<svc>.
+	for _, n := range []int{2, 4, 6} {
+		if len(parts) <= n {
+			return true
+		}
+		if n > 2 && parts[n-2] != "apps" && parts[n-2] != "services" {
+			return true
+		}
+		if !known[filepath.Join(parts[:n]...)] {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,86 @@
+package pipelines
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/spf13/afero"
+)
+
+func TestDiagnoseWithNoDrift(t *testing.T) {
+	fakeFs, gitopsPath := manifestFileFixture(t, "testdata/generate/pipelines.yaml")
+	params := &RepairParameters{PipelinesFolderPath: gitopsPath, OutputPath: gitopsPath}
+	_, _, err := Generate(&GenerateParameters{PipelinesFolderPath: gitopsPath, OutputPath: gitopsPath}, fakeFs)
+	assertNoError(t, err)
+
+	report, err := Diagnose(params, fakeFs)
+	assertNoError(t, err)
+
+	if !report.Clean() {
+		t.Fatalf("Diagnose() got %#v, want a clean report", report)
+	}
+}
+
+func TestRepairConvergesDrift(t *testing.T) {
+	fakeFs, gitopsPath := manifestFileFixture(t, "testdata/generate/pipelines.yaml")
+	params := &RepairParameters{PipelinesFolderPath: gitopsPath, OutputPath: gitopsPath}
+	_, _, err := Generate(&GenerateParameters{PipelinesFolderPath: gitopsPath, OutputPath: gitopsPath}, fakeFs)
+	assertNoError(t, err)
+
+	drifted := "environments/dev/env/base/dev-environment.yaml"
+	missing := "environments/stage/env/overlays/kustomization.yaml"
+	orphaned := []string{
+		"environments/dev/apps/bus/base/kustomization.yaml",
+		"environments/old/env/base/old-environment.yaml",
+	}
+	assertNoError(t, afero.WriteFile(fakeFs, filepath.Join(gitopsPath, drifted), []byte("kind: Namespace\n"), 0644))
+	assertNoError(t, fakeFs.Remove(filepath.Join(gitopsPath, missing)))
+	for _, f := range orphaned {
+		assertNoError(t, afero.WriteFile(fakeFs, filepath.Join(gitopsPath, f), []byte("kind: Kustomization\n"), 0644))
+	}
+	// Files for known environments, that aren't generated, are left alone.
+	assertNoError(t, afero.WriteFile(fakeFs, filepath.Join(gitopsPath, "environments/dev/env/base/extra.yaml"), []byte("kind: ConfigMap\n"), 0644))
+
+	want := &RepairReport{
+		Missing:  []string{missing},
+		Drifted:  []string{drifted},
+		Orphaned: orphaned,
+	}
+	report, err := Diagnose(params, fakeFs)
+	assertNoError(t, err)
+	if diff := cmp.Diff(want, report); diff != "" {
+		t.Fatalf("Diagnose() failed:\n%s", diff)
+	}
+
+	report, err = Repair(params, fakeFs, true)
+	assertNoError(t, err)
+	if diff := cmp.Diff(want, report); diff != "" {
+		t.Fatalf("Repair() failed:\n%s", diff)
+	}
+
+	report, err = Diagnose(params, fakeFs)
+	assertNoError(t, err)
+	if !report.Clean() {
+		t.Fatalf("Diagnose() after Repair() got %#v, want a clean report", report)
+	}
+	assertFileExists(t, fakeFs, filepath.Join(gitopsPath, "environments/dev/env/base/extra.yaml"))
+}
+
+func TestRepairKeepsOrphans(t *testing.T) {
+	fakeFs, gitopsPath := manifestFileFixture(t, "testdata/generate/pipelines.yaml")
+	params := &RepairParameters{PipelinesFolderPath: gitopsPath, OutputPath: gitopsPath}
+	orphan := filepath.Join(gitopsPath, "environments/old/env/base/old-environment.yaml")
+	assertNoError(t, afero.WriteFile(fakeFs, orphan, []byte("kind: Namespace\n"), 0644))
+
+	report, err := Repair(params, fakeFs, false)
+	assertNoError(t, err)
+
+	if diff := cmp.Diff(generatedTree, report.Missing); diff != "" {
+		t.Fatalf("Repair() missing files failed:\n%s", diff)
+	}
+	for _, f := range generatedTree {
+		assertFileExists(t, fakeFs, filepath.Join(gitopsPath, f))
+	}
+	assertFileExists(t, fakeFs, orphan)
+}
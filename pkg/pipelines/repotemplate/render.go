@@ -0,0 +1,131 @@
+package repotemplate
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"text/template"
+)
+
+// The variables that are always available to the templated files, these can't
+// be replaced by user provided variables.
+const (
+	PrefixVar       = "Prefix"
+	OrgVar          = "Org"
+	EnvironmentsVar = "Environments"
+)
+
+var varNameRE = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// ValidateVarName checks that the name can be used as a template variable,
+// it must be a Go identifier, so that it can be referenced as {{ .Name }},
+// and can't be one of the built-in variables.
+func ValidateVarName(name string) error {
+	if !varNameRE.MatchString(name) {
+		return fmt.Errorf("invalid template variable name %q, it must start with a letter or underscore, followed by letters, digits or underscores", name)
+	}
+	switch name {
+	case PrefixVar, OrgVar, EnvironmentsVar:
+		return fmt.Errorf("the template variable %q is built-in and can't be replaced", name)
+	}
+	return nil
+}
+
+// TemplateSuffix is the suffix of the files that are always rendered, it's
+// removed from the rendered file's path.
+const TemplateSuffix = ".tmpl"
+
+// RenderOptions controls which files are rendered.
+type RenderOptions struct {
+	All          bool // If true, every text file is rendered, not only the files with the TemplateSuffix.
+	AllowMissing bool // If true, files that can't be rendered are copied unrendered, rather than failing.
+}
+
+// Render executes the files as Go templates with the vars, and returns the
+// rendered files.
+//
+// Only the files with the TemplateSuffix are rendered, unless All is set, so
+// that files with their own {{ }} syntax, e.g. Helm charts or GitHub Actions
+// workflows, are copied unchanged.  Binary files and files without template
+// actions are never rendered.
+//
+// Templates that can't be parsed, or reference variables that are not
+// defined, are an error, unless AllowMissing is set, in which case the file
+// is returned unrendered, and its path is returned in the skipped files, so
+// that the placeholders can be filled in later.
+func Render(files map[string][]byte, vars map[string]interface{}, o RenderOptions) (map[string][]byte, []string, error) {
+	rendered := map[string][]byte{}
+	skipped := []string{}
+	for k, v := range files {
+		if !strings.HasSuffix(k, TemplateSuffix) && (!o.All || isBinary(v)) {
+			rendered[k] = v
+			continue
+		}
+		name := strings.TrimSuffix(k, TemplateSuffix)
+		if !bytes.Contains(v, []byte("{{")) {
+			rendered[name] = v
+			continue
+		}
+		b, err := render(k, v, vars)
+		if err != nil {
+			if o.AllowMissing && (isParseError(err) || isMissingVar(err)) {
+				rendered[k] = v
+				skipped = append(skipped, k)
+				continue
+			}
+			return nil, nil, fmt.Errorf("failed to render template file %s, use --allow-missing-vars to copy files that can't be rendered unrendered: %w", k, err)
+		}
+		rendered[name] = b
+	}
+	sort.Strings(skipped)
+	return rendered, skipped, nil
+}
+
+func render(name string, body []byte, vars map[string]interface{}) ([]byte, error) {
+	tmpl, err := template.New(name).Option("missingkey=error").Parse(string(body))
+	if err != nil {
+		return nil, parseError{err}
+	}
+	var b bytes.Buffer
+	if err := tmpl.Execute(&b, vars); err != nil {
+		return nil, err
+	}
+	return b.Bytes(), nil
+}
+
+// parseError wraps the errors parsing a template, to distinguish them from
+// errors executing it.
+type parseError struct {
+	err error
+}
+
+func (e parseError) Error() string {
+	return e.err.Error()
+}
+
+func (e parseError) Unwrap() error {
+	return e.err
+}
+
+func isParseError(err error) bool {
+	var pe parseError
+	return errors.As(err, &pe)
+}
+
+// isBinary returns true if the file looks binary, using the same check as Git,
+// a NUL byte in the first 8000 bytes.
+func isBinary(b []byte) bool {
+	if len(b) > 8000 {
+		b = b[:8000]
+	}
+	return bytes.IndexByte(b, 0) != -1
+}
+
+// isMissingVar returns true if the error executing a template is a reference
+// to a variable that's not in the vars.
+func isMissingVar(err error) bool {
+	return strings.Contains(err.Error(), "map has no entry for key")
+}
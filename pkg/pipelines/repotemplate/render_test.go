@@ -0,0 +1,104 @@
+package repotemplate
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/rhd-gitops-example/gitops-cli/pkg/pipelines/helper"
+)
+
+func TestRenderWithMissingVars(t *testing.T) {
+	files := map[string][]byte{
+		"team.yaml":  []byte("team: {{ .TeamName }}\n"),
+		"owner.yaml": []byte("owner: {{ .Owner }}\n"),
+	}
+	vars := map[string]interface{}{"TeamName": "payments"}
+
+	_, _, err := Render(files, vars, RenderOptions{All: true})
+	helper.AssertErrorMatch(t, `failed to render template file owner.yaml.*map has no entry for key "Owner"`, err)
+
+	rendered, skipped, err := Render(files, vars, RenderOptions{All: true, AllowMissing: true})
+	helper.AssertErrorMatch(t, "", err)
+	want := map[string][]byte{
+		"team.yaml":  []byte("team: payments\n"),
+		"owner.yaml": []byte("owner: {{ .Owner }}\n"),
+	}
+	if diff := cmp.Diff(want, rendered); diff != "" {
+		t.Fatalf("rendered files failed:\n%s", diff)
+	}
+	if diff := cmp.Diff([]string{"owner.yaml"}, skipped); diff != "" {
+		t.Fatalf("skipped files failed:\n%s", diff)
+	}
+}
+
+func TestRenderOnlyTemplateFiles(t *testing.T) {
+	files := map[string][]byte{
+		"README.md.tmpl":                []byte("# {{ .TeamName }}\n"),
+		"charts/app/templates/svc.yaml": []byte("name: {{ .Values.name }}\n"),
+		".github/workflows/ci.yaml":     []byte("token: ${{ secrets.TOKEN }}\n"),
+	}
+	vars := map[string]interface{}{"TeamName": "payments"}
+
+	rendered, skipped, err := Render(files, vars, RenderOptions{})
+	helper.AssertErrorMatch(t, "", err)
+
+	want := map[string][]byte{
+		"README.md":                     []byte("# payments\n"),
+		"charts/app/templates/svc.yaml": []byte("name: {{ .Values.name }}\n"),
+		".github/workflows/ci.yaml":     []byte("token: ${{ secrets.TOKEN }}\n"),
+	}
+	if diff := cmp.Diff(want, rendered); diff != "" {
+		t.Fatalf("rendered files failed:\n%s", diff)
+	}
+	if len(skipped) != 0 {
+		t.Fatalf("got skipped files %v, want none", skipped)
+	}
+}
+
+func TestRenderWithUnparseableFiles(t *testing.T) {
+	files := map[string][]byte{
+		".github/workflows/ci.yaml": []byte("token: ${{ secrets.TOKEN }}\n"),
+	}
+
+	_, _, err := Render(files, nil, RenderOptions{All: true})
+	helper.AssertErrorMatch(t, `failed to render template file .github/workflows/ci.yaml.*function "secrets" not defined`, err)
+
+	rendered, skipped, err := Render(files, nil, RenderOptions{All: true, AllowMissing: true})
+	helper.AssertErrorMatch(t, "", err)
+	if diff := cmp.Diff(files, rendered); diff != "" {
+		t.Fatalf("rendered files failed:\n%s", diff)
+	}
+	if diff := cmp.Diff([]string{".github/workflows/ci.yaml"}, skipped); diff != "" {
+		t.Fatalf("skipped files failed:\n%s", diff)
+	}
+}
+
+func TestRenderSkipsBinaryFiles(t *testing.T) {
+	files := map[string][]byte{
+		"logo.png": []byte("\x89PNG\x00{{ .Missing }"),
+	}
+
+	rendered, _, err := Render(files, nil, RenderOptions{All: true})
+	helper.AssertErrorMatch(t, "", err)
+	if diff := cmp.Diff(files, rendered); diff != "" {
+		t.Fatalf("rendered files failed:\n%s", diff)
+	}
+}
+
+func TestValidateVarName(t *testing.T) {
+	tests := []struct {
+		name    string
+		wantErr string
+	}{
+		{"TeamName", ""},
+		{"team_name", ""},
+		{"team-name", `invalid template variable name "team-name"`},
+		{"Prefix", `the template variable "Prefix" is built-in`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			helper.AssertErrorMatch(t, tt.wantErr, ValidateVarName(tt.name))
+		})
+	}
+}
@@ -0,0 +1,228 @@
+package repotemplate
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"net/url"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/rhd-gitops-example/gitops-cli/pkg/pipelines/network"
+)
+
+// lfsPointerPrefix is the first line of every Git LFS pointer file.
+const lfsPointerPrefix = "version https://git-lfs.github.com/spec/v1\n"
+
+// tokenEnvVar is the environment variable that the credential helper reads
+// the token from.
+const tokenEnvVar = "GITOPS_TEMPLATE_TOKEN"
+
+// credentialHelper is a git credential helper that provides the token from
+// the tokenEnvVar as the password, it doesn't store or erase credentials.
+const credentialHelper = `!f() { if test "$1" = get; then echo username=oauth2; echo "password=$` + tokenEnvVar + `"; fi; }; f`
+
+// Options configures the clone of the template repository.
+type Options struct {
+	Token            string // If provided, it's used to authenticate clones of HTTP(S) repositories.
+	AllowLFSPointers bool   // If true, Git LFS pointer files are returned rather than failing.
+
+	// KnownHostsFile is a known_hosts file that's used to verify the host keys
+	// of SSH repositories, rather than the user's known_hosts.
+	KnownHostsFile string
+	// AcceptNewHostKeys accepts the keys of SSH hosts that are not
+	// already known, rather than failing, changed keys are always rejected.
+	AcceptNewHostKeys bool
+}
+
+// Files clones the template repository and returns the files within it,
+// keyed by their path relative to the root of the repository.
+//
+// Git LFS objects are not fetched, if the repository tracks files with LFS,
+// an error is returned unless AllowLFSPointers is set, in which case the
+// pointer files are returned in place of the content.
+func Files(repoURL string, o Options) (map[string][]byte, error) {
+	credentials, err := credentialEnv(repoURL, o.Token)
+	if err != nil {
+		return nil, err
+	}
+	// The clone is run by git, so it isn't affected by the network package's
+	// dialer, the host is checked here instead, local repositories can always
+	// be cloned.
+	if !isLocal(repoURL) {
+		if err := network.CheckHost(repoHost(repoURL)); err != nil {
+			return nil, fmt.Errorf("failed to clone template repository %q: %w", repoURL, err)
+		}
+	}
+	dir, err := ioutil.TempDir("", "gitops-template")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create a directory to clone the template: %w", err)
+	}
+	defer os.RemoveAll(dir)
+
+	cmd := exec.Command("git", "clone", "--quiet", "--depth", "1", repoURL, dir)
+	// Skip the LFS smudge filter if git-lfs is installed, so that the pointers
+	// are consistently cloned, rather than failing on unreachable objects.
+	cmd.Env = append(os.Environ(), "GIT_LFS_SKIP_SMUDGE=1", "GIT_TERMINAL_PROMPT=0", "GIT_SSH_COMMAND="+sshCommand(o))
+	cmd.Env = append(cmd.Env, credentials...)
+	out, err := cmd.CombinedOutput()
+	if err != nil {
+		// The token isn't expected in the output, but it's redacted in case
+		// the remote echoes it.
+		return nil, fmt.Errorf("failed to clone template repository %q: %s", repoURL, redact(string(out), o.Token))
+	}
+
+	files := map[string][]byte{}
+	err = filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if info.IsDir() {
+			if info.Name() == ".git" {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		rel, err := filepath.Rel(dir, path)
+		if err != nil {
+			return err
+		}
+		b, err := ioutil.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		files[filepath.ToSlash(rel)] = b
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to read template repository %q: %w", repoURL, err)
+	}
+	if pointers := lfsPointers(files); len(pointers) > 0 && !o.AllowLFSPointers {
+		return nil, fmt.Errorf("template repository %q contains Git LFS files which are not supported: %s, use --allow-lfs-pointers to copy the pointer files", repoURL, strings.Join(pointers, ", "))
+	}
+	return files, nil
+}
+
+// lfsPointers returns the sorted paths of the Git LFS pointer files, if the
+// .gitattributes in the root of the repository tracks files with LFS.
+func lfsPointers(files map[string][]byte) []string {
+	if !bytes.Contains(files[".gitattributes"], []byte("filter=lfs")) {
+		return nil
+	}
+	pointers := []string{}
+	for k, v := range files {
+		if bytes.HasPrefix(v, []byte(lfsPointerPrefix)) {
+			pointers = append(pointers, k)
+		}
+	}
+	sort.Strings(pointers)
+	return pointers
+}
+
+// sshCommand returns the ssh command for git to use, host keys are always
+// checked, and the command never prompts.
+//
+// The options are appended to the user's GIT_SSH_COMMAND if it's set, so that
+// its own options, e.g. the identity file, are kept.
+func sshCommand(o Options) string {
+	checking := "yes"
+	if o.AcceptNewHostKeys {
+		checking = "accept-new"
+	}
+	cmd := os.Getenv("GIT_SSH_COMMAND")
+	if cmd == "" {
+		cmd = "ssh"
+	}
+	cmd += " -o BatchMode=yes -o StrictHostKeyChecking=" + checking
+	if o.KnownHostsFile != "" {
+		cmd += " -o UserKnownHostsFile=" + shellQuote(o.KnownHostsFile)
+	}
+	return cmd
+}
+
+// shellQuote quotes s for the shell, as git runs the GIT_SSH_COMMAND with it.
+func shellQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
+
+// credentialEnv returns the environment for git to authenticate clones of
+// HTTP(S) repositories with the token.
+//
+// The token is read by a credential helper from the environment, rather than
+// being in the clone URL, so that it's never in the arguments of a process,
+// which other users can see.  The user's credential helpers are replaced, so
+// that the token isn't stored by them.
+func credentialEnv(repoURL, token string) ([]string, error) {
+	if _, ok := scpHost(repoURL); ok {
+		return nil, nil
+	}
+	u, err := url.Parse(repoURL)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse template repository URL %q: %w", repoURL, err)
+	}
+	if token == "" || (u.Scheme != "http" && u.Scheme != "https") {
+		return nil, nil
+	}
+	return []string{
+		"GIT_CONFIG_COUNT=2",
+		"GIT_CONFIG_KEY_0=credential.helper",
+		"GIT_CONFIG_VALUE_0=",
+		"GIT_CONFIG_KEY_1=credential.helper",
+		"GIT_CONFIG_VALUE_1=" + credentialHelper,
+		tokenEnvVar + "=" + token,
+	}, nil
+}
+
+func isLocal(repoURL string) bool {
+	if _, ok := scpHost(repoURL); ok {
+		return false
+	}
+	u, err := url.Parse(repoURL)
+	if err != nil {
+		return false
+	}
+	return u.Scheme == "" || u.Scheme == "file"
+}
+
+// repoHost returns the host of the repository URL.
+func repoHost(repoURL string) string {
+	if host, ok := scpHost(repoURL); ok {
+		return host
+	}
+	u, err := url.Parse(repoURL)
+	if err != nil {
+		return repoURL
+	}
+	return u.Hostname()
+}
+
+// scpHost returns the host of an scp-like repository address, for example
+// git@github.com:org/template.git, which git clones over SSH, but which isn't
+// a URL.
+//
+// As with git, it's only scp-like if there's no scheme, and the first colon
+// comes before any slash.
+func scpHost(repoURL string) (string, bool) {
+	if strings.Contains(repoURL, "://") {
+		return "", false
+	}
+	colon := strings.Index(repoURL, ":")
+	if colon < 1 || strings.Contains(repoURL[:colon], "/") {
+		return "", false
+	}
+	host := repoURL[:colon]
+	if i := strings.LastIndex(host, "@"); i >= 0 {
+		host = host[i+1:]
+	}
+	return host, true
+}
+
+func redact(s, token string) string {
+	if token == "" {
+		return s
+	}
+	return strings.ReplaceAll(s, token, "<redacted>")
+}
@@ -0,0 +1,398 @@
+package repotemplate
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"io"
+	"io/ioutil"
+	"net"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"golang.org/x/crypto/ssh"
+	"golang.org/x/crypto/ssh/knownhosts"
+
+	"github.com/rhd-gitops-example/gitops-cli/pkg/pipelines/helper"
+	"github.com/rhd-gitops-example/gitops-cli/pkg/pipelines/network"
+)
+
+func TestFiles(t *testing.T) {
+	repoURL, cleanup := makeBareRepository(t, map[string]string{
+		"README.md":             "# GitOps\n",
+		"environments/.gitkeep": "",
+	})
+	defer cleanup()
+
+	files, err := Files(repoURL, Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := map[string][]byte{
+		"README.md":             []byte("# GitOps\n"),
+		"environments/.gitkeep": []byte(""),
+	}
+	if diff := cmp.Diff(want, files); diff != "" {
+		t.Fatalf("Files() failed:\n%s", diff)
+	}
+}
+
+func TestFilesWithLFSPointers(t *testing.T) {
+	pointer := "version https://git-lfs.github.com/spec/v1\n" +
+		"oid sha256:4d7a214614ab2935c943f9e0ff69d22eadbb8f32b1258daaa5e2ca24d17e2393\n" +
+		"size 12345\n"
+	repoURL, cleanup := makeBareRepository(t, map[string]string{
+		".gitattributes": "*.bin filter=lfs diff=lfs merge=lfs -text\n",
+		"README.md":      "# GitOps\n",
+		"logo.bin":       pointer,
+	})
+	defer cleanup()
+
+	_, err := Files(repoURL, Options{})
+	helper.AssertErrorMatch(t, "contains Git LFS files which are not supported: logo.bin, use --allow-lfs-pointers", err)
+
+	files, err := Files(repoURL, Options{AllowLFSPointers: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(files["logo.bin"]) != pointer {
+		t.Fatalf("Files() got %q for logo.bin, want the pointer file", files["logo.bin"])
+	}
+}
+
+func TestFilesWithMissingRepository(t *testing.T) {
+	_, err := Files(filepath.Join(os.TempDir(), "unknown-template.git"), Options{})
+	helper.AssertErrorMatch(t, "failed to clone template repository", err)
+}
+
+func TestFilesWithNoNetwork(t *testing.T) {
+	defer func(d bool) {
+		network.Disabled = d
+	}(network.Disabled)
+	network.Disabled = true
+
+	_, err := Files("https://github.com/org/template.git", Options{})
+	helper.AssertErrorMatch(t, `failed to clone template repository "https://github.com/org/template.git": network disabled`, err)
+}
+
+func TestFilesWithDisallowedHost(t *testing.T) {
+	defer func(h []string) {
+		network.AllowedHosts = h
+	}(network.AllowedHosts)
+	network.AllowedHosts = []string{"gitlab.com"}
+
+	_, err := Files("ssh://git@github.com/org/template.git", Options{})
+	helper.AssertErrorMatch(t, `failed to clone template repository "ssh://git@github.com/org/template.git": host github.com not in allowlist`, err)
+
+	_, err = Files("git@github.com:org/template.git", Options{})
+	helper.AssertErrorMatch(t, `failed to clone template repository "git@github.com:org/template.git": host github.com not in allowlist`, err)
+}
+
+func TestFilesOverSSH(t *testing.T) {
+	repoURL, cleanup := makeBareRepository(t, map[string]string{
+		"README.md": "# GitOps\n",
+	})
+	defer cleanup()
+	addr, hostKey, stop := startSSHServer(t)
+	defer stop()
+	otherKey, err := ssh.NewSignerFromKey(mustGenerateKey(t))
+	if err != nil {
+		t.Fatal(err)
+	}
+	sshURL := "ssh://git@" + addr + repoURL
+
+	sshTests := []struct {
+		name      string
+		knownKey  ssh.PublicKey
+		acceptNew bool
+		wantErr   bool
+	}{
+		{"matching host key", hostKey, false, false},
+		{"mismatched host key", otherKey.PublicKey(), false, true},
+		{"mismatched host key accepting new keys", otherKey.PublicKey(), true, true},
+		{"unknown host", nil, false, true},
+		{"unknown host accepting new keys", nil, true, false},
+	}
+
+	for _, tt := range sshTests {
+		t.Run(tt.name, func(rt *testing.T) {
+			knownHosts := filepath.Join(filepath.Dir(repoURL), strings.ReplaceAll(tt.name, " ", "-")+"-known_hosts")
+			content := ""
+			if tt.knownKey != nil {
+				content = knownhosts.Line([]string{knownhosts.Normalize(addr)}, tt.knownKey) + "\n"
+			}
+			if err := ioutil.WriteFile(knownHosts, []byte(content), 0600); err != nil {
+				rt.Fatal(err)
+			}
+
+			files, err := Files(sshURL, Options{KnownHostsFile: knownHosts, AcceptNewHostKeys: tt.acceptNew})
+			if tt.wantErr {
+				helper.AssertErrorMatch(rt, "Host key verification failed", err)
+				return
+			}
+			if err != nil {
+				rt.Fatal(err)
+			}
+			if string(files["README.md"]) != "# GitOps\n" {
+				rt.Fatalf("Files() got %q for README.md", files["README.md"])
+			}
+		})
+	}
+}
+
+func TestFilesOverSSHWithSCPAddress(t *testing.T) {
+	repoURL, cleanup := makeBareRepository(t, map[string]string{
+		"README.md": "# GitOps\n",
+	})
+	defer cleanup()
+	addr, hostKey, stop := startSSHServer(t)
+	defer stop()
+	host, port, err := net.SplitHostPort(addr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	knownHosts := filepath.Join(filepath.Dir(repoURL), "known_hosts")
+	if err := ioutil.WriteFile(knownHosts, []byte(knownhosts.Line([]string{knownhosts.Normalize(addr)}, hostKey)+"\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	// An scp-like address has no port, the user's GIT_SSH_COMMAND provides it.
+	defer stubEnv(t, "GIT_SSH_COMMAND", "ssh -p "+port)()
+
+	files, err := Files("git@"+host+":"+repoURL, Options{KnownHostsFile: knownHosts})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(files["README.md"]) != "# GitOps\n" {
+		t.Fatalf("Files() got %q for README.md", files["README.md"])
+	}
+}
+
+func TestSSHCommand(t *testing.T) {
+	cmdTests := []struct {
+		userCommand string
+		options     Options
+		want        string
+	}{
+		{"", Options{}, "ssh -o BatchMode=yes -o StrictHostKeyChecking=yes"},
+		{"", Options{AcceptNewHostKeys: true}, "ssh -o BatchMode=yes -o StrictHostKeyChecking=accept-new"},
+		{"", Options{KnownHostsFile: "/tmp/it's known_hosts"}, `ssh -o BatchMode=yes -o StrictHostKeyChecking=yes -o UserKnownHostsFile='/tmp/it'\''s known_hosts'`},
+		{"ssh -i ~/.ssh/deploy_key", Options{}, "ssh -i ~/.ssh/deploy_key -o BatchMode=yes -o StrictHostKeyChecking=yes"},
+	}
+
+	for _, tt := range cmdTests {
+		restore := stubEnv(t, "GIT_SSH_COMMAND", tt.userCommand)
+		if got := sshCommand(tt.options); got != tt.want {
+			t.Errorf("sshCommand(%#v) with GIT_SSH_COMMAND %q got %s, want %s", tt.options, tt.userCommand, got, tt.want)
+		}
+		restore()
+	}
+}
+
+func TestCredentialEnv(t *testing.T) {
+	env, err := credentialEnv("/tmp/template.git", "abc123")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if env != nil {
+		t.Fatalf("got credentials for a local repository: %v", env)
+	}
+
+	env, err = credentialEnv("https://github.com/org/template.git", "abc123")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, v := range env {
+		if strings.Contains(v, "abc123") && !strings.HasPrefix(v, tokenEnvVar+"=") {
+			t.Fatalf("token is outside of the %s environment variable: %s", tokenEnvVar, v)
+		}
+	}
+
+	env, err = credentialEnv("git@github.com:org/template.git", "abc123")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if env != nil {
+		t.Fatalf("got credentials for an scp-like SSH repository: %v", env)
+	}
+
+	// git asks the credential helper for the credentials of the repository.
+	cmd := exec.Command("git", "credential", "fill")
+	cmd.Env = append(os.Environ(), env...)
+	cmd.Stdin = strings.NewReader("protocol=https\nhost=github.com\n\n")
+	out, err := cmd.Output()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{"username=oauth2\n", "password=abc123\n"} {
+		if !strings.Contains(string(out), want) {
+			t.Fatalf("git credential fill got %q, want %q", out, want)
+		}
+	}
+}
+
+func TestRepoHost(t *testing.T) {
+	hostTests := []struct {
+		repoURL   string
+		wantHost  string
+		wantLocal bool
+	}{
+		{"https://github.com/org/template.git", "github.com", false},
+		{"ssh://git@github.com:2222/org/template.git", "github.com", false},
+		{"git@github.com:org/template.git", "github.com", false},
+		{"github.com:org/template.git", "github.com", false},
+		{"/tmp/template.git", "", true},
+		{"./templates/app:v1.git", "", true},
+		{"file:///tmp/template.git", "", true},
+	}
+
+	for _, tt := range hostTests {
+		if got := repoHost(tt.repoURL); got != tt.wantHost {
+			t.Errorf("repoHost(%q) got %q, want %q", tt.repoURL, got, tt.wantHost)
+		}
+		if got := isLocal(tt.repoURL); got != tt.wantLocal {
+			t.Errorf("isLocal(%q) got %v, want %v", tt.repoURL, got, tt.wantLocal)
+		}
+	}
+}
+
+// stubEnv sets the environment variable, and returns a function to restore
+// its previous value.
+func stubEnv(t *testing.T, name, value string) func() {
+	t.Helper()
+	orig, ok := os.LookupEnv(name)
+	if err := os.Setenv(name, value); err != nil {
+		t.Fatal(err)
+	}
+	return func() {
+		if ok {
+			os.Setenv(name, orig)
+			return
+		}
+		os.Unsetenv(name)
+	}
+}
+
+// makeBareRepository creates a bare git repository containing a commit with
+// the files, and returns the path to it, and a function to remove it.
+func makeBareRepository(t *testing.T, files map[string]string) (string, func()) {
+	t.Helper()
+	dir, err := ioutil.TempDir("", "template")
+	if err != nil {
+		t.Fatal(err)
+	}
+	work := filepath.Join(dir, "work")
+	for k, v := range files {
+		path := filepath.Join(work, k)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := ioutil.WriteFile(path, []byte(v), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	bare := filepath.Join(dir, "template.git")
+	git := func(args ...string) {
+		t.Helper()
+		cmd := exec.Command("git", append([]string{"-c", "user.name=test", "-c", "user.email=test@example.com"}, args...)...)
+		cmd.Dir = work
+		if out, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("git %v failed: %s", args, out)
+		}
+	}
+	git("init", "--quiet")
+	git("add", ".")
+	git("commit", "--quiet", "-m", "template")
+	git("clone", "--quiet", "--bare", work, bare)
+	return bare, func() {
+		os.RemoveAll(dir)
+	}
+}
+
+// startSSHServer starts an SSH server that accepts any client, and serves
+// git-upload-pack for repositories on the local filesystem, it returns the
+// address and host key of the server, and a function to stop it.
+func startSSHServer(t *testing.T) (string, ssh.PublicKey, func()) {
+	t.Helper()
+	signer, err := ssh.NewSignerFromKey(mustGenerateKey(t))
+	if err != nil {
+		t.Fatal(err)
+	}
+	cfg := &ssh.ServerConfig{NoClientAuth: true}
+	cfg.AddHostKey(signer)
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	go func() {
+		for {
+			conn, err := l.Accept()
+			if err != nil {
+				return
+			}
+			go serveSSH(conn, cfg)
+		}
+	}()
+	return l.Addr().String(), signer.PublicKey(), func() {
+		l.Close()
+	}
+}
+
+func serveSSH(conn net.Conn, cfg *ssh.ServerConfig) {
+	_, chans, reqs, err := ssh.NewServerConn(conn, cfg)
+	if err != nil {
+		return
+	}
+	go ssh.DiscardRequests(reqs)
+	for nc := range chans {
+		if nc.ChannelType() != "session" {
+			_ = nc.Reject(ssh.UnknownChannelType, "only sessions are supported")
+			continue
+		}
+		ch, requests, err := nc.Accept()
+		if err != nil {
+			continue
+		}
+		go serveUploadPack(ch, requests)
+	}
+}
+
+func serveUploadPack(ch ssh.Channel, requests <-chan *ssh.Request) {
+	defer ch.Close()
+	for req := range requests {
+		var payload struct{ Command string }
+		if req.Type != "exec" || ssh.Unmarshal(req.Payload, &payload) != nil || !strings.HasPrefix(payload.Command, "git-upload-pack ") {
+			_ = req.Reply(false, nil)
+			continue
+		}
+		_ = req.Reply(true, nil)
+		cmd := exec.Command("sh", "-c", payload.Command)
+		cmd.Stdout, cmd.Stderr = ch, ch.Stderr()
+		stdin, err := cmd.StdinPipe()
+		if err != nil {
+			return
+		}
+		go func() {
+			_, _ = io.Copy(stdin, ch)
+			stdin.Close()
+		}()
+		status := struct{ Status uint32 }{}
+		if err := cmd.Run(); err != nil {
+			status.Status = 1
+		}
+		_, _ = ch.SendRequest("exit-status", false, ssh.Marshal(&status))
+		return
+	}
+}
+
+func mustGenerateKey(t *testing.T) ed25519.PrivateKey {
+	t.Helper()
+	_, key, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return key
+}
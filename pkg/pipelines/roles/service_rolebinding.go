@@ -38,6 +38,13 @@ func AddSecretToSA(sa *corev1.ServiceAccount, secretName string) *corev1.Service
 	return sa
 }
 
+// AddImagePullSecretToSA allows the provided ServiceAccount to pull images with
+// the named secret.
+func AddImagePullSecretToSA(sa *corev1.ServiceAccount, secretName string) *corev1.ServiceAccount {
+	sa.ImagePullSecrets = append(sa.ImagePullSecrets, corev1.LocalObjectReference{Name: secretName})
+	return sa
+}
+
 // CreateRoleBinding creates and returns a new RoleBinding given name, sa, roleKind, and roleName
 func CreateRoleBinding(name types.NamespacedName, sa *corev1.ServiceAccount, roleKind, roleName string) *v1rbac.RoleBinding {
 	return CreateRoleBindingForSubjects(name, roleKind, roleName, []v1rbac.Subject{{Kind: sa.Kind, Name: sa.Name, Namespace: sa.Namespace}})
@@ -99,6 +99,22 @@ func GetDriverName(rawURL string) (string, error) {
 	return factory.DefaultIdentifier.Identify(host)
 }
 
+// NormalizeRepoURL returns the URL of a repository in a canonical form, so
+// that the same repository is recognised with or without credentials, a
+// trailing slash or the .git suffix, the host is compared case-insensitively.
+//
+// URLs that can't be parsed are returned unchanged.
+func NormalizeRepoURL(rawURL string) string {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return rawURL
+	}
+	u.User = nil
+	u.Host = strings.ToLower(u.Host)
+	u.Path = strings.TrimSuffix(strings.TrimSuffix(u.Path, "/"), ".git")
+	return u.String()
+}
+
 // HostnameFromURL returns the host from a URL.
 func HostnameFromURL(rawURL string) (string, error) {
 	u, err := url.Parse(rawURL)
@@ -1,7 +1,10 @@
 package secrets
 
 import (
+	"bytes"
+	"crypto/rand"
 	"crypto/rsa"
+	"encoding/base64"
 	"errors"
 	"fmt"
 	"io"
@@ -9,6 +12,7 @@ import (
 	"strings"
 
 	ssv1alpha1 "github.com/bitnami-labs/sealed-secrets/pkg/apis/sealed-secrets/v1alpha1"
+	"github.com/bitnami-labs/sealed-secrets/pkg/crypto"
 	"github.com/openshift/client-go/route/clientset/versioned/scheme"
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -56,6 +60,100 @@ func CreateSealedSecret(name, service types.NamespacedName, data, secretKey stri
 	return seal(secret, DefaultPublicKeyFunc, service)
 }
 
+// The scopes that a secret can be sealed in, these determine which Secrets the
+// sealed value can be unsealed for.
+const (
+	StrictScope        = "strict"         // Only a Secret with the same name and namespace.
+	NamespaceWideScope = "namespace-wide" // Any Secret in the same namespace.
+	ClusterWideScope   = "cluster-wide"   // Any Secret in any namespace.
+)
+
+// SealingScopes is the set of scopes that a secret can be sealed in.
+var SealingScopes = []string{StrictScope, NamespaceWideScope, ClusterWideScope}
+
+// IsSealingScope returns true if s is one of the SealingScopes.
+func IsSealingScope(s string) bool {
+	for _, v := range SealingScopes {
+		if s == v {
+			return true
+		}
+	}
+	return false
+}
+
+// CreateScopedSealedSecret creates a SealedSecret with the provided name and
+// body/data, sealed in the scope.
+func CreateScopedSealedSecret(name, service types.NamespacedName, data, secretKey, scope string) (*ssv1alpha1.SealedSecret, error) {
+	return createScopedSealedSecret(name, DefaultPublicKeyFunc, service, data, secretKey, scope)
+}
+
+// CreateScopedSealedSecrets creates a SealedSecret with the provided name and
+// body/data in each of the namespaces, sealed in the scope, the public key is
+// only fetched once for all the namespaces.
+func CreateScopedSealedSecrets(name string, namespaces []string, service types.NamespacedName, data, secretKey, scope string) ([]*ssv1alpha1.SealedSecret, error) {
+	key, err := DefaultPublicKeyFunc(service)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get public key from cluster (is sealed-secrets installed?): %v", err)
+	}
+	pubKey := func(types.NamespacedName) (*rsa.PublicKey, error) {
+		return key, nil
+	}
+	sealed := []*ssv1alpha1.SealedSecret{}
+	for _, ns := range namespaces {
+		s, err := createScopedSealedSecret(types.NamespacedName{Namespace: ns, Name: name}, pubKey, service, data, secretKey, scope)
+		if err != nil {
+			return nil, fmt.Errorf("failed to seal the secret for namespace %s: %w", ns, err)
+		}
+		sealed = append(sealed, s)
+	}
+	return sealed, nil
+}
+
+func createScopedSealedSecret(name types.NamespacedName, pubKey PublicKeyFunc, service types.NamespacedName, data, secretKey, scope string) (*ssv1alpha1.SealedSecret, error) {
+	secret, err := createOpaqueSecret(name, data, secretKey)
+	if err != nil {
+		return nil, err
+	}
+	switch scope {
+	case NamespaceWideScope:
+		secret.Annotations = map[string]string{ssv1alpha1.SealedSecretNamespaceWideAnnotation: "true"}
+	case ClusterWideScope:
+		secret.Annotations = map[string]string{ssv1alpha1.SealedSecretClusterWideAnnotation: "true"}
+	}
+	return seal(secret, pubKey, service)
+}
+
+// SealRaw encrypts the value for the Secret with the name, in the scope, and
+// returns the base64 encoded encrypted value, this is the equivalent of
+// kubeseal --raw, and can be used in the encryptedData of a SealedSecret.
+func SealRaw(name, service types.NamespacedName, value []byte, scope string) (string, error) {
+	return sealRaw(name, DefaultPublicKeyFunc, service, value, scope)
+}
+
+func sealRaw(name types.NamespacedName, pubKey PublicKeyFunc, service types.NamespacedName, value []byte, scope string) (string, error) {
+	key, err := pubKey(service)
+	if err != nil {
+		return "", fmt.Errorf("failed to get public key from cluster (is sealed-secrets installed?): %v", err)
+	}
+	ciphertext, err := crypto.HybridEncrypt(rand.Reader, key, value, encryptionLabel(name, scope))
+	if err != nil {
+		return "", err
+	}
+	return base64.StdEncoding.EncodeToString(ciphertext), nil
+}
+
+// encryptionLabel returns the label that the value is encrypted with, the
+// controller only decrypts the value for Secrets that match the label.
+func encryptionLabel(name types.NamespacedName, scope string) []byte {
+	switch scope {
+	case NamespaceWideScope:
+		return []byte(name.Namespace)
+	case ClusterWideScope:
+		return []byte("")
+	}
+	return []byte(fmt.Sprintf("%s/%s", name.Namespace, name.Name))
+}
+
 // CreateSealedBasicAuthSecret creates a SealedSecret with a BasicAuth type
 // secret.
 func CreateSealedBasicAuthSecret(name, service types.NamespacedName, token string, opts ...meta.ObjectMetaOpt) (*ssv1alpha1.SealedSecret, error) {
@@ -104,6 +202,32 @@ func GetClusterPublicKey(service types.NamespacedName) (*rsa.PublicKey, error) {
 	return parseKey(f)
 }
 
+// GetClusterCert retrieves the PEM encoded sealing certificate from the
+// sealed-secrets-service in the provided namespaced name.
+//
+// The certificate is parsed before it's returned, to ensure that it's usable
+// for sealing.
+func GetClusterCert(service types.NamespacedName) ([]byte, error) {
+	client, err := getRESTClient()
+	if err != nil {
+		return nil, err
+	}
+
+	f, err := openCertCluster(client, service)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	data, err := ioutil.ReadAll(f)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read certificate: %w", err)
+	}
+	if _, err := parseKey(bytes.NewReader(data)); err != nil {
+		return nil, err
+	}
+	return data, nil
+}
+
 // Returns a reader of public key from sealed-secrets-service
 func openCertCluster(c clientv1.CoreV1Interface, service types.NamespacedName) (io.ReadCloser, error) {
 	f, err := c.
@@ -3,6 +3,7 @@ package secrets
 import (
 	"bytes"
 	"crypto/rsa"
+	"encoding/base64"
 	"errors"
 	"fmt"
 	"math/big"
@@ -16,6 +17,7 @@ import (
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/yaml"
 
 	"github.com/rhd-gitops-example/gitops-cli/pkg/pipelines/helper"
 )
@@ -240,6 +242,96 @@ func TestSeal(t *testing.T) {
 	}
 }
 
+func TestSealRaw(t *testing.T) {
+	name := meta.NamespacedName("myns", "mysecret")
+	service := meta.NamespacedName("test-ns", "service")
+
+	for _, scope := range SealingScopes {
+		t.Run(scope, func(rt *testing.T) {
+			raw, err := sealRaw(name, makeTestCertFunc(service), service, []byte("sekret"), scope)
+			if err != nil {
+				rt.Fatal(err)
+			}
+			decoded, err := base64.StdEncoding.DecodeString(raw)
+			if err != nil {
+				rt.Fatalf("raw sealed value is not base64 encoded: %v", err)
+			}
+			if len(decoded) < 100 {
+				rt.Fatalf("raw sealed value is implausibly short: %v", raw)
+			}
+
+			secret, err := createOpaqueSecret(name, "sekret", "token")
+			if err != nil {
+				rt.Fatal(err)
+			}
+			sealed, err := seal(secret, makeTestCertFunc(service), service)
+			if err != nil {
+				rt.Fatal(err)
+			}
+			full, err := yaml.Marshal(sealed)
+			if err != nil {
+				rt.Fatal(err)
+			}
+			if raw == string(full) || strings.Contains(raw, "SealedSecret") {
+				rt.Fatalf("raw sealed value is the full SealedSecret: %s", raw)
+			}
+		})
+	}
+}
+
+func TestCreateScopedSealedSecretsForNamespaces(t *testing.T) {
+	service := meta.NamespacedName("test-ns", "service")
+	calls := 0
+	defer func(f PublicKeyFunc) {
+		DefaultPublicKeyFunc = f
+	}(DefaultPublicKeyFunc)
+	DefaultPublicKeyFunc = func(s types.NamespacedName) (*rsa.PublicKey, error) {
+		calls++
+		return makeTestCertFunc(service)(s)
+	}
+
+	sealed, err := CreateScopedSealedSecrets("github-auth", []string{"dev", "stage"}, service, "sekret", "token", StrictScope)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if calls != 1 {
+		t.Fatalf("the public key was fetched %d times, want 1", calls)
+	}
+	got := []types.NamespacedName{}
+	for _, s := range sealed {
+		got = append(got, types.NamespacedName{Namespace: s.Namespace, Name: s.Name})
+		if _, ok := s.Spec.EncryptedData["token"]; !ok {
+			t.Fatalf("SealedSecret %s/%s has no sealed token", s.Namespace, s.Name)
+		}
+		if s.Annotations[ssv1alpha1.SealedSecretNamespaceWideAnnotation] != "" || s.Annotations[ssv1alpha1.SealedSecretClusterWideAnnotation] != "" {
+			t.Fatalf("SealedSecret %s/%s isn't strictly scoped, got annotations %v", s.Namespace, s.Name, s.Annotations)
+		}
+	}
+	want := []types.NamespacedName{meta.NamespacedName("dev", "github-auth"), meta.NamespacedName("stage", "github-auth")}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Fatalf("SealedSecrets didn't match:\n%s", diff)
+	}
+}
+
+func TestEncryptionLabel(t *testing.T) {
+	name := meta.NamespacedName("myns", "mysecret")
+	labelTests := []struct {
+		scope string
+		want  string
+	}{
+		{StrictScope, "myns/mysecret"},
+		{NamespaceWideScope, "myns"},
+		{ClusterWideScope, ""},
+	}
+
+	for _, tt := range labelTests {
+		if got := string(encryptionLabel(name, tt.scope)); got != tt.want {
+			t.Errorf("encryptionLabel(%q) got %q, want %q", tt.scope, got, tt.want)
+		}
+	}
+}
+
 func makeTestCertFunc(testservice types.NamespacedName) PublicKeyFunc {
 	return func(service types.NamespacedName) (*rsa.PublicKey, error) {
 		if testservice.Namespace != service.Namespace {
@@ -0,0 +1,42 @@
+package secrets
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/openshift/odo/pkg/log"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+// var to allow replacement in tests.
+var pollInterval = 5 * time.Second
+
+// InstalledFunc reports whether the sealed-secrets controller service exists,
+// a NotFound error indicates that it's not installed.
+type InstalledFunc func(service types.NamespacedName) error
+
+// WaitForClusterPublicKey polls the sealed-secrets controller until its public
+// key can be fetched, or the timeout expires.
+//
+// If the controller is not installed, this fails immediately, as waiting would
+// not change the outcome.
+func WaitForClusterPublicKey(service types.NamespacedName, timeout time.Duration, installed InstalledFunc) error {
+	deadline := time.Now().Add(timeout)
+	for {
+		err := installed(service)
+		if errors.IsNotFound(err) {
+			return fmt.Errorf("sealed secrets controller %s is not installed: %w", service, err)
+		}
+		if err == nil {
+			if _, err = DefaultPublicKeyFunc(service); err == nil {
+				return nil
+			}
+		}
+		if time.Now().Add(pollInterval).After(deadline) {
+			return fmt.Errorf("timed out after %s waiting for sealed secrets controller %s: %w", timeout, service, err)
+		}
+		log.Infof("Waiting for sealed secrets controller %s to be ready: %v", service, err)
+		time.Sleep(pollInterval)
+	}
+}
@@ -0,0 +1,80 @@
+package secrets
+
+import (
+	"crypto/rsa"
+	"errors"
+	"testing"
+	"time"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+
+	"github.com/rhd-gitops-example/gitops-cli/pkg/pipelines/helper"
+)
+
+var testService = types.NamespacedName{Namespace: "cicd", Name: "sealed-secrets"}
+
+func TestWaitForClusterPublicKeyRetriesUntilReady(t *testing.T) {
+	defer stubPollInterval(time.Millisecond)()
+	polls := 0
+	defer stubPublicKeyFunc(func(service types.NamespacedName) (*rsa.PublicKey, error) {
+		polls++
+		if polls < 2 {
+			return nil, errors.New("cannot fetch certificate: service unavailable")
+		}
+		return makeTestCertFunc(testService)(service)
+	})()
+
+	err := WaitForClusterPublicKey(testService, time.Minute, installed(nil))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if polls != 2 {
+		t.Fatalf("got %d polls for the public key, want 2", polls)
+	}
+}
+
+func TestWaitForClusterPublicKeyNotInstalled(t *testing.T) {
+	defer stubPollInterval(time.Millisecond)()
+	defer stubPublicKeyFunc(func(service types.NamespacedName) (*rsa.PublicKey, error) {
+		t.Fatal("public key fetched for a controller that is not installed")
+		return nil, nil
+	})()
+	notFound := apierrors.NewNotFound(schema.GroupResource{Resource: "services"}, testService.Name)
+
+	err := WaitForClusterPublicKey(testService, time.Minute, installed(notFound))
+	helper.AssertErrorMatch(t, "sealed secrets controller cicd/sealed-secrets is not installed", err)
+}
+
+func TestWaitForClusterPublicKeyTimeout(t *testing.T) {
+	defer stubPollInterval(time.Millisecond)()
+	defer stubPublicKeyFunc(func(service types.NamespacedName) (*rsa.PublicKey, error) {
+		return nil, errors.New("cannot fetch certificate: service unavailable")
+	})()
+
+	err := WaitForClusterPublicKey(testService, 5*time.Millisecond, installed(nil))
+	helper.AssertErrorMatch(t, "timed out after 5ms waiting for sealed secrets controller cicd/sealed-secrets: cannot fetch certificate", err)
+}
+
+func installed(err error) InstalledFunc {
+	return func(types.NamespacedName) error {
+		return err
+	}
+}
+
+func stubPollInterval(d time.Duration) func() {
+	orig := pollInterval
+	pollInterval = d
+	return func() {
+		pollInterval = orig
+	}
+}
+
+func stubPublicKeyFunc(f PublicKeyFunc) func() {
+	orig := DefaultPublicKeyFunc
+	DefaultPublicKeyFunc = f
+	return func() {
+		DefaultPublicKeyFunc = orig
+	}
+}
@@ -19,6 +19,10 @@ import (
 	"k8s.io/apimachinery/pkg/types"
 )
 
+// DefaultWebhookSecretLength is the length of the webhook secrets that are
+// generated when no secret is provided.
+const DefaultWebhookSecretLength = 20
+
 // AddServiceOptions control how new services are added to the configuration.
 type AddServiceOptions struct {
 	AppName                  string
@@ -27,13 +31,18 @@ type AddServiceOptions struct {
 	ImageRepo                string
 	InternalRegistryHostname string
 	PipelinesFolderPath      string
+	ManifestFile             string // Path of the manifest within the PipelinesFolderPath, defaults to pipelines.yaml.
 	ServiceName              string
 	WebhookSecret            string
 	SealedSecretsService     types.NamespacedName // SealedSecrets service name
+	PipelineType             string               // One of the config.PipelineTypes, defaults to config.BuildDeployPipeline.
+	WebhookSecretLength      int                  // The length of the generated WebhookSecret, defaults to DefaultWebhookSecretLength.
+	ContextDir               string               // The directory within the GitRepoURL that the service is built from, defaults to the root.
+	TriggerBranch            string               // If set, the CI pipeline is only started for pushes to this branch of the GitRepoURL.
 }
 
 func AddService(o *AddServiceOptions, appFs afero.Fs) error {
-	m, err := config.LoadManifest(appFs, o.PipelinesFolderPath)
+	m, err := config.LoadManifestFile(appFs, o.PipelinesFolderPath, o.ManifestFile)
 	if err != nil {
 		return err
 	}
@@ -41,11 +50,17 @@ func AddService(o *AddServiceOptions, appFs afero.Fs) error {
 	if err != nil {
 		return err
 	}
+	// The pipelines file is updated in place to keep the user's comments.
+	delete(files, manifestFileOrDefault(o.ManifestFile))
 
 	_, err = yaml.WriteResources(appFs, o.PipelinesFolderPath, files)
 	if err != nil {
 		return err
 	}
+	err = yaml.MarshalItemToFilePreserving(appFs, filepath.Join(o.PipelinesFolderPath, manifestFileOrDefault(o.ManifestFile)), m)
+	if err != nil {
+		return err
+	}
 	cfg := m.GetPipelinesConfig()
 	if cfg != nil {
 		base := filepath.Join(o.PipelinesFolderPath, config.PathForPipelines(cfg), "base")
@@ -63,9 +78,14 @@ func serviceResources(m *config.Manifest, appFs afero.Fs, o *AddServiceOptions)
 	if err != nil {
 		return nil, err
 	}
+	if o.PipelineType != config.BuildDeployPipeline {
+		svc.PipelineType = o.PipelineType
+	}
+	svc.ContextDir = o.ContextDir
+	svc.TriggerBranch = o.TriggerBranch
 	cfg := m.GetPipelinesConfig()
 	if cfg != nil && o.WebhookSecret == "" && o.GitRepoURL != "" {
-		gitSecret, err := secrets.GenerateString(webhookSecretLength)
+		gitSecret, err := secrets.GenerateString(o.webhookSecretLength())
 		if err != nil {
 			return nil, fmt.Errorf("failed to generate service webhook secret: %v", err)
 		}
@@ -97,7 +117,7 @@ func serviceResources(m *config.Manifest, appFs afero.Fs, o *AddServiceOptions)
 		secretsPath := filepath.Join(config.PathForPipelines(cfg), "base", secretFilename)
 		files[secretsPath] = hookSecret
 
-		if o.ImageRepo != "" {
+		if o.ImageRepo != "" && svc.Builds() {
 			_, resources, bindingName, err := createImageRepoResources(m, cfg, env, o)
 			if err != nil {
 				return nil, err
@@ -121,9 +141,10 @@ func serviceResources(m *config.Manifest, appFs afero.Fs, o *AddServiceOptions)
 		return nil, err
 	}
 
-	files[filepath.Base(filepath.Join(o.PipelinesFolderPath, pipelinesFile))] = m
+	files[manifestFileOrDefault(o.ManifestFile)] = m
 	buildParams := &BuildParameters{
 		PipelinesFolderPath: o.PipelinesFolderPath,
+		ManifestFile:        o.ManifestFile,
 		OutputPath:          o.PipelinesFolderPath,
 	}
 	built, err := buildResources(appFs, buildParams, m)
@@ -133,6 +154,13 @@ func serviceResources(m *config.Manifest, appFs afero.Fs, o *AddServiceOptions)
 	return res.Merge(built, files), nil
 }
 
+func (o *AddServiceOptions) webhookSecretLength() int {
+	if o.WebhookSecretLength == 0 {
+		return DefaultWebhookSecretLength
+	}
+	return o.WebhookSecretLength
+}
+
 func createImageRepoResources(m *config.Manifest, cfg *config.PipelinesConfig, env *config.Environment, p *AddServiceOptions) ([]string, res.Resources, string, error) {
 	isInternalRegistry, imageRepo, err := imagerepo.ValidateImageRepo(p.ImageRepo, p.InternalRegistryHostname)
 	if err != nil {
@@ -142,7 +170,7 @@ func createImageRepoResources(m *config.Manifest, cfg *config.PipelinesConfig, e
 	resources := res.Resources{}
 	filenames := []string{}
 
-	bindingName, bindingFilename, svcImageBinding := createSvcImageBinding(cfg, env, p.AppName, p.ServiceName, imageRepo, !isInternalRegistry)
+	bindingName, bindingFilename, svcImageBinding := createSvcImageBinding(cfg, env, p.AppName, p.ServiceName, imageRepo, p.ContextDir, !isInternalRegistry)
 	resources = res.Merge(svcImageBinding, resources)
 	filenames = append(filenames, bindingFilename)
 
@@ -195,9 +223,9 @@ func makeImageBindingPath(cfg *config.PipelinesConfig, imageRepoBindingFilename
 	return filepath.Join(config.PathForPipelines(cfg), "base", imageRepoBindingFilename)
 }
 
-func createSvcImageBinding(cfg *config.PipelinesConfig, env *config.Environment, appName, svcName, imageRepo string, isTLSVerify bool) (string, string, res.Resources) {
+func createSvcImageBinding(cfg *config.PipelinesConfig, env *config.Environment, appName, svcName, imageRepo, contextDir string, isTLSVerify bool) (string, string, res.Resources) {
 	name := makeSvcImageBindingName(env.Name, appName, svcName)
 	filename := makeSvcImageBindingFilename(name)
 	resourceFilePath := makeImageBindingPath(cfg, filename)
-	return name, filename, res.Resources{resourceFilePath: triggers.CreateImageRepoBinding(cfg.Name, name, imageRepo, strconv.FormatBool(isTLSVerify))}
+	return name, filename, res.Resources{resourceFilePath: triggers.CreateImageRepoBinding(cfg.Name, name, imageRepo, strconv.FormatBool(isTLSVerify), contextDir)}
 }
@@ -5,13 +5,18 @@ import (
 	"crypto/rsa"
 	"fmt"
 	"path/filepath"
+	"strings"
 	"testing"
 
+	ssv1alpha1 "github.com/bitnami-labs/sealed-secrets/pkg/apis/sealed-secrets/v1alpha1"
+	"github.com/bitnami-labs/sealed-secrets/pkg/crypto"
 	"github.com/google/go-cmp/cmp"
 	"github.com/google/go-cmp/cmp/cmpopts"
+	"github.com/openshift/client-go/route/clientset/versioned/scheme"
 	"github.com/rhd-gitops-example/gitops-cli/pkg/pipelines/argocd"
 	"github.com/rhd-gitops-example/gitops-cli/pkg/pipelines/config"
 	"github.com/rhd-gitops-example/gitops-cli/pkg/pipelines/eventlisteners"
+	"github.com/rhd-gitops-example/gitops-cli/pkg/pipelines/helper"
 	"github.com/rhd-gitops-example/gitops-cli/pkg/pipelines/ioutils"
 	"github.com/rhd-gitops-example/gitops-cli/pkg/pipelines/meta"
 	res "github.com/rhd-gitops-example/gitops-cli/pkg/pipelines/resources"
@@ -155,6 +160,100 @@ func TestServiceResourcesWithArgoCD(t *testing.T) {
 	}
 }
 
+func TestServiceResourcesWithGeneratedWebhookSecret(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	assertNoError(t, err)
+	origDefaultPublicKeyFunc := secrets.DefaultPublicKeyFunc
+	secrets.DefaultPublicKeyFunc = func(types.NamespacedName) (*rsa.PublicKey, error) {
+		return &key.PublicKey, nil
+	}
+	defer func() {
+		secrets.DefaultPublicKeyFunc = origDefaultPublicKeyFunc
+	}()
+	o := &AddServiceOptions{
+		AppName:             "test-app",
+		EnvName:             "test-dev",
+		GitRepoURL:          "http://github.com/org/test",
+		PipelinesFolderPath: pipelinesFile,
+		ServiceName:         "test",
+		WebhookSecretLength: 32,
+	}
+
+	got, err := serviceResources(buildManifest(true, false), ioutils.NewMemoryFilesystem(), o)
+	assertNoError(t, err)
+
+	if l := len(o.WebhookSecret); l != 32 {
+		t.Fatalf("generated webhook secret is %d characters, want 32", l)
+	}
+	sealed, ok := got["config/cicd/base/03-secrets/webhook-secret-test-dev-test.yaml"].(*ssv1alpha1.SealedSecret)
+	if !ok {
+		t.Fatalf("serviceResources() didn't create the webhook secret, got %#v", got)
+	}
+	fingerprint, err := crypto.PublicKeyFingerprint(&key.PublicKey)
+	assertNoError(t, err)
+	secret, err := sealed.Unseal(scheme.Codecs, map[string]*rsa.PrivateKey{fingerprint: key})
+	assertNoError(t, err)
+	if s := string(secret.Data[eventlisteners.WebhookSecretKey]); s != o.WebhookSecret {
+		t.Fatalf("sealed webhook secret got %q, want %q", s, o.WebhookSecret)
+	}
+}
+
+func TestServiceResourcesWithWebhookSecretPerService(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	assertNoError(t, err)
+	origDefaultPublicKeyFunc := secrets.DefaultPublicKeyFunc
+	secrets.DefaultPublicKeyFunc = func(types.NamespacedName) (*rsa.PublicKey, error) {
+		return &key.PublicKey, nil
+	}
+	defer func() {
+		secrets.DefaultPublicKeyFunc = origDefaultPublicKeyFunc
+	}()
+	fingerprint, err := crypto.PublicKeyFingerprint(&key.PublicKey)
+	assertNoError(t, err)
+	m := buildManifest(true, false)
+
+	var got res.Resources
+	hookSecrets := map[string]string{}
+	for _, name := range []string{"api", "web"} {
+		got, err = serviceResources(m, ioutils.NewMemoryFilesystem(), &AddServiceOptions{
+			AppName:             "test-app",
+			EnvName:             "test-dev",
+			GitRepoURL:          "http://github.com/org/" + name,
+			PipelinesFolderPath: pipelinesFile,
+			ServiceName:         name,
+		})
+		assertNoError(t, err)
+
+		secretName := "webhook-secret-test-dev-" + name
+		sealed, ok := got["config/cicd/base/03-secrets/"+secretName+".yaml"].(*ssv1alpha1.SealedSecret)
+		if !ok {
+			t.Fatalf("serviceResources() didn't create the webhook secret for %s", name)
+		}
+		secret, err := sealed.Unseal(scheme.Codecs, map[string]*rsa.PrivateKey{fingerprint: key})
+		assertNoError(t, err)
+		hookSecrets[secretName] = string(secret.Data[eventlisteners.WebhookSecretKey])
+	}
+	if hookSecrets["webhook-secret-test-dev-api"] == hookSecrets["webhook-secret-test-dev-web"] {
+		t.Fatalf("services got the same webhook secret %q", hookSecrets["webhook-secret-test-dev-api"])
+	}
+
+	el := got["config/cicd/base/08-eventlisteners/cicd-event-listener.yaml"].(*triggersv1.EventListener)
+	for _, name := range []string{"api", "web"} {
+		var secretRef *triggersv1.SecretRef
+		for _, tr := range el.Spec.Triggers {
+			if tr.Name == triggerName(name) {
+				secretRef = tr.Interceptors[0].GitHub.SecretRef
+			}
+		}
+		if secretRef == nil {
+			t.Fatalf("no trigger was generated for %s", name)
+		}
+		if want := "webhook-secret-test-dev-" + name; secretRef.SecretName != want {
+			t.Errorf("trigger for %s validates with secret %q, want %q", name, secretRef.SecretName, want)
+		}
+	}
+}
+
 func TestServiceResourcesWithoutArgoCD(t *testing.T) {
 	fakeFs := ioutils.NewMemoryFilesystem()
 	m := buildManifest(false, false)
@@ -368,6 +467,65 @@ func TestServiceWithArgoCD(t *testing.T) {
 	}
 }
 
+func TestAddServicePreservesComments(t *testing.T) {
+	defer stubDefaultPublicKeyFunc(t)()
+
+	fakeFs := ioutils.NewMemoryFilesystem()
+	outputPath := afero.GetTempDir(fakeFs, "test")
+	pipelinesPath := filepath.Join(outputPath, pipelinesFile)
+	commented := `# The GitOps configuration for the test organisation.
+config:
+  # ArgoCD is managed by the cluster admins.
+  argocd:
+    namespace: argocd
+  pipelines:
+    name: cicd
+environments:
+  - name: test-dev
+    apps:
+      # The test-app is owned by the platform team.
+      - name: test-app
+        services:
+          - name: test-svc
+            source_url: https://github.com/myproject/test-svc # mirrored from GitLab
+            webhook:
+              secret:
+                name: webhook-secret-test-dev-test-svc
+                namespace: cicd
+gitops_url: http://github.com/org/test
+`
+	assertNoError(t, afero.WriteFile(fakeFs, pipelinesPath, []byte(commented), 0644))
+
+	err := AddService(&AddServiceOptions{
+		AppName:             "new-app",
+		EnvName:             "test-dev",
+		GitRepoURL:          "http://github.com/org/test",
+		PipelinesFolderPath: outputPath,
+		WebhookSecret:       "123",
+		ServiceName:         "test",
+	}, fakeFs)
+	assertNoError(t, err)
+
+	b, err := afero.ReadFile(fakeFs, pipelinesPath)
+	assertNoError(t, err)
+	wantComments := []string{
+		"# The GitOps configuration for the test organisation.",
+		"# ArgoCD is managed by the cluster admins.",
+		"# The test-app is owned by the platform team.",
+		"# mirrored from GitLab",
+	}
+	for _, c := range wantComments {
+		if !strings.Contains(string(b), c) {
+			t.Errorf("AddService() lost comment %q:\n%s", c, b)
+		}
+	}
+	m, err := config.ParsePipelinesFolder(fakeFs, outputPath)
+	assertNoError(t, err)
+	if app := m.GetApplication("test-dev", "new-app"); app == nil {
+		t.Fatalf("AddService() did not add the new-app application:\n%s", b)
+	}
+}
+
 func buildManifest(withPipelines, withArgoCD bool) *config.Manifest {
 	m := config.Manifest{
 		GitOpsURL: "http://github.com/org/test",
@@ -422,6 +580,185 @@ func environment(withPipelinesConfig bool) []*config.Environment {
 	return env
 }
 
+func TestServiceResourcesWithBuildPipeline(t *testing.T) {
+	defer stubDefaultPublicKeyFunc(t)()
+	fakeFs := ioutils.NewMemoryFilesystem()
+	m := buildManifest(true, true)
+
+	got, err := serviceResources(m, fakeFs, &AddServiceOptions{
+		AppName:             "lib-app",
+		EnvName:             "test-dev",
+		GitRepoURL:          "http://github.com/org/lib",
+		PipelinesFolderPath: pipelinesFile,
+		WebhookSecret:       "123",
+		ServiceName:         "lib",
+		PipelineType:        config.BuildPipeline,
+	})
+	assertNoError(t, err)
+
+	svc := m.GetApplication("test-dev", "lib-app").Services[0]
+	if svc.PipelineType != config.BuildPipeline {
+		t.Fatalf("got pipeline type %q, want %q", svc.PipelineType, config.BuildPipeline)
+	}
+	if _, ok := got["config/argocd/test-dev-lib-app-app.yaml"]; ok {
+		t.Fatal("an Application was generated for a build only service")
+	}
+	if _, ok := got["config/argocd/test-dev-test-app-app.yaml"]; !ok {
+		t.Fatal("no Application was generated for the deployed service")
+	}
+	want := &res.Kustomization{Bases: []string{"../../../env/base"}}
+	if diff := cmp.Diff(want, got["environments/test-dev/apps/lib-app/base/kustomization.yaml"]); diff != "" {
+		t.Fatalf("build only service was deployed by the application:\n%s", diff)
+	}
+
+	el := got["config/cicd/base/08-eventlisteners/cicd-event-listener.yaml"].(*triggersv1.EventListener)
+	found := false
+	for _, tr := range el.Spec.Triggers {
+		found = found || tr.Name == triggerName("lib")
+	}
+	if !found {
+		t.Fatal("no build trigger was generated for the build only service")
+	}
+}
+
+func TestServiceResourcesWithDeployPipeline(t *testing.T) {
+	defer stubDefaultPublicKeyFunc(t)()
+	fakeFs := ioutils.NewMemoryFilesystem()
+	m := buildManifest(true, true)
+
+	got, err := serviceResources(m, fakeFs, &AddServiceOptions{
+		AppName:             "test-app",
+		EnvName:             "test-dev",
+		GitRepoURL:          "http://github.com/org/test",
+		PipelinesFolderPath: pipelinesFile,
+		WebhookSecret:       "123",
+		ServiceName:         "test",
+		ImageRepo:           "quay.io/org/test",
+		PipelineType:        config.DeployPipeline,
+	})
+	assertNoError(t, err)
+
+	el := got["config/cicd/base/08-eventlisteners/cicd-event-listener.yaml"].(*triggersv1.EventListener)
+	for _, tr := range el.Spec.Triggers {
+		if tr.Name == triggerName("test") {
+			t.Fatal("a build trigger was generated for a deploy only service")
+		}
+	}
+	if _, ok := got["config/cicd/base/06-bindings/test-dev-test-app-test-binding.yaml"]; ok {
+		t.Fatal("an image binding was generated for a deploy only service")
+	}
+}
+
+func TestServiceResourcesWithTriggerBranch(t *testing.T) {
+	defer stubDefaultPublicKeyFunc(t)()
+	fakeFs := ioutils.NewMemoryFilesystem()
+	m := buildManifest(true, true)
+	m.Config.Pipelines.Branches = []string{"main"}
+
+	got, err := serviceResources(m, fakeFs, &AddServiceOptions{
+		AppName:             "api-app",
+		EnvName:             "test-dev",
+		GitRepoURL:          "http://github.com/org/test",
+		PipelinesFolderPath: pipelinesFile,
+		WebhookSecret:       "123",
+		ServiceName:         "test",
+		ImageRepo:           "quay.io/org/test",
+		PipelineType:        config.BuildDeployPipeline,
+		TriggerBranch:       "develop",
+	})
+	assertNoError(t, err)
+
+	if branch := m.GetApplication("test-dev", "api-app").Services[0].TriggerBranch; branch != "develop" {
+		t.Fatalf("got trigger branch %q, want %q", branch, "develop")
+	}
+	el := got["config/cicd/base/08-eventlisteners/cicd-event-listener.yaml"].(*triggersv1.EventListener)
+	var trigger *triggersv1.EventListenerTrigger
+	for i := range el.Spec.Triggers {
+		if el.Spec.Triggers[i].Name == triggerName("test") {
+			trigger = &el.Spec.Triggers[i]
+		}
+	}
+	if trigger == nil {
+		t.Fatal("no build trigger was generated for the service")
+	}
+	want := []*triggersv1.EventInterceptor{eventlisteners.BranchFilter([]string{"develop"})}
+	if diff := cmp.Diff(want, trigger.Interceptors[1:]); diff != "" {
+		t.Fatalf("the build trigger doesn't filter on the trigger branch:\n%s", diff)
+	}
+}
+
+func TestServiceResourcesWithContextDirs(t *testing.T) {
+	defer stubDefaultPublicKeyFunc(t)()
+	fakeFs := ioutils.NewMemoryFilesystem()
+	m := buildManifest(true, false)
+	m.Environments[0].Pipelines = &config.Pipelines{
+		Integration: &config.TemplateBinding{Template: "app-ci-template", Bindings: []string{"github-push-binding"}},
+	}
+
+	bindings := map[string]triggersv1.TriggerBinding{}
+	for _, svc := range []string{"frontend", "backend"} {
+		got, err := serviceResources(m, fakeFs, &AddServiceOptions{
+			AppName:             "test-app",
+			EnvName:             "test-dev",
+			GitRepoURL:          "http://github.com/org/monorepo.git",
+			PipelinesFolderPath: pipelinesFile,
+			WebhookSecret:       "123",
+			ServiceName:         svc,
+			ImageRepo:           "quay.io/org/" + svc,
+			ContextDir:          "services/" + svc,
+		})
+		assertNoError(t, err)
+		name := makeSvcImageBindingName("test-dev", "test-app", svc)
+		bindings[svc] = got[filepath.Join("config/cicd/base", makeSvcImageBindingFilename(name))].(triggersv1.TriggerBinding)
+	}
+
+	for svc, binding := range bindings {
+		want := []triggersv1.Param{
+			{Name: "imageRepo", Value: "quay.io/org/" + svc},
+			{Name: "tlsVerify", Value: "true"},
+			{Name: "contextDir", Value: "services/" + svc},
+		}
+		if diff := cmp.Diff(want, binding.Spec.Params); diff != "" {
+			t.Errorf("binding for %s failed:\n%s", svc, diff)
+		}
+	}
+	for _, svc := range m.GetApplication("test-dev", "test-app").Services[1:] {
+		if svc.ContextDir != "services/"+svc.Name {
+			t.Errorf("service %s got context dir %q, want %q", svc.Name, svc.ContextDir, "services/"+svc.Name)
+		}
+		if got := svc.Pipelines.Integration.Bindings[0]; got != bindings[svc.Name].Name {
+			t.Errorf("service %s is built with binding %q, want %q", svc.Name, got, bindings[svc.Name].Name)
+		}
+	}
+}
+
+func TestServiceResourcesWithDuplicateSource(t *testing.T) {
+	defer stubDefaultPublicKeyFunc(t)()
+	fakeFs := ioutils.NewMemoryFilesystem()
+	manifest := func() *config.Manifest {
+		m := buildManifest(true, false)
+		m.Environments = append(m.Environments, &config.Environment{Name: "test-stage"})
+		return m
+	}
+	opts := func(envName string) *AddServiceOptions {
+		return &AddServiceOptions{
+			AppName:             "other-app",
+			EnvName:             envName,
+			GitRepoURL:          "https://github.com/myproject/test-svc.git",
+			PipelinesFolderPath: pipelinesFile,
+			WebhookSecret:       "123",
+			ServiceName:         "other-svc",
+		}
+	}
+
+	_, err := serviceResources(manifest(), fakeFs, opts("test-dev"))
+	helper.AssertErrorMatch(t, `duplicate source https://github.com/myproject/test-svc.git in environment test-dev, it is already used by service test-svc`, err)
+
+	// The same repository can be used in another environment.
+	_, err = serviceResources(manifest(), fakeFs, opts("test-stage"))
+	assertNoError(t, err)
+}
+
 func TestCreateSvcImageBinding(t *testing.T) {
 	cfg := &config.PipelinesConfig{
 		Name: "cicd",
@@ -429,7 +766,7 @@ func TestCreateSvcImageBinding(t *testing.T) {
 	env := &config.Environment{
 		Name: "new-env",
 	}
-	bindingName, bindingFilename, resources := createSvcImageBinding(cfg, env, "newapp", "new-svc", "quay.io/user/app", false)
+	bindingName, bindingFilename, resources := createSvcImageBinding(cfg, env, "newapp", "new-svc", "quay.io/user/app", "", false)
 	if diff := cmp.Diff(bindingName, "new-env-newapp-new-svc-binding"); diff != "" {
 		t.Errorf("bindingName failed: %v", diff)
 	}
@@ -0,0 +1,115 @@
+package pipelines
+
+import (
+	"fmt"
+	"io"
+	"text/tabwriter"
+
+	"github.com/spf13/afero"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+
+	"github.com/rhd-gitops-example/gitops-cli/pkg/pipelines/config"
+)
+
+// StatusParameters is a struct that provides flags for the Status command.
+type StatusParameters struct {
+	PipelinesFolderPath string
+}
+
+// defaultCluster is the API server of the cluster that environments without
+// a Cluster are deployed to.
+const defaultCluster = "https://kubernetes.default.svc"
+
+// The statuses of an environment's namespace in the cluster, a namespace that
+// exists has the phase of the Namespace e.g. Active or Terminating.
+const (
+	StatusMissing = "Missing"
+	StatusSkipped = "Skipped"
+)
+
+// NamespaceStatus is the status of the namespace of an environment in the
+// cluster.
+type NamespaceStatus struct {
+	Environment string
+	Namespace   string
+	Status      string
+	Reason      string
+}
+
+// Status writes a table of the status of the namespaces of the manifest's
+// environments, and its CI/CD namespace, in the cluster to out.
+//
+// Environments that are deployed to another cluster are skipped, as they
+// can't be checked against this one.
+func Status(o *StatusParameters, appFs afero.Fs, client kubernetes.Interface, out io.Writer) error {
+	m, err := config.LoadManifest(appFs, o.PipelinesFolderPath)
+	if err != nil {
+		return err
+	}
+	statuses, err := namespaceStatuses(m, client)
+	if err != nil {
+		return err
+	}
+	w := tabwriter.NewWriter(out, 5, 2, 3, ' ', tabwriter.TabIndent)
+	fmt.Fprintln(w, "ENVIRONMENT\tNAMESPACE\tSTATUS\tREASON")
+	for _, s := range statuses {
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", s.Environment, s.Namespace, s.Status, s.Reason)
+	}
+	return w.Flush()
+}
+
+// namespaceStatuses returns the status of the namespace of each environment,
+// in the order of the manifest, following the CI/CD namespace.
+//
+// The namespace of an environment is its name.
+func namespaceStatuses(m *config.Manifest, client kubernetes.Interface) ([]NamespaceStatus, error) {
+	statuses := []NamespaceStatus{}
+	if cfg := m.GetPipelinesConfig(); cfg != nil {
+		s, err := namespaceStatus(client, "cicd", cfg.Name)
+		if err != nil {
+			return nil, err
+		}
+		statuses = append(statuses, s)
+	}
+	for _, env := range m.Environments {
+		if env.Cluster != "" && env.Cluster != defaultCluster {
+			statuses = append(statuses, NamespaceStatus{
+				Environment: env.Name,
+				Namespace:   env.Name,
+				Status:      StatusSkipped,
+				Reason:      fmt.Sprintf("deployed to cluster %s", env.Cluster),
+			})
+			continue
+		}
+		s, err := namespaceStatus(client, env.Name, env.Name)
+		if err != nil {
+			return nil, err
+		}
+		statuses = append(statuses, s)
+	}
+	return statuses, nil
+}
+
+func namespaceStatus(client kubernetes.Interface, envName, name string) (NamespaceStatus, error) {
+	s := NamespaceStatus{Environment: envName, Namespace: name}
+	ns, err := client.CoreV1().Namespaces().Get(name, metav1.GetOptions{})
+	if errors.IsNotFound(err) {
+		s.Status = StatusMissing
+		s.Reason = "the namespace doesn't exist, the environment may not be synced"
+		return s, nil
+	}
+	if err != nil {
+		return s, fmt.Errorf("failed to get namespace %s: %w", name, err)
+	}
+	s.Status = string(ns.Status.Phase)
+	if ns.Status.Phase == "" {
+		s.Status = string(corev1.NamespaceActive)
+	}
+	if ns.Status.Phase == corev1.NamespaceTerminating {
+		s.Reason = "the namespace is being deleted"
+	}
+	return s, nil
+}
@@ -0,0 +1,71 @@
+package pipelines
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+
+	"github.com/rhd-gitops-example/gitops-cli/pkg/pipelines/config"
+)
+
+func TestStatus(t *testing.T) {
+	fakeFs, gitopsPath := manifestFileFixture(t, "testdata/list/pipelines.yaml")
+	client := fake.NewSimpleClientset(testNamespace("cicd", corev1.NamespaceActive))
+	var out bytes.Buffer
+
+	err := Status(&StatusParameters{PipelinesFolderPath: gitopsPath}, fakeFs, client, &out)
+	assertNoError(t, err)
+
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	want := [][]string{
+		{"ENVIRONMENT", "NAMESPACE", "STATUS", "REASON"},
+		{"cicd", "cicd", "Active"},
+		{"dev", "dev", "Missing", "the", "namespace", "doesn't", "exist,", "the", "environment", "may", "not", "be", "synced"},
+		{"prod", "prod", "Skipped", "deployed", "to", "cluster", "https://prod.example.com"},
+	}
+	got := [][]string{}
+	for _, l := range lines {
+		got = append(got, strings.Fields(l))
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Fatalf("status failed:\n%s", diff)
+	}
+}
+
+func TestNamespaceStatuses(t *testing.T) {
+	m := &config.Manifest{
+		Environments: []*config.Environment{
+			{Name: "dev"},
+			{Name: "stage"},
+			{Name: "test"},
+		},
+	}
+	client := fake.NewSimpleClientset(
+		testNamespace("dev", corev1.NamespaceActive),
+		testNamespace("stage", corev1.NamespaceTerminating),
+	)
+
+	statuses, err := namespaceStatuses(m, client)
+	assertNoError(t, err)
+
+	want := []NamespaceStatus{
+		{Environment: "dev", Namespace: "dev", Status: "Active"},
+		{Environment: "stage", Namespace: "stage", Status: "Terminating", Reason: "the namespace is being deleted"},
+		{Environment: "test", Namespace: "test", Status: StatusMissing, Reason: "the namespace doesn't exist, the environment may not be synced"},
+	}
+	if diff := cmp.Diff(want, statuses); diff != "" {
+		t.Fatalf("namespaceStatuses() failed:\n%s", diff)
+	}
+}
+
+func testNamespace(name string, phase corev1.NamespacePhase) *corev1.Namespace {
+	return &corev1.Namespace{
+		ObjectMeta: metav1.ObjectMeta{Name: name},
+		Status:     corev1.NamespaceStatus{Phase: phase},
+	}
+}
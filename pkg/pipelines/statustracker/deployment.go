@@ -9,6 +9,7 @@ import (
 	rbacv1 "k8s.io/api/rbac/v1"
 
 	"github.com/rhd-gitops-example/gitops-cli/pkg/pipelines/deployment"
+	"github.com/rhd-gitops-example/gitops-cli/pkg/pipelines/imagerepo"
 	"github.com/rhd-gitops-example/gitops-cli/pkg/pipelines/meta"
 	res "github.com/rhd-gitops-example/gitops-cli/pkg/pipelines/resources"
 	"github.com/rhd-gitops-example/gitops-cli/pkg/pipelines/roles"
@@ -63,7 +64,9 @@ var (
 
 // Resources returns a list of newly created resources that are required to
 // setup the status-tracker service.
-func Resources(ns, repoURL, driver string) (res.Resources, error) {
+//
+// If registry is not empty, the operator image is pulled from it.
+func Resources(ns, repoURL, driver, registry string) (res.Resources, error) {
 	name := meta.NamespacedName(ns, operatorName)
 	sa := roles.CreateServiceAccount(name)
 
@@ -71,12 +74,12 @@ func Resources(ns, repoURL, driver string) (res.Resources, error) {
 		"02-rolebindings/commit-status-tracker-role.yaml":            roles.CreateRole(name, roleRules),
 		"02-rolebindings/commit-status-tracker-rolebinding.yaml":     roles.CreateRoleBinding(name, sa, "Role", operatorName),
 		"02-rolebindings/commit-status-tracker-service-account.yaml": sa,
-		"10-commit-status-tracker/operator.yaml":                     createStatusTrackerDeployment(ns, repoURL, driver),
+		"10-commit-status-tracker/operator.yaml":                     createStatusTrackerDeployment(ns, repoURL, driver, registry),
 	}, nil
 }
 
-func createStatusTrackerDeployment(ns, repoURL, driver string) *appsv1.Deployment {
-	return deployment.Create(commitStatusAppLabel, ns, operatorName, containerImage,
+func createStatusTrackerDeployment(ns, repoURL, driver, registry string) *appsv1.Deployment {
+	return deployment.Create(commitStatusAppLabel, ns, operatorName, imagerepo.WithRegistry(containerImage, registry),
 		deployment.ServiceAccount(operatorName),
 		deployment.Env(makeEnvironment(repoURL, driver)),
 		deployment.Command([]string{operatorName}))
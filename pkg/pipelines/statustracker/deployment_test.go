@@ -17,7 +17,7 @@ import (
 const testRepoURL = "https://github.com/testing/testing.git"
 
 func TestCreateStatusTrackerDeployment(t *testing.T) {
-	deploy := createStatusTrackerDeployment("dana-cicd", testRepoURL, "")
+	deploy := createStatusTrackerDeployment("dana-cicd", testRepoURL, "", "")
 	want := &appsv1.Deployment{
 		TypeMeta: meta.TypeMeta("Deployment", "apps/v1"),
 		ObjectMeta: meta.ObjectMeta(meta.NamespacedName("dana-cicd", operatorName), meta.AddLabels(
@@ -81,7 +81,7 @@ func TestCreateStatusTrackerDeployment(t *testing.T) {
 func TestResource(t *testing.T) {
 
 	ns := "my-test-ns"
-	generated, err := Resources(ns, testRepoURL, "")
+	generated, err := Resources(ns, testRepoURL, "", "")
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -91,7 +91,7 @@ func TestResource(t *testing.T) {
 		"02-rolebindings/commit-status-tracker-service-account.yaml": sa,
 		"02-rolebindings/commit-status-tracker-role.yaml":            roles.CreateRole(name, roleRules),
 		"02-rolebindings/commit-status-tracker-rolebinding.yaml":     roles.CreateRoleBinding(name, sa, "Role", operatorName),
-		"10-commit-status-tracker/operator.yaml":                     createStatusTrackerDeployment(ns, "https://github.com/testing/testing.git", ""),
+		"10-commit-status-tracker/operator.yaml":                     createStatusTrackerDeployment(ns, "https://github.com/testing/testing.git", "", ""),
 	}
 
 	if diff := cmp.Diff(want, generated); diff != "" {
@@ -0,0 +1,185 @@
+package pipelines
+
+import (
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+
+	"github.com/spf13/afero"
+	pipelinev1 "github.com/tektoncd/pipeline/pkg/apis/pipeline/v1beta1"
+	triggersv1 "github.com/tektoncd/triggers/pkg/apis/triggers/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+	sigsyaml "sigs.k8s.io/yaml"
+
+	"github.com/rhd-gitops-example/gitops-cli/pkg/pipelines/config"
+	res "github.com/rhd-gitops-example/gitops-cli/pkg/pipelines/resources"
+	"github.com/rhd-gitops-example/gitops-cli/pkg/pipelines/tasks"
+	"github.com/rhd-gitops-example/gitops-cli/pkg/pipelines/yaml"
+)
+
+const (
+	tasksDir     = "04-tasks"
+	pipelinesDir = "05-pipelines"
+	templatesDir = "07-templates"
+)
+
+// ScaffoldTaskOptions control the Task that is scaffolded.
+type ScaffoldTaskOptions struct {
+	PipelinesFolderPath string
+	TaskName            string
+	Steps               int    // The number of placeholder steps.
+	Workspaces          int    // The number of placeholder workspaces.
+	Pipeline            string // If set, the Task is run at the end of this Pipeline.
+}
+
+// ScaffoldTask writes a Task with placeholder steps and workspaces to the
+// CI/CD configuration, and adds it to the kustomization, so that it can be
+// completed and referenced from the pipelines.
+//
+// It returns the path of the Task file.
+func ScaffoldTask(o *ScaffoldTaskOptions, appFs afero.Fs) (string, error) {
+	m, err := config.LoadManifest(appFs, o.PipelinesFolderPath)
+	if err != nil {
+		return "", err
+	}
+	cfg := m.GetPipelinesConfig()
+	if cfg == nil {
+		return "", fmt.Errorf("the manifest has no CI/CD configuration to add the Task to")
+	}
+	base := filepath.Join(o.PipelinesFolderPath, config.PathForPipelines(cfg), "base")
+	taskPath := filepath.Join(base, tasksDir, o.TaskName+".yaml")
+	exists, err := afero.Exists(appFs, taskPath)
+	if err != nil {
+		return "", err
+	}
+	if exists {
+		return "", fmt.Errorf("task %s already exists at %s", o.TaskName, taskPath)
+	}
+	files := res.Resources{
+		filepath.Join(tasksDir, o.TaskName+".yaml"): tasks.CreateScaffoldTask(cfg.Name, o.TaskName, o.Steps, o.Workspaces),
+	}
+	if o.Pipeline != "" {
+		pipelinePath := filepath.Join(pipelinesDir, o.Pipeline+".yaml")
+		pipeline, err := readPipeline(appFs, filepath.Join(base, pipelinePath))
+		if err != nil {
+			return "", err
+		}
+		workspaces := tasks.ScaffoldWorkspaceNames(o.Workspaces)
+		addTaskToPipeline(pipeline, o.TaskName, workspaces)
+		files[pipelinePath] = pipeline
+		templates, err := bindTemplateWorkspaces(appFs, base, o.Pipeline, workspaces)
+		if err != nil {
+			return "", err
+		}
+		for k, v := range templates {
+			files[k] = v
+		}
+	}
+	if _, err := yaml.WriteResources(appFs, base, files); err != nil {
+		return "", err
+	}
+	return taskPath, updateKustomization(appFs, base)
+}
+
+func readPipeline(fs afero.Fs, filename string) (*pipelinev1.Pipeline, error) {
+	b, err := afero.ReadFile(fs, filename)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read the Pipeline: %w", err)
+	}
+	pipeline := &pipelinev1.Pipeline{}
+	if err := sigsyaml.Unmarshal(b, pipeline); err != nil {
+		return nil, fmt.Errorf("failed to parse the Pipeline in %s: %w", filename, err)
+	}
+	return pipeline, nil
+}
+
+// addTaskToPipeline runs the Task after the existing tasks of the Pipeline,
+// its workspaces are bound to Pipeline workspaces of the same name, these are
+// declared if the Pipeline doesn't already have them.
+func addTaskToPipeline(pipeline *pipelinev1.Pipeline, taskName string, workspaces []string) {
+	task := pipelinev1.PipelineTask{
+		Name:    taskName,
+		TaskRef: &pipelinev1.TaskRef{Name: taskName, Kind: pipelinev1.NamespacedTaskKind},
+	}
+	for _, t := range pipeline.Spec.Tasks {
+		task.RunAfter = append(task.RunAfter, t.Name)
+	}
+	declared := map[string]bool{}
+	for _, w := range pipeline.Spec.Workspaces {
+		declared[w.Name] = true
+	}
+	for _, w := range workspaces {
+		task.Workspaces = append(task.Workspaces, pipelinev1.WorkspacePipelineTaskBinding{Name: w, Workspace: w})
+		if !declared[w] {
+			pipeline.Spec.Workspaces = append(pipeline.Spec.Workspaces, pipelinev1.PipelineWorkspaceDeclaration{Name: w})
+		}
+	}
+	pipeline.Spec.Tasks = append(pipeline.Spec.Tasks, task)
+}
+
+// bindTemplateWorkspaces binds the workspaces in the PipelineRuns of the
+// TriggerTemplates that run the Pipeline, so that the PipelineRuns provide the
+// workspaces that are added to the Pipeline.
+//
+// The workspaces are bound to an emptyDir, as they're only used by the
+// scaffolded Task, workspaces that are already bound are left unchanged.
+func bindTemplateWorkspaces(fs afero.Fs, base, pipelineName string, workspaces []string) (res.Resources, error) {
+	if len(workspaces) == 0 {
+		return nil, nil
+	}
+	filenames, err := afero.Glob(fs, filepath.Join(base, templatesDir, "*.yaml"))
+	if err != nil {
+		return nil, err
+	}
+	files := res.Resources{}
+	for _, filename := range filenames {
+		b, err := afero.ReadFile(fs, filename)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read the TriggerTemplate: %w", err)
+		}
+		template := &triggersv1.TriggerTemplate{}
+		if err := sigsyaml.Unmarshal(b, template); err != nil {
+			return nil, fmt.Errorf("failed to parse the TriggerTemplate in %s: %w", filename, err)
+		}
+		changed := false
+		for i, rt := range template.Spec.ResourceTemplates {
+			pr := &pipelinev1.PipelineRun{}
+			if err := json.Unmarshal(rt.Raw, pr); err != nil || pr.Kind != "PipelineRun" {
+				continue
+			}
+			if pr.Spec.PipelineRef == nil || pr.Spec.PipelineRef.Name != pipelineName {
+				continue
+			}
+			if !bindWorkspaces(pr, workspaces) {
+				continue
+			}
+			raw, err := json.Marshal(pr)
+			if err != nil {
+				return nil, err
+			}
+			template.Spec.ResourceTemplates[i].Raw = raw
+			changed = true
+		}
+		if changed {
+			files[filepath.Join(templatesDir, filepath.Base(filename))] = template
+		}
+	}
+	return files, nil
+}
+
+// bindWorkspaces binds the workspaces that aren't already bound in the
+// PipelineRun to an emptyDir, and returns true if any were added.
+func bindWorkspaces(pr *pipelinev1.PipelineRun, workspaces []string) bool {
+	bound := map[string]bool{}
+	for _, w := range pr.Spec.Workspaces {
+		bound[w.Name] = true
+	}
+	added := false
+	for _, w := range workspaces {
+		if !bound[w] {
+			pr.Spec.Workspaces = append(pr.Spec.Workspaces, pipelinev1.WorkspaceBinding{Name: w, EmptyDir: &corev1.EmptyDirVolumeSource{}})
+			added = true
+		}
+	}
+	return added
+}
@@ -0,0 +1,118 @@
+package pipelines
+
+import (
+	"encoding/json"
+	"path/filepath"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/spf13/afero"
+	pipelinev1 "github.com/tektoncd/pipeline/pkg/apis/pipeline/v1beta1"
+	triggersv1 "github.com/tektoncd/triggers/pkg/apis/triggers/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/yaml"
+
+	"github.com/rhd-gitops-example/gitops-cli/pkg/pipelines/pipelines"
+	res "github.com/rhd-gitops-example/gitops-cli/pkg/pipelines/resources"
+	"github.com/rhd-gitops-example/gitops-cli/pkg/pipelines/triggers"
+)
+
+func TestScaffoldTask(t *testing.T) {
+	fakeFs, outputPath := taskFixture(t)
+
+	taskPath, err := ScaffoldTask(&ScaffoldTaskOptions{
+		PipelinesFolderPath: outputPath,
+		TaskName:            "build-rust",
+		Steps:               3,
+		Workspaces:          1,
+	}, fakeFs)
+	assertNoError(t, err)
+
+	base := filepath.Join(outputPath, "config/cicd/base")
+	if want := filepath.Join(base, "04-tasks/build-rust.yaml"); taskPath != want {
+		t.Fatalf("got task path %q, want %q", taskPath, want)
+	}
+	task := &pipelinev1.Task{}
+	readYAML(t, fakeFs, taskPath, task)
+	if task.Name != "build-rust" {
+		t.Fatalf("got Task name %q, want %q", task.Name, "build-rust")
+	}
+	if l := len(task.Spec.Steps); l != 3 {
+		t.Fatalf("got %d steps, want 3", l)
+	}
+	k := &res.Kustomization{}
+	readYAML(t, fakeFs, filepath.Join(base, Kustomize), k)
+	if diff := cmp.Diff([]string{"04-tasks/build-rust.yaml", "05-pipelines/app-ci-pipeline.yaml"}, k.Resources); diff != "" {
+		t.Fatalf("the Task wasn't added to the kustomization:\n%s", diff)
+	}
+}
+
+func TestScaffoldTaskReferencedFromPipeline(t *testing.T) {
+	fakeFs, outputPath := taskFixture(t)
+	templatePath := filepath.Join(outputPath, "config/cicd/base", appCIPushTemplatePath)
+	b, err := yaml.Marshal(triggers.CreateDevCIBuildPRTemplate("cicd", "pipeline"))
+	assertNoError(t, err)
+	assertNoError(t, afero.WriteFile(fakeFs, templatePath, b, 0644))
+
+	_, err = ScaffoldTask(&ScaffoldTaskOptions{
+		PipelinesFolderPath: outputPath,
+		TaskName:            "build-rust",
+		Steps:               1,
+		Workspaces:          1,
+		Pipeline:            "app-ci-pipeline",
+	}, fakeFs)
+	assertNoError(t, err)
+
+	pipeline := &pipelinev1.Pipeline{}
+	readYAML(t, fakeFs, filepath.Join(outputPath, "config/cicd/base/05-pipelines/app-ci-pipeline.yaml"), pipeline)
+	want := pipelinev1.PipelineTask{
+		Name:       "build-rust",
+		TaskRef:    &pipelinev1.TaskRef{Name: "build-rust", Kind: pipelinev1.NamespacedTaskKind},
+		RunAfter:   []string{"build-image"},
+		Workspaces: []pipelinev1.WorkspacePipelineTaskBinding{{Name: "workspace-1", Workspace: "workspace-1"}},
+	}
+	if diff := cmp.Diff(want, pipeline.Spec.Tasks[len(pipeline.Spec.Tasks)-1]); diff != "" {
+		t.Fatalf("the Task wasn't referenced from the Pipeline:\n%s", diff)
+	}
+	if diff := cmp.Diff([]pipelinev1.PipelineWorkspaceDeclaration{{Name: "workspace-1"}}, pipeline.Spec.Workspaces); diff != "" {
+		t.Fatalf("the Pipeline workspaces weren't declared:\n%s", diff)
+	}
+
+	template := &triggersv1.TriggerTemplate{}
+	readYAML(t, fakeFs, templatePath, template)
+	pr := &pipelinev1.PipelineRun{}
+	assertNoError(t, json.Unmarshal(template.Spec.ResourceTemplates[0].Raw, pr))
+	wantBindings := []pipelinev1.WorkspaceBinding{{Name: "workspace-1", EmptyDir: &corev1.EmptyDirVolumeSource{}}}
+	if diff := cmp.Diff(wantBindings, pr.Spec.Workspaces); diff != "" {
+		t.Fatalf("the workspaces weren't bound in the TriggerTemplate:\n%s", diff)
+	}
+}
+
+func TestScaffoldTaskThatExists(t *testing.T) {
+	fakeFs, outputPath := taskFixture(t)
+	o := &ScaffoldTaskOptions{PipelinesFolderPath: outputPath, TaskName: "build-rust", Steps: 1}
+	_, err := ScaffoldTask(o, fakeFs)
+	assertNoError(t, err)
+
+	_, err = ScaffoldTask(o, fakeFs)
+	if err == nil {
+		t.Fatal("expected an error scaffolding a Task that already exists")
+	}
+}
+
+func taskFixture(t *testing.T) (afero.Fs, string) {
+	t.Helper()
+	fakeFs, outputPath := manifestFixture(t, buildManifest(true, false))
+	b, err := yaml.Marshal(pipelines.CreateAppCIPipeline(types.NamespacedName{Namespace: "cicd", Name: "app-ci-pipeline"}, defaultBuildTask))
+	assertNoError(t, err)
+	assertNoError(t, afero.WriteFile(fakeFs, filepath.Join(outputPath, "config/cicd/base", appCiPipelinesPath), b, 0644))
+	return fakeFs, outputPath
+}
+
+func readYAML(t *testing.T, fs afero.Fs, filename string, v interface{}) {
+	t.Helper()
+	b, err := afero.ReadFile(fs, filename)
+	assertNoError(t, err)
+	assertNoError(t, yaml.Unmarshal(b, v))
+}
@@ -3,29 +3,33 @@ package tasks
 import (
 	pipelinev1 "github.com/tektoncd/pipeline/pkg/apis/pipeline/v1beta1"
 
+	"github.com/rhd-gitops-example/gitops-cli/pkg/pipelines/imagerepo"
 	"github.com/rhd-gitops-example/gitops-cli/pkg/pipelines/meta"
 )
 
-// CreateDeployFromSourceTask creates DeployFromSourceTask
-func CreateDeployFromSourceTask(ns, script string) pipelinev1.Task {
+const kubectlImage = "quay.io/redhat-developer/k8s-kubectl"
+
+// CreateDeployFromSourceTask creates DeployFromSourceTask, if registry is
+// provided, the step image is pulled from there rather than the default.
+func CreateDeployFromSourceTask(ns, script, registry string) pipelinev1.Task {
 	task := pipelinev1.Task{
 		TypeMeta:   taskTypeMeta,
 		ObjectMeta: meta.ObjectMeta(meta.NamespacedName(ns, "deploy-from-source-task")),
 		Spec: pipelinev1.TaskSpec{
 			Params:    paramsForDeploymentFromSourceTask(),
 			Resources: createResourcesForDeployFromSourceTask(),
-			Steps:     createStepsForDeployFromSourceTask(script),
+			Steps:     createStepsForDeployFromSourceTask(script, imagerepo.WithRegistry(kubectlImage, registry)),
 		},
 	}
 	return task
 }
 
-func createStepsForDeployFromSourceTask(script string) []pipelinev1.Step {
+func createStepsForDeployFromSourceTask(script, image string) []pipelinev1.Step {
 	return []pipelinev1.Step{
 		{
 			Container: createContainer(
 				"run-kubectl",
-				"quay.io/redhat-developer/k8s-kubectl",
+				image,
 				"/workspace/source",
 				nil,
 				nil,
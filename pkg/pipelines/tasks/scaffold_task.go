@@ -0,0 +1,64 @@
+package tasks
+
+import (
+	"fmt"
+
+	pipelinev1 "github.com/tektoncd/pipeline/pkg/apis/pipeline/v1beta1"
+
+	"github.com/rhd-gitops-example/gitops-cli/pkg/pipelines/meta"
+)
+
+const scaffoldImage = "registry.access.redhat.com/ubi8/ubi-minimal"
+
+// CreateScaffoldTask creates a Task with placeholder steps and workspaces, as
+// the starting point for a custom Task, the steps and workspaces are numbered
+// e.g. step-1 and workspace-1.
+func CreateScaffoldTask(ns, name string, steps, workspaces int) pipelinev1.Task {
+	return pipelinev1.Task{
+		TypeMeta:   taskTypeMeta,
+		ObjectMeta: meta.ObjectMeta(meta.NamespacedName(ns, name)),
+		Spec: pipelinev1.TaskSpec{
+			Workspaces: createScaffoldWorkspaces(workspaces),
+			Steps:      createScaffoldSteps(steps, workspaces),
+		},
+	}
+}
+
+// ScaffoldWorkspaceNames returns the names of the workspaces of a Task created
+// by CreateScaffoldTask.
+func ScaffoldWorkspaceNames(workspaces int) []string {
+	names := make([]string, workspaces)
+	for i := range names {
+		names[i] = fmt.Sprintf("workspace-%d", i+1)
+	}
+	return names
+}
+
+func createScaffoldWorkspaces(workspaces int) []pipelinev1.WorkspaceDeclaration {
+	declarations := []pipelinev1.WorkspaceDeclaration{}
+	for _, name := range ScaffoldWorkspaceNames(workspaces) {
+		declarations = append(declarations, pipelinev1.WorkspaceDeclaration{
+			Name:        name,
+			Description: "TODO: describe the files that the Task uses from this workspace.",
+		})
+	}
+	return declarations
+}
+
+// createScaffoldSteps creates the steps, these run in the first workspace, if
+// there is one.
+func createScaffoldSteps(steps, workspaces int) []pipelinev1.Step {
+	workingDir := ""
+	if names := ScaffoldWorkspaceNames(workspaces); len(names) > 0 {
+		workingDir = fmt.Sprintf("$(workspaces.%s.path)", names[0])
+	}
+	created := []pipelinev1.Step{}
+	for i := 1; i <= steps; i++ {
+		name := fmt.Sprintf("step-%d", i)
+		created = append(created, pipelinev1.Step{
+			Container: createContainer(name, scaffoldImage, workingDir, nil, nil),
+			Script:    fmt.Sprintf("#!/usr/bin/env sh\nset -e\necho \"TODO: implement %s\"\n", name),
+		})
+	}
+	return created
+}
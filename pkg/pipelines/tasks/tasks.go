@@ -1,8 +1,13 @@
 package tasks
 
 import (
+	"fmt"
+	"sort"
+	"strings"
+
 	pipelinev1 "github.com/tektoncd/pipeline/pkg/apis/pipeline/v1beta1"
 	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
 
 	"github.com/rhd-gitops-example/gitops-cli/pkg/pipelines/meta"
 )
@@ -49,3 +54,58 @@ func createContainer(name string, image string, workingDir string, cmd []string,
 		Args:       args,
 	}
 }
+
+// ParseResources parses the requests and limits for the steps of a Task from
+// keys of the form "requests.<resource>" or "limits.<resource>" e.g.
+// "limits.memory=1Gi".
+func ParseResources(values map[string]string) (corev1.ResourceRequirements, error) {
+	var reqs corev1.ResourceRequirements
+	keys := make([]string, 0, len(values))
+	for k := range values {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		parts := strings.SplitN(k, ".", 2)
+		if len(parts) != 2 || parts[1] == "" {
+			return reqs, fmt.Errorf("invalid resource %q, must be requests.<resource> or limits.<resource>", k)
+		}
+		q, err := resource.ParseQuantity(values[k])
+		if err != nil {
+			return reqs, fmt.Errorf("invalid quantity %q for resource %s: %w", values[k], k, err)
+		}
+		switch parts[0] {
+		case "requests":
+			if reqs.Requests == nil {
+				reqs.Requests = corev1.ResourceList{}
+			}
+			reqs.Requests[corev1.ResourceName(parts[1])] = q
+		case "limits":
+			if reqs.Limits == nil {
+				reqs.Limits = corev1.ResourceList{}
+			}
+			reqs.Limits[corev1.ResourceName(parts[1])] = q
+		default:
+			return reqs, fmt.Errorf("invalid resource %q, must be requests.<resource> or limits.<resource>", k)
+		}
+	}
+	for name, request := range reqs.Requests {
+		if limit, ok := reqs.Limits[name]; ok && request.Cmp(limit) > 0 {
+			return reqs, fmt.Errorf("the %s request %s is greater than the limit %s", name, request.String(), limit.String())
+		}
+	}
+	return reqs, nil
+}
+
+// WithStepResources sets the requests and limits for all the steps of the
+// Task, if no requests or limits are provided, the Task is unchanged.
+func WithStepResources(task pipelinev1.Task, reqs corev1.ResourceRequirements) pipelinev1.Task {
+	if len(reqs.Requests) == 0 && len(reqs.Limits) == 0 {
+		return task
+	}
+	if task.Spec.StepTemplate == nil {
+		task.Spec.StepTemplate = &corev1.Container{}
+	}
+	task.Spec.StepTemplate.Resources = reqs
+	return task
+}
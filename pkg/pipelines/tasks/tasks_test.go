@@ -1,11 +1,13 @@
 package tasks
 
 import (
+	"strings"
 	"testing"
 
 	"github.com/google/go-cmp/cmp"
 	pipelinev1 "github.com/tektoncd/pipeline/pkg/apis/pipeline/v1beta1"
 	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
 	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
@@ -33,7 +35,7 @@ func TestDeployFromSourceTask(t *testing.T) {
 			},
 		},
 	}
-	deployFromSourceTask := CreateDeployFromSourceTask(testNS, "test")
+	deployFromSourceTask := CreateDeployFromSourceTask(testNS, "test", "")
 	if diff := cmp.Diff(wantedTask, deployFromSourceTask); diff != "" {
 		t.Fatalf("CreateDeployFromSourceTask() failed \n%s", diff)
 	}
@@ -93,3 +95,84 @@ func TestCreateTaskResource(t *testing.T) {
 		t.Fatalf("createTaskResource() failed:\n%s", diff)
 	}
 }
+
+func TestParseResources(t *testing.T) {
+	reqs, err := ParseResources(map[string]string{
+		"requests.cpu":    "250m",
+		"requests.memory": "256Mi",
+		"limits.memory":   "1Gi",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := corev1.ResourceRequirements{
+		Requests: corev1.ResourceList{
+			corev1.ResourceCPU:    resource.MustParse("250m"),
+			corev1.ResourceMemory: resource.MustParse("256Mi"),
+		},
+		Limits: corev1.ResourceList{
+			corev1.ResourceMemory: resource.MustParse("1Gi"),
+		},
+	}
+	if diff := cmp.Diff(want, reqs); diff != "" {
+		t.Fatalf("ParseResources() failed:\n%s", diff)
+	}
+}
+
+func TestParseResourcesErrors(t *testing.T) {
+	resourceTests := []struct {
+		values  map[string]string
+		wantErr string
+	}{
+		{map[string]string{"memory": "1Gi"}, `invalid resource "memory", must be requests.<resource> or limits.<resource>`},
+		{map[string]string{"maximum.memory": "1Gi"}, `invalid resource "maximum.memory", must be requests.<resource> or limits.<resource>`},
+		{map[string]string{"limits.memory": "lots"}, `invalid quantity "lots" for resource limits.memory`},
+		{map[string]string{"requests.cpu": "2", "limits.cpu": "1"}, "the cpu request 2 is greater than the limit 1"},
+	}
+
+	for _, tt := range resourceTests {
+		_, err := ParseResources(tt.values)
+		if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+			t.Errorf("ParseResources(%v) got error %v, want %q", tt.values, err, tt.wantErr)
+		}
+	}
+}
+
+func TestWithStepResources(t *testing.T) {
+	reqs := corev1.ResourceRequirements{
+		Limits: corev1.ResourceList{corev1.ResourceMemory: resource.MustParse("1Gi")},
+	}
+
+	task := WithStepResources(CreateDeployFromSourceTask(testNS, "test", ""), reqs)
+
+	if diff := cmp.Diff(&corev1.Container{Resources: reqs}, task.Spec.StepTemplate); diff != "" {
+		t.Fatalf("WithStepResources() failed:\n%s", diff)
+	}
+	task = WithStepResources(CreateDeployFromSourceTask(testNS, "test", ""), corev1.ResourceRequirements{})
+	if task.Spec.StepTemplate != nil {
+		t.Fatalf("WithStepResources() got step template %v, want none", task.Spec.StepTemplate)
+	}
+}
+
+func TestCreateScaffoldTask(t *testing.T) {
+	task := CreateScaffoldTask(testNS, "build-rust", 3, 2)
+
+	if task.Name != "build-rust" || task.Namespace != testNS {
+		t.Fatalf("got Task %s/%s, want %s/build-rust", task.Namespace, task.Name, testNS)
+	}
+	if l := len(task.Spec.Steps); l != 3 {
+		t.Fatalf("got %d steps, want 3", l)
+	}
+	want := []pipelinev1.WorkspaceDeclaration{
+		{Name: "workspace-1", Description: "TODO: describe the files that the Task uses from this workspace."},
+		{Name: "workspace-2", Description: "TODO: describe the files that the Task uses from this workspace."},
+	}
+	if diff := cmp.Diff(want, task.Spec.Workspaces); diff != "" {
+		t.Fatalf("workspaces didn't match:\n%s", diff)
+	}
+	for _, step := range task.Spec.Steps {
+		if step.WorkingDir != "$(workspaces.workspace-1.path)" {
+			t.Fatalf("step %s has working directory %q, want the first workspace", step.Name, step.WorkingDir)
+		}
+	}
+}
@@ -15,6 +15,9 @@ type tektonBuilder struct {
 	files      res.Resources
 	gitOpsRepo string
 	triggers   []v1alpha1.EventListenerTrigger
+	// Triggers for services with a TriggerBranch, these aren't filtered by
+	// the Branches of the PipelinesConfig.
+	branchTriggers []v1alpha1.EventListenerTrigger
 }
 
 func buildEventListenerResources(gitOpsRepo string, m *config.Manifest) (res.Resources, error) {
@@ -37,12 +40,13 @@ func buildEventListenerResources(gitOpsRepo string, m *config.Manifest) (res.Res
 		return nil, err
 	}
 	cicdPath := config.PathForPipelines(cfg)
-	files[getEventListenerPath(cicdPath)] = eventlisteners.CreateELFromTriggers(cfg.Name, saName, tb.triggers)
+	triggers = append(eventlisteners.FilterBranches(tb.triggers, cfg.Branches), tb.branchTriggers...)
+	files[getEventListenerPath(cicdPath)] = eventlisteners.CreateELFromTriggers(cfg.Name, saName, triggers)
 	return files, nil
 }
 
 func (tb *tektonBuilder) Service(app *config.Application, env *config.Environment, svc *config.Service) error {
-	if svc.SourceURL == "" {
+	if svc.SourceURL == "" || !svc.Builds() {
 		return nil
 	}
 	repo, err := scm.NewRepository(svc.SourceURL)
@@ -51,6 +55,11 @@ func (tb *tektonBuilder) Service(app *config.Application, env *config.Environmen
 	}
 	pipelines := getPipelines(env, svc, repo)
 	ciTrigger := repo.CreatePushTrigger(triggerName(svc.Name), svc.Webhook.Secret.Name, svc.Webhook.Secret.Namespace, pipelines.Integration.Template, pipelines.Integration.Bindings)
+	if svc.TriggerBranch != "" {
+		ciTrigger.Interceptors = append(ciTrigger.Interceptors, eventlisteners.BranchFilter([]string{svc.TriggerBranch}))
+		tb.branchTriggers = append(tb.branchTriggers, ciTrigger)
+		return nil
+	}
 	tb.triggers = append(tb.triggers, ciTrigger)
 	return nil
 }
@@ -65,7 +74,7 @@ func createTriggersForCICD(gitOpsRepo string, cfg *config.PipelinesConfig) ([]v1
 	if err != nil {
 		return []v1alpha1.EventListenerTrigger{}, err
 	}
-	ciTrigger := repo.CreatePushTrigger("ci-dryrun-from-push", eventlisteners.GitOpsWebhookSecret, cfg.Name, "ci-dryrun-from-push-template", []string{repo.PushBindingName()})
+	ciTrigger := repo.CreatePushTrigger("ci-dryrun-from-push", eventlisteners.WebhookSecretName(cfg.WebhookSecret), cfg.Name, "ci-dryrun-from-push-template", []string{repo.PushBindingName()})
 	triggers = append(triggers, ciTrigger)
 	return triggers, nil
 }
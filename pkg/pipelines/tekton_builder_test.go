@@ -38,6 +38,35 @@ func TestBuildEventListener(t *testing.T) {
 	}
 }
 
+func TestBuildEventListenerWithBranchesAndWebhookSecret(t *testing.T) {
+	m := &config.Manifest{
+		Config: &config.Config{
+			Pipelines: &config.PipelinesConfig{
+				Name:          "test-cicd",
+				Branches:      []string{"main"},
+				WebhookSecret: "gitops-hooks",
+			},
+		},
+		Environments: []*config.Environment{
+			testEnv(testService(), "dev"),
+		},
+	}
+	gitOpsRepo := "http://github.com/org/gitops.git"
+	got, err := buildEventListenerResources(gitOpsRepo, m)
+	assertNoError(t, err)
+
+	el := got[getEventListenerPath(filepath.Join("config", "test-cicd"))].(*triggersv1.EventListener)
+	filter := eventlisteners.BranchFilter([]string{"main"})
+	for _, trigger := range el.Spec.Triggers {
+		if diff := cmp.Diff(filter, trigger.Interceptors[len(trigger.Interceptors)-1]); diff != "" {
+			t.Errorf("trigger %s branch filter didn't match:%s\n", trigger.Name, diff)
+		}
+	}
+	if secret := el.Spec.Triggers[0].Interceptors[0].GitHub.SecretRef.SecretName; secret != "gitops-hooks" {
+		t.Errorf("GitOps trigger secret got %q, want %q", secret, "gitops-hooks")
+	}
+}
+
 func TestBuildEventListenerWithServiceWithNoURL(t *testing.T) {
 	m := &config.Manifest{
 
@@ -0,0 +1,89 @@
+package pipelines
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/openshift/odo/pkg/log"
+	"github.com/spf13/afero"
+
+	"github.com/rhd-gitops-example/gitops-cli/pkg/pipelines/config"
+	"github.com/rhd-gitops-example/gitops-cli/pkg/pipelines/repotemplate"
+	res "github.com/rhd-gitops-example/gitops-cli/pkg/pipelines/resources"
+)
+
+// templateFiles is used to fetch the files from a template repository, it's a
+// var to allow replacement in tests.
+var templateFiles = repotemplate.Files
+
+// writeTemplate writes the files from the template repository to the output
+// path, and returns the generated resources to write over them.
+//
+// Generated files replace the template files, unless TemplateWins is set, in
+// which case the conflicting generated files are dropped.  The manifest is
+// always generated, as the rest of the generated files depend on it.
+func writeTemplate(fs afero.Fs, o *BootstrapOptions, generated res.Resources) (res.Resources, error) {
+	files, err := templateFiles(o.FromTemplate, repotemplate.Options{
+		Token:             o.accessToken(o.FromTemplate),
+		AllowLFSPointers:  o.AllowLFSPointers,
+		KnownHostsFile:    o.SSHKnownHostsFile,
+		AcceptNewHostKeys: o.AcceptNewHostKeys,
+	})
+	if err != nil {
+		return nil, err
+	}
+	files, skipped, err := repotemplate.Render(files, templateVars(o, generated), repotemplate.RenderOptions{
+		All:          len(o.TemplateVars) > 0,
+		AllowMissing: o.AllowMissingVars,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to render template repository %s: %w", o.FromTemplate, err)
+	}
+	for _, k := range skipped {
+		o.warningf("Copied %s from the template repository without rendering it, it can't be parsed or references undefined variables", k)
+	}
+	remaining := res.Resources{}
+	for k, v := range generated {
+		if _, ok := files[k]; ok && o.TemplateWins && k != o.manifestFile() {
+			log.Infof("Keeping %s from the template repository", k)
+			continue
+		}
+		remaining[k] = v
+	}
+	for k, v := range files {
+		filename := filepath.Join(o.OutputPath, filepath.FromSlash(k))
+		if err := fs.MkdirAll(filepath.Dir(filename), 0755); err != nil {
+			return nil, fmt.Errorf("failed to create directory for %s: %w", filename, err)
+		}
+		if err := afero.WriteFile(fs, filename, v, 0644); err != nil {
+			return nil, fmt.Errorf("failed to write template file %s: %w", filename, err)
+		}
+	}
+	log.Successf("Copied %d files from template repository %s", len(files), o.FromTemplate)
+	return remaining, nil
+}
+
+// templateVars returns the variables that the template files are rendered
+// with, the built-in variables from the bootstrap options and the generated
+// manifest, and the TemplateVars.
+func templateVars(o *BootstrapOptions, generated res.Resources) map[string]interface{} {
+	vars := map[string]interface{}{}
+	for k, v := range o.TemplateVars {
+		vars[k] = v
+	}
+	vars[repotemplate.PrefixVar] = strings.TrimSuffix(o.Prefix, "-")
+	org := ""
+	if orgRepo, err := orgRepoFromURL(o.GitOpsRepoURL); err == nil && strings.Contains(orgRepo, "/") {
+		org = strings.Split(orgRepo, "/")[0]
+	}
+	vars[repotemplate.OrgVar] = org
+	environments := []string{}
+	if m, ok := generated[o.manifestFile()].(*config.Manifest); ok {
+		for _, env := range m.Environments {
+			environments = append(environments, env.Name)
+		}
+	}
+	vars[repotemplate.EnvironmentsVar] = environments
+	return vars
+}
@@ -0,0 +1,159 @@
+package pipelines
+
+import (
+	"fmt"
+	"path/filepath"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/spf13/afero"
+
+	"github.com/rhd-gitops-example/gitops-cli/pkg/pipelines/config"
+	"github.com/rhd-gitops-example/gitops-cli/pkg/pipelines/ioutils"
+	"github.com/rhd-gitops-example/gitops-cli/pkg/pipelines/repotemplate"
+	res "github.com/rhd-gitops-example/gitops-cli/pkg/pipelines/resources"
+)
+
+func TestWriteTemplate(t *testing.T) {
+	defer stubTemplateFiles(t, map[string][]byte{
+		"README.md":          []byte("# Template\n"),
+		"pipelines.yaml":     []byte("environments: []\n"),
+		"config/kustom.yaml": []byte("template: true\n"),
+	})()
+
+	templateTests := []struct {
+		name         string
+		templateWins bool
+		want         []string
+	}{
+		{"generated wins", false, []string{"config/kustom.yaml", "pipelines.yaml"}},
+		{"template wins", true, []string{"pipelines.yaml"}},
+	}
+
+	for _, tt := range templateTests {
+		t.Run(tt.name, func(rt *testing.T) {
+			fakeFs := ioutils.NewMemoryFilesystem()
+			o := &BootstrapOptions{
+				OutputPath:   "/tmp/gitops",
+				FromTemplate: "https://github.com/org/template.git",
+				TemplateWins: tt.templateWins,
+			}
+			generated := res.Resources{
+				"pipelines.yaml":     map[string]interface{}{"environments": []string{"dev"}},
+				"config/kustom.yaml": map[string]interface{}{"template": false},
+			}
+
+			remaining, err := writeTemplate(fakeFs, o, generated)
+			assertNoError(rt, err)
+
+			if diff := cmp.Diff(tt.want, getResourceFiles(remaining)); diff != "" {
+				rt.Fatalf("remaining files failed:\n%s", diff)
+			}
+			b, err := afero.ReadFile(fakeFs, filepath.Join("/tmp/gitops", "README.md"))
+			assertNoError(rt, err)
+			if string(b) != "# Template\n" {
+				rt.Fatalf("template file not written, got %q", b)
+			}
+		})
+	}
+}
+
+func stubTemplateFiles(t *testing.T, files map[string][]byte) func() {
+	origFunc := templateFiles
+	templateFiles = func(repoURL string, o repotemplate.Options) (map[string][]byte, error) {
+		return files, nil
+	}
+	return func() {
+		templateFiles = origFunc
+	}
+}
+
+func TestWriteTemplateCopiesOtherTemplateSyntax(t *testing.T) {
+	files := map[string]string{
+		"charts/app/templates/deployment.yaml": "image: {{ .Values.image }}\n",
+		".github/workflows/ci.yaml":            "token: ${{ secrets.GITHUB_TOKEN }}\n",
+	}
+	repoFiles := map[string][]byte{
+		"README.md.tmpl": []byte("# {{ .Org }}\n"),
+	}
+	for k, v := range files {
+		repoFiles[k] = []byte(v)
+	}
+	defer stubTemplateFiles(t, repoFiles)()
+	fakeFs := ioutils.NewMemoryFilesystem()
+	o := &BootstrapOptions{
+		OutputPath:    "/tmp/gitops",
+		GitOpsRepoURL: "https://github.com/my-org/gitops.git",
+		FromTemplate:  "https://github.com/org/template.git",
+	}
+
+	_, err := writeTemplate(fakeFs, o, res.Resources{})
+	assertNoError(t, err)
+
+	files["README.md"] = "# my-org\n"
+	for k, v := range files {
+		b, err := afero.ReadFile(fakeFs, filepath.Join("/tmp/gitops", k))
+		assertNoError(t, err)
+		if diff := cmp.Diff(v, string(b)); diff != "" {
+			t.Errorf("%s copied incorrectly:\n%s", k, diff)
+		}
+	}
+}
+
+func TestWriteTemplateWarnsOfUnrenderedFiles(t *testing.T) {
+	defer stubTemplateFiles(t, map[string][]byte{
+		"owner.yaml.tmpl": []byte("owner: {{ .Owner }}\n"),
+	})()
+	warnings := []string{}
+	o := &BootstrapOptions{
+		OutputPath:       "/tmp/gitops",
+		FromTemplate:     "https://github.com/org/template.git",
+		AllowMissingVars: true,
+		Warnf: func(format string, a ...interface{}) {
+			warnings = append(warnings, fmt.Sprintf(format, a...))
+		},
+	}
+
+	_, err := writeTemplate(ioutils.NewMemoryFilesystem(), o, res.Resources{})
+	assertNoError(t, err)
+
+	want := []string{"Copied owner.yaml.tmpl from the template repository without rendering it, it can't be parsed or references undefined variables"}
+	if diff := cmp.Diff(want, warnings); diff != "" {
+		t.Fatalf("warnings failed:\n%s", diff)
+	}
+}
+
+func TestWriteTemplateRendersVariables(t *testing.T) {
+	defer stubTemplateFiles(t, map[string][]byte{
+		"README.md":    []byte("# {{ .TeamName }} in {{ .Org }}\n{{ range .Environments }}- {{ . }}\n{{ end }}Prefix: {{ .Prefix }}\n"),
+		"scripts/x.sh": []byte("echo $(params.name)\n"),
+	})()
+	fakeFs := ioutils.NewMemoryFilesystem()
+	o := &BootstrapOptions{
+		OutputPath:    "/tmp/gitops",
+		GitOpsRepoURL: "https://github.com/my-org/gitops.git",
+		Prefix:        "tst-",
+		FromTemplate:  "https://github.com/org/template.git",
+		TemplateVars:  map[string]string{"TeamName": "payments"},
+	}
+	generated := res.Resources{
+		"pipelines.yaml": &config.Manifest{
+			Environments: []*config.Environment{{Name: "tst-dev"}, {Name: "tst-stage"}},
+		},
+	}
+
+	_, err := writeTemplate(fakeFs, o, generated)
+	assertNoError(t, err)
+
+	want := map[string]string{
+		"README.md":    "# payments in my-org\n- tst-dev\n- tst-stage\nPrefix: tst\n",
+		"scripts/x.sh": "echo $(params.name)\n",
+	}
+	for k, v := range want {
+		b, err := afero.ReadFile(fakeFs, filepath.Join("/tmp/gitops", k))
+		assertNoError(t, err)
+		if diff := cmp.Diff(v, string(b)); diff != "" {
+			t.Errorf("%s rendered incorrectly:\n%s", k, diff)
+		}
+	}
+}
@@ -11,15 +11,22 @@ var (
 )
 
 // CreateImageRepoBinding returns a TriggerBinding with the imageRepo.
-func CreateImageRepoBinding(ns, bindingName, imageRepo, tlsVerify string) triggersv1.TriggerBinding {
+//
+// If the contextDir is not empty, it's bound as the directory to build,
+// otherwise the template's default of the repository root is used.
+func CreateImageRepoBinding(ns, bindingName, imageRepo, tlsVerify, contextDir string) triggersv1.TriggerBinding {
+	params := []triggersv1.Param{
+		createBindingParam("imageRepo", imageRepo),
+		createBindingParam("tlsVerify", tlsVerify),
+	}
+	if contextDir != "" {
+		params = append(params, createBindingParam("contextDir", contextDir))
+	}
 	return triggersv1.TriggerBinding{
 		TypeMeta:   TriggerBindingTypeMeta,
 		ObjectMeta: meta.ObjectMeta(meta.NamespacedName(ns, bindingName)),
 		Spec: triggersv1.TriggerBindingSpec{
-			Params: []triggersv1.Param{
-				createBindingParam("imageRepo", imageRepo),
-				createBindingParam("tlsVerify", tlsVerify),
-			},
+			Params: params,
 		},
 	}
 }
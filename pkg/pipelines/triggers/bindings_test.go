@@ -43,7 +43,7 @@ func TestCreateImageRepoBinding(t *testing.T) {
 			},
 		},
 	}
-	binding := CreateImageRepoBinding("testns", "test-binding", "quay.io/user/testing", "true")
+	binding := CreateImageRepoBinding("testns", "test-binding", "quay.io/user/testing", "true", "")
 	if diff := cmp.Diff(imageRepoBinding, binding); diff != "" {
 		t.Fatalf("CreateImageRepoBinding() failed:\n%s", diff)
 	}
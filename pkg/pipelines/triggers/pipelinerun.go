@@ -1,8 +1,11 @@
 package triggers
 
 import (
+	"time"
+
 	pipelinev1alpha1 "github.com/tektoncd/pipeline/pkg/apis/pipeline/v1alpha1"
 	pipelinev1 "github.com/tektoncd/pipeline/pkg/apis/pipeline/v1beta1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 
 	"github.com/rhd-gitops-example/gitops-cli/pkg/pipelines/meta"
 )
@@ -11,8 +14,29 @@ var (
 	pipelineRunTypeMeta = meta.TypeMeta("PipelineRun", "tekton.dev/v1beta1")
 )
 
-func createDevCDPipelineRun(saName string) pipelinev1.PipelineRun {
-	return pipelinev1.PipelineRun{
+// PipelineRunOption configures the PipelineRuns created by the generated
+// TriggerTemplates.
+type PipelineRunOption func(*pipelinev1.PipelineRun)
+
+// PipelineRunTimeout sets the timeout of the PipelineRuns, a zero duration
+// leaves the Tekton default.
+func PipelineRunTimeout(d time.Duration) PipelineRunOption {
+	return func(pr *pipelinev1.PipelineRun) {
+		if d > 0 {
+			pr.Spec.Timeout = &metav1.Duration{Duration: d}
+		}
+	}
+}
+
+func withOptions(pr pipelinev1.PipelineRun, opts []PipelineRunOption) pipelinev1.PipelineRun {
+	for _, o := range opts {
+		o(&pr)
+	}
+	return pr
+}
+
+func createDevCDPipelineRun(saName string, opts ...PipelineRunOption) pipelinev1.PipelineRun {
+	return withOptions(pipelinev1.PipelineRun{
 		TypeMeta:   pipelineRunTypeMeta,
 		ObjectMeta: meta.ObjectMeta(meta.NamespacedName("", "app-cd-pipeline-run-$(uid)")),
 		Spec: pipelinev1.PipelineRunSpec{
@@ -20,11 +44,11 @@ func createDevCDPipelineRun(saName string) pipelinev1.PipelineRun {
 			PipelineRef:        createPipelineRef("app-cd-pipeline"),
 			Resources:          createDevResource("$(params." + GitCommitID + ")"),
 		},
-	}
+	}, opts)
 }
 
-func createDevCIPipelineRun(saName string) pipelinev1.PipelineRun {
-	return pipelinev1.PipelineRun{
+func createDevCIPipelineRun(saName string, opts ...PipelineRunOption) pipelinev1.PipelineRun {
+	return withOptions(pipelinev1.PipelineRun{
 		TypeMeta:   pipelineRunTypeMeta,
 		ObjectMeta: meta.ObjectMeta(meta.NamespacedName("", "app-ci-pipeline-run-$(uid)"), statusTrackerAnnotations("dev-ci-build-from-pr", "CI build on push event")),
 		Spec: pipelinev1.PipelineRunSpec{
@@ -39,15 +63,16 @@ func createDevCIPipelineRun(saName string) pipelinev1.PipelineRun {
 				createPipelineBindingParam("COMMIT_DATE", "$(params."+GitCommitDate+")"),
 				createPipelineBindingParam("COMMIT_AUTHOR", "$(params."+GitCommitAuthor+")"),
 				createPipelineBindingParam("COMMIT_MESSAGE", "$(params."+GitCommitMessage+")"),
+				createPipelineBindingParam("CONTEXT", "$(params.contextDir)"),
 			},
 			Resources: createDevResource("$(params." + GitCommitID + ")"),
 		},
-	}
+	}, opts)
 
 }
 
-func createCDPipelineRun(saName string) pipelinev1.PipelineRun {
-	return pipelinev1.PipelineRun{
+func createCDPipelineRun(saName string, opts ...PipelineRunOption) pipelinev1.PipelineRun {
+	return withOptions(pipelinev1.PipelineRun{
 		TypeMeta:   pipelineRunTypeMeta,
 		ObjectMeta: meta.ObjectMeta(meta.NamespacedName("", "cd-deploy-from-push-pipeline-$(uid)")),
 		Spec: pipelinev1.PipelineRunSpec{
@@ -55,11 +80,11 @@ func createCDPipelineRun(saName string) pipelinev1.PipelineRun {
 			PipelineRef:        createPipelineRef("cd-deploy-from-push-pipeline"),
 			Resources:          createResources(),
 		},
-	}
+	}, opts)
 }
 
-func createCIPipelineRun(saName string) pipelinev1.PipelineRun {
-	return pipelinev1.PipelineRun{
+func createCIPipelineRun(saName string, opts ...PipelineRunOption) pipelinev1.PipelineRun {
+	return withOptions(pipelinev1.PipelineRun{
 		TypeMeta:   pipelineRunTypeMeta,
 		ObjectMeta: meta.ObjectMeta(meta.NamespacedName("", "ci-dryrun-from-push-pipeline-$(uid)"), statusTrackerAnnotations("ci-dryrun-from-push-pipeline", "CI dry run on push event")),
 		Spec: pipelinev1.PipelineRunSpec{
@@ -67,7 +92,7 @@ func createCIPipelineRun(saName string) pipelinev1.PipelineRun {
 			PipelineRef:        createPipelineRef("ci-dryrun-from-push-pipeline"),
 			Resources:          createResources(),
 		},
-	}
+	}, opts)
 
 }
 
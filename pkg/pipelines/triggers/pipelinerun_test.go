@@ -2,10 +2,12 @@ package triggers
 
 import (
 	"testing"
+	"time"
 
 	"github.com/google/go-cmp/cmp"
 	pipelinev1alpha1 "github.com/tektoncd/pipeline/pkg/apis/pipeline/v1alpha1"
 	pipelinev1 "github.com/tektoncd/pipeline/pkg/apis/pipeline/v1beta1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 
 	"github.com/rhd-gitops-example/gitops-cli/pkg/pipelines/meta"
 )
@@ -47,6 +49,7 @@ func TestCreateDevCIPipelineRun(t *testing.T) {
 				createPipelineBindingParam("COMMIT_DATE", "$(params.io.openshift.build.commit.date)"),
 				createPipelineBindingParam("COMMIT_AUTHOR", "$(params.io.openshift.build.commit.author)"),
 				createPipelineBindingParam("COMMIT_MESSAGE", "$(params.io.openshift.build.commit.message)"),
+				createPipelineBindingParam("CONTEXT", "$(params.contextDir)"),
 			},
 			Resources: createDevResource("$(params.io.openshift.build.commit.id)"),
 		},
@@ -116,3 +119,16 @@ func TestCreateDevResource(t *testing.T) {
 		t.Fatalf("createDevResource() failed: \n%s", diff)
 	}
 }
+
+func TestCreatePipelineRunWithTimeout(t *testing.T) {
+	template := createCIPipelineRun(sName, PipelineRunTimeout(90*time.Minute))
+	want := &metav1.Duration{Duration: 90 * time.Minute}
+	if diff := cmp.Diff(want, template.Spec.Timeout); diff != "" {
+		t.Fatalf("createCIPipelineRun timeout failed:\n%s", diff)
+	}
+
+	template = createCIPipelineRun(sName, PipelineRunTimeout(0))
+	if template.Spec.Timeout != nil {
+		t.Fatalf("createCIPipelineRun got timeout %v, want the default", template.Spec.Timeout)
+	}
+}
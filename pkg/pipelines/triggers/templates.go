@@ -23,17 +23,17 @@ const (
 )
 
 // GenerateTemplates will return a slice of trigger templates
-func GenerateTemplates(ns, saName string) []triggersv1.TriggerTemplate {
+func GenerateTemplates(ns, saName string, opts ...PipelineRunOption) []triggersv1.TriggerTemplate {
 	return []triggersv1.TriggerTemplate{
-		CreateDevCDDeployTemplate(ns, saName),
-		CreateDevCIBuildPRTemplate(ns, saName),
-		CreateCDPushTemplate(ns, saName),
-		CreateCIDryRunTemplate(ns, saName),
+		CreateDevCDDeployTemplate(ns, saName, opts...),
+		CreateDevCIBuildPRTemplate(ns, saName, opts...),
+		CreateCDPushTemplate(ns, saName, opts...),
+		CreateCIDryRunTemplate(ns, saName, opts...),
 	}
 }
 
 // CreateDevCDDeployTemplate creates DevCDDeployTemplate
-func CreateDevCDDeployTemplate(ns, saName string) triggersv1.TriggerTemplate {
+func CreateDevCDDeployTemplate(ns, saName string, opts ...PipelineRunOption) triggersv1.TriggerTemplate {
 	return triggersv1.TriggerTemplate{
 		TypeMeta:   triggerTemplateTypeMeta,
 		ObjectMeta: meta.ObjectMeta(meta.NamespacedName(ns, "app-cd-template")),
@@ -45,7 +45,7 @@ func CreateDevCDDeployTemplate(ns, saName string) triggersv1.TriggerTemplate {
 			ResourceTemplates: []triggersv1.TriggerResourceTemplate{
 				{
 					RawExtension: runtime.RawExtension{
-						Raw: createDevCDResourceTemplate(saName),
+						Raw: createDevCDResourceTemplate(saName, opts...),
 					},
 				},
 			},
@@ -54,7 +54,7 @@ func CreateDevCDDeployTemplate(ns, saName string) triggersv1.TriggerTemplate {
 }
 
 // CreateDevCIBuildPRTemplate creates DevCIBuildPRTemplate
-func CreateDevCIBuildPRTemplate(ns, saName string) triggersv1.TriggerTemplate {
+func CreateDevCIBuildPRTemplate(ns, saName string, opts ...PipelineRunOption) triggersv1.TriggerTemplate {
 	return triggersv1.TriggerTemplate{
 		TypeMeta: triggerTemplateTypeMeta,
 		ObjectMeta: meta.ObjectMeta(
@@ -70,11 +70,12 @@ func CreateDevCIBuildPRTemplate(ns, saName string) triggersv1.TriggerTemplate {
 				createTemplateParamSpec("fullname", "The GitHub repository for this PullRequest."),
 				createTemplateParamSpec("imageRepo", "The repository to push built images to."),
 				createTemplateParamSpec("tlsVerify", "Enable image repostiory TLS certification verification."),
+				createTemplateParamSpecDefault("contextDir", "The directory within the repository to build.", "."),
 			},
 			ResourceTemplates: []triggersv1.TriggerResourceTemplate{
 				{
 					RawExtension: runtime.RawExtension{
-						Raw: createDevCIResourceTemplate(saName),
+						Raw: createDevCIResourceTemplate(saName, opts...),
 					},
 				},
 			},
@@ -84,7 +85,7 @@ func CreateDevCIBuildPRTemplate(ns, saName string) triggersv1.TriggerTemplate {
 }
 
 // CreateCDPushTemplate returns TriggerTemplate for CD Push Request
-func CreateCDPushTemplate(ns, saName string) triggersv1.TriggerTemplate {
+func CreateCDPushTemplate(ns, saName string, opts ...PipelineRunOption) triggersv1.TriggerTemplate {
 	return triggersv1.TriggerTemplate{
 		TypeMeta:   triggerTemplateTypeMeta,
 		ObjectMeta: meta.ObjectMeta(meta.NamespacedName(ns, "cd-deploy-from-push-template")),
@@ -100,7 +101,7 @@ func CreateCDPushTemplate(ns, saName string) triggersv1.TriggerTemplate {
 			ResourceTemplates: []triggersv1.TriggerResourceTemplate{
 				{
 					RawExtension: runtime.RawExtension{
-						Raw: createCDResourceTemplate(saName),
+						Raw: createCDResourceTemplate(saName, opts...),
 					},
 				},
 			},
@@ -109,7 +110,7 @@ func CreateCDPushTemplate(ns, saName string) triggersv1.TriggerTemplate {
 }
 
 // CreateCIDryRunTemplate returns TriggerTemplate for CI Dry Try
-func CreateCIDryRunTemplate(ns, saName string) triggersv1.TriggerTemplate {
+func CreateCIDryRunTemplate(ns, saName string, opts ...PipelineRunOption) triggersv1.TriggerTemplate {
 	return triggersv1.TriggerTemplate{
 		TypeMeta:   triggerTemplateTypeMeta,
 		ObjectMeta: meta.ObjectMeta(meta.NamespacedName(ns, "ci-dryrun-from-push-template")),
@@ -122,7 +123,7 @@ func CreateCIDryRunTemplate(ns, saName string) triggersv1.TriggerTemplate {
 			ResourceTemplates: []triggersv1.TriggerResourceTemplate{
 				{
 					RawExtension: runtime.RawExtension{
-						Raw: createCIResourceTemplate(saName),
+						Raw: createCIResourceTemplate(saName, opts...),
 					},
 				},
 			},
@@ -145,23 +146,23 @@ func createTemplateParamSpec(name string, description string) triggersv1.ParamSp
 	}
 }
 
-func createDevCDResourceTemplate(saName string) []byte {
-	byteTemplate, _ := json.Marshal(createDevCDPipelineRun(saName))
+func createDevCDResourceTemplate(saName string, opts ...PipelineRunOption) []byte {
+	byteTemplate, _ := json.Marshal(createDevCDPipelineRun(saName, opts...))
 	return byteTemplate
 }
 
-func createDevCIResourceTemplate(saName string) []byte {
-	byteTemplateCI, _ := json.Marshal(createDevCIPipelineRun(saName))
+func createDevCIResourceTemplate(saName string, opts ...PipelineRunOption) []byte {
+	byteTemplateCI, _ := json.Marshal(createDevCIPipelineRun(saName, opts...))
 	return byteTemplateCI
 }
 
-func createCDResourceTemplate(saName string) []byte {
-	byteStageCD, _ := json.Marshal(createCDPipelineRun(saName))
+func createCDResourceTemplate(saName string, opts ...PipelineRunOption) []byte {
+	byteStageCD, _ := json.Marshal(createCDPipelineRun(saName, opts...))
 	return byteStageCD
 }
 
-func createCIResourceTemplate(saName string) []byte {
-	byteStageCI, _ := json.Marshal(createCIPipelineRun(saName))
+func createCIResourceTemplate(saName string, opts ...PipelineRunOption) []byte {
+	byteStageCI, _ := json.Marshal(createCIPipelineRun(saName, opts...))
 	return byteStageCI
 }
 
@@ -89,6 +89,11 @@ func TestCreateDevCIBuildPRTemplate(t *testing.T) {
 					Name:        "tlsVerify",
 					Description: "Enable image repostiory TLS certification verification.",
 				},
+				{
+					Name:        "contextDir",
+					Description: "The directory within the repository to build.",
+					Default:     strPtr("."),
+				},
 			},
 			ResourceTemplates: []triggersv1.TriggerResourceTemplate{
 				{
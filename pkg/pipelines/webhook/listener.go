@@ -0,0 +1,119 @@
+package webhook
+
+import (
+	"bytes"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/rhd-gitops-example/gitops-cli/pkg/pipelines/network"
+)
+
+// listenerTimeout is how long to wait for the EventListener to respond.
+const listenerTimeout = 10 * time.Second
+
+// pingPayload is the body of a GitHub ping event, EventListeners don't trigger
+// any pipelines for it.
+const pingPayload = `{"zen":"Keep it logically awesome.","hook_id":0}`
+
+// ListenerReport describes the response of an EventListener to a ping.
+type ListenerReport struct {
+	URL        string
+	StatusCode int
+	Status     string
+	TLS        bool   // True if the EventListener is served over HTTPS.
+	TLSError   string // Why the certificate is not trusted, if it isn't.
+}
+
+// CheckListener sends a ping to the EventListener at the URL, and reports the
+// response, an error is returned if the EventListener can't be reached.
+//
+// An untrusted certificate is reported rather than failing, and the ping is
+// sent again without verifying the certificate, to report the status.
+//
+// Requests use the proxy from the environment, and trust the certificate
+// authorities in the caFile, if provided, as well as the system's.
+func CheckListener(listenerURL, caFile string) (*ListenerReport, error) {
+	u, err := url.Parse(listenerURL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return nil, fmt.Errorf("invalid EventListener URL %q, it must be an http or https URL", listenerURL)
+	}
+	tlsConfig, err := listenerTLSConfig(caFile)
+	if err != nil {
+		return nil, err
+	}
+	report := &ListenerReport{URL: listenerURL, TLS: u.Scheme == "https"}
+	resp, err := ping(listenerClient(tlsConfig), listenerURL)
+	if err != nil && isCertificateError(err) {
+		report.TLSError = errors.Unwrap(err).Error()
+		tlsConfig.InsecureSkipVerify = true
+		resp, err = ping(listenerClient(tlsConfig), listenerURL)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("unable to reach the EventListener at %s: %w", listenerURL, err)
+	}
+	report.StatusCode = resp.StatusCode
+	report.Status = resp.Status
+	return report, nil
+}
+
+func ping(client *http.Client, listenerURL string) (*http.Response, error) {
+	req, err := http.NewRequest(http.MethodPost, listenerURL, bytes.NewBufferString(pingPayload))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-GitHub-Event", "ping")
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	_, _ = ioutil.ReadAll(resp.Body)
+	return resp, nil
+}
+
+func listenerClient(tlsConfig *tls.Config) *http.Client {
+	transport := &http.Transport{
+		Proxy:           http.ProxyFromEnvironment,
+		TLSClientConfig: tlsConfig,
+	}
+	if network.Restricted() {
+		transport.Proxy = nil
+		transport.DialContext = network.Dial
+	}
+	return &http.Client{
+		Timeout:   listenerTimeout,
+		Transport: transport,
+	}
+}
+
+func listenerTLSConfig(caFile string) (*tls.Config, error) {
+	if caFile == "" {
+		return &tls.Config{}, nil
+	}
+	b, err := ioutil.ReadFile(caFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read the CA file: %w", err)
+	}
+	pool, err := x509.SystemCertPool()
+	if err != nil {
+		pool = x509.NewCertPool()
+	}
+	if !pool.AppendCertsFromPEM(b) {
+		return nil, fmt.Errorf("no certificates found in the CA file %s", caFile)
+	}
+	return &tls.Config{RootCAs: pool}, nil
+}
+
+func isCertificateError(err error) bool {
+	var unknownAuthority x509.UnknownAuthorityError
+	var hostname x509.HostnameError
+	var invalid x509.CertificateInvalidError
+	return errors.As(err, &unknownAuthority) || errors.As(err, &hostname) || errors.As(err, &invalid)
+}
@@ -0,0 +1,89 @@
+package webhook
+
+import (
+	"encoding/pem"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/rhd-gitops-example/gitops-cli/pkg/pipelines/helper"
+)
+
+func TestCheckListener(t *testing.T) {
+	statusTests := []int{http.StatusAccepted, http.StatusNotFound, http.StatusServiceUnavailable}
+
+	for _, status := range statusTests {
+		t.Run(http.StatusText(status), func(rt *testing.T) {
+			ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if r.Method != http.MethodPost || r.Header.Get("X-GitHub-Event") != "ping" {
+					rt.Errorf("got %s request for %q event, want a ping", r.Method, r.Header.Get("X-GitHub-Event"))
+				}
+				w.WriteHeader(status)
+			}))
+			defer ts.Close()
+
+			report, err := CheckListener(ts.URL, "")
+			if err != nil {
+				rt.Fatal(err)
+			}
+
+			want := &ListenerReport{URL: ts.URL, StatusCode: status, Status: fmt.Sprintf("%d %s", status, http.StatusText(status))}
+			if diff := cmp.Diff(want, report); diff != "" {
+				rt.Fatalf("CheckListener() failed:\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestCheckListenerWithTLS(t *testing.T) {
+	ts := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusAccepted)
+	}))
+	defer ts.Close()
+	dir, err := ioutil.TempDir("", "listener")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	caFile := filepath.Join(dir, "ca.pem")
+	err = ioutil.WriteFile(caFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: ts.Certificate().Raw}), 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	report, err := CheckListener(ts.URL, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if report.TLSError == "" || report.StatusCode != http.StatusAccepted {
+		t.Fatalf("got %#v, want an untrusted certificate and the status", report)
+	}
+
+	report, err = CheckListener(ts.URL, caFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := &ListenerReport{URL: ts.URL, TLS: true, StatusCode: http.StatusAccepted, Status: "202 Accepted"}
+	if diff := cmp.Diff(want, report); diff != "" {
+		t.Fatalf("CheckListener() with CA file failed:\n%s", diff)
+	}
+}
+
+func TestCheckListenerUnreachable(t *testing.T) {
+	ts := httptest.NewServer(http.NotFoundHandler())
+	ts.Close()
+
+	_, err := CheckListener(ts.URL, "")
+	helper.AssertErrorMatch(t, "unable to reach the EventListener at "+ts.URL, err)
+}
+
+func TestCheckListenerWithInvalidURL(t *testing.T) {
+	_, err := CheckListener("gitops-webhook.example.com", "")
+	helper.AssertErrorMatch(t, `invalid EventListener URL "gitops-webhook.example.com"`, err)
+}
@@ -0,0 +1,83 @@
+package webhook
+
+import (
+	"fmt"
+	"net"
+	"net/url"
+
+	"github.com/rhd-gitops-example/gitops-cli/pkg/pipelines/scm"
+)
+
+// publicGitHosts are the hosts of the SaaS Git hosting services, these deliver
+// webhooks from the internet.
+var publicGitHosts = map[string]bool{
+	"github.com":    true,
+	"gitlab.com":    true,
+	"bitbucket.org": true,
+}
+
+// privateNetworks are the RFC1918 IPv4 networks, and the IPv6 unique local
+// network, addresses in these can't be reached from the internet.
+var privateNetworks = parseCIDRs("10.0.0.0/8", "172.16.0.0/12", "192.168.0.0/16", "fc00::/7")
+
+// lookupIP is a var so that it can be replaced in tests.
+var lookupIP = net.LookupIP
+
+// CheckReach returns an error if the target Git repository is on a SaaS Git
+// hosting service, but the EventListener resolves to a private address, as the
+// service is unlikely to be able to deliver webhooks to it.
+//
+// This is a best-effort check, if the EventListener host can't be resolved,
+// no error is returned.
+func CheckReach(pipelinesFile string, serviceName *QualifiedServiceName, isCICD bool) error {
+	webhook, err := newWebhookInfo("", nil, pipelinesFile, serviceName, isCICD)
+	if err != nil {
+		return err
+	}
+	return checkReach(webhook.gitRepoURL, webhook.listenerURL)
+}
+
+func checkReach(gitRepoURL, listenerURL string) error {
+	repoHost, err := scm.HostnameFromURL(gitRepoURL)
+	if err != nil {
+		return fmt.Errorf("failed to get hostname from URL %q: %w", gitRepoURL, err)
+	}
+	if !publicGitHosts[repoHost] {
+		return nil
+	}
+	u, err := url.Parse(listenerURL)
+	if err != nil {
+		return fmt.Errorf("failed to parse EventListener URL %q: %w", listenerURL, err)
+	}
+	ips, err := lookupIP(u.Hostname())
+	if err != nil {
+		return nil
+	}
+	for _, ip := range ips {
+		if isPrivateIP(ip) {
+			return fmt.Errorf("the EventListener at %s resolves to the private address %s, %s is unlikely to be able to deliver webhooks to it", listenerURL, ip, repoHost)
+		}
+	}
+	return nil
+}
+
+func isPrivateIP(ip net.IP) bool {
+	for _, n := range privateNetworks {
+		if n.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+func parseCIDRs(cidrs ...string) []*net.IPNet {
+	nets := []*net.IPNet{}
+	for _, c := range cidrs {
+		_, n, err := net.ParseCIDR(c)
+		if err != nil {
+			panic(err)
+		}
+		nets = append(nets, n)
+	}
+	return nets
+}
@@ -0,0 +1,53 @@
+package webhook
+
+import (
+	"errors"
+	"net"
+	"testing"
+
+	"github.com/rhd-gitops-example/gitops-cli/pkg/pipelines/helper"
+)
+
+func TestCheckReach(t *testing.T) {
+	defer stubLookupIP(t, map[string]string{
+		"listener.internal.example.com": "10.0.12.34",
+		"listener.apps.example.com":     "203.0.113.10",
+	})()
+
+	reachTests := []struct {
+		name        string
+		gitRepoURL  string
+		listenerURL string
+		wantErr     string
+	}{
+		{"public repo and private listener", "https://github.com/my-org/gitops.git", "https://listener.internal.example.com",
+			"the EventListener at https://listener.internal.example.com resolves to the private address 10.0.12.34, github.com is unlikely to be able to deliver webhooks to it"},
+		{"public repo and public listener", "https://github.com/my-org/gitops.git", "https://listener.apps.example.com", ""},
+		{"private repo and private listener", "https://git.internal.example.com/my-org/gitops.git", "https://listener.internal.example.com", ""},
+		{"unresolvable listener", "https://gitlab.com/my-org/gitops.git", "https://unknown.example.com", ""},
+	}
+
+	for _, tt := range reachTests {
+		t.Run(tt.name, func(rt *testing.T) {
+			err := checkReach(tt.gitRepoURL, tt.listenerURL)
+			if !helper.ErrorMatch(rt, tt.wantErr, err) {
+				rt.Fatalf("got error %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func stubLookupIP(t *testing.T, addrs map[string]string) func() {
+	t.Helper()
+	f := lookupIP
+	lookupIP = func(host string) ([]net.IP, error) {
+		addr, ok := addrs[host]
+		if !ok {
+			return nil, errors.New("no such host")
+		}
+		return []net.IP{net.ParseIP(addr)}, nil
+	}
+	return func() {
+		lookupIP = f
+	}
+}
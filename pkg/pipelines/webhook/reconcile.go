@@ -0,0 +1,175 @@
+package webhook
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/rhd-gitops-example/gitops-cli/pkg/pipelines/git"
+)
+
+// Reconciliation describes the webhooks that Reconcile created and deleted,
+// each is described by the webhook ID and the repository URL.
+type Reconciliation struct {
+	Created []string `json:"created"`
+	Deleted []string `json:"deleted"`
+}
+
+// orphanedHooks are the webhooks on a repository that deliver to the
+// EventListener, but don't correspond to a service in the manifest.
+type orphanedHooks struct {
+	webhook *webhookInfo
+	ids     []string
+}
+
+// Reconcile creates the missing webhooks on the GitOps repository and the
+// source repositories of the services in the manifest, and deletes the
+// webhooks that deliver to the EventListener, but don't correspond to them.
+//
+// A managed repository only needs one webhook, any others that deliver to the
+// EventListener are deleted, as are all of those on the repoURLs that aren't
+// in the manifest e.g. the source repositories of removed services. Webhooks
+// that deliver elsewhere are left alone.
+//
+// The deletions are only made if they're confirmed.
+func Reconcile(accessToken string, credentials git.Credentials, pipelinesFile string, repoURLs []string, confirm func(deletions []string) bool) (*Reconciliation, error) {
+	manifest, err := loadManifest(pipelinesFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse pipelines: %v", err)
+	}
+	cfg := manifest.GetPipelinesConfig()
+	if cfg == nil {
+		return nil, errors.New("failed to get CICD environment")
+	}
+	clusterResources, err := newResources()
+	if err != nil {
+		return nil, err
+	}
+	listenerURL, err := getListenerURL(clusterResources, cfg.Name)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get event listener URL: %v", err)
+	}
+
+	newInfo := func(gitRepoURL string, serviceName *QualifiedServiceName, isCICD bool) (*webhookInfo, error) {
+		token := credentials.Token(gitRepoURL, accessToken)
+		repository, err := git.NewRepository(gitRepoURL, token)
+		if err != nil {
+			return nil, err
+		}
+		return &webhookInfo{
+			clusterResource: clusterResources,
+			repository:      repository,
+			gitRepoURL:      gitRepoURL,
+			cicdNamepace:    cfg.Name,
+			listenerURL:     listenerURL,
+			accessToken:     token,
+			serviceName:     serviceName,
+			isCICD:          isCICD,
+			gitOpsSecret:    cfg.WebhookSecret,
+		}, nil
+	}
+
+	// The first service with a source repository gets the webhook, as with
+	// Create, there's only one webhook for a repository.
+	seen := map[string]bool{}
+	managed := []*webhookInfo{}
+	if manifest.GitOpsURL != "" {
+		w, err := newInfo(manifest.GitOpsURL, nil, true)
+		if err != nil {
+			return nil, err
+		}
+		managed = append(managed, w)
+		seen[repoKey(manifest.GitOpsURL)] = true
+	}
+	for _, env := range manifest.Environments {
+		for _, app := range env.Apps {
+			for _, svc := range app.Services {
+				if svc.SourceURL == "" || seen[repoKey(svc.SourceURL)] {
+					continue
+				}
+				w, err := newInfo(svc.SourceURL, &QualifiedServiceName{EnvironmentName: env.Name, ServiceName: svc.Name}, false)
+				if err != nil {
+					return nil, err
+				}
+				managed = append(managed, w)
+				seen[repoKey(svc.SourceURL)] = true
+			}
+		}
+	}
+	unmanaged := []*webhookInfo{}
+	for _, u := range repoURLs {
+		if seen[repoKey(u)] {
+			continue
+		}
+		w, err := newInfo(u, nil, false)
+		if err != nil {
+			return nil, err
+		}
+		unmanaged = append(unmanaged, w)
+		seen[repoKey(u)] = true
+	}
+	return reconcile(managed, unmanaged, confirm)
+}
+
+func reconcile(managed, unmanaged []*webhookInfo, confirm func([]string) bool) (*Reconciliation, error) {
+	result := &Reconciliation{Created: []string{}, Deleted: []string{}}
+	orphaned := []orphanedHooks{}
+	for _, w := range managed {
+		ids, err := w.list()
+		if err != nil {
+			return nil, fmt.Errorf("failed to list webhooks on %s: %w", w.gitRepoURL, err)
+		}
+		if len(ids) == 0 {
+			id, err := w.create()
+			if err != nil {
+				return result, fmt.Errorf("failed to create webhook on %s: %w", w.gitRepoURL, err)
+			}
+			result.Created = append(result.Created, hookRef(w, id))
+			continue
+		}
+		if len(ids) > 1 {
+			orphaned = append(orphaned, orphanedHooks{webhook: w, ids: ids[1:]})
+		}
+	}
+	for _, w := range unmanaged {
+		ids, err := w.list()
+		if err != nil {
+			return nil, fmt.Errorf("failed to list webhooks on %s: %w", w.gitRepoURL, err)
+		}
+		if len(ids) > 0 {
+			orphaned = append(orphaned, orphanedHooks{webhook: w, ids: ids})
+		}
+	}
+	if len(orphaned) == 0 {
+		return result, nil
+	}
+
+	deletions := []string{}
+	for _, o := range orphaned {
+		for _, id := range o.ids {
+			deletions = append(deletions, fmt.Sprintf("Delete webhook %s delivering to %s", hookRef(o.webhook, id), o.webhook.listenerURL))
+		}
+	}
+	if !confirm(deletions) {
+		return result, nil
+	}
+	for _, o := range orphaned {
+		deleted, err := o.webhook.delete(o.ids)
+		for _, id := range deleted {
+			result.Deleted = append(result.Deleted, hookRef(o.webhook, id))
+		}
+		if err != nil {
+			return result, err
+		}
+	}
+	return result, nil
+}
+
+func hookRef(w *webhookInfo, id string) string {
+	return id + " on " + w.gitRepoURL
+}
+
+// repoKey identifies a repository, with or without the .git suffix.
+func repoKey(repoURL string) string {
+	return strings.TrimSuffix(repoURL, ".git")
+}
@@ -0,0 +1,112 @@
+package webhook
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/jenkins-x/go-scm/scm/factory"
+
+	"github.com/rhd-gitops-example/gitops-cli/pkg/pipelines/git"
+)
+
+const (
+	testListenerURL = "https://gitops-webhook.example.com"
+	testExternalURL = "https://ci.example.com/hooks"
+)
+
+func TestReconcileDeletesOrphanedWebhooks(t *testing.T) {
+	origID := factory.DefaultIdentifier
+	defer func() {
+		factory.DefaultIdentifier = origID
+	}()
+	factory.DefaultIdentifier = factory.NewDriverIdentifier(factory.Mapping("fake.com", "fake"))
+
+	gitops := newFakeWebhookInfo(t, "https://fake.com/org/gitops.git")
+	managedID := mustCreateWebhook(t, gitops.repository, testListenerURL)
+	removed := newFakeWebhookInfo(t, "https://fake.com/org/removed-service.git")
+	orphanedID := mustCreateWebhook(t, removed.repository, testListenerURL)
+	externalID := mustCreateWebhook(t, removed.repository, testExternalURL)
+
+	var confirmed []string
+	got, err := reconcile([]*webhookInfo{gitops}, []*webhookInfo{removed}, func(deletions []string) bool {
+		confirmed = deletions
+		return true
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := &Reconciliation{
+		Created: []string{},
+		Deleted: []string{orphanedID + " on https://fake.com/org/removed-service.git"},
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Fatalf("reconciliation mismatch got\n%s", diff)
+	}
+	wantConfirmed := []string{"Delete webhook " + orphanedID + " on https://fake.com/org/removed-service.git delivering to " + testListenerURL}
+	if diff := cmp.Diff(wantConfirmed, confirmed); diff != "" {
+		t.Fatalf("confirmed deletions mismatch got\n%s", diff)
+	}
+	assertWebhooks(t, gitops.repository, testListenerURL, []string{managedID})
+	assertWebhooks(t, removed.repository, testListenerURL, []string{})
+	assertWebhooks(t, removed.repository, testExternalURL, []string{externalID})
+}
+
+func TestReconcileWithoutConfirmationDeletesNothing(t *testing.T) {
+	origID := factory.DefaultIdentifier
+	defer func() {
+		factory.DefaultIdentifier = origID
+	}()
+	factory.DefaultIdentifier = factory.NewDriverIdentifier(factory.Mapping("fake.com", "fake"))
+
+	gitops := newFakeWebhookInfo(t, "https://fake.com/org/gitops.git")
+	managedID := mustCreateWebhook(t, gitops.repository, testListenerURL)
+	duplicateID := mustCreateWebhook(t, gitops.repository, testListenerURL)
+
+	got, err := reconcile([]*webhookInfo{gitops}, nil, func(deletions []string) bool {
+		return false
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if diff := cmp.Diff(&Reconciliation{Created: []string{}, Deleted: []string{}}, got); diff != "" {
+		t.Fatalf("reconciliation mismatch got\n%s", diff)
+	}
+	assertWebhooks(t, gitops.repository, testListenerURL, []string{managedID, duplicateID})
+}
+
+func newFakeWebhookInfo(t *testing.T, repoURL string) *webhookInfo {
+	t.Helper()
+	repo, err := git.NewRepository(repoURL, "token")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &webhookInfo{
+		repository:   repo,
+		gitRepoURL:   repoURL,
+		cicdNamepace: "cicd",
+		listenerURL:  testListenerURL,
+		isCICD:       true,
+	}
+}
+
+func mustCreateWebhook(t *testing.T, repo *git.Repository, listenerURL string) string {
+	t.Helper()
+	id, err := repo.CreateWebhook(listenerURL, "secret", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return id
+}
+
+func assertWebhooks(t *testing.T, repo *git.Repository, listenerURL string, want []string) {
+	t.Helper()
+	ids, err := repo.ListWebhooks(listenerURL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff(want, ids); diff != "" {
+		t.Fatalf("webhooks delivering to %s mismatch got\n%s", listenerURL, diff)
+	}
+}
@@ -3,6 +3,8 @@ package webhook
 import (
 	"errors"
 	"fmt"
+	"path/filepath"
+	"time"
 
 	"github.com/rhd-gitops-example/gitops-cli/pkg/pipelines/config"
 	"github.com/rhd-gitops-example/gitops-cli/pkg/pipelines/eventlisteners"
@@ -21,6 +23,11 @@ type webhookInfo struct {
 	accessToken     string
 	serviceName     *QualifiedServiceName
 	isCICD          bool
+	branchFilter    string
+	contentType     string // The content type of the webhook's payloads, by default JSON.
+	gitOpsSecret    string
+	dryRun          bool     // If true, the webhook is not created, the creation is recorded in planned.
+	planned         []string // The actions that would have been taken in a dry-run.
 }
 
 // QualifiedServiceName represents three part name of a service (Environment, Application, and Service)
@@ -31,28 +38,70 @@ type QualifiedServiceName struct {
 
 // Create creates a new webhook on the target Git Repository
 // It returns the ID of created webhook.
-func Create(accessToken, pipelinesFile string, serviceName *QualifiedServiceName, isCICD bool) (string, error) {
-	webhook, err := newWebhookInfo(accessToken, pipelinesFile, serviceName, isCICD)
+//
+// The pipelinesFile is the path of the manifest file, the other functions in
+// this package accept it too.
+//
+// If the credentials have a token for the repository's host, it's used instead
+// of the accessToken.
+//
+// If a branchFilter is provided, push events are only sent for matching
+// branches, this is only supported for GitLab repositories, GitLab webhooks
+// can't filter push events by the paths that they change.
+//
+// The contentType is the content type of the payloads, the EventListener
+// expects git.JSONContentType, which is used if it's empty.
+func Create(accessToken string, credentials git.Credentials, pipelinesFile string, serviceName *QualifiedServiceName, isCICD bool, branchFilter, contentType string) (string, error) {
+	webhook, err := newWebhookInfo(accessToken, credentials, pipelinesFile, serviceName, isCICD)
 	if err != nil {
 		return "", err
 	}
+	return webhook.createIfMissing(branchFilter, contentType)
+}
 
-	exists, err := webhook.exists()
+// ValidateApp validates that the GitHub App is installed for the target Git
+// repository, no webhook is created on the repository, as the app's webhook
+// delivers the events for all the repositories it's installed on.
+// It returns the EventListener URL that the app's webhook must deliver to.
+func ValidateApp(app *git.GitHubApp, pipelinesFile string, serviceName *QualifiedServiceName, isCICD bool) (string, error) {
+	webhook, err := newWebhookInfo("", nil, pipelinesFile, serviceName, isCICD)
 	if err != nil {
 		return "", err
 	}
+	if err := webhook.repository.ValidateAppInstallation(app); err != nil {
+		return "", err
+	}
+	return webhook.listenerURL, nil
+}
 
-	if exists {
-		return "", errors.New("webhook already exists")
+// Plan returns the actions that Create would take, without taking them, the
+// Git repository and cluster are only read.
+func Plan(accessToken string, credentials git.Credentials, pipelinesFile string, serviceName *QualifiedServiceName, isCICD bool, branchFilter, contentType string) ([]string, error) {
+	webhook, err := newWebhookInfo(accessToken, credentials, pipelinesFile, serviceName, isCICD)
+	if err != nil {
+		return nil, err
+	}
+	webhook.dryRun = true
+	if _, err := webhook.createIfMissing(branchFilter, contentType); err != nil {
+		return nil, err
 	}
+	return webhook.planned, nil
+}
 
-	return webhook.create()
+// Verify waits for the Git hosting service to deliver the ping for the
+// webhook with the id, and returns an error if the delivery failed.
+func Verify(accessToken string, credentials git.Credentials, pipelinesFile string, serviceName *QualifiedServiceName, isCICD bool, id string, timeout time.Duration) error {
+	webhook, err := newWebhookInfo(accessToken, credentials, pipelinesFile, serviceName, isCICD)
+	if err != nil {
+		return err
+	}
+	return webhook.repository.VerifyWebhook(id, timeout)
 }
 
 // Delete deletes webhooks on the target Git Repository that match the listener address
 // It returns the IDs of deleted webhooks.
-func Delete(accessToken, pipelinesFile string, serviceName *QualifiedServiceName, isCICD bool) ([]string, error) {
-	webhook, err := newWebhookInfo(accessToken, pipelinesFile, serviceName, isCICD)
+func Delete(accessToken string, credentials git.Credentials, pipelinesFile string, serviceName *QualifiedServiceName, isCICD bool) ([]string, error) {
+	webhook, err := newWebhookInfo(accessToken, credentials, pipelinesFile, serviceName, isCICD)
 	if err != nil {
 		return nil, err
 	}
@@ -66,8 +115,8 @@ func Delete(accessToken, pipelinesFile string, serviceName *QualifiedServiceName
 }
 
 // List returns an array of webhook IDs for the target Git repository/listeners
-func List(accessToken, pipelinesFile string, serviceName *QualifiedServiceName, isCICD bool) ([]string, error) {
-	webhook, err := newWebhookInfo(accessToken, pipelinesFile, serviceName, isCICD)
+func List(accessToken string, credentials git.Credentials, pipelinesFile string, serviceName *QualifiedServiceName, isCICD bool) ([]string, error) {
+	webhook, err := newWebhookInfo(accessToken, credentials, pipelinesFile, serviceName, isCICD)
 	if err != nil {
 		return nil, err
 	}
@@ -75,8 +124,49 @@ func List(accessToken, pipelinesFile string, serviceName *QualifiedServiceName,
 	return webhook.list()
 }
 
-func newWebhookInfo(accessToken, pipelinesFile string, serviceName *QualifiedServiceName, isCICD bool) (*webhookInfo, error) {
-	manifest, err := config.LoadManifest(ioutils.NewFilesystem(), pipelinesFile)
+// ListFailing returns the IDs of the webhooks for the target Git
+// repository/listeners whose most recent delivery failed.
+//
+// If since isn't zero, only webhooks whose most recent delivery was within
+// that duration are returned.
+func ListFailing(accessToken string, credentials git.Credentials, pipelinesFile string, serviceName *QualifiedServiceName, isCICD bool, since time.Duration) ([]string, error) {
+	webhook, err := newWebhookInfo(accessToken, credentials, pipelinesFile, serviceName, isCICD)
+	if err != nil {
+		return nil, err
+	}
+	ids, err := webhook.list()
+	if err != nil {
+		return nil, err
+	}
+	return failingWebhooks(webhook.repository, ids, since, time.Now())
+}
+
+// deliveryRecorder is implemented by repositories that record the deliveries
+// to their webhooks.
+type deliveryRecorder interface {
+	LastDelivery(id string) (*git.HookDelivery, error)
+}
+
+func failingWebhooks(r deliveryRecorder, ids []string, since time.Duration, now time.Time) ([]string, error) {
+	failing := []string{}
+	for _, id := range ids {
+		last, err := r.LastDelivery(id)
+		if err != nil {
+			return nil, err
+		}
+		if last == nil || !last.Failed() {
+			continue
+		}
+		if since != 0 && last.DeliveredAt.Before(now.Add(-since)) {
+			continue
+		}
+		failing = append(failing, id)
+	}
+	return failing, nil
+}
+
+func newWebhookInfo(accessToken string, credentials git.Credentials, pipelinesFile string, serviceName *QualifiedServiceName, isCICD bool) (*webhookInfo, error) {
+	manifest, err := loadManifest(pipelinesFile)
 	if err != nil {
 		return nil, fmt.Errorf("failed to parse pipelines: %v", err)
 	}
@@ -97,6 +187,8 @@ func newWebhookInfo(accessToken, pipelinesFile string, serviceName *QualifiedSer
 		return nil, err
 	}
 
+	// The access token is only used if there's no credential for the host.
+	accessToken = credentials.Token(gitRepoURL, accessToken)
 	repository, err := git.NewRepository(gitRepoURL, accessToken)
 	if err != nil {
 		return nil, err
@@ -107,7 +199,17 @@ func newWebhookInfo(accessToken, pipelinesFile string, serviceName *QualifiedSer
 		return nil, fmt.Errorf("failed to get event listener URL: %v", err)
 	}
 
-	return &webhookInfo{clusterResources, repository, gitRepoURL, cicdNamepace, listenerURL, accessToken, serviceName, isCICD}, nil
+	return &webhookInfo{
+		clusterResource: clusterResources,
+		repository:      repository,
+		gitRepoURL:      gitRepoURL,
+		cicdNamepace:    cicdNamepace,
+		listenerURL:     listenerURL,
+		accessToken:     accessToken,
+		serviceName:     serviceName,
+		isCICD:          isCICD,
+		gitOpsSecret:    cfg.WebhookSecret,
+	}, nil
 }
 
 func (w *webhookInfo) exists() (bool, error) {
@@ -127,13 +229,52 @@ func (w *webhookInfo) delete(ids []string) ([]string, error) {
 	return w.repository.DeleteWebhooks(ids)
 }
 
+func (w *webhookInfo) createIfMissing(branchFilter, contentType string) (string, error) {
+	if branchFilter != "" {
+		if err := w.repository.ValidateBranchFilter(); err != nil {
+			return "", err
+		}
+		w.branchFilter = branchFilter
+	}
+	if err := w.repository.ValidateContentType(contentType); err != nil {
+		return "", err
+	}
+	w.contentType = contentType
+
+	exists, err := w.exists()
+	if err != nil {
+		return "", err
+	}
+
+	if exists {
+		return "", errors.New("webhook already exists")
+	}
+
+	return w.create()
+}
+
 func (w *webhookInfo) create() (string, error) {
-	secret, err := getWebhookSecret(w.clusterResource, w.cicdNamepace, w.isCICD, w.serviceName)
+	secretName := webhookSecretName(w.isCICD, w.serviceName, w.gitOpsSecret)
+	if w.dryRun {
+		action := fmt.Sprintf("Create a webhook on %s delivering to %s, authenticated with the secret %s/%s", w.gitRepoURL, w.listenerURL, w.cicdNamepace, secretName)
+		if w.branchFilter != "" {
+			action += fmt.Sprintf(", for branches matching %s", w.branchFilter)
+		}
+		if w.contentType != "" && w.contentType != git.JSONContentType {
+			action += fmt.Sprintf(", with %s payloads", w.contentType)
+		}
+		w.planned = append(w.planned, action)
+		return "", nil
+	}
+	secret, err := w.clusterResource.getWebhookSecret(w.cicdNamepace, secretName, eventlisteners.WebhookSecretKey)
 	if err != nil {
 		return "", fmt.Errorf("failed to get webhook secret: %v", err)
 	}
 
-	return w.repository.CreateWebhook(w.listenerURL, secret)
+	if w.branchFilter != "" {
+		return w.repository.CreateWebhookWithBranchFilter(w.listenerURL, secret, w.branchFilter)
+	}
+	return w.repository.CreateWebhook(w.listenerURL, secret, w.contentType)
 }
 
 // Get Git repository URL whether it is CICD configuration or service source repository
@@ -180,14 +321,17 @@ func buildURL(host string, hasTLS bool) string {
 	return scheme + "://" + host
 }
 
-func getWebhookSecret(r *resources, namespace string, isCICD bool, service *QualifiedServiceName) (string, error) {
-	var secretName string
+func webhookSecretName(isCICD bool, service *QualifiedServiceName, gitOpsSecret string) string {
 	if isCICD {
-		secretName = eventlisteners.GitOpsWebhookSecret
-	} else {
-		// currently, use the app name to create webhook secret name.
-		// also currently, service webhook secret are in CICI namespace
-		secretName = secrets.MakeServiceWebhookSecretName(service.EnvironmentName, service.ServiceName)
+		return eventlisteners.WebhookSecretName(gitOpsSecret)
 	}
-	return r.getWebhookSecret(namespace, secretName, eventlisteners.WebhookSecretKey)
+	// currently, use the app name to create webhook secret name.
+	// also currently, service webhook secret are in CICI namespace
+	return secrets.MakeServiceWebhookSecretName(service.EnvironmentName, service.ServiceName)
+}
+
+// loadManifest loads the manifest from the pipelinesFile, the path of the
+// manifest file e.g. gitops/pipelines.yaml.
+func loadManifest(pipelinesFile string) (*config.Manifest, error) {
+	return config.LoadManifestFile(ioutils.NewFilesystem(), filepath.Dir(pipelinesFile), filepath.Base(pipelinesFile))
 }
@@ -3,9 +3,12 @@ package webhook
 import (
 	"fmt"
 	"testing"
+	"time"
 
 	"github.com/google/go-cmp/cmp"
+	"github.com/jenkins-x/go-scm/scm/factory"
 	"github.com/rhd-gitops-example/gitops-cli/pkg/pipelines/config"
+	"github.com/rhd-gitops-example/gitops-cli/pkg/pipelines/git"
 )
 
 func TestBuildURL(t *testing.T) {
@@ -127,3 +130,98 @@ func TestGetGitRepoURL(t *testing.T) {
 		})
 	}
 }
+
+func TestCreateIfMissingWithDryRun(t *testing.T) {
+	origID := factory.DefaultIdentifier
+	defer func() {
+		factory.DefaultIdentifier = origID
+	}()
+	factory.DefaultIdentifier = factory.NewDriverIdentifier(factory.Mapping("fake.com", "fake"))
+	repo, err := git.NewRepository("https://fake.com/foo/bar.git", "token")
+	if err != nil {
+		t.Fatal(err)
+	}
+	// There's no clusterResource, so fetching the secret would fail.
+	w := &webhookInfo{
+		repository:   repo,
+		gitRepoURL:   "https://fake.com/foo/bar.git",
+		cicdNamepace: "cicd",
+		listenerURL:  "https://gitops-webhook.example.com",
+		isCICD:       true,
+		dryRun:       true,
+	}
+
+	id, err := w.createIfMissing("", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if id != "" {
+		t.Fatalf("got webhook id %q in a dry-run", id)
+	}
+	want := []string{
+		"Create a webhook on https://fake.com/foo/bar.git delivering to https://gitops-webhook.example.com, authenticated with the secret cicd/gitops-webhook-secret",
+	}
+	if diff := cmp.Diff(want, w.planned); diff != "" {
+		t.Fatalf("planned actions mismatch got\n%s", diff)
+	}
+	ids, err := repo.ListWebhooks(w.listenerURL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(ids) > 0 {
+		t.Fatalf("dry-run created webhooks %v", ids)
+	}
+}
+
+func TestFailingWebhooks(t *testing.T) {
+	now := time.Date(2020, time.August, 10, 12, 0, 0, 0, time.UTC)
+	deliveries := fakeDeliveries{
+		"1": {DeliveredAt: now.Add(-time.Minute), Status: "OK", StatusCode: 200},
+		"2": {DeliveredAt: now.Add(-time.Minute), Status: "Service Unavailable", StatusCode: 503},
+		"3": {DeliveredAt: now.Add(-48 * time.Hour), Status: "Not Found", StatusCode: 404},
+		"4": {DeliveredAt: now.Add(-time.Hour), Status: "Invalid HTTP Response: connection refused"},
+		"5": nil,
+	}
+	ids := []string{"1", "2", "3", "4", "5"}
+
+	failingTests := []struct {
+		since time.Duration
+		want  []string
+	}{
+		{0, []string{"2", "3", "4"}},
+		{24 * time.Hour, []string{"2", "4"}},
+		{30 * time.Minute, []string{"2"}},
+	}
+
+	for _, tt := range failingTests {
+		t.Run(fmt.Sprintf("since %s", tt.since), func(rt *testing.T) {
+			got, err := failingWebhooks(deliveries, ids, tt.since, now)
+			if err != nil {
+				rt.Fatal(err)
+			}
+			if diff := cmp.Diff(tt.want, got); diff != "" {
+				rt.Fatalf("failing webhooks mismatch got\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestFailingWebhooksWithUnknownWebhook(t *testing.T) {
+	_, err := failingWebhooks(fakeDeliveries{}, []string{"6"}, 0, time.Now())
+
+	if err == nil || err.Error() != "unknown webhook 6" {
+		t.Fatalf("got error %v, want unknown webhook 6", err)
+	}
+}
+
+// fakeDeliveries returns the last delivery for each webhook ID.
+type fakeDeliveries map[string]*git.HookDelivery
+
+func (f fakeDeliveries) LastDelivery(id string) (*git.HookDelivery, error) {
+	d, ok := f[id]
+	if !ok {
+		return nil, fmt.Errorf("unknown webhook %s", id)
+	}
+	return d, nil
+}
@@ -0,0 +1,66 @@
+package yaml
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"path/filepath"
+	"time"
+
+	"github.com/spf13/afero"
+)
+
+// WriteArchive marshals the values to YAML, like WriteResources, but writes
+// them as entries in a gzip compressed tar archive at filename, rather than
+// to a directory tree, the paths of the entries are the keys of the files.
+//
+// The entries are written in sorted order, with a fixed modification time, so
+// that the same files always produce the same archive.
+//
+// It returns the list of filenames written to the archive, in sorted order.
+func WriteArchive(fs afero.Fs, filename string, files map[string]interface{}) ([]string, error) {
+	if err := fs.MkdirAll(filepath.Dir(filename), 0755); err != nil {
+		return nil, fmt.Errorf("failed to create the directory for %s: %w", filename, err)
+	}
+	f, err := fs.Create(filename)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create archive %s: %w", filename, err)
+	}
+	defer f.Close()
+	written, err := writeArchive(f, files)
+	if err != nil {
+		return nil, fmt.Errorf("failed to write archive %s: %w", filename, err)
+	}
+	return written, f.Close()
+}
+
+func writeArchive(out io.Writer, files map[string]interface{}) ([]string, error) {
+	gz := gzip.NewWriter(out)
+	tw := tar.NewWriter(gz)
+	written := []string{}
+	for _, filename := range sortedFilenames(files) {
+		data, err := Marshal(files[filename])
+		if err != nil {
+			return nil, fmt.Errorf("failed to marshal data for %s: %v", filename, err)
+		}
+		hdr := &tar.Header{
+			Name:     filepath.ToSlash(filename),
+			Mode:     0644,
+			Size:     int64(len(data)),
+			ModTime:  time.Unix(0, 0),
+			Typeflag: tar.TypeReg,
+		}
+		if err := tw.WriteHeader(hdr); err != nil {
+			return nil, err
+		}
+		if _, err := tw.Write(data); err != nil {
+			return nil, err
+		}
+		written = append(written, filename)
+	}
+	if err := tw.Close(); err != nil {
+		return nil, err
+	}
+	return written, gz.Close()
+}
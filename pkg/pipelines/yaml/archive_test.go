@@ -0,0 +1,62 @@
+package yaml
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"io"
+	"io/ioutil"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	res "github.com/rhd-gitops-example/gitops-cli/pkg/pipelines/resources"
+	"github.com/spf13/afero"
+)
+
+func TestWriteArchive(t *testing.T) {
+	fs := afero.NewMemMapFs()
+	files := map[string]interface{}{
+		"environments/dev/kustomization.yaml": res.Kustomization{Resources: []string{"02-service.yaml", "01-namespace.yaml"}},
+		"config/labels.yaml":                  map[string]interface{}{"team": "payments", "app": "api"},
+	}
+
+	written, err := WriteArchive(fs, "/tmp/out/gitops.tgz", files)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff([]string{"config/labels.yaml", "environments/dev/kustomization.yaml"}, written); diff != "" {
+		t.Fatalf("written filenames failed:\n%s", diff)
+	}
+
+	f, err := fs.Open("/tmp/out/gitops.tgz")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	gz, err := gzip.NewReader(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tr := tar.NewReader(gz)
+	got := map[string]string{}
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		b, err := ioutil.ReadAll(tr)
+		if err != nil {
+			t.Fatal(err)
+		}
+		got[hdr.Name] = string(b)
+	}
+	want := map[string]string{
+		"config/labels.yaml":                  "app: api\nteam: payments\n",
+		"environments/dev/kustomization.yaml": "resources:\n- 01-namespace.yaml\n- 02-service.yaml\n",
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Fatalf("archive entries failed:\n%s", diff)
+	}
+}
@@ -0,0 +1,60 @@
+package yaml
+
+import (
+	"sort"
+
+	res "github.com/rhd-gitops-example/gitops-cli/pkg/pipelines/resources"
+	"sigs.k8s.io/yaml"
+)
+
+// Marshal marshals the item to YAML in a canonical form, so that the same item
+// always produces byte-identical output.
+//
+// The YAML is converted from the JSON encoding, and the keys of the resulting
+// mappings are sorted, the resources and bases of Kustomizations are also
+// sorted, as their order isn't significant.
+func Marshal(item interface{}) ([]byte, error) {
+	return yaml.Marshal(canonicalize(item))
+}
+
+func canonicalize(item interface{}) interface{} {
+	switch v := item.(type) {
+	case res.Kustomization:
+		return sortedKustomization(v)
+	case *res.Kustomization:
+		if v == nil {
+			return v
+		}
+		k := sortedKustomization(*v)
+		return &k
+	}
+	return item
+}
+
+func sortedKustomization(k res.Kustomization) res.Kustomization {
+	k.Resources = sortedStrings(k.Resources)
+	k.Bases = sortedStrings(k.Bases)
+	return k
+}
+
+// sortedStrings returns a sorted copy of s, so that the item being marshaled
+// isn't modified.
+func sortedStrings(s []string) []string {
+	if s == nil {
+		return nil
+	}
+	sorted := append([]string{}, s...)
+	sort.Strings(sorted)
+	return sorted
+}
+
+// sortedFilenames returns the filenames of the files in order, so that they're
+// written and reported in the same order each time.
+func sortedFilenames(files map[string]interface{}) []string {
+	filenames := make([]string, 0, len(files))
+	for filename := range files {
+		filenames = append(filenames, filename)
+	}
+	sort.Strings(filenames)
+	return filenames
+}
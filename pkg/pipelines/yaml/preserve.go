@@ -0,0 +1,254 @@
+package yaml
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+
+	"github.com/spf13/afero"
+	yamlv3 "gopkg.in/yaml.v3"
+)
+
+// MarshalItemToFilePreserving is like MarshalItemToFile, but if the file
+// already exists, only the parts of the document that differ from the item are
+// rewritten, this preserves comments, anchors and aliases in the rest of the
+// file.
+func MarshalItemToFilePreserving(fs afero.Fs, filename string, item interface{}) error {
+	original, err := afero.ReadFile(fs, filename)
+	if err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to read %s: %v", filename, err)
+	}
+	data, err := MergeDocument(original, item)
+	if err != nil {
+		return fmt.Errorf("failed to update %s: %v", filename, err)
+	}
+	err = fs.MkdirAll(filepath.Dir(filename), 0755)
+	if err != nil {
+		return fmt.Errorf("failed to MkDirAll for %s: %v", filename, err)
+	}
+	return afero.WriteFile(fs, filename, data, 0644)
+}
+
+// MergeDocument marshals the item to YAML, reusing the nodes from the original
+// document where the values are unchanged.
+//
+// If the original is empty, this is the same as marshaling the item.
+func MergeDocument(original []byte, item interface{}) ([]byte, error) {
+	data, err := Marshal(item)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal data: %v", err)
+	}
+	if len(bytes.TrimSpace(original)) == 0 {
+		return data, nil
+	}
+	var dst, src yamlv3.Node
+	if err := yamlv3.Unmarshal(original, &dst); err != nil {
+		return nil, err
+	}
+	if err := yamlv3.Unmarshal(data, &src); err != nil {
+		return nil, err
+	}
+	if len(dst.Content) == 0 || len(src.Content) == 0 {
+		return data, nil
+	}
+	if err := patchNode(dst.Content[0], src.Content[0]); err != nil {
+		return nil, err
+	}
+	inlineDanglingAliases(&dst, map[*yamlv3.Node]bool{})
+	var buf bytes.Buffer
+	enc := yamlv3.NewEncoder(&buf)
+	enc.SetIndent(2)
+	if err := enc.Encode(&dst); err != nil {
+		return nil, err
+	}
+	if err := enc.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// patchNode updates dst so that it decodes to the same value as src, leaving
+// any parts of dst that already match untouched.
+func patchNode(dst, src *yamlv3.Node) error {
+	dstValue, err := decodeNode(dst)
+	if err != nil {
+		return err
+	}
+	srcValue, err := decodeNode(src)
+	if err != nil {
+		return err
+	}
+	if reflect.DeepEqual(dstValue, srcValue) {
+		return nil
+	}
+	switch {
+	case dst.Kind == yamlv3.MappingNode && src.Kind == yamlv3.MappingNode:
+		return patchMapping(dst, src, dstValue)
+	case dst.Kind == yamlv3.SequenceNode && src.Kind == yamlv3.SequenceNode:
+		return patchSequence(dst, src)
+	}
+	replaceNode(dst, src)
+	return nil
+}
+
+func patchMapping(dst, src *yamlv3.Node, dstValue interface{}) error {
+	// This includes the values from any merge keys e.g. <<: *defaults
+	merged, _ := dstValue.(map[string]interface{})
+	for i := 0; i+1 < len(src.Content); i += 2 {
+		key, value := src.Content[i], src.Content[i+1]
+		if existing := mappingValue(dst, key.Value); existing != nil {
+			if err := patchNode(existing, value); err != nil {
+				return err
+			}
+			continue
+		}
+		v, err := decodeNode(value)
+		if err != nil {
+			return err
+		}
+		if mv, ok := merged[key.Value]; ok && reflect.DeepEqual(mv, v) {
+			continue
+		}
+		dst.Content = append(dst.Content, key, value)
+	}
+
+	content := []*yamlv3.Node{}
+	for i := 0; i+1 < len(dst.Content); i += 2 {
+		key := dst.Content[i].Value
+		if key == "<<" || mappingValue(src, key) != nil {
+			content = append(content, dst.Content[i], dst.Content[i+1])
+		}
+	}
+	dst.Content = content
+	return nil
+}
+
+// patchSequence matches items by name where they have one, and by position
+// otherwise, the resulting order is the order of the items in src.
+func patchSequence(dst, src *yamlv3.Node) error {
+	used := map[*yamlv3.Node]bool{}
+	content := []*yamlv3.Node{}
+	for i, item := range src.Content {
+		existing := matchingItem(dst, item, i)
+		if existing == nil || used[existing] {
+			content = append(content, item)
+			continue
+		}
+		used[existing] = true
+		if err := patchNode(existing, item); err != nil {
+			return err
+		}
+		content = append(content, existing)
+	}
+	dst.Content = content
+	return nil
+}
+
+func matchingItem(seq, item *yamlv3.Node, i int) *yamlv3.Node {
+	if name := nodeName(item); name != "" {
+		for _, n := range seq.Content {
+			if nodeName(n) == name {
+				return n
+			}
+		}
+		return nil
+	}
+	if i < len(seq.Content) {
+		return seq.Content[i]
+	}
+	return nil
+}
+
+func nodeName(n *yamlv3.Node) string {
+	if n.Kind == yamlv3.AliasNode {
+		n = n.Alias
+	}
+	if v := mappingValue(n, "name"); v != nil && v.Kind == yamlv3.ScalarNode {
+		return v.Value
+	}
+	return ""
+}
+
+func mappingValue(n *yamlv3.Node, key string) *yamlv3.Node {
+	if n.Kind != yamlv3.MappingNode {
+		return nil
+	}
+	for i := 0; i+1 < len(n.Content); i += 2 {
+		if n.Content[i].Value == key {
+			return n.Content[i+1]
+		}
+	}
+	return nil
+}
+
+// replaceNode replaces the dst node with src, keeping the comments from dst.
+func replaceNode(dst, src *yamlv3.Node) {
+	head, line, foot := dst.HeadComment, dst.LineComment, dst.FootComment
+	*dst = *src
+	dst.HeadComment, dst.LineComment, dst.FootComment = head, line, foot
+}
+
+// decodeNode decodes n, mappings are always decoded to map[string]interface{},
+// as mappings with merge keys are otherwise decoded to
+// map[interface{}]interface{}.
+func decodeNode(n *yamlv3.Node) (interface{}, error) {
+	var v interface{}
+	if err := n.Decode(&v); err != nil {
+		return nil, err
+	}
+	return normalizeValue(v), nil
+}
+
+func normalizeValue(v interface{}) interface{} {
+	switch v := v.(type) {
+	case map[interface{}]interface{}:
+		m := map[string]interface{}{}
+		for k, mv := range v {
+			m[fmt.Sprint(k)] = normalizeValue(mv)
+		}
+		return m
+	case map[string]interface{}:
+		for k, mv := range v {
+			v[k] = normalizeValue(mv)
+		}
+	case []interface{}:
+		for i, sv := range v {
+			v[i] = normalizeValue(sv)
+		}
+	}
+	return v
+}
+
+// inlineDanglingAliases replaces aliases to nodes whose anchor is no longer
+// defined before the alias, e.g. because the anchored item was removed, with a
+// copy of the node.
+//
+// The tag of merge keys is also cleared, so that they're written as <<: rather
+// than !!merge <<:.
+func inlineDanglingAliases(n *yamlv3.Node, anchored map[*yamlv3.Node]bool) {
+	switch {
+	case n.Kind == yamlv3.AliasNode && !anchored[n.Alias]:
+		*n = *copyNode(n.Alias)
+	case n.Kind == yamlv3.ScalarNode && n.Value == "<<" && n.Tag == "!!merge":
+		n.Tag = ""
+	}
+	if n.Anchor != "" {
+		anchored[n] = true
+	}
+	for _, c := range n.Content {
+		inlineDanglingAliases(c, anchored)
+	}
+}
+
+// copyNode returns a deep copy of n without any anchors.
+func copyNode(n *yamlv3.Node) *yamlv3.Node {
+	c := *n
+	c.Anchor = ""
+	c.Content = make([]*yamlv3.Node, len(n.Content))
+	for i, child := range n.Content {
+		c.Content[i] = copyNode(child)
+	}
+	return &c
+}
@@ -0,0 +1,110 @@
+package yaml
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/spf13/afero"
+	"sigs.k8s.io/yaml"
+)
+
+const anchoredDocument = `# The environments share a cluster.
+environments:
+- &defaults
+  name: dev
+  cluster: &cluster https://dev.example.com
+- <<: *defaults
+  name: stage
+- name: prod
+  cluster: *cluster
+`
+
+type testEnvironment struct {
+	Name    string `json:"name"`
+	Cluster string `json:"cluster,omitempty"`
+}
+
+type testManifest struct {
+	Environments []testEnvironment `json:"environments"`
+}
+
+func TestMergeDocumentPreservesAnchors(t *testing.T) {
+	m := parseTestManifest(t, anchoredDocument)
+	m.Environments = append(m.Environments, testEnvironment{Name: "test"})
+
+	b, err := MergeDocument([]byte(anchoredDocument), m)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := anchoredDocument + "- name: test\n"
+	if diff := cmp.Diff(want, string(b)); diff != "" {
+		t.Fatalf("merged document failed:\n%s", diff)
+	}
+	if diff := cmp.Diff(m, parseTestManifest(t, string(b))); diff != "" {
+		t.Fatalf("merged document values failed:\n%s", diff)
+	}
+}
+
+func TestMergeDocumentWithChangedValues(t *testing.T) {
+	m := parseTestManifest(t, anchoredDocument)
+	m.Environments[2].Cluster = "https://prod.example.com"
+	m.Environments = m.Environments[1:]
+
+	b, err := MergeDocument([]byte(anchoredDocument), m)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if diff := cmp.Diff(m, parseTestManifest(t, string(b))); diff != "" {
+		t.Fatalf("merged document values failed:\n%s", diff)
+	}
+	if !strings.HasPrefix(string(b), "# The environments share a cluster.\n") {
+		t.Fatalf("merged document lost the leading comment:\n%s", b)
+	}
+}
+
+func TestMergeDocumentWithNoOriginal(t *testing.T) {
+	m := testManifest{Environments: []testEnvironment{{Name: "dev"}}}
+
+	b, err := MergeDocument(nil, m)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := "environments:\n- name: dev\n"
+	if diff := cmp.Diff(want, string(b)); diff != "" {
+		t.Fatalf("merged document failed:\n%s", diff)
+	}
+}
+
+func TestMarshalItemToFilePreserving(t *testing.T) {
+	fs := afero.NewMemMapFs()
+	filename := "/tmp/gitops/pipelines.yaml"
+	if err := afero.WriteFile(fs, filename, []byte(anchoredDocument), 0644); err != nil {
+		t.Fatal(err)
+	}
+	m := parseTestManifest(t, anchoredDocument)
+
+	if err := MarshalItemToFilePreserving(fs, filename, m); err != nil {
+		t.Fatal(err)
+	}
+
+	b, err := afero.ReadFile(fs, filename)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff(anchoredDocument, string(b)); diff != "" {
+		t.Fatalf("unchanged document was rewritten:\n%s", diff)
+	}
+}
+
+func parseTestManifest(t *testing.T, s string) testManifest {
+	t.Helper()
+	var m testManifest
+	if err := yaml.Unmarshal([]byte(s), &m); err != nil {
+		t.Fatal(err)
+	}
+	return m
+}
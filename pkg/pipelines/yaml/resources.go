@@ -1,23 +1,23 @@
 package yaml
 
 import (
+	"bytes"
 	"fmt"
 	"io"
 	"path/filepath"
 
 	"github.com/spf13/afero"
-	"sigs.k8s.io/yaml"
 )
 
 // WriteResources takes a prefix path, and a map of paths to values, and will
 // marshal the values to the filenames as YAML resources, joining the prefix to
 // the filenames before writing.
 //
-// It returns the list of filenames written out.
+// It returns the list of filenames written out, in sorted order.
 func WriteResources(fs afero.Fs, path string, files map[string]interface{}) ([]string, error) {
 	filenames := make([]string, 0)
-	for filename, item := range files {
-		err := MarshalItemToFile(fs, filepath.Join(path, filename), item)
+	for _, filename := range sortedFilenames(files) {
+		err := MarshalItemToFile(fs, filepath.Join(path, filename), files[filename])
 		if err != nil {
 			return nil, err
 		}
@@ -26,6 +26,34 @@ func WriteResources(fs afero.Fs, path string, files map[string]interface{}) ([]s
 	return filenames, nil
 }
 
+// WriteChangedResources is like WriteResources, but files that already have
+// the marshaled content are not rewritten, so that unchanged files aren't
+// touched.
+//
+// It returns the filenames written out, and the filenames that were unchanged.
+func WriteChangedResources(fs afero.Fs, path string, files map[string]interface{}) ([]string, []string, error) {
+	written := []string{}
+	unchanged := []string{}
+	for _, filename := range sortedFilenames(files) {
+		item := files[filename]
+		data, err := Marshal(item)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to marshal data: %v", err)
+		}
+		existing, err := afero.ReadFile(fs, filepath.Join(path, filename))
+		if err == nil && bytes.Equal(existing, data) {
+			unchanged = append(unchanged, filename)
+			continue
+		}
+		err = MarshalItemToFile(fs, filepath.Join(path, filename), item)
+		if err != nil {
+			return nil, nil, err
+		}
+		written = append(written, filename)
+	}
+	return written, unchanged, nil
+}
+
 // MarshalItemToFile marshals item to file
 func MarshalItemToFile(fs afero.Fs, filename string, item interface{}) error {
 	err := fs.MkdirAll(filepath.Dir(filename), 0755)
@@ -42,7 +70,7 @@ func MarshalItemToFile(fs afero.Fs, filename string, item interface{}) error {
 
 // MarshalOutput marshal output to given writer
 func MarshalOutput(out io.Writer, output interface{}) error {
-	data, err := yaml.Marshal(output)
+	data, err := Marshal(output)
 	if err != nil {
 		return fmt.Errorf("failed to marshal data: %v", err)
 	}
@@ -0,0 +1,73 @@
+package yaml
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	res "github.com/rhd-gitops-example/gitops-cli/pkg/pipelines/resources"
+	"github.com/spf13/afero"
+)
+
+func TestWriteResourcesIsReproducible(t *testing.T) {
+	files := func() map[string]interface{} {
+		return map[string]interface{}{
+			"config/kustomization.yaml": &res.Kustomization{
+				Resources: []string{"03-service.yaml", "01-namespace.yaml", "02-deployment.yaml"},
+				Bases:     []string{"../stage", "../dev"},
+			},
+			"config/labels.yaml": map[string]interface{}{
+				"zone": "b", "app": "api", "tier": "backend", "env": "dev", "team": "payments",
+			},
+			"environments/kustomization.yaml": res.Kustomization{Resources: []string{"stage", "dev"}},
+		}
+	}
+
+	write := func() (afero.Fs, []string) {
+		fs := afero.NewMemMapFs()
+		filenames, err := WriteResources(fs, "/tmp/gitops", files())
+		if err != nil {
+			t.Fatal(err)
+		}
+		return fs, filenames
+	}
+	first, firstFilenames := write()
+	second, secondFilenames := write()
+
+	if diff := cmp.Diff(firstFilenames, secondFilenames); diff != "" {
+		t.Fatalf("written filenames differ:\n%s", diff)
+	}
+	for _, filename := range firstFilenames {
+		path := filepath.Join("/tmp/gitops", filename)
+		a, err := afero.ReadFile(first, path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		b, err := afero.ReadFile(second, path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if diff := cmp.Diff(string(a), string(b)); diff != "" {
+			t.Fatalf("%s differs between runs:\n%s", filename, diff)
+		}
+	}
+
+	want := "bases:\n- ../dev\n- ../stage\nresources:\n- 01-namespace.yaml\n- 02-deployment.yaml\n- 03-service.yaml\n"
+	got, err := afero.ReadFile(first, "/tmp/gitops/config/kustomization.yaml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff(want, string(got)); diff != "" {
+		t.Fatalf("kustomization was not canonicalized:\n%s", diff)
+	}
+}
+
+func TestMarshalDoesNotModifyItem(t *testing.T) {
+	k := &res.Kustomization{Resources: []string{"b.yaml", "a.yaml"}}
+	if _, err := Marshal(k); err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff([]string{"b.yaml", "a.yaml"}, k.Resources); diff != "" {
+		t.Fatalf("Marshal modified the item:\n%s", diff)
+	}
+}